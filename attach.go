@@ -0,0 +1,48 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// AttachSelf moves the calling process, including all of its threads, into
+// the cgroup. It is intended for shims and init-like processes that place
+// themselves in a cgroup before starting any workload.
+func AttachSelf(c Cgroup) error {
+	return c.Add(Process{Pid: os.Getpid()})
+}
+
+// AttachCurrentThread locks the calling goroutine to its OS thread and moves
+// only that thread into the cgroup.
+//
+// On success the goroutine is left locked to the thread so that the Go
+// runtime does not schedule unrelated work on a thread in a different cgroup.
+// The caller should not call runtime.UnlockOSThread; instead letting the
+// goroutine exit while locked causes the runtime to terminate the thread.
+// On failure the thread is unlocked again.
+func AttachCurrentThread(c Cgroup) error {
+	runtime.LockOSThread()
+	if err := c.AddTask(Process{Pid: unix.Gettid()}); err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestAuditSink(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	var buf bytes.Buffer
+	SetAuditSink(NewAuditWriter(&buf))
+	defer SetAuditSink(nil)
+
+	pids := NewPids(mock.root)
+	if err := pids.Create("test", &specs.LinuxResources{
+		Pids: &specs.LinuxPids{Limit: 10},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var r AuditRecord
+	if err := json.NewDecoder(&buf).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(mock.root, "pids", "test", "pids.max"); r.Path != expected {
+		t.Errorf("expected path %q but received %q", expected, r.Path)
+	}
+	if r.Value != "10" {
+		t.Errorf("expected value 10 but received %q", r.Value)
+	}
+	if r.Time.IsZero() || r.Error != "" {
+		t.Errorf("unexpected record %+v", r)
+	}
+}
@@ -0,0 +1,29 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package auto applies the cgroup limits of the process to the Go runtime
+// when imported:
+//
+//	import _ "github.com/containerd/cgroups/autotune/auto"
+package auto
+
+import "github.com/containerd/cgroups/autotune"
+
+func init() {
+	// errors are ignored, the runtime keeps its defaults when the
+	// limits cannot be read
+	autotune.Apply()
+}
@@ -0,0 +1,435 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package autotune adjusts the Go runtime (GOMAXPROCS and the soft memory
+// limit) to the cpu and memory limits of the cgroup the process is running in.
+package autotune
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/cgroups"
+	"github.com/containerd/cgroups/clock"
+	v2 "github.com/containerd/cgroups/v2"
+)
+
+const (
+	defaultMountpoint  = "/sys/fs/cgroup"
+	defaultMemoryRatio = 0.9
+	defaultInterval    = 10 * time.Second
+	minPollInterval    = 100 * time.Millisecond
+)
+
+// Limits are the effective cpu and memory limits of a cgroup, taking the
+// limits of all of its parents into account
+type Limits struct {
+	// CPU is the number of cpus allowed by the cpu quota, 0 when unlimited
+	CPU float64
+	// Memory is the memory limit in bytes, 0 when unlimited
+	Memory int64
+}
+
+// Opt allows the tuning behavior to be configured
+type Opt func(*config)
+
+type config struct {
+	mountpoint  string
+	memoryRatio float64
+	minProcs    int
+	interval    time.Duration
+	clock       clock.Clock
+}
+
+// WithMountpoint sets the mountpoint of the cgroup filesystem
+func WithMountpoint(mountpoint string) Opt {
+	return func(c *config) {
+		c.mountpoint = mountpoint
+	}
+}
+
+// WithMemoryRatio sets the fraction of the memory limit that is used as the
+// runtime's soft memory limit, leaving headroom for non heap memory
+func WithMemoryRatio(ratio float64) Opt {
+	return func(c *config) {
+		c.memoryRatio = ratio
+	}
+}
+
+// WithMinProcs sets the lower bound for GOMAXPROCS
+func WithMinProcs(n int) Opt {
+	return func(c *config) {
+		c.minProcs = n
+	}
+}
+
+// WithInterval sets the longest time Watch goes without checking the limits
+// for changes, whether it polls or missed an inotify event
+func WithInterval(interval time.Duration) Opt {
+	return func(c *config) {
+		c.interval = interval
+	}
+}
+
+// WithClock sets the clock pacing the polling of Watch
+func WithClock(c clock.Clock) Opt {
+	return func(config *config) {
+		config.clock = clock.Or(c)
+	}
+}
+
+func newConfig(opts []Opt) *config {
+	c := &config{
+		mountpoint:  defaultMountpoint,
+		memoryRatio: defaultMemoryRatio,
+		minProcs:    1,
+		interval:    defaultInterval,
+		clock:       clock.Real,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Apply reads the limits of the calling process's cgroup and adjusts
+// GOMAXPROCS and the runtime's soft memory limit to match them.
+// Values explicitly set through the GOMAXPROCS or GOMEMLIMIT environment
+// variables are left untouched.
+func Apply(opts ...Opt) (Limits, error) {
+	c := newConfig(opts)
+	l, err := c.limits()
+	if err != nil {
+		return Limits{}, err
+	}
+	c.apply(l)
+	return l, nil
+}
+
+// Watch applies the limits and re-applies them whenever they change until the
+// context is canceled. The limit files of the process's cgroup and of its
+// parents are watched with a v2.Watcher, using inotify where possible and
+// polling otherwise.
+func Watch(ctx context.Context, opts ...Opt) error {
+	c := newConfig(opts)
+	current, err := c.limits()
+	if err != nil {
+		return err
+	}
+	c.apply(current)
+	files, err := c.limitFiles()
+	if err != nil {
+		return err
+	}
+	return c.watch(ctx, files, current, c.limits, c.apply)
+}
+
+// watch re-reads the limits with read whenever one of files, keyed by their
+// directory, changes and calls apply when they differ from current
+func (c *config) watch(ctx context.Context, files map[string][]string, current Limits, read func() (Limits, error), apply func(Limits)) error {
+	var watchers []*v2.Watcher
+	defer func() {
+		for _, w := range watchers {
+			w.Close()
+		}
+	}()
+	for dir, names := range files {
+		w, err := v2.NewWatcher(dir, names, c.watchOpts()...)
+		if err != nil {
+			return err
+		}
+		watchers = append(watchers, w)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	// the watchers are closed only once nothing waits on them anymore
+	defer wg.Wait()
+	defer cancel()
+	var (
+		changed = make(chan struct{}, 1)
+		errCh   = make(chan error, len(watchers))
+	)
+	// re-read the limits once the watchers are set up in case they changed
+	// after current was read
+	changed <- struct{}{}
+	for _, w := range watchers {
+		wg.Add(1)
+		go func(w *v2.Watcher) {
+			defer wg.Done()
+			for {
+				if _, err := w.Wait(ctx); err != nil {
+					errCh <- err
+					return
+				}
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}(w)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		case <-changed:
+		}
+		l, err := read()
+		if err != nil {
+			// the limits may be unreadable for a short time while
+			// an external agent is changing them
+			continue
+		}
+		if l != current {
+			apply(l)
+			current = l
+		}
+	}
+}
+
+func (c *config) watchOpts() []v2.WatchOpts {
+	min := minPollInterval
+	if c.interval < min {
+		min = c.interval
+	}
+	return []v2.WatchOpts{
+		v2.WithPollInterval(min, c.interval),
+		v2.WithWatchClock(c.clock),
+	}
+}
+
+func (c *config) apply(l Limits) {
+	if os.Getenv("GOMAXPROCS") == "" {
+		// a quota can allow more cpus than the machine has
+		procs := runtime.NumCPU()
+		if l.CPU > 0 && l.CPU < float64(procs) {
+			procs = int(math.Floor(l.CPU))
+		}
+		if procs < c.minProcs {
+			procs = c.minProcs
+		}
+		runtime.GOMAXPROCS(procs)
+	}
+	if os.Getenv("GOMEMLIMIT") == "" {
+		limit := int64(math.MaxInt64)
+		if l.Memory > 0 {
+			limit = int64(float64(l.Memory) * c.memoryRatio)
+		}
+		setMemoryLimit(limit)
+	}
+}
+
+func (c *config) limits() (Limits, error) {
+	if cgroups.Mode() == cgroups.Unified {
+		group, err := v2.NestedGroupPath("")
+		if err != nil {
+			return Limits{}, err
+		}
+		return readUnifiedLimits(c.mountpoint, group)
+	}
+	path := cgroups.NestedPath("")
+	cpu, err := path(cgroups.Cpu)
+	if err != nil {
+		return Limits{}, err
+	}
+	memory, err := path(cgroups.Memory)
+	if err != nil {
+		return Limits{}, err
+	}
+	return readLegacyLimits(c.mountpoint, cpu, memory)
+}
+
+// limitFiles returns the existing limit files read by limits, keyed by the
+// directory of the process's group or parent they are in
+func (c *config) limitFiles() (map[string][]string, error) {
+	files := make(map[string][]string)
+	if cgroups.Mode() == cgroups.Unified {
+		group, err := v2.NestedGroupPath("")
+		if err != nil {
+			return nil, err
+		}
+		err = existingFiles(files, c.mountpoint, group, "cpu.max", "memory.max")
+		return files, err
+	}
+	path := cgroups.NestedPath("")
+	cpu, err := path(cgroups.Cpu)
+	if err != nil {
+		return nil, err
+	}
+	memory, err := path(cgroups.Memory)
+	if err != nil {
+		return nil, err
+	}
+	if err := existingFiles(files, filepath.Join(c.mountpoint, string(cgroups.Cpu)), cpu, "cpu.cfs_quota_us", "cpu.cfs_period_us"); err != nil {
+		return nil, err
+	}
+	if err := existingFiles(files, filepath.Join(c.mountpoint, string(cgroups.Memory)), memory, "memory.limit_in_bytes"); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// existingFiles adds the names that exist in group and each of its parents
+// up to root to files
+func existingFiles(files map[string][]string, root, group string, names ...string) error {
+	return walkUp(filepath.Join(root, group), root, func(dir string) error {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return err
+			}
+			files[dir] = append(files[dir], name)
+		}
+		return nil
+	})
+}
+
+// readUnifiedLimits returns the smallest limits set on the group or any of its parents
+func readUnifiedLimits(mountpoint, group string) (Limits, error) {
+	var l Limits
+	err := walkUp(filepath.Join(mountpoint, group), mountpoint, func(dir string) error {
+		cpu, err := readFile(filepath.Join(dir, "cpu.max"))
+		if err != nil {
+			return err
+		}
+		if fields := strings.Fields(cpu); len(fields) == 2 && fields[0] != "max" {
+			quota, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return err
+			}
+			period, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return err
+			}
+			l.CPU = minLimit(l.CPU, quota/period)
+		}
+		memory, err := readFile(filepath.Join(dir, "memory.max"))
+		if err != nil {
+			return err
+		}
+		if memory != "" && memory != "max" {
+			v, err := strconv.ParseInt(memory, 10, 64)
+			if err != nil {
+				return err
+			}
+			l.Memory = int64(minLimit(float64(l.Memory), float64(v)))
+		}
+		return nil
+	})
+	return l, err
+}
+
+// unlimitedMemory is the threshold above which a v1 memory limit is treated
+// as unset, the kernel reports PAGE_COUNTER_MAX rounded down to the page size
+const unlimitedMemory = 1 << 62
+
+// readLegacyLimits returns the smallest limits set on the groups or any of their parents
+func readLegacyLimits(mountpoint, cpuGroup, memoryGroup string) (Limits, error) {
+	var l Limits
+	cpuRoot := filepath.Join(mountpoint, string(cgroups.Cpu))
+	if err := walkUp(filepath.Join(cpuRoot, cpuGroup), cpuRoot, func(dir string) error {
+		quota, err := readFile(filepath.Join(dir, "cpu.cfs_quota_us"))
+		if err != nil || quota == "" || quota == "-1" {
+			return err
+		}
+		period, err := readFile(filepath.Join(dir, "cpu.cfs_period_us"))
+		if err != nil {
+			return err
+		}
+		q, err := strconv.ParseFloat(quota, 64)
+		if err != nil {
+			return err
+		}
+		p, err := strconv.ParseFloat(period, 64)
+		if err != nil {
+			return err
+		}
+		if q > 0 && p > 0 {
+			l.CPU = minLimit(l.CPU, q/p)
+		}
+		return nil
+	}); err != nil {
+		return Limits{}, err
+	}
+	memoryRoot := filepath.Join(mountpoint, string(cgroups.Memory))
+	if err := walkUp(filepath.Join(memoryRoot, memoryGroup), memoryRoot, func(dir string) error {
+		limit, err := readFile(filepath.Join(dir, "memory.limit_in_bytes"))
+		if err != nil || limit == "" {
+			return err
+		}
+		v, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return err
+		}
+		if v < unlimitedMemory {
+			l.Memory = int64(minLimit(float64(l.Memory), float64(v)))
+		}
+		return nil
+	}); err != nil {
+		return Limits{}, err
+	}
+	return l, nil
+}
+
+// walkUp calls fn for dir and each of its parents up to and including root
+func walkUp(dir, root string, fn func(string) error) error {
+	for {
+		if err := fn(dir); err != nil {
+			return err
+		}
+		if dir == root || len(dir) <= len(root) {
+			return nil
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// minLimit returns the smallest of two limits where 0 is unlimited
+func minLimit(a, b float64) float64 {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// readFile returns the trimmed contents of a file, or an empty string when
+// the file does not exist because the controller is not enabled
+func readFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,179 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package autotune
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadUnifiedLimits(t *testing.T) {
+	root, err := ioutil.TempDir("", "autotune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	writeFiles(t, filepath.Join(root, "parent"), map[string]string{
+		"cpu.max":    "max 100000",
+		"memory.max": "1073741824",
+	})
+	writeFiles(t, filepath.Join(root, "parent", "child"), map[string]string{
+		"cpu.max":    "250000 100000",
+		"memory.max": "max",
+	})
+	l, err := readUnifiedLimits(root, "/parent/child")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.CPU != 2.5 {
+		t.Errorf("expected cpu limit 2.5 but received %v", l.CPU)
+	}
+	if l.Memory != 1073741824 {
+		t.Errorf("expected memory limit of the parent but received %d", l.Memory)
+	}
+}
+
+func TestReadLegacyLimits(t *testing.T) {
+	root, err := ioutil.TempDir("", "autotune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	writeFiles(t, filepath.Join(root, "cpu", "test"), map[string]string{
+		"cpu.cfs_quota_us":  "-1",
+		"cpu.cfs_period_us": "100000",
+	})
+	writeFiles(t, filepath.Join(root, "memory", "test"), map[string]string{
+		"memory.limit_in_bytes": "9223372036854771712",
+	})
+	l, err := readLegacyLimits(root, "/test", "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != (Limits{}) {
+		t.Errorf("expected no limits but received %+v", l)
+	}
+
+	writeFiles(t, filepath.Join(root, "cpu", "test"), map[string]string{
+		"cpu.cfs_quota_us": "50000",
+	})
+	writeFiles(t, filepath.Join(root, "memory", "test"), map[string]string{
+		"memory.limit_in_bytes": "536870912",
+	})
+	l, err = readLegacyLimits(root, "/test", "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.CPU != 0.5 || l.Memory != 536870912 {
+		t.Errorf("unexpected limits %+v", l)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	root, err := ioutil.TempDir("", "autotune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	writeFiles(t, filepath.Join(root, "parent"), map[string]string{
+		"memory.max": "1073741824",
+	})
+	writeFiles(t, filepath.Join(root, "parent", "child"), map[string]string{
+		"cpu.max":    "max 100000",
+		"memory.max": "max",
+	})
+	files := make(map[string][]string)
+	if err := existingFiles(files, root, "/parent/child", "cpu.max", "memory.max"); err != nil {
+		t.Fatal(err)
+	}
+	if len(files[filepath.Join(root, "parent")]) != 1 || len(files[filepath.Join(root, "parent", "child")]) != 2 {
+		t.Fatalf("unexpected limit files %v", files)
+	}
+	read := func() (Limits, error) {
+		return readUnifiedLimits(root, "/parent/child")
+	}
+	current, err := read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	applied := make(chan Limits, 1)
+	errCh := make(chan error, 1)
+	c := newConfig([]Opt{WithInterval(50 * time.Millisecond)})
+	go func() {
+		errCh <- c.watch(ctx, files, current, read, func(l Limits) {
+			applied <- l
+		})
+	}()
+	// write the quota in a single write so that the watcher never sees
+	// the file truncated
+	f, err := os.OpenFile(filepath.Join(root, "parent", "child", "cpu.max"), os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("250000 100000\n")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	select {
+	case l := <-applied:
+		if l.CPU != 2.5 || l.Memory != 1073741824 {
+			t.Fatalf("unexpected limits %+v", l)
+		}
+	case err := <-errCh:
+		t.Fatalf("watch returned before the change was applied: %v", err)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the change to be applied")
+	}
+	cancel()
+	if err := <-errCh; err != context.Canceled {
+		t.Fatalf("expected the watch to be canceled but received %v", err)
+	}
+}
+
+func TestApplyCapsProcs(t *testing.T) {
+	if os.Getenv("GOMAXPROCS") != "" {
+		t.Skip("GOMAXPROCS is set")
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	c := newConfig(nil)
+	c.apply(Limits{CPU: float64(runtime.NumCPU() + 4)})
+	if procs := runtime.GOMAXPROCS(0); procs != runtime.NumCPU() {
+		t.Fatalf("expected GOMAXPROCS to be capped at %d but it is %d", runtime.NumCPU(), procs)
+	}
+}
@@ -18,6 +18,7 @@ package cgroups
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -27,6 +28,7 @@ import (
 
 	v1 "github.com/containerd/cgroups/stats/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sirupsen/logrus"
 )
 
 // NewBlkio returns a Blkio controller given the root folder of cgroups.
@@ -49,9 +51,36 @@ func ProcRoot(path string) func(controller *blkioController) {
 	}
 }
 
+// BlkioStatFiles selects the sets of blkio stat files read by Stat
+type BlkioStatFiles int
+
+const (
+	// BlkioStatRecursive reads the stats of the cgroup and its descendants
+	BlkioStatRecursive BlkioStatFiles = 1 << iota
+	// BlkioStatNonRecursive reads the stats of the cgroup itself
+	BlkioStatNonRecursive
+	// BlkioStatThrottle reads the throttle.* stats kept by the throttling
+	// policy, which are available whatever the io scheduler
+	BlkioStatThrottle
+
+	// BlkioStatAll reads every blkio stat file
+	BlkioStatAll = BlkioStatRecursive | BlkioStatNonRecursive | BlkioStatThrottle
+)
+
+// BlkioStats sets the blkio stat files read by Stat, each file read costs a
+// syscall per device in the kernel so reading fewer bounds the cost of Stat.
+// Without it the recursive stats are read, falling back to the throttle stats
+// when the cgroup does not use the CFQ scheduler.
+func BlkioStats(files BlkioStatFiles) func(controller *blkioController) {
+	return func(c *blkioController) {
+		c.statFiles = files
+	}
+}
+
 type blkioController struct {
-	root     string
-	procRoot string
+	root      string
+	procRoot  string
+	statFiles BlkioStatFiles
 }
 
 func (b *blkioController) Name() Name {
@@ -89,6 +118,9 @@ func (b *blkioController) Update(path string, resources *specs.LinuxResources) e
 
 func (b *blkioController) Stat(path string, stats *v1.Metrics) error {
 	stats.Blkio = &v1.BlkIOStat{}
+	if b.statFiles != 0 {
+		return b.readStatFiles(path, stats.Blkio)
+	}
 
 	var settings []blkioStatSettings
 
@@ -130,13 +162,11 @@ func (b *blkioController) Stat(path string, stats *v1.Metrics) error {
 		}
 	}
 
-	f, err := os.Open(filepath.Join(b.procRoot, "diskstats"))
+	diskstats, err := readBounded(filepath.Join(b.procRoot, "diskstats"))
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-
-	devices, err := getDevices(f)
+	devices, err := getDevices(bytes.NewReader(diskstats))
 	if err != nil {
 		return err
 	}
@@ -172,31 +202,86 @@ func (b *blkioController) Stat(path string, stats *v1.Metrics) error {
 	return nil
 }
 
+// readStatFiles reads the sets of stat files selected with BlkioStats, files
+// that the kernel does not provide are skipped
+func (b *blkioController) readStatFiles(path string, stats *v1.BlkIOStat) error {
+	var (
+		files    = b.statFiles
+		settings []blkioStatSettings
+	)
+	if files&BlkioStatRecursive != 0 {
+		settings = append(settings, []blkioStatSettings{
+			{name: "sectors_recursive", entry: &stats.SectorsRecursive},
+			{name: "io_service_bytes_recursive", entry: &stats.IoServiceBytesRecursive},
+			{name: "io_serviced_recursive", entry: &stats.IoServicedRecursive},
+			{name: "io_queued_recursive", entry: &stats.IoQueuedRecursive},
+			{name: "io_service_time_recursive", entry: &stats.IoServiceTimeRecursive},
+			{name: "io_wait_time_recursive", entry: &stats.IoWaitTimeRecursive},
+			{name: "io_merged_recursive", entry: &stats.IoMergedRecursive},
+			{name: "time_recursive", entry: &stats.IoTimeRecursive},
+		}...)
+	}
+	if files&BlkioStatNonRecursive != 0 {
+		settings = append(settings, []blkioStatSettings{
+			{name: "sectors", entry: &stats.Sectors},
+			{name: "io_service_bytes", entry: &stats.IoServiceBytes},
+			{name: "io_serviced", entry: &stats.IoServiced},
+			{name: "io_queued", entry: &stats.IoQueued},
+			{name: "io_service_time", entry: &stats.IoServiceTime},
+			{name: "io_wait_time", entry: &stats.IoWaitTime},
+			{name: "io_merged", entry: &stats.IoMerged},
+			{name: "time", entry: &stats.IoTime},
+		}...)
+	}
+	if files&BlkioStatThrottle != 0 {
+		settings = append(settings, []blkioStatSettings{
+			{name: "throttle.io_service_bytes", entry: &stats.ThrottleIoServiceBytes},
+			{name: "throttle.io_serviced", entry: &stats.ThrottleIoServiced},
+			{name: "throttle.io_service_bytes_recursive", entry: &stats.ThrottleIoServiceBytesRecursive},
+			{name: "throttle.io_serviced_recursive", entry: &stats.ThrottleIoServicedRecursive},
+		}...)
+	}
+	diskstats, err := readBounded(filepath.Join(b.procRoot, "diskstats"))
+	if err != nil {
+		return err
+	}
+	devices, err := getDevices(bytes.NewReader(diskstats))
+	if err != nil {
+		return err
+	}
+	for _, t := range settings {
+		if err := b.readEntry(devices, path, t.name, t.entry); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *blkioController) readEntry(devices map[deviceKey]string, path, name string, entry *[]*v1.BlkIOEntry) error {
-	f, err := os.Open(filepath.Join(b.Path(path), "blkio."+name))
+	data, err := readBounded(filepath.Join(b.Path(path), "blkio."+name))
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	sc := bufio.NewScanner(f)
+	sc := bufio.NewScanner(bytes.NewReader(data))
 	for sc.Scan() {
 		// format: dev type amount
 		fields := strings.FieldsFunc(sc.Text(), splitBlkIOStatLine)
 		if len(fields) < 3 {
-			if len(fields) == 2 && fields[0] == "Total" {
-				// skip total line
-				continue
-			} else {
-				return fmt.Errorf("invalid line found while parsing %s: %s", path, sc.Text())
+			if len(fields) != 2 || fields[0] != "Total" {
+				logrus.Warnf("cgroups: skipping invalid line while parsing blkio.%s of %s: %q", name, path, sc.Text())
 			}
+			// skip total line
+			continue
 		}
 		major, err := strconv.ParseUint(fields[0], 10, 64)
 		if err != nil {
-			return err
+			logrus.Warnf("cgroups: skipping invalid line while parsing blkio.%s of %s: %q", name, path, sc.Text())
+			continue
 		}
 		minor, err := strconv.ParseUint(fields[1], 10, 64)
 		if err != nil {
-			return err
+			logrus.Warnf("cgroups: skipping invalid line while parsing blkio.%s of %s: %q", name, path, sc.Text())
+			continue
 		}
 		op := ""
 		valueField := 2
@@ -206,7 +291,8 @@ func (b *blkioController) readEntry(devices map[deviceKey]string, path, name str
 		}
 		v, err := strconv.ParseUint(fields[valueField], 10, 64)
 		if err != nil {
-			return err
+			logrus.Warnf("cgroups: skipping invalid line while parsing blkio.%s of %s: %q", name, path, sc.Text())
+			continue
 		}
 		*entry = append(*entry, &v1.BlkIOEntry{
 			Device: devices[deviceKey{major, minor}],
@@ -17,7 +17,9 @@
 package cgroups
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -102,3 +104,75 @@ func TestNewBlkio_Proc(t *testing.T) {
 		t.Fatalf("expected proc FS root %q but received %q", expectedProc, ctrl.procRoot)
 	}
 }
+
+func TestBlkioStatFiles(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	if err := ioutil.WriteFile(filepath.Join(mock.root, "diskstats"), []byte(data), defaultFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(mock.root, "blkio", "test")
+	if err := os.MkdirAll(dir, defaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"blkio.io_serviced":                    "8:0 Read 10\n8:0 Write 20\nTotal 30\n",
+		"blkio.io_serviced_recursive":          "8:0 Read 11\n8:0 Write 21\nTotal 32\n",
+		"blkio.throttle.io_service_bytes":      "8:0 Read 4096\nTotal 4096\n",
+		"blkio.throttle.io_serviced":           "8:0 Read 1\nTotal 1\n",
+		"blkio.sectors":                        "8:0 8\n",
+		"blkio.io_wait_time":                   "8:0 Read 100\n",
+		"blkio.throttle.io_serviced_recursive": "8:0 Read 2\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), defaultFilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range []struct {
+		files    BlkioStatFiles
+		expected func(*v1.BlkIOStat) bool
+	}{
+		{
+			files: BlkioStatNonRecursive,
+			expected: func(s *v1.BlkIOStat) bool {
+				return len(s.IoServiced) == 2 && len(s.Sectors) == 1 && len(s.IoWaitTime) == 1 &&
+					len(s.IoServicedRecursive) == 0 && len(s.ThrottleIoServiced) == 0
+			},
+		},
+		{
+			files: BlkioStatRecursive,
+			expected: func(s *v1.BlkIOStat) bool {
+				return len(s.IoServicedRecursive) == 2 && s.IoServicedRecursive[0].Value == 11 &&
+					len(s.IoServiced) == 0
+			},
+		},
+		{
+			files: BlkioStatThrottle,
+			expected: func(s *v1.BlkIOStat) bool {
+				return len(s.ThrottleIoServiceBytes) == 1 && s.ThrottleIoServiceBytes[0].Value == 4096 &&
+					len(s.ThrottleIoServicedRecursive) == 1 && len(s.IoServicedRecursive) == 0
+			},
+		},
+		{
+			files: BlkioStatAll,
+			expected: func(s *v1.BlkIOStat) bool {
+				return len(s.IoServiced) == 2 && len(s.IoServicedRecursive) == 2 && len(s.ThrottleIoServiced) == 1
+			},
+		},
+	} {
+		ctrl := NewBlkio(mock.root, ProcRoot(mock.root), BlkioStats(tc.files))
+		var metrics v1.Metrics
+		if err := ctrl.Stat("test", &metrics); err != nil {
+			t.Fatal(err)
+		}
+		if !tc.expected(metrics.Blkio) {
+			t.Errorf("unexpected stats for files %d: %+v", tc.files, metrics.Blkio)
+		}
+		if s := metrics.Blkio.IoServiced; len(s) > 0 && s[0].Device != "/dev/sda" {
+			t.Errorf("expected device /dev/sda but received %q", s[0].Device)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package cadvisor converts the metrics collected by this module into the
+// ContainerStats shape used by cAdvisor and the kubelet, so that existing
+// pipelines can consume them unchanged.
+//
+// The types mirror cAdvisor's info/v1 package, including the json field
+// names, without depending on it.
+package cadvisor
+
+import (
+	"time"
+
+	v1 "github.com/containerd/cgroups/stats/v1"
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+type ContainerStats struct {
+	Timestamp time.Time               `json:"timestamp"`
+	Cpu       CpuStats                `json:"cpu,omitempty"`
+	DiskIo    DiskIoStats             `json:"diskio,omitempty"`
+	Memory    MemoryStats             `json:"memory,omitempty"`
+	Hugetlb   map[string]HugetlbStats `json:"hugetlb,omitempty"`
+	Network   NetworkStats            `json:"network,omitempty"`
+	TaskStats LoadStats               `json:"task_stats,omitempty"`
+	Processes ProcessStats            `json:"processes,omitempty"`
+}
+
+type CpuStats struct {
+	Usage CpuUsage `json:"usage"`
+	CFS   CpuCFS   `json:"cfs"`
+}
+
+// CpuUsage is the cpu time used, all values are in nanoseconds
+type CpuUsage struct {
+	Total  uint64   `json:"total"`
+	PerCpu []uint64 `json:"per_cpu_usage,omitempty"`
+	User   uint64   `json:"user"`
+	System uint64   `json:"system"`
+}
+
+type CpuCFS struct {
+	Periods          uint64 `json:"periods"`
+	ThrottledPeriods uint64 `json:"throttled_periods"`
+	// ThrottledTime is in nanoseconds
+	ThrottledTime uint64 `json:"throttled_time"`
+}
+
+type DiskIoStats struct {
+	IoServiceBytes []PerDiskStats `json:"io_service_bytes,omitempty"`
+	IoServiced     []PerDiskStats `json:"io_serviced,omitempty"`
+	IoQueued       []PerDiskStats `json:"io_queued,omitempty"`
+	Sectors        []PerDiskStats `json:"sectors,omitempty"`
+	IoServiceTime  []PerDiskStats `json:"io_service_time,omitempty"`
+	IoWaitTime     []PerDiskStats `json:"io_wait_time,omitempty"`
+	IoMerged       []PerDiskStats `json:"io_merged,omitempty"`
+	IoTime         []PerDiskStats `json:"io_time,omitempty"`
+}
+
+type PerDiskStats struct {
+	Device string            `json:"device"`
+	Major  uint64            `json:"major"`
+	Minor  uint64            `json:"minor"`
+	Stats  map[string]uint64 `json:"stats"`
+}
+
+type MemoryStats struct {
+	Usage            uint64                `json:"usage"`
+	MaxUsage         uint64                `json:"max_usage"`
+	Cache            uint64                `json:"cache"`
+	RSS              uint64                `json:"rss"`
+	Swap             uint64                `json:"swap"`
+	MappedFile       uint64                `json:"mapped_file"`
+	WorkingSet       uint64                `json:"working_set"`
+	Failcnt          uint64                `json:"failcnt"`
+	ContainerData    MemoryStatsMemoryData `json:"container_data,omitempty"`
+	HierarchicalData MemoryStatsMemoryData `json:"hierarchical_data,omitempty"`
+}
+
+type MemoryStatsMemoryData struct {
+	Pgfault    uint64 `json:"pgfault"`
+	Pgmajfault uint64 `json:"pgmajfault"`
+}
+
+type HugetlbStats struct {
+	Usage    uint64 `json:"usage,omitempty"`
+	MaxUsage uint64 `json:"max_usage,omitempty"`
+	Failcnt  uint64 `json:"failcnt"`
+}
+
+type InterfaceStats struct {
+	Name      string `json:"name"`
+	RxBytes   uint64 `json:"rx_bytes"`
+	RxPackets uint64 `json:"rx_packets"`
+	RxErrors  uint64 `json:"rx_errors"`
+	RxDropped uint64 `json:"rx_dropped"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	TxPackets uint64 `json:"tx_packets"`
+	TxErrors  uint64 `json:"tx_errors"`
+	TxDropped uint64 `json:"tx_dropped"`
+}
+
+type NetworkStats struct {
+	InterfaceStats `json:",inline"`
+	Interfaces     []InterfaceStats `json:"interfaces,omitempty"`
+}
+
+type LoadStats struct {
+	NrSleeping        uint64 `json:"nr_sleeping"`
+	NrRunning         uint64 `json:"nr_running"`
+	NrStopped         uint64 `json:"nr_stopped"`
+	NrUninterruptible uint64 `json:"nr_uninterruptible"`
+	NrIoWait          uint64 `json:"nr_io_wait"`
+}
+
+type ProcessStats struct {
+	ThreadsCurrent uint64 `json:"threads_current,omitempty"`
+	ThreadsMax     uint64 `json:"threads_max,omitempty"`
+}
+
+// FromV1 converts v1 metrics into cAdvisor stats taken at timestamp
+func FromV1(m *v1.Metrics, timestamp time.Time) *ContainerStats {
+	s := &ContainerStats{
+		Timestamp: timestamp,
+	}
+	if cpu := m.CPU; cpu != nil {
+		if u := cpu.Usage; u != nil {
+			s.Cpu.Usage = CpuUsage{
+				Total:  u.Total,
+				PerCpu: u.PerCPU,
+				User:   u.User,
+				System: u.Kernel,
+			}
+		}
+		if t := cpu.Throttling; t != nil {
+			s.Cpu.CFS = CpuCFS{
+				Periods:          t.Periods,
+				ThrottledPeriods: t.ThrottledPeriods,
+				ThrottledTime:    t.ThrottledTime,
+			}
+		}
+	}
+	if mem := m.Memory; mem != nil {
+		s.Memory = MemoryStats{
+			Cache:      mem.TotalCache,
+			RSS:        mem.TotalRSS,
+			MappedFile: mem.TotalMappedFile,
+			ContainerData: MemoryStatsMemoryData{
+				Pgfault:    mem.PgFault,
+				Pgmajfault: mem.PgMajFault,
+			},
+			HierarchicalData: MemoryStatsMemoryData{
+				Pgfault:    mem.TotalPgFault,
+				Pgmajfault: mem.TotalPgMajFault,
+			},
+		}
+		if u := mem.Usage; u != nil {
+			s.Memory.Usage = u.Usage
+			s.Memory.MaxUsage = u.Max
+			s.Memory.Failcnt = u.Failcnt
+		}
+		if sw := mem.Swap; sw != nil && sw.Usage > s.Memory.Usage {
+			// memsw includes the memory usage
+			s.Memory.Swap = sw.Usage - s.Memory.Usage
+		}
+		s.Memory.WorkingSet = workingSet(s.Memory.Usage, mem.TotalInactiveFile)
+	}
+	if b := m.Blkio; b != nil {
+		s.DiskIo = DiskIoStats{
+			IoServiceBytes: perDisk(b.IoServiceBytesRecursive),
+			IoServiced:     perDisk(b.IoServicedRecursive),
+			IoQueued:       perDisk(b.IoQueuedRecursive),
+			Sectors:        perDisk(b.SectorsRecursive),
+			IoServiceTime:  perDisk(b.IoServiceTimeRecursive),
+			IoWaitTime:     perDisk(b.IoWaitTimeRecursive),
+			IoMerged:       perDisk(b.IoMergedRecursive),
+			IoTime:         perDisk(b.IoTimeRecursive),
+		}
+	}
+	if len(m.Hugetlb) > 0 {
+		s.Hugetlb = make(map[string]HugetlbStats, len(m.Hugetlb))
+		for _, h := range m.Hugetlb {
+			s.Hugetlb[h.Pagesize] = HugetlbStats{
+				Usage:    h.Usage,
+				MaxUsage: h.Max,
+				Failcnt:  h.Failcnt,
+			}
+		}
+	}
+	for i, n := range m.Network {
+		is := InterfaceStats{
+			Name:      n.Name,
+			RxBytes:   n.RxBytes,
+			RxPackets: n.RxPackets,
+			RxErrors:  n.RxErrors,
+			RxDropped: n.RxDropped,
+			TxBytes:   n.TxBytes,
+			TxPackets: n.TxPackets,
+			TxErrors:  n.TxErrors,
+			TxDropped: n.TxDropped,
+		}
+		// cAdvisor reports the first interface inline for compatibility
+		if i == 0 {
+			s.Network.InterfaceStats = is
+		}
+		s.Network.Interfaces = append(s.Network.Interfaces, is)
+	}
+	if c := m.CgroupStats; c != nil {
+		s.TaskStats = LoadStats{
+			NrSleeping:        c.NrSleeping,
+			NrRunning:         c.NrRunning,
+			NrStopped:         c.NrStopped,
+			NrUninterruptible: c.NrUninterruptible,
+			NrIoWait:          c.NrIoWait,
+		}
+	}
+	if p := m.Pids; p != nil {
+		s.Processes = ProcessStats{
+			ThreadsCurrent: p.Current,
+			ThreadsMax:     p.Limit,
+		}
+	}
+	return s
+}
+
+// FromV2 converts v2 metrics into cAdvisor stats taken at timestamp
+func FromV2(m *stats.Metrics, timestamp time.Time) *ContainerStats {
+	s := &ContainerStats{
+		Timestamp: timestamp,
+	}
+	if cpu := m.CPU; cpu != nil {
+		s.Cpu = CpuStats{
+			Usage: CpuUsage{
+				Total:  cpu.UsageUsec * uint64(time.Microsecond),
+				User:   cpu.UserUsec * uint64(time.Microsecond),
+				System: cpu.SystemUsec * uint64(time.Microsecond),
+			},
+			CFS: CpuCFS{
+				Periods:          cpu.NrPeriods,
+				ThrottledPeriods: cpu.NrThrottled,
+				ThrottledTime:    cpu.ThrottledUsec * uint64(time.Microsecond),
+			},
+		}
+	}
+	if mem := m.Memory; mem != nil {
+		data := MemoryStatsMemoryData{
+			Pgfault:    mem.Pgfault,
+			Pgmajfault: mem.Pgmajfault,
+		}
+		s.Memory = MemoryStats{
+			Usage:            mem.Usage,
+			Cache:            mem.File,
+			RSS:              mem.Anon,
+			Swap:             mem.SwapUsage,
+			MappedFile:       mem.FileMapped,
+			WorkingSet:       workingSet(mem.Usage, mem.InactiveFile),
+			ContainerData:    data,
+			HierarchicalData: data,
+		}
+	}
+	if e := m.MemoryEvents; e != nil {
+		s.Memory.Failcnt = e.Max
+	}
+	if io := m.Io; io != nil {
+		for _, e := range io.Usage {
+			s.DiskIo.IoServiceBytes = append(s.DiskIo.IoServiceBytes, PerDiskStats{
+				Major: e.Major,
+				Minor: e.Minor,
+				Stats: map[string]uint64{
+					"Read":  e.Rbytes,
+					"Write": e.Wbytes,
+					"Total": e.Rbytes + e.Wbytes,
+				},
+			})
+			s.DiskIo.IoServiced = append(s.DiskIo.IoServiced, PerDiskStats{
+				Major: e.Major,
+				Minor: e.Minor,
+				Stats: map[string]uint64{
+					"Read":  e.Rios,
+					"Write": e.Wios,
+					"Total": e.Rios + e.Wios,
+				},
+			})
+		}
+	}
+	if len(m.Hugetlb) > 0 {
+		s.Hugetlb = make(map[string]HugetlbStats, len(m.Hugetlb))
+		for _, h := range m.Hugetlb {
+			s.Hugetlb[h.Pagesize] = HugetlbStats{
+				Usage: h.Current,
+			}
+		}
+	}
+	if p := m.Pids; p != nil {
+		s.Processes = ProcessStats{
+			ThreadsCurrent: p.Current,
+			ThreadsMax:     p.Limit,
+		}
+	}
+	return s
+}
+
+// workingSet matches cAdvisor's working set, the usage minus the inactive
+// file pages that can be reclaimed
+func workingSet(usage, inactiveFile uint64) uint64 {
+	if inactiveFile > usage {
+		return 0
+	}
+	return usage - inactiveFile
+}
+
+// perDisk groups the per operation entries by device
+func perDisk(entries []*v1.BlkIOEntry) []PerDiskStats {
+	var (
+		out   []PerDiskStats
+		index = make(map[[2]uint64]int)
+	)
+	for _, e := range entries {
+		key := [2]uint64{e.Major, e.Minor}
+		i, ok := index[key]
+		if !ok {
+			i = len(out)
+			index[key] = i
+			out = append(out, PerDiskStats{
+				Device: e.Device,
+				Major:  e.Major,
+				Minor:  e.Minor,
+				Stats:  make(map[string]uint64),
+			})
+		}
+		// the recursive files have a device-less total line
+		op := e.Op
+		if op == "" {
+			op = "Count"
+		}
+		out[i].Stats[op] = e.Value
+	}
+	return out
+}
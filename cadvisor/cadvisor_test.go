@@ -0,0 +1,78 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cadvisor
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/containerd/cgroups/stats/v1"
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestFromV1(t *testing.T) {
+	now := time.Now()
+	s := FromV1(&v1.Metrics{
+		CPU: &v1.CPUStat{
+			Usage: &v1.CPUUsage{Total: 300, User: 200, Kernel: 100, PerCPU: []uint64{150, 150}},
+		},
+		Memory: &v1.MemoryStat{
+			TotalCache:        40,
+			TotalRSS:          60,
+			TotalInactiveFile: 30,
+			Usage:             &v1.MemoryEntry{Usage: 100, Max: 120},
+			Swap:              &v1.MemoryEntry{Usage: 110},
+		},
+		Blkio: &v1.BlkIOStat{
+			IoServiceBytesRecursive: []*v1.BlkIOEntry{
+				{Op: "Read", Major: 8, Minor: 0, Value: 10},
+				{Op: "Write", Major: 8, Minor: 0, Value: 20},
+				{Op: "Read", Major: 8, Minor: 16, Value: 5},
+			},
+		},
+		Hugetlb: []*v1.HugetlbStat{{Pagesize: "2MB", Usage: 2, Max: 4}},
+	}, now)
+	if s.Cpu.Usage.System != 100 || len(s.Cpu.Usage.PerCpu) != 2 {
+		t.Fatalf("unexpected cpu stats %+v", s.Cpu)
+	}
+	if s.Memory.WorkingSet != 70 || s.Memory.Swap != 10 || s.Memory.MaxUsage != 120 {
+		t.Fatalf("unexpected memory stats %+v", s.Memory)
+	}
+	if len(s.DiskIo.IoServiceBytes) != 2 || s.DiskIo.IoServiceBytes[0].Stats["Write"] != 20 {
+		t.Fatalf("unexpected disk io stats %+v", s.DiskIo)
+	}
+	if s.Hugetlb["2MB"].MaxUsage != 4 {
+		t.Fatalf("unexpected hugetlb stats %+v", s.Hugetlb)
+	}
+}
+
+func TestFromV2(t *testing.T) {
+	s := FromV2(&stats.Metrics{
+		CPU:    &stats.CPUStat{UsageUsec: 3, ThrottledUsec: 1},
+		Memory: &stats.MemoryStat{Usage: 100, InactiveFile: 150},
+		Io:     &stats.IOStat{Usage: []*stats.IOEntry{{Major: 8, Rbytes: 1, Wbytes: 2}}},
+	}, time.Now())
+	if s.Cpu.Usage.Total != 3000 || s.Cpu.CFS.ThrottledTime != 1000 {
+		t.Fatalf("expected nanosecond cpu times but received %+v", s.Cpu)
+	}
+	if s.Memory.WorkingSet != 0 {
+		t.Fatalf("working set must not underflow, received %d", s.Memory.WorkingSet)
+	}
+	if total := s.DiskIo.IoServiceBytes[0].Stats["Total"]; total != 3 {
+		t.Fatalf("expected total of 3 bytes but received %d", total)
+	}
+}
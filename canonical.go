@@ -0,0 +1,112 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// defaultMountpoint is where the cgroup filesystems are mounted on most
+// systems
+const defaultMountpoint = "/sys/fs/cgroup"
+
+// hierarchyDirs are the directories below the default mountpoint that hold
+// a hierarchy rather than a group, besides the controllers
+var hierarchyDirs = map[string]bool{
+	"systemd": true,
+	"unified": true,
+}
+
+// CanonicalPath returns the group path of p in the form of the third field
+// of /proc/<pid>/cgroup, e.g. "/user.slice/session-1.scope". The path is
+// made absolute and cleaned, and the mountpoint of its hierarchy is removed,
+// whether it is /sys/fs/cgroup on the unified hierarchy or the directory of
+// a v1 controller such as /sys/fs/cgroup/cpu,cpuacct. Paths of groups below
+// different v1 controllers therefore compare equal.
+//
+// The directory of a controller is only recognized after /sys/fs/cgroup, a
+// group named after a controller directly below the root of the unified
+// hierarchy cannot be told apart from it.
+func CanonicalPath(p string) string {
+	p = filepath.Clean("/" + p)
+	rest, ok := trimPathPrefix(p, defaultMountpoint)
+	if !ok {
+		return p
+	}
+	elems := strings.SplitN(strings.TrimPrefix(rest, "/"), "/", 2)
+	if isHierarchyDir(elems[0]) {
+		if len(elems) == 1 {
+			return "/"
+		}
+		return "/" + elems[1]
+	}
+	return rest
+}
+
+// PathsEqual returns true if a and b are the same group, see CanonicalPath
+func PathsEqual(a, b string) bool {
+	return CanonicalPath(a) == CanonicalPath(b)
+}
+
+// IsDescendant returns true if group is below ancestor, comparing whole path
+// elements so that "/a/bc" is not below "/a/b". A group is not a descendant
+// of itself. Both paths are canonicalized first, see CanonicalPath.
+func IsDescendant(ancestor, group string) bool {
+	rest, ok := trimPathPrefix(CanonicalPath(group), CanonicalPath(ancestor))
+	return ok && rest != "/"
+}
+
+// trimPathPrefix removes the whole path elements of prefix from p, returning
+// the rest as an absolute path and whether p is at or below prefix
+func trimPathPrefix(p, prefix string) (string, bool) {
+	if prefix == "/" {
+		return p, true
+	}
+	if p == prefix {
+		return "/", true
+	}
+	if strings.HasPrefix(p, prefix+"/") {
+		return p[len(prefix):], true
+	}
+	return "", false
+}
+
+// isHierarchyDir returns true if dir is the directory of a hierarchy below
+// the default mountpoint, such as "memory" or "cpu,cpuacct"
+func isHierarchyDir(dir string) bool {
+	if hierarchyDirs[dir] {
+		return true
+	}
+	if dir == "" {
+		return false
+	}
+	for _, name := range strings.Split(dir, ",") {
+		if !isController(Name(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isController(name Name) bool {
+	switch name {
+	case Devices, Hugetlb, Freezer, Pids, NetCLS, NetPrio, PerfEvent, Cpuset, Cpu, Cpuacct, Memory, Blkio, Rdma:
+		return true
+	}
+	return false
+}
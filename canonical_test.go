@@ -0,0 +1,69 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import "testing"
+
+func TestCanonicalPath(t *testing.T) {
+	for _, tc := range []struct {
+		path, expected string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"test", "/test"},
+		{"//a//b/", "/a/b"},
+		{"/a/../b", "/b"},
+		{"/sys/fs/cgroup", "/"},
+		{"/sys/fs/cgroup/a/b", "/a/b"},
+		{"/sys/fs/cgroup/memory/a", "/a"},
+		{"/sys/fs/cgroup/cpu,cpuacct/a", "/a"},
+		{"/sys/fs/cgroup/cpuacct,cpu/a", "/a"},
+		{"/sys/fs/cgroup/net_cls,net_prio", "/"},
+		{"/sys/fs/cgroup/systemd/user.slice", "/user.slice"},
+		{"/sys/fs/cgroup/unified/user.slice", "/user.slice"},
+		{"/sys/fs/cgroupfoo/a", "/sys/fs/cgroupfoo/a"},
+		{"/sys/fs/cgroup/cpu,notacontroller/a", "/cpu,notacontroller/a"},
+	} {
+		if actual := CanonicalPath(tc.path); actual != tc.expected {
+			t.Errorf("CanonicalPath(%q): expected %q but received %q", tc.path, tc.expected, actual)
+		}
+	}
+}
+
+func TestIsDescendant(t *testing.T) {
+	for _, tc := range []struct {
+		ancestor, group string
+		expected        bool
+	}{
+		{"/a", "/a/b", true},
+		{"/a", "/a/b/c", true},
+		{"/a", "/a", false},
+		{"/a/b", "/a/bc", false},
+		{"/", "/a", true},
+		{"/", "/", false},
+		{"/sys/fs/cgroup/memory/a", "/sys/fs/cgroup/cpu,cpuacct/a/b", true},
+		{"a//", "/a/b/", true},
+		{"/a/b", "/a", false},
+	} {
+		if actual := IsDescendant(tc.ancestor, tc.group); actual != tc.expected {
+			t.Errorf("IsDescendant(%q, %q): expected %v but received %v", tc.ancestor, tc.group, tc.expected, actual)
+		}
+	}
+	if !PathsEqual("/sys/fs/cgroup/memory/a/", "a") {
+		t.Error("expected paths to be equal")
+	}
+}
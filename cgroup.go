@@ -24,12 +24,20 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/containerd/cgroups/clock"
 	v1 "github.com/containerd/cgroups/stats/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
 )
 
 // New returns a new control via the cgroup cgroups interface
+//
+// The cgroup is created in the subsystems in the order they are returned by
+// the hierarchy, and the resources of each subsystem are written as it is
+// created. The cpuset is populated with the cpus and mems of its parent
+// before any process can be added, and on update the memory limit is
+// ordered against the memory+swap limit so that it is never above it.
 func New(hierarchy Hierarchy, path Path, resources *specs.LinuxResources, opts ...InitOpts) (Cgroup, error) {
 	config := newInitConfig()
 	for _, o := range opts {
@@ -41,6 +49,9 @@ func New(hierarchy Hierarchy, path Path, resources *specs.LinuxResources, opts .
 	if err != nil {
 		return nil, err
 	}
+	if config.AccountingOnly {
+		resources = &specs.LinuxResources{}
+	}
 	var active []Subsystem
 	for _, s := range subsystems {
 		// check if subsystem exists
@@ -53,18 +64,92 @@ func New(hierarchy Hierarchy, path Path, resources *specs.LinuxResources, opts .
 						}
 					}
 				}
+				config.Warnings.add(s.Name(), "subsystem not active, the cgroup is not created in it")
 				continue
 			}
 			return nil, err
 		}
+		if config.AccountingKnobs {
+			if err := enableAccounting(s, path); err != nil {
+				return nil, err
+			}
+		}
 		active = append(active, s)
 	}
 	return &cgroup{
 		path:       path,
 		subsystems: active,
+		clock:      config.ClockSource,
+		pacer:      clock.Or(config.Pacer),
 	}, nil
 }
 
+// GetOrCreate loads the cgroup at path if it exists and creates it with the
+// resources otherwise, so it can be called any number of times for the same
+// path. A cgroup that exists in only some of the subsystems, such as one
+// whose creation was interrupted, is created with the resources in the
+// others. The resources are only applied to the subsystems the cgroup
+// already existed in when WithUpdateExisting is provided.
+func GetOrCreate(hierarchy Hierarchy, path Path, resources *specs.LinuxResources, opts ...InitOpts) (Cgroup, error) {
+	config := newInitConfig()
+	for _, o := range opts {
+		if err := o(config); err != nil {
+			return nil, err
+		}
+	}
+	cg, err := Load(hierarchy, path, opts...)
+	if err != nil {
+		if err == ErrCgroupDeleted {
+			return New(hierarchy, path, resources, opts...)
+		}
+		return nil, err
+	}
+	if err := cg.(*cgroup).createMissing(hierarchy, resources, config); err != nil {
+		return nil, err
+	}
+	if config.UpdateExisting && !config.AccountingOnly && resources != nil {
+		if err := cg.(*cgroup).update(resources, config.Warnings); err != nil {
+			return nil, err
+		}
+	}
+	return cg, nil
+}
+
+// createMissing creates the cgroup with the resources in the subsystems of
+// the hierarchy it is not in yet, keeping the order of the hierarchy
+func (c *cgroup) createMissing(hierarchy Hierarchy, resources *specs.LinuxResources, config *InitConfig) error {
+	subsystems, err := hierarchy()
+	if err != nil {
+		return err
+	}
+	if config.AccountingOnly || resources == nil {
+		resources = &specs.LinuxResources{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var active []Subsystem
+	for _, s := range subsystems {
+		if existing := c.getSubsystem(s.Name()); existing != nil {
+			active = append(active, existing)
+			continue
+		}
+		if err := initializeSubsystem(s, c.path, resources); err != nil {
+			if err == ErrControllerNotActive {
+				continue
+			}
+			return err
+		}
+		if config.AccountingKnobs {
+			if err := enableAccounting(s, c.path); err != nil {
+				return err
+			}
+		}
+		active = append(active, s)
+	}
+	c.subsystems = active
+	return nil
+}
+
 // Load will load an existing cgroup and allow it to be controlled
 // All static path should not include `/sys/fs/cgroup/` prefix, it should start with your own cgroups name
 func Load(hierarchy Hierarchy, path Path, opts ...InitOpts) (Cgroup, error) {
@@ -113,6 +198,8 @@ func Load(hierarchy Hierarchy, path Path, opts ...InitOpts) (Cgroup, error) {
 	return &cgroup{
 		path:       path,
 		subsystems: activeSubsystems,
+		clock:      config.ClockSource,
+		pacer:      clock.Or(config.Pacer),
 	}, nil
 }
 
@@ -120,6 +207,8 @@ type cgroup struct {
 	path Path
 
 	subsystems []Subsystem
+	clock      ClockSource
+	pacer      clock.Clock
 	mu         sync.Mutex
 	err        error
 }
@@ -140,6 +229,8 @@ func (c *cgroup) New(name string, resources *specs.LinuxResources) (Cgroup, erro
 	return &cgroup{
 		path:       path,
 		subsystems: c.subsystems,
+		clock:      c.clock,
+		pacer:      c.pacer,
 	}, nil
 }
 
@@ -149,8 +240,17 @@ func (c *cgroup) Subsystems() []Subsystem {
 	return c.subsystems
 }
 
-// Add moves the provided process into the new cgroup
+// Add moves the provided process into the new cgroup. ErrFrozen or ErrDying
+// is returned when the kernel refuses the move because of the cgroup's state.
+// A process is not moved back out of the subsystems it was already added to
+// when adding it to a later one fails.
 func (c *cgroup) Add(process Process) error {
+	return c.AddWithOpts(process)
+}
+
+// AddWithOpts moves the provided process into the new cgroup like Add, in all
+// of its subsystems unless WithSubsystems is provided
+func (c *cgroup) AddWithOpts(process Process, opts ...AddOpts) error {
 	if process.Pid <= 0 {
 		return ErrInvalidPid
 	}
@@ -159,21 +259,30 @@ func (c *cgroup) Add(process Process) error {
 	if c.err != nil {
 		return c.err
 	}
-	return c.add(process)
+	subsystems, err := c.addSubsystems(opts)
+	if err != nil {
+		return err
+	}
+	return c.add(process, subsystems)
 }
 
-func (c *cgroup) add(process Process) error {
-	for _, s := range pathers(c.subsystems) {
+func (c *cgroup) add(process Process, subsystems []pather) error {
+	for _, s := range subsystems {
 		p, err := c.path(s.Name())
 		if err != nil {
 			return err
 		}
+		if ap, ok := s.(attachPreparer); ok {
+			if err := ap.prepareAttach(p); err != nil {
+				return err
+			}
+		}
 		if err := retryingWriteFile(
 			filepath.Join(s.Path(p), cgroupProcs),
 			[]byte(strconv.Itoa(process.Pid)),
 			defaultFilePerm,
 		); err != nil {
-			return err
+			return c.addError(err)
 		}
 	}
 	return nil
@@ -181,6 +290,12 @@ func (c *cgroup) add(process Process) error {
 
 // AddTask moves the provided tasks (threads) into the new cgroup
 func (c *cgroup) AddTask(process Process) error {
+	return c.AddTaskWithOpts(process)
+}
+
+// AddTaskWithOpts moves the provided tasks (threads) into the new cgroup like
+// AddTask, in all of its subsystems unless WithSubsystems is provided
+func (c *cgroup) AddTaskWithOpts(process Process, opts ...AddOpts) error {
 	if process.Pid <= 0 {
 		return ErrInvalidPid
 	}
@@ -189,31 +304,117 @@ func (c *cgroup) AddTask(process Process) error {
 	if c.err != nil {
 		return c.err
 	}
-	return c.addTask(process)
+	subsystems, err := c.addSubsystems(opts)
+	if err != nil {
+		return err
+	}
+	return c.addTask(process, subsystems)
 }
 
-func (c *cgroup) addTask(process Process) error {
-	for _, s := range pathers(c.subsystems) {
+func (c *cgroup) addTask(process Process, subsystems []pather) error {
+	for _, s := range subsystems {
 		p, err := c.path(s.Name())
 		if err != nil {
 			return err
 		}
+		if ap, ok := s.(attachPreparer); ok {
+			if err := ap.prepareAttach(p); err != nil {
+				return err
+			}
+		}
 		if err := retryingWriteFile(
 			filepath.Join(s.Path(p), cgroupTasks),
 			[]byte(strconv.Itoa(process.Pid)),
 			defaultFilePerm,
 		); err != nil {
-			return err
+			return c.addError(err)
 		}
 	}
 	return nil
 }
 
-// Delete will remove the control group from each of the subsystems registered
+// addSubsystems returns the subsystems a process is added to with the
+// options, in the order of the cgroup's subsystems. Subsystems co-mounted
+// with a selected one, like cpu and cpuacct, share its hierarchy and are
+// selected as well, as the process moves in them regardless.
+func (c *cgroup) addSubsystems(opts []AddOpts) ([]pather, error) {
+	var config addConfig
+	for _, o := range opts {
+		o(&config)
+	}
+	all := pathers(c.subsystems)
+	if len(config.subsystems) == 0 {
+		return all, nil
+	}
+	selected := make(map[Name]bool, len(config.subsystems))
+	for _, name := range config.subsystems {
+		if _, ok := c.getSubsystem(name).(pather); !ok {
+			return nil, errors.Wrapf(ErrControllerNotActive, "subsystem %s", name)
+		}
+		selected[name] = true
+	}
+	hierarchies := make(map[string]bool, len(config.subsystems))
+	for _, s := range all {
+		if selected[s.Name()] {
+			hierarchies[hierarchyRoot(s)] = true
+		}
+	}
+	var out []pather
+	for _, s := range all {
+		if selected[s.Name()] || hierarchies[hierarchyRoot(s)] {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// hierarchyRoot returns the mount of the subsystem's hierarchy with symlinks
+// resolved, which is the same for co-mounted subsystems
+func hierarchyRoot(s pather) string {
+	root := s.Path("/")
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		return resolved
+	}
+	return root
+}
+
+// addError returns ErrFrozen or ErrDying when the state of the cgroup is why
+// the kernel refused to move a process into it, so that callers can thaw and
+// retry or give up. Other errors are returned unchanged.
+func (c *cgroup) addError(err error) error {
+	if errors.Is(err, unix.ENODEV) {
+		return &StateError{State: ErrDying, Err: err}
+	}
+	if !errors.Is(err, unix.EBUSY) && !os.IsNotExist(errors.Cause(err)) {
+		return err
+	}
+	// the handle is left as it is, adding to it must not mark it deleted
+	if !c.exists() {
+		return &StateError{State: ErrDying, Err: err}
+	}
+	// EBUSY is also how the kernel enforces that processes are only in
+	// leaves, it is only caused by the freezer when the group is frozen
+	if s := c.getSubsystem(Freezer); s != nil && errors.Is(err, unix.EBUSY) {
+		if sp, perr := c.path(Freezer); perr == nil {
+			if state, _ := s.(*freezerController).state(sp); state == Frozen || state == Freezing {
+				return &StateError{State: ErrFrozen, Err: err}
+			}
+		}
+	}
+	return err
+}
+
+// Delete will remove the control group from each of the subsystems registered.
+// Deleting a cgroup that was already deleted is not an error.
 func (c *cgroup) Delete() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.err != nil {
+		// deleting an already deleted cgroup is not an error so that callers
+		// racing with another teardown do not need to special case it
+		if c.err == ErrCgroupDeleted {
+			return nil
+		}
 		return c.err
 	}
 	var errs []string
@@ -223,7 +424,12 @@ func (c *cgroup) Delete() error {
 			if err != nil {
 				return err
 			}
-			if err := d.Delete(sp); err != nil {
+			if pd, ok := s.(pacedDeleter); ok {
+				err = pd.deletePaced(sp, clock.Or(c.pacer))
+			} else {
+				err = d.Delete(sp)
+			}
+			if err != nil {
 				errs = append(errs, string(s.Name()))
 			}
 			continue
@@ -234,7 +440,7 @@ func (c *cgroup) Delete() error {
 				return err
 			}
 			path := p.Path(sp)
-			if err := remove(path); err != nil {
+			if err := remove(path, clock.Or(c.pacer)); err != nil {
 				errs = append(errs, path)
 			}
 		}
@@ -262,6 +468,7 @@ func (c *cgroup) Stat(handlers ...ErrorHandler) (*v1.Metrics, error) {
 				Throttling: &v1.Throttle{},
 				Usage:      &v1.CPUUsage{},
 			},
+			Timestamp: c.clock.now(),
 		}
 		wg   = &sync.WaitGroup{}
 		errs = make(chan error, len(c.subsystems))
@@ -287,6 +494,12 @@ func (c *cgroup) Stat(handlers ...ErrorHandler) (*v1.Metrics, error) {
 	}
 	wg.Wait()
 	close(errs)
+	// report a deletion that raced with reading the stats as such instead
+	// of the errors or partial stats it caused
+	c.checkExists()
+	if c.err != nil {
+		return nil, c.err
+	}
 	for err := range errs {
 		return nil, err
 	}
@@ -299,6 +512,12 @@ func (c *cgroup) Stat(handlers ...ErrorHandler) (*v1.Metrics, error) {
 // live processes and other operations like Stats being performed at the
 // same time
 func (c *cgroup) Update(resources *specs.LinuxResources) error {
+	return c.update(resources, nil)
+}
+
+// update updates the cgroup, adding warnings to w for the resources of the
+// subsystems the cgroup is not in
+func (c *cgroup) update(resources *specs.LinuxResources, w *Warnings) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.err != nil {
@@ -315,9 +534,56 @@ func (c *cgroup) Update(resources *specs.LinuxResources) error {
 			}
 		}
 	}
+	for _, name := range resourceSubsystems(resources) {
+		if c.getSubsystem(name) == nil {
+			w.add(name, "not updated, the cgroup is not in the subsystem")
+		}
+	}
 	return nil
 }
 
+// resourceSubsystems returns the subsystems that apply the resources that
+// are set
+func resourceSubsystems(r *specs.LinuxResources) []Name {
+	if r == nil {
+		return nil
+	}
+	var names []Name
+	if r.Memory != nil {
+		names = append(names, Memory)
+	}
+	if r.CPU != nil {
+		names = append(names, Cpu)
+		if r.CPU.Cpus != "" || r.CPU.Mems != "" {
+			names = append(names, Cpuset)
+		}
+	}
+	if r.Pids != nil {
+		names = append(names, Pids)
+	}
+	if r.BlockIO != nil {
+		names = append(names, Blkio)
+	}
+	if len(r.HugepageLimits) > 0 {
+		names = append(names, Hugetlb)
+	}
+	if r.Network != nil {
+		if r.Network.ClassID != nil {
+			names = append(names, NetCLS)
+		}
+		if len(r.Network.Priorities) > 0 {
+			names = append(names, NetPrio)
+		}
+	}
+	if len(r.Devices) > 0 {
+		names = append(names, Devices)
+	}
+	if len(r.Rdma) > 0 {
+		names = append(names, Rdma)
+	}
+	return names
+}
+
 // Processes returns the processes running inside the cgroup along
 // with the subsystem used, pid, and path
 func (c *cgroup) Processes(subsystem Name, recursive bool) ([]Process, error) {
@@ -419,7 +685,7 @@ func (c *cgroup) Freeze() error {
 	if err != nil {
 		return err
 	}
-	return s.(*freezerController).Freeze(sp)
+	return s.(*freezerController).waitState(sp, Frozen, clock.Or(c.pacer))
 }
 
 // Thaw thaws out the cgroup and all the processes inside it
@@ -437,7 +703,7 @@ func (c *cgroup) Thaw() error {
 	if err != nil {
 		return err
 	}
-	return s.(*freezerController).Thaw(sp)
+	return s.(*freezerController).waitState(sp, Thawed, clock.Or(c.pacer))
 }
 
 // OOMEventFD returns the memory cgroup's out of memory event fd that triggers
@@ -537,16 +803,22 @@ func (c *cgroup) getSubsystem(n Name) Subsystem {
 }
 
 func (c *cgroup) checkExists() {
+	if !c.exists() {
+		c.err = ErrCgroupDeleted
+	}
+}
+
+// exists returns false only when the cgroup is gone from every subsystem, a
+// group missing from some of them still has the rest to be removed by Delete
+func (c *cgroup) exists() bool {
 	for _, s := range pathers(c.subsystems) {
 		p, err := c.path(s.Name())
 		if err != nil {
-			return
+			return true
 		}
-		if _, err := os.Lstat(s.Path(p)); err != nil {
-			if os.IsNotExist(err) {
-				c.err = ErrCgroupDeleted
-				return
-			}
+		if _, err := os.Lstat(s.Path(p)); !os.IsNotExist(err) {
+			return true
 		}
 	}
+	return false
 }
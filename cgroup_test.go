@@ -25,6 +25,8 @@ import (
 	"testing"
 
 	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
 )
 
 // using t.Error in test were defers do cleanup on the filesystem
@@ -56,6 +58,145 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreateAccountingOnly(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	limit := int64(1 << 20)
+	if _, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{
+		Pids:   &specs.LinuxPids{Limit: 10},
+		Memory: &specs.LinuxMemory{Limit: &limit},
+	}, WithAccountingOnly(), WithAccountingKnobs()); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"pids/test/pids.max", "memory/test/memory.limit_in_bytes"} {
+		if _, err := os.Stat(filepath.Join(mock.root, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be written, got %v", name, err)
+		}
+	}
+	for name, value := range map[string]string{
+		"memory.use_hierarchy":       "1",
+		"memory.kmem.limit_in_bytes": "-1",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(mock.root, string(Memory), "test", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != value {
+			t.Errorf("expected %s of %s, got %q", name, value, data)
+		}
+	}
+}
+
+func TestCreateWarnings(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	path := func(name Name) (string, error) {
+		if name == Hugetlb {
+			return "", ErrControllerNotActive
+		}
+		return "test", nil
+	}
+	var warnings Warnings
+	if _, err := New(mock.hierarchy, path, &specs.LinuxResources{}, WithWarnings(&warnings)); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Subsystem != Hugetlb {
+		t.Fatalf("expected a warning for the inactive hugetlb subsystem, got %v", warnings)
+	}
+}
+
+func TestGetOrCreate(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	limit := int64(10)
+	if _, err := GetOrCreate(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{
+		Pids: &specs.LinuxPids{Limit: limit},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	maxPath := filepath.Join(mock.root, string(Pids), "test", "pids.max")
+	readMax := func() string {
+		data, err := ioutil.ReadFile(maxPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if max := readMax(); max != "10" {
+		t.Fatalf("expected pids.max of 10 but received %q", max)
+	}
+
+	updated := &specs.LinuxResources{
+		Pids: &specs.LinuxPids{Limit: 20},
+	}
+	if _, err := GetOrCreate(mock.hierarchy, StaticPath("test"), updated); err != nil {
+		t.Fatal(err)
+	}
+	if max := readMax(); max != "10" {
+		t.Fatalf("expected the existing pids.max to be kept but received %q", max)
+	}
+	if _, err := GetOrCreate(mock.hierarchy, StaticPath("test"), updated, WithUpdateExisting()); err != nil {
+		t.Fatal(err)
+	}
+	if max := readMax(); max != "20" {
+		t.Fatalf("expected pids.max to be updated to 20 but received %q", max)
+	}
+
+	// resources of subsystems the existing cgroup is not in are reported
+	path := func(name Name) (string, error) {
+		if name == Hugetlb {
+			return "", ErrControllerNotActive
+		}
+		return "test", nil
+	}
+	var warnings Warnings
+	if _, err := GetOrCreate(mock.hierarchy, path, &specs.LinuxResources{
+		HugepageLimits: []specs.LinuxHugepageLimit{{Pagesize: "2MB", Limit: 1 << 21}},
+	}, WithUpdateExisting(), WithWarnings(&warnings)); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Subsystem != Hugetlb {
+		t.Fatalf("expected a warning for the hugetlb limits, got %v", warnings)
+	}
+}
+
+func TestGetOrCreatePartial(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	// a cgroup whose creation stopped after the first subsystems
+	if err := os.MkdirAll(filepath.Join(mock.root, string(Devices), "test"), defaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	cg, err := GetOrCreate(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{
+		Pids: &specs.LinuxPids{Limit: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(mock.root, string(Pids), "test", "pids.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "10" {
+		t.Fatalf("expected pids.max of 10 but received %q", data)
+	}
+	if len(cg.Subsystems()) != len(mock.subsystems) {
+		t.Fatalf("expected the cgroup in %d subsystems but it is in %d", len(mock.subsystems), len(cg.Subsystems()))
+	}
+}
+
 func TestStat(t *testing.T) {
 	mock, err := newMock()
 	if err != nil {
@@ -101,6 +242,72 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+func TestAddSubsystems(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := control.AddWithOpts(Process{Pid: 1234}, WithSubsystems(Memory, Cpu)); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range Subsystems() {
+		err := checkPid(mock, filepath.Join(string(s), "test"), 1234)
+		switch s {
+		case Memory, Cpu:
+			if err != nil {
+				t.Fatal(err)
+			}
+		default:
+			if err == nil {
+				t.Fatalf("expected the process not to be added to %s", s)
+			}
+		}
+	}
+	err = control.AddWithOpts(Process{Pid: 5678}, WithSubsystems(Memory, "unknown"))
+	if errors.Cause(err) != ErrControllerNotActive {
+		t.Fatalf("expected ErrControllerNotActive but received %v", err)
+	}
+	if err := checkPid(mock, filepath.Join(string(Memory), "test"), 1234); err != nil {
+		t.Fatalf("expected nothing to be written for an unknown subsystem: %v", err)
+	}
+}
+
+func TestAddSubsystemsCoMounted(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	// mount cpuacct together with cpu, as most distributions do
+	cpuacct := filepath.Join(mock.root, string(Cpuacct))
+	if err := os.Remove(cpuacct); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(mock.root, string(Cpu)), cpuacct); err != nil {
+		t.Fatal(err)
+	}
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subsystems, err := control.(*cgroup).addSubsystems([]AddOpts{WithSubsystems(Cpu)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []Name
+	for _, s := range subsystems {
+		names = append(names, s.Name())
+	}
+	if len(names) != 2 || names[0] != Cpu || names[1] != Cpuacct {
+		t.Fatalf("expected cpu and cpuacct to be selected but received %v", names)
+	}
+}
+
 func TestAddTask(t *testing.T) {
 	mock, err := newMock()
 	if err != nil {
@@ -315,6 +522,60 @@ func TestDelete(t *testing.T) {
 	if err := control.Delete(); err != nil {
 		t.Error(err)
 	}
+	if err := control.Delete(); err != nil {
+		t.Errorf("expected deleting twice to succeed: %v", err)
+	}
+}
+
+func TestStatDeleted(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// remove the cgroup behind the back of the handle
+	for _, s := range mock.subsystems {
+		if err := os.RemoveAll(filepath.Join(mock.root, string(s.Name()), "test")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := control.Stat(IgnoreNotExist); err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted, got %v", err)
+	}
+	if err := control.Delete(); err != nil {
+		t.Fatalf("expected deleting a removed cgroup to succeed: %v", err)
+	}
+}
+
+func TestDeletePartiallyRemoved(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a single subsystem losing the group does not make it deleted
+	if err := os.RemoveAll(filepath.Join(mock.root, string(Cpu), "test")); err != nil {
+		t.Fatal(err)
+	}
+	if state := control.State(); state == Deleted {
+		t.Fatal("expected a partially removed cgroup not to be deleted")
+	}
+	if err := control.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range Subsystems() {
+		if _, err := os.Stat(filepath.Join(mock.root, string(s), "test")); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed: %v", s, err)
+		}
+	}
 }
 
 func TestCreateSubCgroup(t *testing.T) {
@@ -435,3 +696,41 @@ func TestCpusetParent(t *testing.T) {
 		}
 	}
 }
+
+func TestAddError(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := control.(*cgroup)
+	busy := &os.PathError{Op: "write", Path: "cgroup.procs", Err: unix.EBUSY}
+	if err := c.addError(busy); err != busy {
+		t.Fatalf("expected EBUSY to be returned unchanged for a thawed cgroup, got %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(mock.root, "freezer", "test", "freezer.state"), []byte("FROZEN\n"), defaultFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.addError(busy); errors.Cause(err) != ErrFrozen || !errors.Is(err, unix.EBUSY) {
+		t.Fatalf("expected ErrFrozen wrapping EBUSY, got %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(mock.root, "*", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range matches {
+		if err := os.RemoveAll(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.addError(busy); errors.Cause(err) != ErrDying {
+		t.Fatalf("expected ErrDying for a removed cgroup, got %v", err)
+	}
+	if c.err != nil {
+		t.Fatalf("expected the handle to be unchanged, got %v", c.err)
+	}
+}
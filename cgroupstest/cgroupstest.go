@@ -0,0 +1,328 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package cgroupstest provides a sandbox group in the cgroup2 hierarchy for
+// tests that need to create real cgroups
+package cgroupstest
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// Sandbox is a group in the cgroup2 filesystem, every group a test creates
+// under it is removed when the sandbox is closed
+type Sandbox struct {
+	// Mountpoint is where the cgroup2 filesystem is mounted
+	Mountpoint string
+	// Group is the path of the sandbox group relative to Mountpoint
+	Group string
+
+	// mounted is set when New mounted the filesystem itself
+	mounted bool
+	// entered is set when the sandbox holds a reference to the leaf
+	entered bool
+}
+
+// New creates a sandbox group as a sibling of the test process's group. An
+// existing mount of the cgroup2 filesystem is used when there is one, so
+// that a crashed test leaves no mount behind. Otherwise the filesystem is
+// mounted at a temporary directory with the options it already has: the
+// options are shared by every mount and a new mount from the initial cgroup
+// namespace would reset them. The test is skipped when it does not run as
+// root or the kernel does not support cgroup2.
+//
+// A group other than the root cannot both have processes and enable
+// controllers for its children, so unless the test process runs in the
+// root group it is first moved into a leaf group of its own and the
+// controllers of the group it ran in are enabled for the sandbox. The test
+// is skipped when other processes share that group. The process is moved
+// back once the last sandbox is closed.
+func New(t testing.TB) *Sandbox {
+	if os.Geteuid() != 0 {
+		t.Skip("the cgroup sandbox requires root")
+	}
+	existing, options, err := findMount()
+	if err != nil {
+		t.Skipf("unable to read the cgroup2 mounts: %v", err)
+	}
+	s := &Sandbox{Mountpoint: existing}
+	if existing == "" {
+		mountpoint, err := ioutil.TempDir("", "cgroupstest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := unix.Mount("cgroup2", mountpoint, "cgroup2", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, options); err != nil {
+			os.Remove(mountpoint)
+			t.Skipf("unable to mount cgroup2: %v", err)
+		}
+		s.Mountpoint, s.mounted = mountpoint, true
+	}
+	self, err := selfGroup()
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	parent, err := enterLeaf(s.Mountpoint, self)
+	if err != nil {
+		s.Close()
+		t.Skipf("unable to delegate the controllers of %s to the sandbox: %v", self, err)
+	}
+	s.entered = true
+	dir, err := ioutil.TempDir(filepath.Join(s.Mountpoint, parent), fmt.Sprintf("cgroupstest-%d-", os.Getpid()))
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	s.Group = filepath.Join(parent, filepath.Base(dir))
+	return s
+}
+
+// Path returns the absolute path of the sandbox group
+func (s *Sandbox) Path() string {
+	return filepath.Join(s.Mountpoint, s.Group)
+}
+
+// Close removes the sandbox group and its descendants and unmounts the
+// filesystem if New mounted it. Processes left in the groups keep them from
+// being removed.
+func (s *Sandbox) Close() error {
+	var err error
+	if s.Group != "" {
+		err = removeGroups(s.Path())
+	}
+	if s.entered {
+		if lerr := exitLeaf(s.Mountpoint); lerr != nil && err == nil {
+			err = lerr
+		}
+		s.entered = false
+	}
+	if !s.mounted {
+		return err
+	}
+	if uerr := unix.Unmount(s.Mountpoint, unix.MNT_DETACH); uerr != nil && err == nil {
+		err = uerr
+	}
+	if rerr := os.Remove(s.Mountpoint); rerr != nil && err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// leaf is the group the test process is moved to so that the group it ran
+// in has no processes of its own, shared by the sandboxes of the process.
+// The groups are relative to the mountpoint as every sandbox may use its
+// own mount of the filesystem.
+var leaf struct {
+	sync.Mutex
+	users int
+	// parent is the group the process ran in, group its leaf and empty when
+	// the process runs in the root group and was not moved
+	parent, group string
+	// enabled are the controllers enabled in the subtree_control of parent
+	enabled []string
+}
+
+// enterLeaf moves the process out of self into a leaf group, unless self is
+// the root group or the process was moved already, and enables the
+// controllers of the parent for its children. It returns the group the
+// sandboxes are created in.
+func enterLeaf(mountpoint, self string) (string, error) {
+	leaf.Lock()
+	defer leaf.Unlock()
+	if leaf.users > 0 {
+		leaf.users++
+		return leaf.parent, nil
+	}
+	if self == "/" {
+		leaf.parent, leaf.group, leaf.enabled = self, "", nil
+		leaf.users++
+		return self, nil
+	}
+	group := filepath.Join(self, fmt.Sprintf("cgroupstest-leaf-%d", os.Getpid()))
+	if err := os.Mkdir(filepath.Join(mountpoint, group), 0755); err != nil {
+		return "", err
+	}
+	if err := writeGroupFile(mountpoint, group, "cgroup.procs", strconv.Itoa(os.Getpid())); err != nil {
+		unix.Rmdir(filepath.Join(mountpoint, group))
+		return "", err
+	}
+	leaf.parent, leaf.group = self, group
+	enabled, err := enableControllers(mountpoint, self)
+	leaf.enabled = enabled
+	if err != nil {
+		leaveLeaf(mountpoint)
+		return "", err
+	}
+	leaf.users++
+	return self, nil
+}
+
+// exitLeaf drops a reference to the leaf, moving the process back to the
+// group it ran in once the last sandbox is closed
+func exitLeaf(mountpoint string) error {
+	leaf.Lock()
+	defer leaf.Unlock()
+	if leaf.users--; leaf.users > 0 {
+		return nil
+	}
+	return leaveLeaf(mountpoint)
+}
+
+// leaveLeaf disables the controllers that were enabled for the sandboxes,
+// moves the process back to the parent and removes the leaf
+func leaveLeaf(mountpoint string) error {
+	if leaf.group == "" {
+		return nil
+	}
+	var err error
+	for _, c := range leaf.enabled {
+		// the parent cannot take the process back while it delegates
+		// controllers to its children
+		if werr := writeGroupFile(mountpoint, leaf.parent, "cgroup.subtree_control", "-"+c); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	if werr := writeGroupFile(mountpoint, leaf.parent, "cgroup.procs", strconv.Itoa(os.Getpid())); werr != nil && err == nil {
+		err = werr
+	}
+	if rerr := unix.Rmdir(filepath.Join(mountpoint, leaf.group)); rerr != nil && err == nil {
+		err = rerr
+	}
+	leaf.group, leaf.enabled = "", nil
+	return err
+}
+
+// enableControllers enables the controllers available to group for its
+// children, returning those that were not enabled already
+func enableControllers(mountpoint, group string) ([]string, error) {
+	available, err := ioutil.ReadFile(filepath.Join(mountpoint, group, "cgroup.controllers"))
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(mountpoint, group, "cgroup.subtree_control"))
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]bool)
+	for _, c := range strings.Fields(string(data)) {
+		current[c] = true
+	}
+	var enabled []string
+	for _, c := range strings.Fields(string(available)) {
+		if current[c] {
+			continue
+		}
+		if err := writeGroupFile(mountpoint, group, "cgroup.subtree_control", "+"+c); err != nil {
+			return enabled, err
+		}
+		enabled = append(enabled, c)
+	}
+	return enabled, nil
+}
+
+func writeGroupFile(mountpoint, group, name, value string) error {
+	return ioutil.WriteFile(filepath.Join(mountpoint, group, name), []byte(value), 0)
+}
+
+// removeGroups removes the group at path after its descendants, only the
+// directories need to be removed on a cgroup filesystem
+func removeGroups(path string) error {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			if err := removeGroups(filepath.Join(path, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return unix.Rmdir(path)
+}
+
+// findMount returns a mountpoint of the cgroup2 filesystem that shows the
+// root of the hierarchy, and the superblock options of any cgroup2 mount.
+// Both are empty when the filesystem is not mounted.
+func findMount() (string, string, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	var mountpoint, options string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		// the optional fields end with a "-" separator followed by the
+		// filesystem type, the source and the superblock options
+		if len(fields) < 10 || fields[len(fields)-3] != "cgroup2" {
+			continue
+		}
+		if options == "" {
+			options = superOptions(fields[len(fields)-1])
+		}
+		if fields[3] == "/" && !strings.ContainsRune(fields[4], '\\') {
+			mountpoint = fields[4]
+			break
+		}
+	}
+	return mountpoint, options, s.Err()
+}
+
+// superOptions returns the cgroup2 options from the superblock options of
+// mountinfo, leaving out rw and the seclabel reported by SELinux
+func superOptions(options string) string {
+	var out []string
+	for _, o := range strings.Split(options, ",") {
+		if o != "rw" && o != "ro" && o != "seclabel" {
+			out = append(out, o)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// selfGroup returns the unified group of the current process
+func selfGroup() (string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if group := strings.TrimPrefix(s.Text(), "0::"); group != s.Text() {
+			return group, nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "/", nil
+}
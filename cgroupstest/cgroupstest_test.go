@@ -0,0 +1,109 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroupstest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestSandbox(t *testing.T) {
+	s := New(t)
+	if _, err := os.Stat(filepath.Join(s.Path(), "cgroup.procs")); err != nil {
+		t.Fatalf("expected the sandbox to be a cgroup2 group: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(s.Path(), "a", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.Path()); !os.IsNotExist(err) {
+		t.Fatalf("expected the sandbox group to be removed: %v", err)
+	}
+	_, err := os.Stat(s.Mountpoint)
+	if s.mounted && !os.IsNotExist(err) {
+		t.Fatalf("expected the mountpoint to be removed: %v", err)
+	}
+	if !s.mounted && err != nil {
+		t.Fatalf("expected the existing mount to be kept: %v", err)
+	}
+}
+
+func TestSandboxLeaf(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("the cgroup sandbox requires root")
+	}
+	mountpoint, _, err := findMount()
+	if err != nil || mountpoint == "" {
+		t.Skipf("no cgroup2 mount: %v", err)
+	}
+	self, err := selfGroup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// run the test process in a group that is not the root
+	outer := filepath.Join(self, fmt.Sprintf("cgroupstest-outer-%d", os.Getpid()))
+	if err := os.Mkdir(filepath.Join(mountpoint, outer), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Rmdir(filepath.Join(mountpoint, outer))
+	if err := writeGroupFile(mountpoint, outer, "cgroup.procs", strconv.Itoa(os.Getpid())); err != nil {
+		t.Fatal(err)
+	}
+	defer writeGroupFile(mountpoint, self, "cgroup.procs", strconv.Itoa(os.Getpid()))
+
+	s := New(t)
+	if filepath.Dir(s.Group) != outer {
+		t.Fatalf("expected the sandbox to be created in %s, got %s", outer, s.Group)
+	}
+	if group, err := selfGroup(); err != nil || filepath.Dir(group) != outer || group == s.Group {
+		t.Fatalf("expected the process to be moved to a leaf next to the sandbox, got %s: %v", group, err)
+	}
+	available, err := ioutil.ReadFile(filepath.Join(mountpoint, outer, "cgroup.controllers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabled, err := ioutil.ReadFile(filepath.Join(mountpoint, outer, "cgroup.subtree_control"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(enabled) != string(available) {
+		t.Fatalf("expected the controllers %q to be enabled for the sandbox, got %q", available, enabled)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if group, err := selfGroup(); err != nil || group != outer {
+		t.Fatalf("expected the process to be moved back to %s, got %s: %v", outer, group, err)
+	}
+	entries, err := ioutil.ReadDir(filepath.Join(mountpoint, outer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			t.Fatalf("expected the leaf to be removed, found %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package clock provides the time to the time-dependent behavior of the
+// cgroups packages, such as retry backoff, polling and sampling intervals
+// and replay windows, so that tests can replace it with a fake clock and
+// run deterministically
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a source of time
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After sends the current time on the returned channel once d has
+	// elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Sleep blocks until d has elapsed on the clock
+func Sleep(c Clock, d time.Duration) {
+	<-c.After(d)
+}
+
+// Ticker paces a loop at a fixed interval on a clock. Unlike waiting on
+// After in every iteration, the ticks are scheduled from the first one so
+// that the time spent between them does not make the interval drift, and
+// ticks missed by a slow loop are dropped like those of a time.Ticker.
+type Ticker struct {
+	c    Clock
+	d    time.Duration
+	next time.Time
+}
+
+// NewTicker returns a ticker whose first tick is d from now
+func NewTicker(c Clock, d time.Duration) *Ticker {
+	c = Or(c)
+	return &Ticker{
+		c:    c,
+		d:    d,
+		next: c.Now().Add(d),
+	}
+}
+
+// Next returns a channel that receives the time of the next tick, at once
+// if it is already due
+func (t *Ticker) Next() <-chan time.Time {
+	now := t.c.Now()
+	next := t.next
+	for t.next = next.Add(t.d); !t.next.After(now); {
+		t.next = t.next.Add(t.d)
+	}
+	return t.c.After(next.Sub(now))
+}
+
+// Or returns c, or Real if c is nil
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+// Fake is a clock that only advances when Advance is called
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFake returns a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the time of the fake clock
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the time once the clock has been
+// advanced by d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, waiter{
+		at: f.now.Add(d),
+		ch: ch,
+	})
+	f.cond.Broadcast()
+	return ch
+}
+
+// Advance moves the clock forward by d, waking the waiters that are due
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- f.now
+	}
+	f.waiters = pending
+}
+
+// Waiters returns the number of pending After calls
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until there are at least n pending After calls, letting
+// a test advance the clock only once the code under test is waiting on it
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
@@ -0,0 +1,112 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	if now := f.Now(); !now.Equal(start) {
+		t.Fatalf("expected %s, got %s", start, now)
+	}
+	select {
+	case <-f.After(0):
+	default:
+		t.Fatal("expected a zero duration to fire immediately")
+	}
+
+	done := make(chan time.Time)
+	go func() {
+		done <- <-f.After(time.Second)
+	}()
+	f.BlockUntil(1)
+	f.Advance(500 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("expected the waiter to still be pending")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if n := f.Waiters(); n != 1 {
+		t.Fatalf("expected 1 waiter, got %d", n)
+	}
+	f.Advance(500 * time.Millisecond)
+	if now := <-done; !now.Equal(start.Add(time.Second)) {
+		t.Fatalf("expected the waiter to receive %s, got %s", start.Add(time.Second), now)
+	}
+	if n := f.Waiters(); n != 0 {
+		t.Fatalf("expected no waiters, got %d", n)
+	}
+}
+
+func TestOr(t *testing.T) {
+	if Or(nil) != Real {
+		t.Fatal("expected nil to use the real clock")
+	}
+	f := NewFake(time.Time{})
+	if Or(f) != f {
+		t.Fatal("expected the clock to be kept")
+	}
+}
+
+func TestTicker(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	tk := NewTicker(f, 10*time.Second)
+	fired := func(ch <-chan time.Time) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+
+	ch := tk.Next()
+	f.Advance(10 * time.Second)
+	if !fired(ch) {
+		t.Fatal("expected the first tick after the interval")
+	}
+	// the time spent after a tick is not added to the interval
+	f.Advance(3 * time.Second)
+	ch = tk.Next()
+	f.Advance(6 * time.Second)
+	if fired(ch) {
+		t.Fatal("expected the second tick to still be pending")
+	}
+	f.Advance(time.Second)
+	if !fired(ch) {
+		t.Fatal("expected the second tick 10s after the first")
+	}
+	// ticks missed by a slow loop are dropped
+	f.Advance(25 * time.Second)
+	if !fired(tk.Next()) {
+		t.Fatal("expected a due tick to fire at once")
+	}
+	ch = tk.Next()
+	f.Advance(4 * time.Second)
+	if fired(ch) {
+		t.Fatal("expected the missed ticks to be dropped")
+	}
+	f.Advance(time.Second)
+	if !fired(ch) {
+		t.Fatal("expected the tick on the original schedule")
+	}
+}
@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/containerd/cgroups/stats/v1"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestStatTimestamp(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{}, WithClockSource(ClockBoottime))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, err := control.Stat(IgnoreNotExist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	cur, err := control.Stat(IgnoreNotExist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev.Timestamp == 0 {
+		t.Fatal("expected metrics to be timestamped")
+	}
+	if d := Interval(prev, cur); d < time.Millisecond {
+		t.Fatalf("expected at least 1ms between samples, got %s", d)
+	}
+}
+
+func TestInterval(t *testing.T) {
+	for _, test := range []struct {
+		prev, cur uint64
+		expected  time.Duration
+	}{
+		{100, 350, 250},
+		{0, 350, 0},
+		{350, 100, 0},
+	} {
+		if d := Interval(&v1.Metrics{Timestamp: test.prev}, &v1.Metrics{Timestamp: test.cur}); d != test.expected {
+			t.Errorf("%d-%d: expected %s, got %s", test.prev, test.cur, test.expected, d)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/containerd/cgroups/service"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "cgroupd"
+	app.Version = "1"
+	app.Usage = "cgroup management broker for unprivileged clients"
+	app.Flags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "debug",
+			Usage: "enable debug output in the logs",
+		},
+		cli.StringFlag{
+			Name:  "address",
+			Usage: "unix socket to serve the api on",
+			Value: "/run/cgroupd/cgroupd.sock",
+		},
+		cli.IntFlag{
+			Name:  "gid",
+			Usage: "group allowed to connect to the socket",
+			Value: -1,
+		},
+		cli.StringFlag{
+			Name:  "root",
+			Usage: "parent group that all requests are scoped to, required as any client can manage every group below it",
+		},
+		cli.StringFlag{
+			Name:  "mountpoint",
+			Usage: "cgroup mountpoint",
+			Value: "/sys/fs/cgroup",
+		},
+		cli.IntFlag{
+			Name:  "subscribe-max-paths",
+			Usage: "maximum number of groups of a subscription",
+			Value: 64,
+		},
+		cli.DurationFlag{
+			Name:  "subscribe-min-interval",
+			Usage: "minimum interval between the samples of a subscription",
+			Value: 100 * time.Millisecond,
+		},
+	}
+	app.Before = func(clix *cli.Context) error {
+		if clix.GlobalBool("debug") {
+			logrus.SetLevel(logrus.DebugLevel)
+		}
+		return nil
+	}
+	app.Action = serve
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func serve(clix *cli.Context) error {
+	root := clix.GlobalString("root")
+	if root == "" {
+		return errors.New("--root is required, use \"/\" to broker all groups of the host")
+	}
+	address := clix.GlobalString("address")
+	l, err := listen(address, clix.GlobalInt("gid"))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(address)
+
+	server := grpc.NewServer()
+	service.NewServer(
+		service.WithRoot(root),
+		service.WithMountpoint(clix.GlobalString("mountpoint")),
+		service.WithSubscribeLimits(clix.GlobalInt("subscribe-max-paths"), clix.GlobalDuration("subscribe-min-interval")),
+	).Register(server)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		s := <-signals
+		logrus.WithField("signal", s).Debug("shutting down")
+		server.GracefulStop()
+	}()
+	logrus.WithField("address", address).Info("serving cgroups api")
+	return server.Serve(l)
+}
+
+// listen creates the unix socket at address, only allowing the owner and the
+// provided gid to connect to it
+func listen(address string, gid int) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(address), 0711); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	perm := os.FileMode(0600)
+	if gid >= 0 {
+		if err := os.Chown(address, -1, gid); err != nil {
+			l.Close()
+			return nil, err
+		}
+		perm = 0660
+	}
+	if err := os.Chmod(address, perm); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
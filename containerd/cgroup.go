@@ -0,0 +1,200 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package containerd adapts the v1 and v2 cgroup implementations to the
+// single shape used by containerd's runtime shims, so that callers do not need
+// to branch on the host's cgroups mode.
+package containerd
+
+import (
+	"github.com/containerd/cgroups"
+	v1 "github.com/containerd/cgroups/stats/v1"
+	v2 "github.com/containerd/cgroups/v2"
+	"github.com/containerd/cgroups/v2/stats"
+	"github.com/gogo/protobuf/proto"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// unifiedMountpoint is the mountpoint used for v2 groups
+const unifiedMountpoint = "/sys/fs/cgroup"
+
+// Metrics are the stats of a cgroup, either a *v1.Metrics or a
+// *stats.Metrics depending on the hierarchy. They are registered protobuf
+// messages so that they can be marshaled into a task's metrics as they are.
+type Metrics = proto.Message
+
+var (
+	_ Metrics = &v1.Metrics{}
+	_ Metrics = &stats.Metrics{}
+)
+
+// Cgroup is the cgroup functionality containerd's runtime uses for a task
+type Cgroup interface {
+	// Add adds a process to the cgroup
+	Add(pid int) error
+	// Update updates the cgroup with the provided resources, nil resources
+	// leave the cgroup unchanged
+	Update(resources *specs.LinuxResources) error
+	// Stat returns the metrics of the cgroup
+	Stat() (Metrics, error)
+	// Pids returns the pids of all the processes in the cgroup and its children
+	Pids() ([]int, error)
+	// Freeze freezes all processes in the cgroup
+	Freeze() error
+	// Thaw thaws all processes in the cgroup
+	Thaw() error
+	// Delete removes the cgroup
+	Delete() error
+}
+
+// New creates a new cgroup at path using the host's cgroups mode
+func New(path string, resources *specs.LinuxResources) (Cgroup, error) {
+	if resources == nil {
+		resources = &specs.LinuxResources{}
+	}
+	if cgroups.Mode() == cgroups.Unified {
+		m, err := v2.NewManager(unifiedMountpoint, path, v2.ToResources(resources))
+		if err != nil {
+			return nil, err
+		}
+		return FromV2(m), nil
+	}
+	cg, err := cgroups.New(cgroups.V1, cgroups.StaticPath(path), resources)
+	if err != nil {
+		return nil, err
+	}
+	return FromV1(cg), nil
+}
+
+// Load loads an existing cgroup at path using the host's cgroups mode
+func Load(path string) (Cgroup, error) {
+	if cgroups.Mode() == cgroups.Unified {
+		m, err := v2.LoadManager(unifiedMountpoint, path)
+		if err != nil {
+			return nil, err
+		}
+		return FromV2(m), nil
+	}
+	cg, err := cgroups.Load(cgroups.V1, cgroups.StaticPath(path))
+	if err != nil {
+		return nil, err
+	}
+	return FromV1(cg), nil
+}
+
+// FromV1 returns a Cgroup for an existing v1 cgroup
+func FromV1(cg cgroups.Cgroup) Cgroup {
+	return &v1Cgroup{cg: cg}
+}
+
+// FromV2 returns a Cgroup for an existing v2 manager
+func FromV2(m *v2.Manager) Cgroup {
+	return &v2Cgroup{m: m}
+}
+
+type v1Cgroup struct {
+	cg cgroups.Cgroup
+}
+
+func (c *v1Cgroup) Add(pid int) error {
+	return c.cg.Add(cgroups.Process{Pid: pid})
+}
+
+func (c *v1Cgroup) Update(resources *specs.LinuxResources) error {
+	if resources == nil {
+		return nil
+	}
+	return c.cg.Update(resources)
+}
+
+func (c *v1Cgroup) Stat() (Metrics, error) {
+	metrics, err := c.cg.Stat(cgroups.IgnoreNotExist)
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+func (c *v1Cgroup) Pids() ([]int, error) {
+	processes, err := c.cg.Processes(cgroups.Devices, true)
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int, len(processes))
+	for i, p := range processes {
+		pids[i] = p.Pid
+	}
+	return pids, nil
+}
+
+func (c *v1Cgroup) Freeze() error {
+	return c.cg.Freeze()
+}
+
+func (c *v1Cgroup) Thaw() error {
+	return c.cg.Thaw()
+}
+
+func (c *v1Cgroup) Delete() error {
+	return c.cg.Delete()
+}
+
+type v2Cgroup struct {
+	m *v2.Manager
+}
+
+func (c *v2Cgroup) Add(pid int) error {
+	return c.m.AddProc(uint64(pid))
+}
+
+func (c *v2Cgroup) Update(resources *specs.LinuxResources) error {
+	if resources == nil {
+		return nil
+	}
+	return c.m.Update(v2.ToResources(resources))
+}
+
+func (c *v2Cgroup) Stat() (Metrics, error) {
+	metrics, err := c.m.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+func (c *v2Cgroup) Pids() ([]int, error) {
+	procs, err := c.m.Procs(true)
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int, len(procs))
+	for i, p := range procs {
+		pids[i] = int(p)
+	}
+	return pids, nil
+}
+
+func (c *v2Cgroup) Freeze() error {
+	return c.m.Freeze()
+}
+
+func (c *v2Cgroup) Thaw() error {
+	return c.m.Thaw()
+}
+
+func (c *v2Cgroup) Delete() error {
+	return c.m.Delete()
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package containerd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/cgroups"
+	v2 "github.com/containerd/cgroups/v2"
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestV2Adapter(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "containerd-cgroups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	group := filepath.Join(mountpoint, "task")
+	if err := os.Mkdir(group, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{
+		"cgroup.controllers": "pids",
+		"cgroup.procs":       "",
+		"pids.current":       "1",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(group, name), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := v2.LoadManager(mountpoint, "/task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cg := FromV2(m)
+	if err := cg.Add(42); err != nil {
+		t.Fatal(err)
+	}
+	pids, err := cg.Pids()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pids) != 1 || pids[0] != 42 {
+		t.Fatalf("expected pids [42] but received %v", pids)
+	}
+	metrics, err := cg.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, ok := metrics.(*stats.Metrics)
+	if !ok {
+		t.Fatalf("expected v2 metrics but received %T", metrics)
+	}
+	if m2.Pids == nil || m2.Pids.Current != 1 {
+		t.Fatalf("unexpected pids stats %+v", m2.Pids)
+	}
+}
+
+// nilCgroup panics on every call, so an adapter must not reach it
+type nilCgroup struct {
+	cgroups.Cgroup
+}
+
+func TestUpdateNilResources(t *testing.T) {
+	if err := FromV1(nilCgroup{}).Update(nil); err != nil {
+		t.Fatalf("v1: %v", err)
+	}
+
+	mountpoint, err := ioutil.TempDir("", "containerd-cgroups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, "cgroup.controllers"), []byte("pids"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := v2.LoadManager(mountpoint, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := FromV2(m).Update(nil); err != nil {
+		t.Fatalf("v2: %v", err)
+	}
+	entries, err := ioutil.ReadDir(mountpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("v2: expected nothing to be written but found %d files", len(entries))
+	}
+}
@@ -24,9 +24,10 @@ import (
 )
 
 const (
-	cgroupProcs    = "cgroup.procs"
-	cgroupTasks    = "tasks"
-	defaultDirPerm = 0755
+	cgroupProcs         = "cgroup.procs"
+	cgroupTasks         = "tasks"
+	cgroupCloneChildren = "cgroup.clone_children"
+	defaultDirPerm      = 0755
 )
 
 // defaultFilePerm is a var so that the test framework can change the filemode
@@ -61,8 +62,14 @@ type Cgroup interface {
 	New(string, *specs.LinuxResources) (Cgroup, error)
 	// Add adds a process to the cgroup (cgroup.procs)
 	Add(Process) error
+	// AddWithOpts adds a process to the cgroup (cgroup.procs) as configured
+	// by the options
+	AddWithOpts(Process, ...AddOpts) error
 	// AddTask adds a process to the cgroup (tasks)
 	AddTask(Process) error
+	// AddTaskWithOpts adds a process to the cgroup (tasks) as configured by
+	// the options
+	AddTaskWithOpts(Process, ...AddOpts) error
 	// Delete removes the cgroup as a whole
 	Delete() error
 	// MoveTo moves all the processes under the calling cgroup to the provided one
@@ -18,12 +18,14 @@ package cgroups
 
 import (
 	"bufio"
+	"bytes"
 	"os"
 	"path/filepath"
 	"strconv"
 
 	v1 "github.com/containerd/cgroups/stats/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/sirupsen/logrus"
 )
 
 func NewCpu(root string) *cpuController {
@@ -100,17 +102,17 @@ func (c *cpuController) Update(path string, resources *specs.LinuxResources) err
 }
 
 func (c *cpuController) Stat(path string, stats *v1.Metrics) error {
-	f, err := os.Open(filepath.Join(c.Path(path), "cpu.stat"))
+	data, err := readBounded(filepath.Join(c.Path(path), "cpu.stat"))
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 	// get or create the cpu field because cpuacct can also set values on this struct
-	sc := bufio.NewScanner(f)
+	sc := bufio.NewScanner(bytes.NewReader(data))
 	for sc.Scan() {
 		key, v, err := parseKV(sc.Text())
 		if err != nil {
-			return err
+			logrus.Warnf("cgroups: skipping invalid cpu.stat line %q: %v", sc.Text(), err)
+			continue
 		}
 		switch key {
 		case "nr_periods":
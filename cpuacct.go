@@ -18,7 +18,6 @@ package cgroups
 
 import (
 	"fmt"
-	"io/ioutil"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -70,7 +69,7 @@ func (c *cpuacctController) Stat(path string, stats *v1.Metrics) error {
 
 func (c *cpuacctController) percpuUsage(path string) ([]uint64, error) {
 	var usage []uint64
-	data, err := ioutil.ReadFile(filepath.Join(c.Path(path), "cpuacct.usage_percpu"))
+	data, err := readBounded(filepath.Join(c.Path(path), "cpuacct.usage_percpu"))
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +85,7 @@ func (c *cpuacctController) percpuUsage(path string) ([]uint64, error) {
 
 func (c *cpuacctController) getUsage(path string) (user uint64, kernel uint64, err error) {
 	statPath := filepath.Join(c.Path(path), "cpuacct.stat")
-	data, err := ioutil.ReadFile(statPath)
+	data, err := readBounded(statPath)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -19,21 +19,34 @@ package cgroups
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 )
 
-func NewCpuset(root string) *cpusetController {
-	return &cpusetController{
+func NewCpuset(root string, options ...func(*cpusetController)) *cpusetController {
+	c := &cpusetController{
 		root: filepath.Join(root, string(Cpuset)),
 	}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// CloneChildren sets cgroup.clone_children on the parents of created cgroups
+// so the kernel initializes the cpus and mems of new children from their
+// parent. Without it they are copied from the parent by the controller.
+func CloneChildren() func(*cpusetController) {
+	return func(c *cpusetController) {
+		c.cloneChildren = true
+	}
 }
 
 type cpusetController struct {
-	root string
+	root          string
+	cloneChildren bool
 }
 
 func (c *cpusetController) Name() Name {
@@ -48,6 +61,9 @@ func (c *cpusetController) Create(path string, resources *specs.LinuxResources)
 	if err := c.ensureParent(c.Path(path), c.root); err != nil {
 		return err
 	}
+	if err := c.setCloneChildren(filepath.Dir(c.Path(path))); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(c.Path(path), defaultDirPerm); err != nil {
 		return err
 	}
@@ -87,10 +103,10 @@ func (c *cpusetController) Update(path string, resources *specs.LinuxResources)
 }
 
 func (c *cpusetController) getValues(path string) (cpus []byte, mems []byte, err error) {
-	if cpus, err = ioutil.ReadFile(filepath.Join(path, "cpuset.cpus")); err != nil && !os.IsNotExist(err) {
+	if cpus, err = readBounded(filepath.Join(path, "cpuset.cpus")); err != nil && !os.IsNotExist(err) {
 		return
 	}
-	if mems, err = ioutil.ReadFile(filepath.Join(path, "cpuset.mems")); err != nil && !os.IsNotExist(err) {
+	if mems, err = readBounded(filepath.Join(path, "cpuset.mems")); err != nil && !os.IsNotExist(err) {
 		return
 	}
 	return cpus, mems, nil
@@ -113,12 +129,39 @@ func (c *cpusetController) ensureParent(current, root string) error {
 			return err
 		}
 	}
+	if err := c.setCloneChildren(parent); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(current, defaultDirPerm); err != nil {
 		return err
 	}
 	return c.copyIfNeeded(current, parent)
 }
 
+// prepareAttach populates the cpus and mems of the cgroup from its parent if
+// they are still empty, writing tasks to a cpuset cgroup without them fails
+// with ENOSPC
+func (c *cpusetController) prepareAttach(path string) error {
+	current := c.Path(path)
+	if cleanPath(current) == c.root {
+		return nil
+	}
+	return c.copyIfNeeded(current, filepath.Dir(current))
+}
+
+// setCloneChildren enables cgroup.clone_children on the parent when the
+// controller is configured with CloneChildren
+func (c *cpusetController) setCloneChildren(parent string) error {
+	if !c.cloneChildren {
+		return nil
+	}
+	return retryingWriteFile(
+		filepath.Join(parent, cgroupCloneChildren),
+		[]byte("1"),
+		defaultFilePerm,
+	)
+}
+
 // copyIfNeeded copies the cpuset.cpus and cpuset.mems from the parent
 // directory to the current directory if the file's contents are 0
 func (c *cpusetController) copyIfNeeded(current, parent string) error {
@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestCpusetCloneChildren(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	c := NewCpuset(mock.root, CloneChildren())
+	if err := c.Create("parent/child", &specs.LinuxResources{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"", "parent"} {
+		data, err := ioutil.ReadFile(filepath.Join(c.Path(dir), cgroupCloneChildren))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "1" {
+			t.Errorf("expected clone_children to be enabled in %q but received %q", dir, data)
+		}
+	}
+}
+
+func TestCpusetPopulatedBeforeAttach(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(SingleSubsystem(mock.hierarchy, Cpuset), StaticPath("/test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpus := filepath.Join(mock.root, "cpuset", "test", "cpuset.cpus")
+	// simulate a cgroup created without its cpus populated
+	if err := ioutil.WriteFile(cpus, nil, defaultFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := control.Add(Process{Pid: 1234}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(cpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := ioutil.ReadFile(filepath.Join(mock.root, "cpuset", "cpuset.cpus"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(parent) {
+		t.Fatalf("expected cpus %q copied from the parent but received %q", parent, data)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"time"
+
+	v1 "github.com/containerd/cgroups/stats/v1"
+)
+
+// CPUUtilization is the CPU time consumed by a cgroup over an interval,
+// normalized by the length of that interval. Values are expressed in CPUs,
+// so 1.0 is one fully busy CPU and 2.5 is two and a half.
+type CPUUtilization struct {
+	Total  float64
+	User   float64
+	Kernel float64
+}
+
+// Percent returns the total utilization as a percentage of one CPU
+func (u CPUUtilization) Percent() float64 {
+	return u.Total * 100
+}
+
+// PercentOfLimit returns the total utilization as a percentage of the CFS
+// quota, as set in cpu.cfs_quota_us and cpu.cfs_period_us. It returns 0 when
+// the cgroup is not limited.
+func (u CPUUtilization) PercentOfLimit(quota int64, period uint64) float64 {
+	if quota <= 0 || period == 0 {
+		return 0
+	}
+	return u.Total / (float64(quota) / float64(period)) * 100
+}
+
+// CPUUsage computes the utilization between two samples of cgroup metrics
+// taken elapsed apart.
+//
+// The total comes from cpuacct.usage and is exact. User and kernel time come
+// from cpuacct.stat which the kernel reports in USER_HZ ticks; Stat already
+// converts them to nanoseconds but they are only accurate to a tick (10ms),
+// so over short intervals User+Kernel may not add up to Total.
+//
+// ErrNilMetrics is returned when either sample is nil.
+func CPUUsage(prev, cur *v1.Metrics, elapsed time.Duration) (CPUUtilization, error) {
+	if elapsed <= 0 {
+		return CPUUtilization{}, ErrInvalidInterval
+	}
+	if prev == nil || cur == nil {
+		return CPUUtilization{}, ErrNilMetrics
+	}
+	var a, b v1.CPUUsage
+	if prev.CPU != nil && prev.CPU.Usage != nil {
+		a = *prev.CPU.Usage
+	}
+	if cur.CPU != nil && cur.CPU.Usage != nil {
+		b = *cur.CPU.Usage
+	}
+	if b.Total < a.Total || b.User < a.User || b.Kernel < a.Kernel {
+		return CPUUtilization{}, ErrCounterReset
+	}
+	ns := float64(elapsed.Nanoseconds())
+	return CPUUtilization{
+		Total:  float64(b.Total-a.Total) / ns,
+		User:   float64(b.User-a.User) / ns,
+		Kernel: float64(b.Kernel-a.Kernel) / ns,
+	}, nil
+}
+
+// Throttling describes how much a cgroup was held back by its CFS quota over
+// an interval
+type Throttling struct {
+	// Periods is the fraction of enforcement periods in which the cgroup
+	// was throttled, between 0 and 1
+	Periods float64
+	// Time is the share of the interval the cgroup spent throttled
+	Time float64
+}
+
+// ThrottleRatio computes the throttling between two samples of cgroup
+// metrics taken elapsed apart from the nr_periods, nr_throttled and
+// throttled_time counters of cpu.stat. A Periods value consistently above
+// a few percent is the usual signal that the CPU limit is set too low.
+// ErrNilMetrics is returned when either sample is nil.
+func ThrottleRatio(prev, cur *v1.Metrics, elapsed time.Duration) (Throttling, error) {
+	if elapsed <= 0 {
+		return Throttling{}, ErrInvalidInterval
+	}
+	if prev == nil || cur == nil {
+		return Throttling{}, ErrNilMetrics
+	}
+	var a, b v1.Throttle
+	if prev.CPU != nil && prev.CPU.Throttling != nil {
+		a = *prev.CPU.Throttling
+	}
+	if cur.CPU != nil && cur.CPU.Throttling != nil {
+		b = *cur.CPU.Throttling
+	}
+	if b.Periods < a.Periods || b.ThrottledPeriods < a.ThrottledPeriods || b.ThrottledTime < a.ThrottledTime {
+		return Throttling{}, ErrCounterReset
+	}
+	var t Throttling
+	if periods := b.Periods - a.Periods; periods > 0 {
+		t.Periods = float64(b.ThrottledPeriods-a.ThrottledPeriods) / float64(periods)
+	}
+	t.Time = float64(b.ThrottledTime-a.ThrottledTime) / float64(elapsed.Nanoseconds())
+	return t, nil
+}
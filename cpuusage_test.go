@@ -0,0 +1,90 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/containerd/cgroups/stats/v1"
+)
+
+func cpuMetrics(total, user, kernel uint64) *v1.Metrics {
+	return &v1.Metrics{
+		CPU: &v1.CPUStat{
+			Usage: &v1.CPUUsage{
+				Total:  total,
+				User:   user,
+				Kernel: kernel,
+			},
+		},
+	}
+}
+
+func TestCPUUsage(t *testing.T) {
+	prev := cpuMetrics(1e9, 5e8, 5e8)
+	cur := cpuMetrics(4e9, 2e9, 2e9)
+	u, err := CPUUsage(prev, cur, 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Total != 1.5 || u.User != 0.75 || u.Kernel != 0.75 {
+		t.Fatalf("unexpected utilization %+v", u)
+	}
+	if p := u.Percent(); p != 150 {
+		t.Fatalf("expected 150%% of one CPU, got %v", p)
+	}
+	if p := u.PercentOfLimit(200000, 100000); p != 75 {
+		t.Fatalf("expected 75%% of limit, got %v", p)
+	}
+	if p := u.PercentOfLimit(-1, 100000); p != 0 {
+		t.Fatalf("expected 0 for an unlimited cgroup, got %v", p)
+	}
+}
+
+func TestCPUUsageErrors(t *testing.T) {
+	if _, err := CPUUsage(cpuMetrics(2, 0, 0), cpuMetrics(1, 0, 0), time.Second); err != ErrCounterReset {
+		t.Fatalf("expected ErrCounterReset, got %v", err)
+	}
+	if _, err := CPUUsage(cpuMetrics(1, 0, 0), cpuMetrics(2, 0, 0), 0); err != ErrInvalidInterval {
+		t.Fatalf("expected ErrInvalidInterval, got %v", err)
+	}
+	if _, err := CPUUsage(cpuMetrics(1, 0, 0), nil, time.Second); err != ErrNilMetrics {
+		t.Fatalf("expected ErrNilMetrics, got %v", err)
+	}
+}
+
+func TestThrottleRatio(t *testing.T) {
+	prev := &v1.Metrics{CPU: &v1.CPUStat{Throttling: &v1.Throttle{Periods: 100, ThrottledPeriods: 10, ThrottledTime: 1e8}}}
+	cur := &v1.Metrics{CPU: &v1.CPUStat{Throttling: &v1.Throttle{Periods: 200, ThrottledPeriods: 35, ThrottledTime: 6e8}}}
+	r, err := ThrottleRatio(prev, cur, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Periods != 0.25 || r.Time != 0.05 {
+		t.Fatalf("unexpected throttling %+v", r)
+	}
+	if r, err := ThrottleRatio(prev, prev, time.Second); err != nil || r.Periods != 0 {
+		t.Fatalf("expected no throttling without elapsed periods, got %+v (%v)", r, err)
+	}
+	if _, err := ThrottleRatio(cur, prev, time.Second); err != ErrCounterReset {
+		t.Fatalf("expected ErrCounterReset, got %v", err)
+	}
+	if _, err := ThrottleRatio(prev, nil, time.Second); err != ErrNilMetrics {
+		t.Fatalf("expected ErrNilMetrics, got %v", err)
+	}
+}
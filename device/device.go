@@ -0,0 +1,103 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package device resolves between device paths and the major:minor numbers
+// used by device cgroup rules and io limits
+package device
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// Block is the type of block devices
+	Block = "b"
+	// Char is the type of character devices
+	Char = "c"
+)
+
+// ErrNotDevice is returned when a path is not a device node
+var ErrNotDevice = errors.New("not a device node")
+
+var (
+	sysfs = "/sys"
+	devfs = "/dev"
+)
+
+// Number returns the type, major and minor number of the device node at path,
+// symlinks such as /dev/disk/by-id entries are followed
+func Number(path string) (typ string, major, minor int64, err error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return "", 0, 0, &os.PathError{Op: "stat", Path: path, Err: err}
+	}
+	switch st.Mode & unix.S_IFMT {
+	case unix.S_IFBLK:
+		typ = Block
+	case unix.S_IFCHR:
+		typ = Char
+	default:
+		return "", 0, 0, errors.Wrapf(ErrNotDevice, "%s", path)
+	}
+	return typ, int64(unix.Major(uint64(st.Rdev))), int64(unix.Minor(uint64(st.Rdev))), nil
+}
+
+// Backing returns the major and minor number of the block device backing the
+// filesystem that path is on, to limit the io of a mount or directory
+func Backing(path string) (major, minor int64, err error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return 0, 0, &os.PathError{Op: "stat", Path: path, Err: err}
+	}
+	return int64(unix.Major(uint64(st.Dev))), int64(unix.Minor(uint64(st.Dev))), nil
+}
+
+// Path returns the /dev path of the device of type Block or Char with the
+// major and minor number
+func Path(typ string, major, minor int64) (string, error) {
+	var class string
+	switch typ {
+	case Block:
+		class = "block"
+	case Char:
+		class = "char"
+	default:
+		return "", errors.Errorf("invalid device type %q", typ)
+	}
+	uevent := filepath.Join(sysfs, "dev", class, fmt.Sprintf("%d:%d", major, minor), "uevent")
+	f, err := os.Open(uevent)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if name := strings.TrimPrefix(s.Text(), "DEVNAME="); name != s.Text() {
+			return filepath.Join(devfs, name), nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.Errorf("device %d:%d has no DEVNAME in %s", major, minor, uevent)
+}
@@ -0,0 +1,91 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package device
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestNumber(t *testing.T) {
+	typ, major, minor, err := Number("/dev/null")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ != Char || major != 1 || minor != 3 {
+		t.Fatalf("expected c 1:3 for /dev/null but received %s %d:%d", typ, major, minor)
+	}
+	if _, _, _, err := Number("/"); errors.Cause(err) != ErrNotDevice {
+		t.Fatalf("expected ErrNotDevice for a directory but received %v", err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-device")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sysfs = dir
+	defer func() {
+		sysfs = "/sys"
+	}()
+	dev := filepath.Join(dir, "dev", "block", "8:16")
+	if err := os.MkdirAll(dev, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dev, "uevent"), []byte("MAJOR=8\nMINOR=16\nDEVNAME=sdb\nDEVTYPE=disk\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := Path(Block, 8, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/dev/sdb" {
+		t.Fatalf("expected /dev/sdb but received %q", path)
+	}
+	if _, err := Path(Char, 8, 16); !os.IsNotExist(err) {
+		t.Fatalf("expected a missing char device to not exist but received %v", err)
+	}
+}
+
+func TestBacking(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-device")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	dmajor, dminor, err := Backing(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmajor, fminor, err := Backing(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dmajor != fmajor || dminor != fminor {
+		t.Fatalf("expected a file to be on the same device as its directory: %d:%d != %d:%d", fmajor, fminor, dmajor, dminor)
+	}
+}
@@ -0,0 +1,394 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package docker converts the metrics collected by this module into the
+// stats format returned by the Docker Engine's /containers/{id}/stats
+// endpoint, for tools that emulate or proxy that api.
+//
+// The types mirror the Engine's api/types package, including the json field
+// names, without depending on it.
+package docker
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/containerd/cgroups/stats/v1"
+	"github.com/containerd/cgroups/topology"
+	"github.com/containerd/cgroups/v2/stats"
+	"github.com/pkg/errors"
+)
+
+// clockTicks is the USER_HZ used by the kernel when reporting /proc/stat
+const clockTicks = 100
+
+type ThrottlingData struct {
+	Periods          uint64 `json:"periods"`
+	ThrottledPeriods uint64 `json:"throttled_periods"`
+	ThrottledTime    uint64 `json:"throttled_time"`
+}
+
+type CPUUsage struct {
+	TotalUsage        uint64   `json:"total_usage"`
+	PercpuUsage       []uint64 `json:"percpu_usage,omitempty"`
+	UsageInKernelmode uint64   `json:"usage_in_kernelmode"`
+	UsageInUsermode   uint64   `json:"usage_in_usermode"`
+}
+
+type CPUStats struct {
+	CPUUsage       CPUUsage       `json:"cpu_usage"`
+	SystemUsage    uint64         `json:"system_cpu_usage,omitempty"`
+	OnlineCPUs     uint32         `json:"online_cpus,omitempty"`
+	ThrottlingData ThrottlingData `json:"throttling_data,omitempty"`
+}
+
+type MemoryStats struct {
+	Usage    uint64            `json:"usage,omitempty"`
+	MaxUsage uint64            `json:"max_usage,omitempty"`
+	Stats    map[string]uint64 `json:"stats,omitempty"`
+	Failcnt  uint64            `json:"failcnt,omitempty"`
+	Limit    uint64            `json:"limit,omitempty"`
+}
+
+type BlkioStatEntry struct {
+	Major uint64 `json:"major"`
+	Minor uint64 `json:"minor"`
+	Op    string `json:"op"`
+	Value uint64 `json:"value"`
+}
+
+type BlkioStats struct {
+	IoServiceBytesRecursive []BlkioStatEntry `json:"io_service_bytes_recursive"`
+	IoServicedRecursive     []BlkioStatEntry `json:"io_serviced_recursive"`
+	IoQueuedRecursive       []BlkioStatEntry `json:"io_queue_recursive"`
+	IoServiceTimeRecursive  []BlkioStatEntry `json:"io_service_time_recursive"`
+	IoWaitTimeRecursive     []BlkioStatEntry `json:"io_wait_time_recursive"`
+	IoMergedRecursive       []BlkioStatEntry `json:"io_merged_recursive"`
+	IoTimeRecursive         []BlkioStatEntry `json:"io_time_recursive"`
+	SectorsRecursive        []BlkioStatEntry `json:"sectors_recursive"`
+}
+
+type NetworkStats struct {
+	RxBytes   uint64 `json:"rx_bytes"`
+	RxPackets uint64 `json:"rx_packets"`
+	RxErrors  uint64 `json:"rx_errors"`
+	RxDropped uint64 `json:"rx_dropped"`
+	TxBytes   uint64 `json:"tx_bytes"`
+	TxPackets uint64 `json:"tx_packets"`
+	TxErrors  uint64 `json:"tx_errors"`
+	TxDropped uint64 `json:"tx_dropped"`
+}
+
+type PidsStats struct {
+	Current uint64 `json:"current,omitempty"`
+	Limit   uint64 `json:"limit,omitempty"`
+}
+
+type Stats struct {
+	Read        time.Time   `json:"read"`
+	PreRead     time.Time   `json:"preread"`
+	PidsStats   PidsStats   `json:"pids_stats,omitempty"`
+	BlkioStats  BlkioStats  `json:"blkio_stats,omitempty"`
+	NumProcs    uint32      `json:"num_procs"`
+	CPUStats    CPUStats    `json:"cpu_stats,omitempty"`
+	PreCPUStats CPUStats    `json:"precpu_stats,omitempty"`
+	MemoryStats MemoryStats `json:"memory_stats,omitempty"`
+}
+
+// StatsJSON is the body returned by the stats endpoint
+type StatsJSON struct {
+	Stats
+
+	Name     string                  `json:"name,omitempty"`
+	ID       string                  `json:"id,omitempty"`
+	Networks map[string]NetworkStats `json:"networks,omitempty"`
+}
+
+// SetPrevious sets the precpu stats from a previous sample, which clients
+// use to calculate the cpu percentage between the two
+func (s *StatsJSON) SetPrevious(pre *StatsJSON) {
+	s.PreRead = pre.Read
+	s.PreCPUStats = pre.CPUStats
+}
+
+// FromV1 converts v1 metrics into the stats format read at the provided time.
+// The system cpu usage is left for the caller to set, see SystemCPUUsage.
+func FromV1(m *v1.Metrics, read time.Time) *StatsJSON {
+	s := &StatsJSON{
+		Stats: Stats{
+			Read: read,
+		},
+	}
+	if cpu := m.CPU; cpu != nil {
+		if u := cpu.Usage; u != nil {
+			s.CPUStats.CPUUsage = CPUUsage{
+				TotalUsage:        u.Total,
+				PercpuUsage:       u.PerCPU,
+				UsageInKernelmode: u.Kernel,
+				UsageInUsermode:   u.User,
+			}
+			s.CPUStats.OnlineCPUs = uint32(len(u.PerCPU))
+		}
+		if t := cpu.Throttling; t != nil {
+			s.CPUStats.ThrottlingData = ThrottlingData{
+				Periods:          t.Periods,
+				ThrottledPeriods: t.ThrottledPeriods,
+				ThrottledTime:    t.ThrottledTime,
+			}
+		}
+	}
+	if mem := m.Memory; mem != nil {
+		s.MemoryStats.Stats = map[string]uint64{
+			"cache":                     mem.Cache,
+			"rss":                       mem.RSS,
+			"rss_huge":                  mem.RSSHuge,
+			"mapped_file":               mem.MappedFile,
+			"dirty":                     mem.Dirty,
+			"writeback":                 mem.Writeback,
+			"pgpgin":                    mem.PgPgIn,
+			"pgpgout":                   mem.PgPgOut,
+			"pgfault":                   mem.PgFault,
+			"pgmajfault":                mem.PgMajFault,
+			"inactive_anon":             mem.InactiveAnon,
+			"active_anon":               mem.ActiveAnon,
+			"inactive_file":             mem.InactiveFile,
+			"active_file":               mem.ActiveFile,
+			"unevictable":               mem.Unevictable,
+			"hierarchical_memory_limit": mem.HierarchicalMemoryLimit,
+			"hierarchical_memsw_limit":  mem.HierarchicalSwapLimit,
+			"total_cache":               mem.TotalCache,
+			"total_rss":                 mem.TotalRSS,
+			"total_rss_huge":            mem.TotalRSSHuge,
+			"total_mapped_file":         mem.TotalMappedFile,
+			"total_dirty":               mem.TotalDirty,
+			"total_writeback":           mem.TotalWriteback,
+			"total_pgpgin":              mem.TotalPgPgIn,
+			"total_pgpgout":             mem.TotalPgPgOut,
+			"total_pgfault":             mem.TotalPgFault,
+			"total_pgmajfault":          mem.TotalPgMajFault,
+			"total_inactive_anon":       mem.TotalInactiveAnon,
+			"total_active_anon":         mem.TotalActiveAnon,
+			"total_inactive_file":       mem.TotalInactiveFile,
+			"total_active_file":         mem.TotalActiveFile,
+			"total_unevictable":         mem.TotalUnevictable,
+		}
+		if u := mem.Usage; u != nil {
+			s.MemoryStats.Usage = u.Usage
+			s.MemoryStats.MaxUsage = u.Max
+			s.MemoryStats.Failcnt = u.Failcnt
+			s.MemoryStats.Limit = u.Limit
+		}
+	}
+	if b := m.Blkio; b != nil {
+		s.BlkioStats = BlkioStats{
+			IoServiceBytesRecursive: blkioEntries(b.IoServiceBytesRecursive),
+			IoServicedRecursive:     blkioEntries(b.IoServicedRecursive),
+			IoQueuedRecursive:       blkioEntries(b.IoQueuedRecursive),
+			IoServiceTimeRecursive:  blkioEntries(b.IoServiceTimeRecursive),
+			IoWaitTimeRecursive:     blkioEntries(b.IoWaitTimeRecursive),
+			IoMergedRecursive:       blkioEntries(b.IoMergedRecursive),
+			IoTimeRecursive:         blkioEntries(b.IoTimeRecursive),
+			SectorsRecursive:        blkioEntries(b.SectorsRecursive),
+		}
+	}
+	if p := m.Pids; p != nil {
+		s.PidsStats = PidsStats{
+			Current: p.Current,
+			Limit:   p.Limit,
+		}
+	}
+	if len(m.Network) > 0 {
+		s.Networks = make(map[string]NetworkStats, len(m.Network))
+		for _, n := range m.Network {
+			s.Networks[n.Name] = NetworkStats{
+				RxBytes:   n.RxBytes,
+				RxPackets: n.RxPackets,
+				RxErrors:  n.RxErrors,
+				RxDropped: n.RxDropped,
+				TxBytes:   n.TxBytes,
+				TxPackets: n.TxPackets,
+				TxErrors:  n.TxErrors,
+				TxDropped: n.TxDropped,
+			}
+		}
+	}
+	return s
+}
+
+// FromV2 converts v2 metrics into the stats format read at the provided time,
+// matching the Engine's own cgroup v2 conversion.
+//
+// cgroup v2 has no per cpu accounting so PercpuUsage is left empty and
+// OnlineCPUs is set to the cpus of the host; callers that know the group's
+// path can set it from EffectiveCPUs instead
+func FromV2(m *stats.Metrics, read time.Time) *StatsJSON {
+	s := &StatsJSON{
+		Stats: Stats{
+			Read: read,
+		},
+	}
+	if cpu := m.CPU; cpu != nil {
+		s.CPUStats = CPUStats{
+			CPUUsage: CPUUsage{
+				TotalUsage:        cpu.UsageUsec * 1000,
+				UsageInKernelmode: cpu.SystemUsec * 1000,
+				UsageInUsermode:   cpu.UserUsec * 1000,
+			},
+			OnlineCPUs: uint32(runtime.NumCPU()),
+			ThrottlingData: ThrottlingData{
+				Periods:          cpu.NrPeriods,
+				ThrottledPeriods: cpu.NrThrottled,
+				ThrottledTime:    cpu.ThrottledUsec * 1000,
+			},
+		}
+	}
+	if mem := m.Memory; mem != nil {
+		s.MemoryStats = MemoryStats{
+			Usage: mem.Usage,
+			Limit: mem.UsageLimit,
+			Stats: map[string]uint64{
+				"anon":                   mem.Anon,
+				"file":                   mem.File,
+				"kernel_stack":           mem.KernelStack,
+				"slab":                   mem.Slab,
+				"sock":                   mem.Sock,
+				"shmem":                  mem.Shmem,
+				"file_mapped":            mem.FileMapped,
+				"file_dirty":             mem.FileDirty,
+				"file_writeback":         mem.FileWriteback,
+				"anon_thp":               mem.AnonThp,
+				"inactive_anon":          mem.InactiveAnon,
+				"active_anon":            mem.ActiveAnon,
+				"inactive_file":          mem.InactiveFile,
+				"active_file":            mem.ActiveFile,
+				"unevictable":            mem.Unevictable,
+				"slab_reclaimable":       mem.SlabReclaimable,
+				"slab_unreclaimable":     mem.SlabUnreclaimable,
+				"pgfault":                mem.Pgfault,
+				"pgmajfault":             mem.Pgmajfault,
+				"workingset_refault":     mem.WorkingsetRefault,
+				"workingset_activate":    mem.WorkingsetActivate,
+				"workingset_nodereclaim": mem.WorkingsetNodereclaim,
+				"pgrefill":               mem.Pgrefill,
+				"pgscan":                 mem.Pgscan,
+				"pgsteal":                mem.Pgsteal,
+				"pgactivate":             mem.Pgactivate,
+				"pgdeactivate":           mem.Pgdeactivate,
+				"pglazyfree":             mem.Pglazyfree,
+				"pglazyfreed":            mem.Pglazyfreed,
+				"thp_fault_alloc":        mem.ThpFaultAlloc,
+				"thp_collapse_alloc":     mem.ThpCollapseAlloc,
+			},
+		}
+	}
+	if io := m.Io; io != nil {
+		for _, e := range io.Usage {
+			s.BlkioStats.IoServiceBytesRecursive = append(s.BlkioStats.IoServiceBytesRecursive,
+				BlkioStatEntry{Major: e.Major, Minor: e.Minor, Op: "read", Value: e.Rbytes},
+				BlkioStatEntry{Major: e.Major, Minor: e.Minor, Op: "write", Value: e.Wbytes},
+			)
+			s.BlkioStats.IoServicedRecursive = append(s.BlkioStats.IoServicedRecursive,
+				BlkioStatEntry{Major: e.Major, Minor: e.Minor, Op: "read", Value: e.Rios},
+				BlkioStatEntry{Major: e.Major, Minor: e.Minor, Op: "write", Value: e.Wios},
+			)
+		}
+	}
+	if p := m.Pids; p != nil {
+		s.PidsStats = PidsStats{
+			Current: p.Current,
+			Limit:   p.Limit,
+		}
+	}
+	return s
+}
+
+// EffectiveCPUs returns the number of cpus the v2 group at path may run on,
+// read from its cpuset.cpus.effective, falling back to the cpus of the host
+// when the cpuset controller is not enabled for the group
+func EffectiveCPUs(path string) (uint32, error) {
+	data, err := ioutil.ReadFile(filepath.Join(path, "cpuset.cpus.effective"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return uint32(runtime.NumCPU()), nil
+		}
+		return 0, err
+	}
+	cpus, err := topology.ParseCPUSet(string(data))
+	if err != nil {
+		return 0, errors.Wrapf(err, "parse %s", filepath.Join(path, "cpuset.cpus.effective"))
+	}
+	if cpus.IsEmpty() {
+		return uint32(runtime.NumCPU()), nil
+	}
+	return uint32(cpus.Len()), nil
+}
+
+// SystemCPUUsage returns the host's cumulative cpu time in nanoseconds, as
+// reported in the system_cpu_usage field
+func SystemCPUUsage() (uint64, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return parseSystemCPUUsage(f)
+}
+
+func parseSystemCPUUsage(r io.Reader) (uint64, error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		parts := strings.Fields(s.Text())
+		if len(parts) == 0 || parts[0] != "cpu" {
+			continue
+		}
+		if len(parts) < 8 {
+			return 0, errors.Errorf("invalid number of cpu fields in /proc/stat")
+		}
+		var total uint64
+		for _, field := range parts[1:8] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, errors.Wrapf(err, "unable to parse /proc/stat field %q", field)
+			}
+			total += v
+		}
+		return total * uint64(time.Second) / clockTicks, nil
+	}
+	if err := s.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("no cpu line in /proc/stat")
+}
+
+func blkioEntries(entries []*v1.BlkIOEntry) []BlkioStatEntry {
+	out := make([]BlkioStatEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, BlkioStatEntry{
+			Major: e.Major,
+			Minor: e.Minor,
+			Op:    e.Op,
+			Value: e.Value,
+		})
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package docker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/containerd/cgroups/stats/v1"
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestFromV1(t *testing.T) {
+	s := FromV1(&v1.Metrics{
+		CPU: &v1.CPUStat{
+			Usage: &v1.CPUUsage{Total: 300, PerCPU: []uint64{100, 200}},
+		},
+		Memory: &v1.MemoryStat{
+			TotalCache: 5,
+			Usage:      &v1.MemoryEntry{Usage: 10, Limit: 20},
+		},
+		Blkio: &v1.BlkIOStat{
+			IoServiceBytesRecursive: []*v1.BlkIOEntry{{Op: "Read", Major: 8, Value: 1}},
+		},
+	}, time.Now())
+	if s.CPUStats.OnlineCPUs != 2 || s.MemoryStats.Limit != 20 || s.MemoryStats.Stats["total_cache"] != 5 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"cpu_stats"`, `"memory_stats"`, `"blkio_stats"`, `"io_service_bytes_recursive":[{"major":8,"minor":0,"op":"Read","value":1}]`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("expected %s in %s", field, data)
+		}
+	}
+}
+
+func TestFromV2(t *testing.T) {
+	s := FromV2(&stats.Metrics{
+		CPU:    &stats.CPUStat{UsageUsec: 2, UserUsec: 1, SystemUsec: 1},
+		Memory: &stats.MemoryStat{Usage: 10, UsageLimit: 100, Anon: 4},
+		Io:     &stats.IOStat{Usage: []*stats.IOEntry{{Major: 8, Rios: 3, Wios: 4}}},
+	}, time.Now())
+	if s.CPUStats.CPUUsage.TotalUsage != 2000 || s.MemoryStats.Stats["anon"] != 4 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	if s.CPUStats.OnlineCPUs != uint32(runtime.NumCPU()) {
+		t.Fatalf("expected %d online cpus but received %d", runtime.NumCPU(), s.CPUStats.OnlineCPUs)
+	}
+	if serviced := s.BlkioStats.IoServicedRecursive; len(serviced) != 2 || serviced[1].Op != "write" || serviced[1].Value != 4 {
+		t.Fatalf("unexpected blkio stats %+v", serviced)
+	}
+}
+
+func TestEffectiveCPUs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-cpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	n, err := EffectiveCPUs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != uint32(runtime.NumCPU()) {
+		t.Fatalf("expected %d cpus without a cpuset but received %d", runtime.NumCPU(), n)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cpuset.cpus.effective"), []byte("0-2,5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = EffectiveCPUs(dir); err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Fatalf("expected 4 cpus but received %d", n)
+	}
+}
+
+func TestParseSystemCPUUsage(t *testing.T) {
+	const stat = "cpu  100 0 100 800 0 0 0 0 0 0\ncpu0 50 0 50 400 0 0 0 0 0 0\n"
+	usage, err := parseSystemCPUUsage(strings.NewReader(stat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := uint64(10 * time.Second); usage != expected {
+		t.Fatalf("expected %d but received %d", expected, usage)
+	}
+}
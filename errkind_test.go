@@ -0,0 +1,54 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestKindOf(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		kind ErrorKind
+	}{
+		{nil, KindUnknown},
+		{errors.New("other"), KindUnknown},
+		{ErrCgroupDeleted, KindNotFound},
+		{errors.Wrap(ErrHugePageSizeNotSupported, "hugepage size \"3MB\""), KindUnsupported},
+		{ErrInvalidPid, KindInvalidInput},
+		{&os.PathError{Op: "open", Path: "/sys/fs/cgroup/pids/test/pids.max", Err: syscall.ENOENT}, KindNotFound},
+		{errors.Wrap(&os.PathError{Op: "write", Path: "cgroup.procs", Err: syscall.EACCES}, "add"), KindPermissionDenied},
+		{&os.PathError{Op: "remove", Path: "test", Err: syscall.EBUSY}, KindBusy},
+		{errors.Wrap(ErrFrozen, "write cgroup.procs"), KindBusy},
+		{errors.Wrap(ErrDying, "write cgroup.procs"), KindNotFound},
+		{&os.PathError{Op: "write", Path: "memory.limit_in_bytes", Err: syscall.EINVAL}, KindInvalidInput},
+	} {
+		if kind := KindOf(tc.err); kind != tc.kind {
+			t.Errorf("expected %v to be %s but received %s", tc.err, tc.kind, kind)
+		}
+	}
+	if !IsRetryable(&os.PathError{Op: "remove", Path: "test", Err: syscall.EAGAIN}) {
+		t.Error("expected EAGAIN to be retryable")
+	}
+	if IsRetryable(ErrCgroupDeleted) {
+		t.Error("expected a deleted cgroup not to be retryable")
+	}
+}
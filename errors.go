@@ -19,6 +19,8 @@ package cgroups
 import (
 	"errors"
 	"os"
+
+	"github.com/containerd/cgroups/internal/cgfs"
 )
 
 var (
@@ -29,8 +31,48 @@ var (
 	ErrMemoryNotSupported       = errors.New("cgroups: memory cgroup not supported on this system")
 	ErrCgroupDeleted            = errors.New("cgroups: cgroup deleted")
 	ErrNoCgroupMountDestination = errors.New("cgroups: cannot find cgroup mount destination")
+	ErrHugePageSizeNotSupported = errors.New("cgroups: hugepage size not supported on this system")
+	ErrUnexpectedFileType       = cgfs.ErrUnexpectedFileType
+	ErrInvalidInterval          = errors.New("cgroups: sample interval must be greater than 0")
+	ErrCounterReset             = errors.New("cgroups: counter decreased between samples")
+	ErrNilMetrics               = errors.New("cgroups: metrics reference is nil")
+	ErrFrozen                   = errors.New("cgroups: cgroup is frozen, thaw it before adding processes")
+	ErrDying                    = errors.New("cgroups: cgroup is being removed")
+	ErrUnitExists               = cgfs.ErrUnitExists
+	ErrUnitMasked               = cgfs.ErrUnitMasked
+	ErrDependencyFailed         = cgfs.ErrDependencyFailed
+	ErrJobFailed                = cgfs.ErrJobFailed
 )
 
+// StateError is returned when the kernel refused to move a process into a
+// cgroup because of the cgroup's state. errors.Cause returns the state,
+// ErrFrozen or ErrDying, while errors.Is and errors.As also match the
+// kernel's error.
+type StateError struct {
+	// State is ErrFrozen or ErrDying
+	State error
+	Err   error
+}
+
+func (e *StateError) Error() string {
+	return e.State.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the kernel's error
+func (e *StateError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the state for github.com/pkg/errors
+func (e *StateError) Cause() error {
+	return e.State
+}
+
+// Is matches the state
+func (e *StateError) Is(target error) bool {
+	return target == e.State
+}
+
 // ErrorHandler is a function that handles and acts on errors
 type ErrorHandler func(err error) error
 
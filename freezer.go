@@ -17,10 +17,11 @@
 package cgroups
 
 import (
-	"io/ioutil"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/containerd/cgroups/clock"
 )
 
 func NewFreezer(root string) *freezerController {
@@ -42,11 +43,11 @@ func (f *freezerController) Path(path string) string {
 }
 
 func (f *freezerController) Freeze(path string) error {
-	return f.waitState(path, Frozen)
+	return f.waitState(path, Frozen, clock.Real)
 }
 
 func (f *freezerController) Thaw(path string) error {
-	return f.waitState(path, Thawed)
+	return f.waitState(path, Thawed, clock.Real)
 }
 
 func (f *freezerController) changeState(path string, state State) error {
@@ -58,14 +59,16 @@ func (f *freezerController) changeState(path string, state State) error {
 }
 
 func (f *freezerController) state(path string) (State, error) {
-	current, err := ioutil.ReadFile(filepath.Join(f.root, path, "freezer.state"))
+	current, err := readBounded(filepath.Join(f.root, path, "freezer.state"))
 	if err != nil {
 		return "", err
 	}
 	return State(strings.ToLower(strings.TrimSpace(string(current)))), nil
 }
 
-func (f *freezerController) waitState(path string, state State) error {
+// waitState writes state until the cgroup reaches it, polling every
+// millisecond on pacer
+func (f *freezerController) waitState(path string, state State, pacer clock.Clock) error {
 	for {
 		if err := f.changeState(path, state); err != nil {
 			return err
@@ -77,6 +80,6 @@ func (f *freezerController) waitState(path string, state State) error {
 		if current == state {
 			return nil
 		}
-		time.Sleep(1 * time.Millisecond)
+		clock.Sleep(pacer, time.Millisecond)
 	}
 }
@@ -18,3 +18,11 @@ package cgroups
 
 // Hierarchy enables both unified and split hierarchy for cgroups
 type Hierarchy func() ([]Subsystem, error)
+
+// StaticHierarchy returns a hierarchy of the provided subsystems, created with
+// the New* constructors for a custom root or a subset of the controllers
+func StaticHierarchy(subsystems ...Subsystem) Hierarchy {
+	return func() ([]Subsystem, error) {
+		return subsystems, nil
+	}
+}
@@ -0,0 +1,74 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestV1Root(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	// only the mounted subsystems are part of the hierarchy
+	if err := os.RemoveAll(filepath.Join(mock.root, string(Rdma))); err != nil {
+		t.Fatal(err)
+	}
+	subsystems, err := V1Root(mock.root)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range subsystems {
+		if s.Name() == Rdma {
+			t.Fatal("expected the missing rdma subsystem to be skipped")
+		}
+	}
+	control, err := New(V1Root(mock.root), StaticPath("/test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(mock.root, string(Pids), "test")); err != nil {
+		t.Fatalf("expected the group to be created under the root: %v", err)
+	}
+	if _, err := Load(V1Root(mock.root), StaticPath("/test")); err != nil {
+		t.Fatal(err)
+	}
+	if err := control.Delete(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStaticHierarchy(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(StaticHierarchy(NewPids(mock.root)), StaticPath("/test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subsystems := control.Subsystems(); len(subsystems) != 1 || subsystems[0].Name() != Pids {
+		t.Fatalf("expected only the pids subsystem but received %v", subsystems)
+	}
+}
@@ -0,0 +1,308 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package httpapi serves the cgroups of the host over HTTP for local
+// debugging tools and health endpoints. The handlers are mounted on a mux
+// provided by the caller:
+//
+//	GET /cgroups                  lists the groups under the root
+//	GET /cgroups/{path}/stats     returns the metrics of a group as JSON
+//	GET /cgroups/{path}/events    streams the events of a group as server-sent events
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/containerd/cgroups"
+	v2 "github.com/containerd/cgroups/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMountpoint = "/sys/fs/cgroup"
+	prefix            = "/cgroups"
+)
+
+// Opt configures a Handler
+type Opt func(*Handler)
+
+// WithRoot scopes all requests to the groups under root
+func WithRoot(root string) Opt {
+	return func(h *Handler) {
+		h.root = root
+	}
+}
+
+// WithMountpoint sets the unified mountpoint used on cgroups v2 hosts
+func WithMountpoint(mountpoint string) Opt {
+	return func(h *Handler) {
+		h.mountpoint = mountpoint
+	}
+}
+
+// WithMode overrides the detected cgroups mode of the host
+func WithMode(mode cgroups.CGMode) Opt {
+	return func(h *Handler) {
+		h.mode = mode
+	}
+}
+
+// Handler serves the cgroups API
+type Handler struct {
+	root       string
+	mountpoint string
+	mode       cgroups.CGMode
+	// events shares the watches of memory events among the streams
+	mux *v2.EventMux
+}
+
+// New returns a new Handler for the host's cgroups mode
+func New(opts ...Opt) *Handler {
+	h := &Handler{
+		root:       "/",
+		mountpoint: defaultMountpoint,
+		mode:       cgroups.Mode(),
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	h.mux = v2.NewEventMux()
+	return h
+}
+
+// Register mounts the handlers on the mux under /cgroups
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc(prefix, h.list)
+	mux.HandleFunc(prefix+"/", h.group)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var (
+		groups []string
+		err    error
+	)
+	switch h.mode {
+	case cgroups.Unified:
+		groups, err = walk(filepath.Join(h.mountpoint, h.root))
+	case cgroups.Legacy, cgroups.Hybrid:
+		groups, err = h.walkLegacy()
+	default:
+		http.Error(w, "cgroups are not available on this host", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, groups)
+}
+
+func (h *Handler) group(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, prefix)
+	switch {
+	case strings.HasSuffix(path, "/stats"):
+		group, err := h.groupPath(strings.TrimSuffix(path, "/stats"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.stats(w, group)
+	case strings.HasSuffix(path, "/events"):
+		group, err := h.groupPath(strings.TrimSuffix(path, "/events"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.events(w, r, group)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) stats(w http.ResponseWriter, group string) {
+	switch h.mode {
+	case cgroups.Unified:
+		m, err := h.loadUnified(group)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		defer m.Close()
+		metrics, err := m.Stat()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, metrics)
+	default:
+		cg, err := cgroups.Load(cgroups.V1, cgroups.StaticPath(group))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		metrics, err := cg.Stat(cgroups.IgnoreNotExist)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, metrics)
+	}
+}
+
+func (h *Handler) events(w http.ResponseWriter, r *http.Request, group string) {
+	if h.mode != cgroups.Unified {
+		http.Error(w, "events are only supported on cgroups v2 hosts", http.StatusNotImplemented)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	m, err := h.loadUnified(group)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer m.Close()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// the watch ends when the client goes away, or with an error when the
+	// group is removed
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	events, errCh := h.mux.Subscribe(ctx, m)
+	for e := range events {
+		data, err := json.Marshal(e.Event)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: memory\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+	if err := <-errCh; err != nil {
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", strings.Replace(err.Error(), "\n", " ", -1))
+		flusher.Flush()
+	}
+}
+
+// groupPath returns the cgroup path for the request's path, scoped to the
+// handler's root so that clients cannot escape it with ".." elements
+func (h *Handler) groupPath(path string) (string, error) {
+	clean := filepath.Clean("/" + path)
+	if clean == "/" {
+		return "", errors.New("path must name a group under the root")
+	}
+	return filepath.Join(h.root, clean), nil
+}
+
+func (h *Handler) loadUnified(group string) (*v2.Manager, error) {
+	if _, err := os.Stat(filepath.Join(h.mountpoint, group)); err != nil {
+		return nil, err
+	}
+	return v2.LoadManager(h.mountpoint, group)
+}
+
+// walkLegacy returns the groups under the root of any v1 subsystem
+func (h *Handler) walkLegacy() ([]string, error) {
+	subsystems, err := cgroups.V1()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	for _, s := range subsystems {
+		p, ok := s.(interface {
+			Path(string) string
+		})
+		if !ok {
+			continue
+		}
+		groups, err := walk(p.Path(h.root))
+		if err != nil {
+			if os.IsNotExist(errors.Cause(err)) {
+				continue
+			}
+			return nil, err
+		}
+		for _, g := range groups {
+			seen[g] = struct{}{}
+		}
+	}
+	groups := make([]string, 0, len(seen))
+	for g := range seen {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// walk returns the paths, relative to root, of every group below root
+func walk(root string) ([]string, error) {
+	groups := []string{}
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, "/"+rel)
+		return nil
+	})
+	return groups, err
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError maps the package errors to http status codes
+func writeError(w http.ResponseWriter, err error) {
+	cause := errors.Cause(err)
+	switch {
+	case os.IsNotExist(cause), cause == cgroups.ErrCgroupDeleted, cause == v2.ErrCgroupDeleted:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case os.IsPermission(cause):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case cause == v2.ErrInvalidGroupPath:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
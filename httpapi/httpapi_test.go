@@ -0,0 +1,117 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package httpapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/containerd/cgroups"
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-httpapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"a", "a/b", "c"} {
+		if err := os.MkdirAll(filepath.Join(mountpoint, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range map[string]string{
+		"a/cgroup.controllers": "pids",
+		"a/pids.current":       "3",
+		"a/pids.max":           "max",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(mountpoint, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mux := http.NewServeMux()
+	New(WithMode(cgroups.Unified), WithMountpoint(mountpoint)).Register(mux)
+	return httptest.NewServer(mux), mountpoint
+}
+
+func TestList(t *testing.T) {
+	server, mountpoint := newTestServer(t)
+	defer os.RemoveAll(mountpoint)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/cgroups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var groups []string
+	if err := json.NewDecoder(resp.Body).Decode(&groups); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"/a", "/a/b", "/c"}; !reflect.DeepEqual(groups, expected) {
+		t.Fatalf("expected groups %v but received %v", expected, groups)
+	}
+}
+
+func TestStats(t *testing.T) {
+	server, mountpoint := newTestServer(t)
+	defer os.RemoveAll(mountpoint)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/cgroups/a/stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 but received %d", resp.StatusCode)
+	}
+	var metrics stats.Metrics
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Pids == nil || metrics.Pids.Current != 3 {
+		t.Fatalf("expected 3 pids but received %+v", metrics.Pids)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	server, mountpoint := newTestServer(t)
+	defer os.RemoveAll(mountpoint)
+	defer server.Close()
+
+	for path, expected := range map[string]int{
+		"/cgroups/missing/stats": http.StatusNotFound,
+		"/cgroups/stats":         http.StatusBadRequest,
+		"/cgroups/a/unknown":     http.StatusNotFound,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("expected status %d for %s but received %d", expected, path, resp.StatusCode)
+		}
+	}
+}
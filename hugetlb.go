@@ -24,10 +24,11 @@ import (
 
 	v1 "github.com/containerd/cgroups/stats/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
 )
 
 func NewHugetlb(root string) (*hugetlbController, error) {
-	sizes, err := hugePageSizes()
+	sizes, err := HugePageSizes()
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +53,12 @@ func (h *hugetlbController) Path(path string) string {
 }
 
 func (h *hugetlbController) Create(path string, resources *specs.LinuxResources) error {
+	// validate every limit first so that none is written if one is invalid
+	for _, limit := range resources.HugepageLimits {
+		if !h.supported(limit.Pagesize) {
+			return errors.Wrapf(ErrHugePageSizeNotSupported, "hugepage size %q", limit.Pagesize)
+		}
+	}
 	if err := os.MkdirAll(h.Path(path), defaultDirPerm); err != nil {
 		return err
 	}
@@ -67,6 +74,16 @@ func (h *hugetlbController) Create(path string, resources *specs.LinuxResources)
 	return nil
 }
 
+// supported returns true if the pagesize is one of the sizes discovered on the host
+func (h *hugetlbController) supported(size string) bool {
+	for _, s := range h.sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *hugetlbController) Stat(path string, stats *v1.Metrics) error {
 	for _, size := range h.sizes {
 		s, err := h.readSizeStat(path, size)
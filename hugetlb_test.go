@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+)
+
+func TestHugePageSizes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hugepages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"hugepages-2048kB", "hugepages-1048576kB"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func(old string) { hugePagesDir = old }(hugePagesDir)
+	hugePagesDir = dir
+
+	sizes, err := HugePageSizes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"1GB", "2MB"}; !reflect.DeepEqual(sizes, expected) {
+		t.Fatalf("expected sizes %v but received %v", expected, sizes)
+	}
+}
+
+func TestHugetlbUnsupportedSize(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	h := &hugetlbController{
+		root:  filepath.Join(mock.root, string(Hugetlb)),
+		sizes: []string{"2MB"},
+	}
+	resources := &specs.LinuxResources{
+		HugepageLimits: []specs.LinuxHugepageLimit{
+			{Pagesize: "2MB", Limit: 1024},
+			{Pagesize: "1GB", Limit: 1024},
+		},
+	}
+	err = h.Create("test", resources)
+	if errors.Cause(err) != ErrHugePageSizeNotSupported {
+		t.Fatalf("expected ErrHugePageSizeNotSupported but received %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(h.Path("test"), "hugetlb.2MB.limit_in_bytes")); !os.IsNotExist(err) {
+		t.Fatalf("expected no limit to be written before the invalid one: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package cgfs holds the cgroup filesystem helpers shared by the cgroups
+// and v2 packages
+package cgfs
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AuditRecord describes a single write made to a cgroup interface file
+type AuditRecord struct {
+	Time  time.Time `json:"time"`
+	Path  string    `json:"path"`
+	Value string    `json:"value"`
+	// Error is set when the write failed
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink receives a record of every file write performed by the module
+type AuditSink func(AuditRecord)
+
+var auditSink atomic.Value
+
+// SetAuditSink sets the sink that every file write is recorded to,
+// a nil sink disables the audit trail
+func SetAuditSink(sink AuditSink) {
+	auditSink.Store(sink)
+}
+
+// NewAuditWriter returns a sink that writes each record to w as a line of JSON
+func NewAuditWriter(w io.Writer) AuditSink {
+	var (
+		mu  sync.Mutex
+		enc = json.NewEncoder(w)
+	)
+	return func(r AuditRecord) {
+		mu.Lock()
+		enc.Encode(r)
+		mu.Unlock()
+	}
+}
+
+// Audit records the write of data to path to the sink, if one is set
+func Audit(path string, data []byte, err error) {
+	sink, _ := auditSink.Load().(AuditSink)
+	if sink == nil {
+		return
+	}
+	r := AuditRecord{
+		Time:  time.Now(),
+		Path:  path,
+		Value: string(data),
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	sink(r)
+}
@@ -0,0 +1,91 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"errors"
+	"syscall"
+
+	perrors "github.com/pkg/errors"
+)
+
+// ErrorKind categorizes the errors returned by the cgroups and v2 packages so
+// that callers can decide how to handle them without matching on error
+// strings
+type ErrorKind int
+
+const (
+	// KindUnknown is any error that does not fit another kind
+	KindUnknown ErrorKind = iota
+	// KindNotFound is returned when the cgroup, a controller's file or the
+	// cgroup filesystem does not exist
+	KindNotFound
+	// KindPermissionDenied is returned when the caller cannot modify the cgroup
+	KindPermissionDenied
+	// KindUnsupported is returned when the kernel does not support a
+	// controller or feature
+	KindUnsupported
+	// KindBusy is returned for transient failures that may succeed if the
+	// operation is retried, such as removing a cgroup that still has tasks
+	KindBusy
+	// KindInvalidInput is returned when a value is rejected as invalid
+	KindInvalidInput
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindPermissionDenied:
+		return "permission denied"
+	case KindUnsupported:
+		return "unsupported"
+	case KindBusy:
+		return "busy"
+	case KindInvalidInput:
+		return "invalid input"
+	}
+	return "unknown"
+}
+
+// KindOf returns the kind of the errors shared by the packages and of the
+// errno err wraps, looking through wrapped errors
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return KindUnknown
+	}
+	if perrors.Cause(err) == ErrUnexpectedFileType {
+		return KindInvalidInput
+	}
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return KindUnknown
+	}
+	switch errno {
+	case syscall.ENOENT, syscall.ESRCH:
+		return KindNotFound
+	case syscall.EPERM, syscall.EACCES, syscall.EROFS:
+		return KindPermissionDenied
+	case syscall.ENODEV, syscall.EOPNOTSUPP, syscall.ENOSYS:
+		return KindUnsupported
+	case syscall.EBUSY, syscall.EAGAIN, syscall.EINTR:
+		return KindBusy
+	case syscall.EINVAL, syscall.ERANGE:
+		return KindInvalidInput
+	}
+	return KindUnknown
+}
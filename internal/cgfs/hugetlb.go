@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"io/ioutil"
+	"strings"
+
+	units "github.com/docker/go-units"
+)
+
+// HugePageSizes returns the hugepage sizes supported by the host, as used in
+// the hugetlb file names (e.g. "2MB", "1GB"), discovered from the entries of
+// dir, which is /sys/kernel/mm/hugepages on the host
+func HugePageSizes(dir string) ([]string, error) {
+	var (
+		pageSizes []string
+		sizeList  = []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range files {
+		nameArray := strings.Split(st.Name(), "-")
+		if len(nameArray) != 2 {
+			continue
+		}
+		pageSize, err := units.RAMInBytes(nameArray[1])
+		if err != nil {
+			return nil, err
+		}
+		pageSizes = append(pageSizes, units.CustomSize("%g%s", float64(pageSize), 1024.0, sizeList))
+	}
+	return pageSizes, nil
+}
@@ -0,0 +1,78 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultMaxReadSize is far larger than any cgroup or proc file the package
+// reads on a sane kernel
+const defaultMaxReadSize = 1 << 20
+
+var maxReadSize int64 = defaultMaxReadSize
+
+// SetMaxReadSize sets the upper bound of bytes read from a single stat or
+// limit file by either package. Larger files are truncated at their last complete line and a
+// warning is logged, so that the stats parsed from them are partial. Process
+// lists are always read in full.
+func SetMaxReadSize(n int64) {
+	if n <= 0 {
+		n = defaultMaxReadSize
+	}
+	atomic.StoreInt64(&maxReadSize, n)
+}
+
+// MaxReadSize returns the upper bound set by SetMaxReadSize
+func MaxReadSize() int64 {
+	return atomic.LoadInt64(&maxReadSize)
+}
+
+// ReadBounded reads the file at path, up to the configured maximum size
+func ReadBounded(path string) ([]byte, error) {
+	f, err := OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	max := MaxReadSize()
+	data, err := ioutil.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return nil, err
+	}
+	return Truncate(data, max, path), nil
+}
+
+// Truncate cuts data longer than max at its last complete line, logging a
+// warning for the file at path
+func Truncate(data []byte, max int64, path string) []byte {
+	if int64(len(data)) <= max {
+		return data
+	}
+	data = data[:max]
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[:i+1]
+	}
+	logrus.Warnf("cgroups: %s is larger than %d bytes, parsing the first %d bytes", path, max, len(data))
+	return data
+}
@@ -0,0 +1,46 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadBounded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "memory.stat")
+	if err := ioutil.WriteFile(path, []byte("anon 1\nfile 2\nkernel_stack 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetMaxReadSize(20)
+	defer SetMaxReadSize(0)
+
+	data, err := ReadBounded(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "anon 1\nfile 2\n"; string(data) != expected {
+		t.Fatalf("expected the file to be truncated to %q but received %q", expected, data)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// ErrUnexpectedFileType is returned when a cgroup file is not of the expected
+// type, such as a symlink or a directory planted in a delegated subtree
+var ErrUnexpectedFileType = errors.New("cgroups: unexpected file type")
+
+// OpenFile opens a cgroup file without following a symlink in its last
+// element and rejects anything but a regular file, so that links planted in
+// a delegated, writable subtree cannot redirect the package's reads and writes
+func OpenFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	f, err := os.OpenFile(path, flag|unix.O_NOFOLLOW|unix.O_CLOEXEC, perm)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFileType(f, 0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// OpenDir opens a cgroup directory without following a symlink in its last
+// element
+func OpenDir(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFileType(f, os.ModeDir); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// WriteFile writes data to the cgroup file at path, like ioutil.WriteFile
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func checkFileType(f *os.File, typ os.FileMode) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeType != typ {
+		return errors.Wrapf(ErrUnexpectedFileType, "%s has mode %v", f.Name(), info.Mode())
+	}
+	return nil
+}
+
+// WriteFileAt writes value to the file name in the cgroup directory open as
+// dirFd, opening it relative to the directory without following symlinks,
+// rejecting anything but a regular file, retrying writes interrupted by
+// EINTR and recording the write to the audit sink. Errors from the kernel
+// are returned as *os.PathError.
+func WriteFileAt(dirFd int, name string, value []byte) error {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return errors.Errorf("cgroups: invalid file name %q", name)
+	}
+	path := name
+	if dir, err := os.Readlink(fmt.Sprintf("/proc/self/fd/%d", dirFd)); err == nil {
+		path = filepath.Join(dir, name)
+	}
+	for {
+		err := writeFileAt(dirFd, name, path, value)
+		if !errors.Is(err, unix.EINTR) {
+			Audit(path, value, err)
+			return err
+		}
+	}
+}
+
+func writeFileAt(dirFd int, name, path string, value []byte) error {
+	fd, err := unix.Openat(dirFd, name, unix.O_WRONLY|unix.O_TRUNC|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return &os.PathError{Op: "openat", Path: path, Err: err}
+	}
+	f := os.NewFile(uintptr(fd), path)
+	if err := checkFileType(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+	_, err = f.Write(value)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-writefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "pids.max"), []byte("max"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "pids.max"), filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	fd := int(d.Fd())
+
+	if err := WriteFileAt(fd, "pids.max", []byte("10")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "pids.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "10" {
+		t.Fatalf("expected 10 to be written, got %q", data)
+	}
+	if err := WriteFileAt(fd, "link", []byte("20")); err == nil {
+		t.Fatal("expected writing through a symlink to fail")
+	}
+	if err := WriteFileAt(fd, "missing", []byte("1")); !os.IsNotExist(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if err := WriteFileAt(fd, "../pids.max", []byte("1")); err == nil {
+		t.Fatal("expected a name with a separator to be rejected")
+	}
+}
@@ -0,0 +1,146 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const cgroupProcs = "cgroup.procs"
+
+// ProcsIterator streams the pids of a cgroup from cgroup.procs without
+// reading them all into memory first, which matters for cgroups with tens
+// of thousands of processes. Iteration can be stopped at any point by
+// calling Close.
+type ProcsIterator struct {
+	root      string
+	recursive bool
+	dirs      []string
+	file      *os.File
+	scanner   *bufio.Scanner
+	dir       string
+	pid       uint64
+	err       error
+}
+
+// NewProcsIterator returns an iterator over the pids of the cgroup directory
+// root and, when recursive is true, of all its descendants
+func NewProcsIterator(root string, recursive bool) *ProcsIterator {
+	return &ProcsIterator{
+		root:      root,
+		recursive: recursive,
+		dirs:      []string{root},
+	}
+}
+
+// Next advances to the next pid, returning false when there are no more
+// pids or an error occurred
+func (it *ProcsIterator) Next() bool {
+	for it.err == nil {
+		if it.scanner != nil {
+			for it.scanner.Scan() {
+				t := strings.TrimSpace(it.scanner.Text())
+				if t == "" {
+					continue
+				}
+				pid, err := strconv.ParseUint(t, 10, 64)
+				if err != nil {
+					it.err = err
+					return false
+				}
+				it.pid = pid
+				return true
+			}
+			it.err = it.scanner.Err()
+			it.closeFile()
+			continue
+		}
+		if len(it.dirs) == 0 {
+			return false
+		}
+		dir := it.dirs[len(it.dirs)-1]
+		it.dirs = it.dirs[:len(it.dirs)-1]
+		if err := it.open(dir); err != nil {
+			// descendants removed while iterating are skipped
+			if os.IsNotExist(err) && dir != it.root {
+				continue
+			}
+			it.err = err
+		}
+	}
+	return false
+}
+
+func (it *ProcsIterator) open(dir string) error {
+	f, err := OpenFile(filepath.Join(dir, cgroupProcs), os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	if it.recursive {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		// push in reverse so that children are visited in lexical order
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].IsDir() {
+				it.dirs = append(it.dirs, filepath.Join(dir, entries[i].Name()))
+			}
+		}
+	}
+	it.dir = dir
+	it.file = f
+	it.scanner = bufio.NewScanner(f)
+	return nil
+}
+
+func (it *ProcsIterator) closeFile() {
+	if it.file != nil {
+		it.file.Close()
+		it.file = nil
+	}
+	it.scanner = nil
+}
+
+// Pid returns the current pid
+func (it *ProcsIterator) Pid() uint64 {
+	return it.pid
+}
+
+// Dir returns the cgroup directory the current pid was read from
+func (it *ProcsIterator) Dir() string {
+	return it.dir
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ProcsIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and releases the open file, it is safe to call
+// at any time and more than once
+func (it *ProcsIterator) Close() error {
+	it.closeFile()
+	it.dirs = nil
+	return nil
+}
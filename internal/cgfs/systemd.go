@@ -0,0 +1,101 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrUnitExists       = errors.New("cgroups: systemd unit already exists")
+	ErrUnitMasked       = errors.New("cgroups: systemd unit is masked")
+	ErrDependencyFailed = errors.New("cgroups: systemd job dependency failed")
+	ErrJobFailed        = errors.New("cgroups: systemd job failed")
+)
+
+// JobResult translates the result of a systemd job, as delivered on the
+// channel passed to the dbus call, into an error
+func JobResult(unit, result string) error {
+	switch result {
+	case "done":
+		return nil
+	case "dependency":
+		return errors.Wrapf(ErrDependencyFailed, "unit %s", unit)
+	}
+	return errors.Wrapf(ErrJobFailed, "unit %s: %s", unit, result)
+}
+
+// UnitError translates the dbus errors of queuing a job for a unit
+func UnitError(err error) error {
+	if dbusError, ok := err.(dbus.Error); ok {
+		switch dbusError.Name {
+		case "org.freedesktop.systemd1.UnitExists":
+			return errors.Wrap(ErrUnitExists, err.Error())
+		case "org.freedesktop.systemd1.UnitMasked":
+			return errors.Wrap(ErrUnitMasked, err.Error())
+		}
+	}
+	return err
+}
+
+// UnitRemoveTimeout is how long to wait for systemd to unload a stopped unit
+const UnitRemoveTimeout = 5 * time.Second
+
+// UnitConn is the part of the systemd dbus connection used to wait for the
+// removal of a unit
+type UnitConn interface {
+	GetUnitProperties(unit string) (map[string]interface{}, error)
+	ResetFailedUnit(unit string) error
+}
+
+// WaitUnitRemoved waits for systemd to unload a stopped unit, resetting it
+// if it is in the failed state as systemd keeps failed units loaded until
+// then. Starting a unit with the same name fails while it is still loaded.
+func WaitUnitRemoved(conn UnitConn, unit string, timeout time.Duration, clk clock.Clock) error {
+	var (
+		deadline = clk.Now().Add(timeout)
+		interval = time.Millisecond
+	)
+	for {
+		props, err := conn.GetUnitProperties(unit)
+		if err != nil {
+			if dbusError, ok := err.(dbus.Error); ok && dbusError.Name == "org.freedesktop.systemd1.NoSuchUnit" {
+				return nil
+			}
+			return err
+		}
+		if props["LoadState"] == "not-found" {
+			return nil
+		}
+		if props["ActiveState"] == "failed" {
+			if err := conn.ResetFailedUnit(unit); err != nil {
+				return err
+			}
+		}
+		if clk.Now().After(deadline) {
+			return errors.Errorf("cgroups: timed out waiting for unit %s to be removed", unit)
+		}
+		clock.Sleep(clk, interval)
+		if interval *= 2; interval > 100*time.Millisecond {
+			interval = 100 * time.Millisecond
+		}
+	}
+}
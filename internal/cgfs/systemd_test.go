@@ -0,0 +1,101 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+func TestJobResult(t *testing.T) {
+	for _, tc := range []struct {
+		result   string
+		expected error
+	}{
+		{"done", nil},
+		{"dependency", ErrDependencyFailed},
+		{"failed", ErrJobFailed},
+		{"timeout", ErrJobFailed},
+		{"canceled", ErrJobFailed},
+	} {
+		if err := JobResult("test.scope", tc.result); errors.Cause(err) != tc.expected {
+			t.Errorf("expected %v for result %q, got %v", tc.expected, tc.result, err)
+		}
+	}
+}
+
+func TestUnitError(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		expected error
+	}{
+		{"org.freedesktop.systemd1.UnitExists", ErrUnitExists},
+		{"org.freedesktop.systemd1.UnitMasked", ErrUnitMasked},
+	} {
+		err := UnitError(dbus.Error{Name: tc.name, Body: []interface{}{"test.scope"}})
+		if errors.Cause(err) != tc.expected {
+			t.Errorf("expected %v for %s, got %v", tc.expected, tc.name, err)
+		}
+	}
+	other := dbus.Error{Name: "org.freedesktop.DBus.Error.AccessDenied"}
+	if err := UnitError(other); err.(dbus.Error).Name != other.Name {
+		t.Errorf("expected other errors to be returned unchanged, got %v", err)
+	}
+}
+
+type fakeUnitConn struct {
+	states []string
+	resets int
+}
+
+func (f *fakeUnitConn) GetUnitProperties(unit string) (map[string]interface{}, error) {
+	if len(f.states) == 0 {
+		return nil, dbus.Error{Name: "org.freedesktop.systemd1.NoSuchUnit"}
+	}
+	state := f.states[0]
+	f.states = f.states[1:]
+	if state == "not-found" {
+		return map[string]interface{}{"LoadState": state, "ActiveState": "inactive"}, nil
+	}
+	return map[string]interface{}{"LoadState": "loaded", "ActiveState": state}, nil
+}
+
+func (f *fakeUnitConn) ResetFailedUnit(unit string) error {
+	f.resets++
+	return nil
+}
+
+func TestWaitUnitRemoved(t *testing.T) {
+	conn := &fakeUnitConn{states: []string{"deactivating", "failed", "not-found"}}
+	if err := WaitUnitRemoved(conn, "test.scope", time.Second, clock.Real); err != nil {
+		t.Fatal(err)
+	}
+	if conn.resets != 1 {
+		t.Fatalf("expected the failed unit to be reset once, got %d", conn.resets)
+	}
+	if err := WaitUnitRemoved(&fakeUnitConn{}, "test.scope", time.Second, clock.Real); err != nil {
+		t.Fatalf("expected a unit that does not exist to be removed: %v", err)
+	}
+	stuck := &fakeUnitConn{states: []string{"active", "active", "active", "active", "active"}}
+	if err := WaitUnitRemoved(stuck, "test.scope", 5*time.Millisecond, clock.Real); err == nil {
+		t.Fatal("expected a timeout waiting for an active unit")
+	}
+}
@@ -0,0 +1,31 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import "github.com/containerd/cgroups/internal/cgfs"
+
+// SetMaxReadSize sets the upper bound of bytes read from a single stat or
+// limit file. Larger files are truncated at their last complete line and a
+// warning is logged, so that the stats parsed from them are partial. Process
+// lists are always read in full. The bound is shared with the v2 package.
+func SetMaxReadSize(n int64) {
+	cgfs.SetMaxReadSize(n)
+}
+
+func readBounded(path string) ([]byte, error) {
+	return cgfs.ReadBounded(path)
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// LoadAll loads every cgroup at or below root for which filter returns true,
+// keyed by their path. A nil filter loads all of them.
+//
+// Unlike calling Load for each cgroup the hierarchy is discovered only once,
+// which makes it suitable for daemons reattaching to many cgroups at start.
+// A cgroup is loaded with the subsystems it exists in.
+func LoadAll(hierarchy Hierarchy, root string, filter func(path string) bool) (map[string]Cgroup, error) {
+	subsystems, err := hierarchy()
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string][]Subsystem)
+	for _, s := range pathers(subsystems) {
+		s := s
+		if err := walkGroups(s.Path(root), root, func(path string) {
+			if filter == nil || filter(path) {
+				found[path] = append(found[path], s)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	out := make(map[string]Cgroup, len(found))
+	for path, subsystems := range found {
+		out[path] = &cgroup{
+			path:       StaticPath(path),
+			subsystems: subsystems,
+		}
+	}
+	return out, nil
+}
+
+// walkGroups calls fn with the path of every directory at or below dir,
+// where dir is the directory of the cgroup path. A missing dir is skipped.
+func walkGroups(dir, path string, fn func(path string)) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	fn(path)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if err := walkGroups(filepath.Join(dir, e.Name()), filepath.Join(path, e.Name()), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"strings"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestLoadAll(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	for _, path := range []string{"/parent/a", "/parent/b", "/other"} {
+		if _, err := New(mock.hierarchy, StaticPath(path), &specs.LinuxResources{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	calls := 0
+	hierarchy := func() ([]Subsystem, error) {
+		calls++
+		return mock.hierarchy()
+	}
+	all, err := LoadAll(hierarchy, "/parent", func(path string) bool {
+		return strings.HasPrefix(path, "/parent/")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the hierarchy to be discovered once, got %d", calls)
+	}
+	if len(all) != 2 || all["/parent/a"] == nil || all["/parent/b"] == nil {
+		t.Fatalf("unexpected cgroups %v", all)
+	}
+	expected, err := Load(mock.hierarchy, StaticPath("/parent/a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(all["/parent/a"].Subsystems()); got != len(expected.Subsystems()) {
+		t.Fatalf("expected %d subsystems, got %d", len(expected.Subsystems()), got)
+	}
+}
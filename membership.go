@@ -0,0 +1,124 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Membership is the placement of a process of a cgroup across the
+// subsystems of the cgroup
+type Membership struct {
+	Pid int
+	// Path is the group of the process in the subsystem it was listed from,
+	// as read from /proc/<pid>/cgroup
+	Path string
+	// Paths are the groups of the process in every subsystem of the cgroup
+	// that the process has an entry for
+	Paths map[Name]string
+	// Mismatched are the subsystems in which the process is in another
+	// group than Path, sorted by name
+	Mismatched []Name
+}
+
+// SplitBrain returns true if the process is not in the same group in every
+// subsystem, e.g. after a migration that only moved it in some of them
+func (m Membership) SplitBrain() bool {
+	return len(m.Mismatched) > 0
+}
+
+// ProcessMembership returns the membership of every process of the cgroup
+// in subsystem across all subsystems of the cgroup, read from
+// /proc/<pid>/cgroup, to detect processes whose placement was split by a
+// partial migration. Processes that exit while they are read are left out.
+func ProcessMembership(c Cgroup, subsystem Name, opts ...ProcOpts) ([]Membership, error) {
+	config := procConfig{
+		root: defaultProcRoot,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	processes, err := c.Processes(subsystem, false)
+	if err != nil {
+		return nil, err
+	}
+	var names []Name
+	for _, s := range pathers(c.Subsystems()) {
+		names = append(names, s.Name())
+	}
+	var out []Membership
+	for _, p := range processes {
+		file := filepath.Join(config.root, strconv.Itoa(p.Pid), "cgroup")
+		paths, err := ParseCgroupFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "parse cgroup file %s", file)
+		}
+		m, ok := membership(p.Pid, subsystem, names, paths)
+		if !ok {
+			return nil, errors.Errorf("cgroups: process %d has no %s entry in %s", p.Pid, subsystem, file)
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// membership compares the groups of the process in the named subsystems to
+// its group in subsystem, paths are the entries of /proc/<pid>/cgroup. False
+// is returned if the process has no entry for subsystem.
+func membership(pid int, subsystem Name, names []Name, paths map[string]string) (Membership, bool) {
+	path, ok := subsystemPath(paths, subsystem)
+	if !ok {
+		return Membership{}, false
+	}
+	m := Membership{
+		Pid:   pid,
+		Path:  path,
+		Paths: make(map[Name]string, len(names)),
+	}
+	for _, name := range names {
+		p, ok := subsystemPath(paths, name)
+		if !ok {
+			continue
+		}
+		m.Paths[name] = p
+		if p != path {
+			m.Mismatched = append(m.Mismatched, name)
+		}
+	}
+	sort.Slice(m.Mismatched, func(i, j int) bool {
+		return m.Mismatched[i] < m.Mismatched[j]
+	})
+	return m, true
+}
+
+// subsystemPath returns the group of the subsystem from the entries of
+// /proc/<pid>/cgroup, where named hierarchies are keyed by "name=<name>"
+func subsystemPath(paths map[string]string, name Name) (string, bool) {
+	if p, ok := paths[string(name)]; ok {
+		return p, true
+	}
+	p, ok := paths["name="+string(name)]
+	return p, ok
+}
@@ -0,0 +1,81 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestProcessMembership(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(mock.root, string(Memory), "test", cgroupProcs), []byte("1\n2\n3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	procRoot, err := ioutil.TempDir("", "cgroups-procfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(procRoot)
+	// pid 3 exited after it was listed
+	for pid, data := range map[string]string{
+		"1": "5:memory:/test\n4:cpu,cpuacct:/test\n3:freezer:/test\n1:name=systemd:/test\n",
+		"2": "5:memory:/test\n4:cpu,cpuacct:/other\n3:freezer:/test\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(procRoot, pid), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(procRoot, pid, "cgroup"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	members, err := ProcessMembership(control, Memory, WithProcRoot(procRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("expected 2 processes but received %d", len(members))
+	}
+	if m := members[0]; m.Pid != 1 || m.SplitBrain() {
+		t.Fatalf("expected pid 1 in the same group everywhere but received %+v", m)
+	}
+	m := members[1]
+	if m.Pid != 2 || !m.SplitBrain() {
+		t.Fatalf("expected pid 2 to be split but received %+v", m)
+	}
+	if expected := []Name{Cpu, Cpuacct}; !reflect.DeepEqual(m.Mismatched, expected) {
+		t.Fatalf("expected mismatched %v but received %v", expected, m.Mismatched)
+	}
+	if m.Paths[Cpu] != "/other" || m.Path != "/test" {
+		t.Fatalf("unexpected paths %+v", m)
+	}
+}
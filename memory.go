@@ -18,6 +18,8 @@ package cgroups
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -28,6 +30,8 @@ import (
 
 	v1 "github.com/containerd/cgroups/stats/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
 )
 
@@ -225,6 +229,38 @@ func (m *memoryController) Create(path string, resources *specs.LinuxResources)
 	return m.set(path, getMemorySettings(resources))
 }
 
+// errnoENOTSUPP is the kernel's internal ENOTSUPP, which some kernels leak to
+// userspace for kernel memory limits and has no constant in x/sys/unix
+const errnoENOTSUPP = syscall.Errno(524)
+
+// enableAccounting turns on hierarchical accounting and activates kernel
+// memory accounting by setting and removing a kernel memory limit, which
+// must happen before the cgroup has tasks or children. Kernels without
+// memory.kmem.limit_in_bytes, or that refuse kernel memory limits with
+// EOPNOTSUPP or ENOTSUPP (5.16 and newer), always account kernel memory.
+func (m *memoryController) enableAccounting(path string) error {
+	if err := retryingWriteFile(
+		filepath.Join(m.Path(path), "memory.use_hierarchy"),
+		[]byte("1"),
+		defaultFilePerm,
+	); err != nil {
+		return err
+	}
+	for _, i := range []int64{1, -1} {
+		if err := retryingWriteFile(
+			filepath.Join(m.Path(path), "memory.kmem.limit_in_bytes"),
+			[]byte(strconv.FormatInt(i, 10)),
+			defaultFilePerm,
+		); err != nil {
+			if os.IsNotExist(err) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, errnoENOTSUPP) {
+				return nil
+			}
+			return checkEBUSY(err)
+		}
+	}
+	return nil
+}
+
 func (m *memoryController) Update(path string, resources *specs.LinuxResources) error {
 	if resources.Memory == nil {
 		return nil
@@ -234,32 +270,49 @@ func (m *memoryController) Update(path string, resources *specs.LinuxResources)
 	}
 	settings := getMemorySettings(resources)
 	if g(resources.Memory.Limit) && g(resources.Memory.Swap) {
-		// if the updated swap value is larger than the current memory limit set the swap changes first
-		// then set the memory limit as swap must always be larger than the current limit
-		current, err := readUint(filepath.Join(m.Path(path), "memory.limit_in_bytes"))
+		// the memory limit must never be above the memory+swap limit, if
+		// the updated memory limit is above the current memory+swap limit
+		// set the swap changes first and then the memory limit
+		current, err := readUint(filepath.Join(m.Path(path), "memory.memsw.limit_in_bytes"))
 		if err != nil {
 			return err
 		}
-		if current < uint64(*resources.Memory.Swap) {
-			settings[0], settings[1] = settings[1], settings[0]
+		if uint64(*resources.Memory.Limit) > current {
+			settings = swapFirst(settings)
 		}
 	}
 	return m.set(path, settings)
 }
 
+// swapFirst returns the settings with the memory+swap limit moved before the
+// memory limit
+func swapFirst(settings []memorySettings) []memorySettings {
+	out := make([]memorySettings, 0, len(settings))
+	for _, t := range settings {
+		if t.name == "memsw.limit_in_bytes" {
+			out = append(out, t)
+		}
+	}
+	for _, t := range settings {
+		if t.name != "memsw.limit_in_bytes" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
 func (m *memoryController) Stat(path string, stats *v1.Metrics) error {
-	f, err := os.Open(filepath.Join(m.Path(path), "memory.stat"))
+	data, err := readBounded(filepath.Join(m.Path(path), "memory.stat"))
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 	stats.Memory = &v1.MemoryStat{
 		Usage:     &v1.MemoryEntry{},
 		Swap:      &v1.MemoryEntry{},
 		Kernel:    &v1.MemoryEntry{},
 		KernelTCP: &v1.MemoryEntry{},
 	}
-	if err := m.parseStats(f, stats.Memory); err != nil {
+	if err := m.parseStats(bytes.NewReader(data), stats.Memory); err != nil {
 		return err
 	}
 	for _, t := range []struct {
@@ -329,12 +382,13 @@ func (m *memoryController) parseStats(r io.Reader, stat *v1.MemoryStat) error {
 		line int
 	)
 	for sc.Scan() {
+		line++
 		key, v, err := parseKV(sc.Text())
 		if err != nil {
-			return fmt.Errorf("%d: %v", line, err)
+			logrus.Warnf("cgroups: skipping invalid memory.stat line %d %q: %v", line, sc.Text(), err)
+			continue
 		}
 		raw[key] = v
-		line++
 	}
 	if err := sc.Err(); err != nil {
 		return err
@@ -389,6 +443,53 @@ func (m *memoryController) set(path string, settings []memorySettings) error {
 	return nil
 }
 
+// ForceEmpty reclaims as many pages charged to the cgroup as possible by
+// writing memory.force_empty. Calling it before Delete pushes the page cache
+// of the cgroup back to the parent rather than leaving it charged to a
+// removed cgroup. The write can take a long time for cgroups with a large
+// page cache, when ctx is done before it completes ctx.Err() is returned
+// while the kernel carries on reclaiming in the background.
+func ForceEmpty(ctx context.Context, c Cgroup) error {
+	cg, ok := c.(*cgroup)
+	if !ok {
+		return errors.Errorf("cgroups: cannot force empty %T", c)
+	}
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	if cg.err != nil {
+		return cg.err
+	}
+	s := cg.getSubsystem(Memory)
+	if s == nil {
+		return ErrMemoryNotSupported
+	}
+	sp, err := cg.path(Memory)
+	if err != nil {
+		return err
+	}
+	return s.(*memoryController).forceEmpty(ctx, sp)
+}
+
+func (m *memoryController) forceEmpty(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- retryingWriteFile(
+			filepath.Join(m.Path(path), "memory.force_empty"),
+			[]byte("0"),
+			defaultFilePerm,
+		)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
 type memorySettings struct {
 	name  string
 	value *int64
@@ -459,7 +560,7 @@ func (m *memoryController) memoryEvent(path string, event MemoryEvent) (uintptr,
 	if err != nil {
 		return 0, err
 	}
-	evtFile, err := os.Open(filepath.Join(root, event.EventFile()))
+	evtFile, err := openFile(filepath.Join(root, event.EventFile()), os.O_RDONLY, 0)
 	if err != nil {
 		unix.Close(efd)
 		return 0, err
@@ -17,6 +17,7 @@
 package cgroups
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -25,6 +26,7 @@ import (
 	"testing"
 
 	v1 "github.com/containerd/cgroups/stats/v1"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
 )
 
 const memoryData = `cache 1
@@ -261,3 +263,127 @@ func buildMemoryMetrics(t *testing.T, modules []string, metrics []string) string
 	}
 	return tmpRoot
 }
+
+func TestParseMemoryStatsSkipsInvalidLines(t *testing.T) {
+	var (
+		c = &memoryController{}
+		m = &v1.MemoryStat{}
+		r = strings.NewReader("cache 1\ngarbage\nrss x\nmapped_file 3\n")
+	)
+	if err := c.parseStats(r, m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Cache != 1 || m.RSS != 0 || m.MappedFile != 3 {
+		t.Fatalf("expected the valid lines to be parsed but received %+v", m)
+	}
+}
+
+func TestForceEmpty(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := path.Join(mock.root, "memory", "test", "memory.force_empty")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ForceEmpty(ctx, control); err != context.Canceled {
+		t.Fatalf("expected canceled context to be returned, got %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("expected memory.force_empty not to be written: %v", err)
+	}
+
+	if err := ForceEmpty(context.Background(), control); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "0" {
+		t.Fatalf("expected 0 to be written, got %q", data)
+	}
+}
+
+func TestMoveChargeAtImmigrate(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetMoveChargeAtImmigrate(control, MoveChargeAnon|MoveChargeFile); err != nil {
+		t.Fatal(err)
+	}
+	charge, err := MoveChargeAtImmigrate(control)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if charge != MoveChargeAnon|MoveChargeFile {
+		t.Fatalf("expected move charge 3, got %d", charge)
+	}
+	if err := SetMoveChargeAtImmigrate(control, 4); err == nil {
+		t.Fatal("expected invalid move charge to be rejected")
+	}
+}
+
+func TestMemoryUpdateOrder(t *testing.T) {
+	var (
+		limit int64 = 300
+		swap  int64 = 400
+	)
+	settings := getMemorySettings(&specs.LinuxResources{
+		Memory: &specs.LinuxMemory{Limit: &limit, Swap: &swap},
+	})
+	var names []string
+	for _, s := range swapFirst(settings) {
+		names = append(names, s.name)
+	}
+	if names[0] != "memsw.limit_in_bytes" || names[1] != "limit_in_bytes" || len(names) != len(settings) {
+		t.Fatalf("expected the memory+swap limit to be set first but received %v", names)
+	}
+
+	tmpRoot, err := ioutil.TempDir("", "memory-update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpRoot)
+	mc := NewMemory(tmpRoot)
+	if err := os.MkdirAll(path.Join(tmpRoot, "memory"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for file, value := range map[string]string{
+		"memory.limit_in_bytes":       "100",
+		"memory.memsw.limit_in_bytes": "200",
+	} {
+		if err := ioutil.WriteFile(path.Join(tmpRoot, "memory", file), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mc.Update("", &specs.LinuxResources{
+		Memory: &specs.LinuxMemory{Limit: &limit, Swap: &swap},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for file, expected := range map[string]string{
+		"memory.limit_in_bytes":       "300",
+		"memory.memsw.limit_in_bytes": "400",
+	} {
+		data, err := ioutil.ReadFile(path.Join(tmpRoot, "memory", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("expected %s to be %s but received %s", file, expected, data)
+		}
+	}
+}
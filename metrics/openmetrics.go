@@ -0,0 +1,215 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package metrics encodes the metrics collected by this module in the
+// OpenMetrics text format, which Prometheus scrapes, for tools that do not
+// want to depend on a Prometheus client.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+// ContentType is the content type of the OpenMetrics text format, to be set
+// on HTTP responses written with WriteOpenMetrics
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// WriteOpenMetrics writes the metrics of a cgroup to w in the OpenMetrics
+// text format, with labels, e.g. the container id, added to every sample.
+// Counters of microseconds are converted to seconds and limits of "max" are
+// written as +Inf. The exposition is terminated with "# EOF", so the metrics
+// of several cgroups must share a single call's labels or be merged by the
+// caller.
+func WriteOpenMetrics(w io.Writer, m *stats.Metrics, labels map[string]string) error {
+	e := &encoder{
+		w:      bufio.NewWriter(w),
+		labels: formatLabels(labels),
+	}
+	if cpu := m.CPU; cpu != nil {
+		e.counter("cgroup_cpu_usage_seconds", "seconds", "Total cpu time used", seconds(cpu.UsageUsec))
+		e.counter("cgroup_cpu_user_seconds", "seconds", "Cpu time used in user mode", seconds(cpu.UserUsec))
+		e.counter("cgroup_cpu_system_seconds", "seconds", "Cpu time used in kernel mode", seconds(cpu.SystemUsec))
+		e.counter("cgroup_cpu_periods", "", "Enforcement periods elapsed", formatUint(cpu.NrPeriods))
+		e.counter("cgroup_cpu_throttled_periods", "", "Enforcement periods the cgroup was throttled in", formatUint(cpu.NrThrottled))
+		e.counter("cgroup_cpu_throttled_seconds", "seconds", "Time the cgroup was throttled for", seconds(cpu.ThrottledUsec))
+	}
+	if mem := m.Memory; mem != nil {
+		e.gauge("cgroup_memory_usage_bytes", "bytes", "Memory used", formatUint(mem.Usage))
+		e.gauge("cgroup_memory_limit_bytes", "bytes", "Memory limit", limit(mem.UsageLimit))
+		e.gauge("cgroup_memory_swap_usage_bytes", "bytes", "Swap used", formatUint(mem.SwapUsage))
+		e.gauge("cgroup_memory_swap_limit_bytes", "bytes", "Swap limit", limit(mem.SwapLimit))
+		e.family("cgroup_memory_stat_bytes", "gauge", "bytes", "Memory used by type")
+		for _, s := range []struct {
+			typ   string
+			value uint64
+		}{
+			{"anon", mem.Anon},
+			{"file", mem.File},
+			{"kernel_stack", mem.KernelStack},
+			{"slab", mem.Slab},
+			{"sock", mem.Sock},
+			{"shmem", mem.Shmem},
+			{"file_mapped", mem.FileMapped},
+			{"file_dirty", mem.FileDirty},
+			{"file_writeback", mem.FileWriteback},
+			{"unevictable", mem.Unevictable},
+		} {
+			e.sample("cgroup_memory_stat_bytes", formatUint(s.value), "type", s.typ)
+		}
+	}
+	if events := m.MemoryEvents; events != nil {
+		e.family("cgroup_memory_events", "counter", "", "Memory events by type")
+		for _, s := range []struct {
+			event string
+			value uint64
+		}{
+			{"low", events.Low},
+			{"high", events.High},
+			{"max", events.Max},
+			{"oom", events.Oom},
+			{"oom_kill", events.OomKill},
+		} {
+			e.sample("cgroup_memory_events_total", formatUint(s.value), "event", s.event)
+		}
+	}
+	if pids := m.Pids; pids != nil {
+		e.gauge("cgroup_pids_current", "", "Number of tasks", formatUint(pids.Current))
+		e.gauge("cgroup_pids_limit", "", "Maximum number of tasks", limit(pids.Limit))
+	}
+	if blkio := m.Io; blkio != nil && len(blkio.Usage) > 0 {
+		for _, f := range []struct {
+			name, unit, help string
+			value            func(*stats.IOEntry) uint64
+		}{
+			{"cgroup_io_read_bytes", "bytes", "Bytes read", func(e *stats.IOEntry) uint64 { return e.Rbytes }},
+			{"cgroup_io_write_bytes", "bytes", "Bytes written", func(e *stats.IOEntry) uint64 { return e.Wbytes }},
+			{"cgroup_io_reads", "", "Read operations", func(e *stats.IOEntry) uint64 { return e.Rios }},
+			{"cgroup_io_writes", "", "Write operations", func(e *stats.IOEntry) uint64 { return e.Wios }},
+		} {
+			e.family(f.name, "counter", f.unit, f.help)
+			for _, entry := range blkio.Usage {
+				device := strconv.FormatUint(entry.Major, 10) + ":" + strconv.FormatUint(entry.Minor, 10)
+				e.sample(f.name+"_total", formatUint(f.value(entry)), "device", device)
+			}
+		}
+	}
+	if len(m.Hugetlb) > 0 {
+		e.family("cgroup_hugetlb_usage_bytes", "gauge", "bytes", "Hugetlb memory used by page size")
+		for _, h := range m.Hugetlb {
+			e.sample("cgroup_hugetlb_usage_bytes", formatUint(h.Current), "pagesize", h.Pagesize)
+		}
+		e.family("cgroup_hugetlb_limit_bytes", "gauge", "bytes", "Hugetlb limit by page size")
+		for _, h := range m.Hugetlb {
+			e.sample("cgroup_hugetlb_limit_bytes", limit(h.Max), "pagesize", h.Pagesize)
+		}
+	}
+	e.write("# EOF\n")
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// encoder writes metric families, keeping the first error
+type encoder struct {
+	w      *bufio.Writer
+	labels []string
+	err    error
+}
+
+func (e *encoder) write(s string) {
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+// family writes the metadata of a metric family
+func (e *encoder) family(name, typ, unit, help string) {
+	e.write("# TYPE " + name + " " + typ + "\n")
+	if unit != "" {
+		e.write("# UNIT " + name + " " + unit + "\n")
+	}
+	e.write("# HELP " + name + " " + help + ".\n")
+}
+
+// counter writes a family with a single counter sample
+func (e *encoder) counter(name, unit, help, value string) {
+	e.family(name, "counter", unit, help)
+	e.sample(name+"_total", value)
+}
+
+// gauge writes a family with a single gauge sample
+func (e *encoder) gauge(name, unit, help, value string) {
+	e.family(name, "gauge", unit, help)
+	e.sample(name, value)
+}
+
+// sample writes a sample with the encoder's labels and the extra label
+// name and value pairs
+func (e *encoder) sample(name, value string, extra ...string) {
+	labels := e.labels
+	if len(extra) > 0 {
+		labels = append([]string{}, labels...)
+		for i := 0; i+1 < len(extra); i += 2 {
+			labels = append(labels, extra[i]+"=\""+escape(extra[i+1])+"\"")
+		}
+	}
+	if len(labels) > 0 {
+		name += "{" + strings.Join(labels, ",") + "}"
+	}
+	e.write(name + " " + value + "\n")
+}
+
+// formatLabels returns the labels formatted as name="value", sorted by name
+func formatLabels(labels map[string]string) []string {
+	out := make([]string, 0, len(labels))
+	for name, value := range labels {
+		out = append(out, name+"=\""+escape(value)+"\"")
+	}
+	sort.Strings(out)
+	return out
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escape escapes a label value
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatUint(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
+
+// seconds formats microseconds as seconds
+func seconds(usec uint64) string {
+	return strconv.FormatFloat(float64(usec)/1e6, 'f', -1, 64)
+}
+
+// limit formats a limit, "max" is read as math.MaxUint64
+func limit(v uint64) string {
+	if v == math.MaxUint64 {
+		return "+Inf"
+	}
+	return formatUint(v)
+}
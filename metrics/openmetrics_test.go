@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	m := &stats.Metrics{
+		CPU: &stats.CPUStat{
+			UsageUsec: 1500000,
+		},
+		Memory: &stats.MemoryStat{
+			Usage:      4096,
+			UsageLimit: math.MaxUint64,
+		},
+		Io: &stats.IOStat{
+			Usage: []*stats.IOEntry{{Major: 8, Minor: 0, Rbytes: 512}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteOpenMetrics(&buf, m, map[string]string{
+		"id":   "test",
+		"name": "a \"b\"\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE cgroup_cpu_usage_seconds counter",
+		"# UNIT cgroup_cpu_usage_seconds seconds",
+		`cgroup_cpu_usage_seconds_total{id="test",name="a \"b\"\n"} 1.5`,
+		`cgroup_memory_usage_bytes{id="test",name="a \"b\"\n"} 4096`,
+		`cgroup_memory_limit_bytes{id="test",name="a \"b\"\n"} +Inf`,
+		`cgroup_io_read_bytes_total{id="test",name="a \"b\"\n",device="8:0"} 512`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("expected line %q in output:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "cgroup_pids") {
+		t.Error("expected no pids metrics without pids stats")
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Error("expected output to end with # EOF")
+	}
+}
@@ -0,0 +1,86 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// MoveCharge selects the memory charged to a process that moves with it
+// when the process is added to another memory cgroup
+type MoveCharge uint64
+
+const (
+	// MoveChargeAnon moves the anonymous pages and swap used by the process
+	MoveChargeAnon MoveCharge = 1 << iota
+	// MoveChargeFile moves the file pages mapped by the process
+	MoveChargeFile
+)
+
+// MoveChargeAtImmigrate returns the memory.move_charge_at_immigrate setting
+// of the cgroup
+func MoveChargeAtImmigrate(c Cgroup) (MoveCharge, error) {
+	file, err := moveChargeFile(c)
+	if err != nil {
+		return 0, err
+	}
+	v, err := readUint(file)
+	if err != nil {
+		return 0, err
+	}
+	return MoveCharge(v), nil
+}
+
+// SetMoveChargeAtImmigrate configures which memory charges move along with
+// processes added to the cgroup. By default charges stay with the cgroup the
+// memory was allocated in, so migrating a process leaves its memory
+// accounted to the source cgroup. The setting applies to the destination
+// cgroup and must be set before processes are added to it.
+func SetMoveChargeAtImmigrate(c Cgroup, charge MoveCharge) error {
+	if charge&^(MoveChargeAnon|MoveChargeFile) != 0 {
+		return errors.Errorf("cgroups: invalid move charge %d", charge)
+	}
+	file, err := moveChargeFile(c)
+	if err != nil {
+		return err
+	}
+	return retryingWriteFile(file, []byte(strconv.FormatUint(uint64(charge), 10)), defaultFilePerm)
+}
+
+func moveChargeFile(c Cgroup) (string, error) {
+	cg, ok := c.(*cgroup)
+	if !ok {
+		return "", errors.Errorf("cgroups: cannot move charges of %T", c)
+	}
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	if cg.err != nil {
+		return "", cg.err
+	}
+	s := cg.getSubsystem(Memory)
+	if s == nil {
+		return "", ErrMemoryNotSupported
+	}
+	sp, err := cg.path(Memory)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.(*memoryController).Path(sp), "memory.move_charge_at_immigrate"), nil
+}
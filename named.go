@@ -16,7 +16,16 @@
 
 package cgroups
 
-import "path/filepath"
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
 
 func NewNamed(root string, name Name) *namedController {
 	return &namedController{
@@ -37,3 +46,55 @@ func (n *namedController) Name() Name {
 func (n *namedController) Path(path string) string {
 	return filepath.Join(n.root, string(n.name), path)
 }
+
+// Named returns a hierarchy for the custom named hierarchy mounted at
+// root/name. Named hierarchies have no controllers attached and only group
+// and track processes; use MountNamed to mount one that does not exist yet.
+func Named(root string, name Name) Hierarchy {
+	return func() ([]Subsystem, error) {
+		return []Subsystem{NewNamed(root, name)}, nil
+	}
+}
+
+// MountNamed mounts the named hierarchy, like mount -t cgroup -o none,name=<name>,
+// at root/name. Nothing is done if it is already mounted there.
+func MountNamed(root string, name Name) error {
+	dir := filepath.Join(root, string(name))
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+	mounted, err := isNamedMounted(f, dir, name)
+	f.Close()
+	if err != nil || mounted {
+		return err
+	}
+	if err := os.MkdirAll(dir, defaultDirPerm); err != nil {
+		return err
+	}
+	if err := unix.Mount("cgroup", dir, "cgroup", 0, "none,name="+string(name)); err != nil {
+		return errors.Wrapf(err, "mount named hierarchy %s at %s", name, dir)
+	}
+	return nil
+}
+
+// isNamedMounted returns true if mountinfo has the named hierarchy mounted at dir
+func isNamedMounted(r io.Reader, dir string, name Name) (bool, error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Split(s.Text(), " ")
+		if len(fields) < 10 {
+			// broken mountinfo?
+			continue
+		}
+		if fields[4] != dir || fields[len(fields)-3] != "cgroup" {
+			continue
+		}
+		for _, opt := range strings.Split(fields[len(fields)-1], ",") {
+			if opt == "name="+string(name) {
+				return true, nil
+			}
+		}
+	}
+	return false, s.Err()
+}
@@ -16,7 +16,14 @@
 
 package cgroups
 
-import "testing"
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
 
 func TestNamedNameValue(t *testing.T) {
 	n := NewNamed("/sys/fs/cgroup", "systemd")
@@ -32,3 +39,50 @@ func TestNamedPath(t *testing.T) {
 		t.Fatalf("expected %q but received %q from named cgroup", expected, path)
 	}
 }
+
+func TestIsNamedMounted(t *testing.T) {
+	const mountinfo = `25 30 0:22 / /sys/fs/cgroup rw,nosuid,nodev,noexec shared:9 - tmpfs tmpfs ro,mode=755
+26 25 0:23 / /sys/fs/cgroup/systemd rw,nosuid,nodev,noexec,relatime shared:10 - cgroup cgroup rw,xattr,name=systemd
+27 25 0:24 / /sys/fs/cgroup/myagent rw,relatime shared:11 - cgroup cgroup rw,name=myagent
+`
+	for _, tc := range []struct {
+		dir      string
+		name     Name
+		expected bool
+	}{
+		{"/sys/fs/cgroup/myagent", "myagent", true},
+		{"/sys/fs/cgroup/systemd", "systemd", true},
+		{"/sys/fs/cgroup/other", "other", false},
+		{"/sys/fs/cgroup/systemd", "myagent", false},
+	} {
+		mounted, err := isNamedMounted(strings.NewReader(mountinfo), tc.dir, tc.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mounted != tc.expected {
+			t.Errorf("expected %s mounted at %s to be %v", tc.name, tc.dir, tc.expected)
+		}
+	}
+}
+
+func TestNamedHierarchy(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(Named(mock.root, "myagent"), StaticPath("/test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := control.Add(Process{Pid: 1234}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(mock.root, "myagent", "test", cgroupProcs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid := strings.TrimSpace(string(data)); pid != "1234" {
+		t.Fatalf("expected pid 1234 in the named hierarchy but received %q", pid)
+	}
+}
@@ -0,0 +1,206 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package netstat attributes network statistics to a cgroup by
+// cross-referencing the network namespaces of its processes in procfs.
+//
+// Cgroups do not account network traffic. The counters collected here are
+// those of the network namespaces the processes are in, so they describe
+// the cgroup only when it owns its namespaces, as containers usually do.
+// Processes in the host network namespace are skipped unless
+// WithHostNamespace is given, as its counters cover the whole host.
+package netstat
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const defaultProcRoot = "/proc"
+
+// Interface holds the counters of a network interface from /proc/net/dev
+type Interface struct {
+	Name      string
+	RxBytes   uint64
+	RxPackets uint64
+	RxErrors  uint64
+	RxDropped uint64
+	TxBytes   uint64
+	TxPackets uint64
+	TxErrors  uint64
+	TxDropped uint64
+}
+
+// Namespace holds the interfaces of one network namespace
+type Namespace struct {
+	// ID identifies the namespace, e.g. "net:[4026531840]"
+	ID         string
+	Interfaces []Interface
+}
+
+// Stats are the network statistics attributed to a set of processes
+type Stats struct {
+	Namespaces []Namespace
+}
+
+// Total sums the counters of all interfaces in all namespaces, excluding
+// the loopback interfaces
+func (s *Stats) Total() Interface {
+	var t Interface
+	for _, ns := range s.Namespaces {
+		for _, i := range ns.Interfaces {
+			if i.Name == "lo" {
+				continue
+			}
+			t.RxBytes += i.RxBytes
+			t.RxPackets += i.RxPackets
+			t.RxErrors += i.RxErrors
+			t.RxDropped += i.RxDropped
+			t.TxBytes += i.TxBytes
+			t.TxPackets += i.TxPackets
+			t.TxErrors += i.TxErrors
+			t.TxDropped += i.TxDropped
+		}
+	}
+	return t
+}
+
+// Opts configures Collect
+type Opts func(*config)
+
+type config struct {
+	procRoot    string
+	includeHost bool
+}
+
+// WithProcRoot reads procfs from root instead of /proc
+func WithProcRoot(root string) Opts {
+	return func(c *config) {
+		c.procRoot = root
+	}
+}
+
+// WithHostNamespace includes processes in the host network namespace,
+// the namespace of pid 1
+func WithHostNamespace() Opts {
+	return func(c *config) {
+		c.includeHost = true
+	}
+}
+
+// Collect returns the statistics of the network namespaces of pids, such as
+// the processes of a cgroup. Each namespace is read once however many of
+// the processes are in it, and processes that exit while collecting are
+// skipped.
+func Collect(pids []uint64, opts ...Opts) (*Stats, error) {
+	c := config{
+		procRoot: defaultProcRoot,
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+	var host string
+	if !c.includeHost {
+		var err error
+		if host, err = os.Readlink(filepath.Join(c.procRoot, "1", "ns", "net")); err != nil {
+			return nil, errors.Wrap(err, "failed to read the host network namespace")
+		}
+	}
+	var (
+		stats Stats
+		seen  = make(map[string]struct{})
+	)
+	for _, pid := range pids {
+		dir := filepath.Join(c.procRoot, strconv.FormatUint(pid, 10))
+		id, err := os.Readlink(filepath.Join(dir, "ns", "net"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if _, ok := seen[id]; ok || id == host {
+			continue
+		}
+		seen[id] = struct{}{}
+		data, err := ioutil.ReadFile(filepath.Join(dir, "net", "dev"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		interfaces, err := parseNetDev(data)
+		if err != nil {
+			return nil, err
+		}
+		stats.Namespaces = append(stats.Namespaces, Namespace{
+			ID:         id,
+			Interfaces: interfaces,
+		})
+	}
+	sort.Slice(stats.Namespaces, func(i, j int) bool {
+		return stats.Namespaces[i].ID < stats.Namespaces[j].ID
+	})
+	return &stats, nil
+}
+
+// parseNetDev parses the contents of /proc/net/dev
+func parseNetDev(data []byte) ([]Interface, error) {
+	var (
+		out []Interface
+		s   = bufio.NewScanner(bytes.NewReader(data))
+	)
+	for s.Scan() {
+		parts := strings.SplitN(s.Text(), ":", 2)
+		if len(parts) != 2 {
+			// the two header lines
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		if len(fields) < 16 {
+			return nil, errors.Errorf("invalid net/dev line %q", s.Text())
+		}
+		var v [16]uint64
+		for i := range v {
+			n, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid net/dev line %q", s.Text())
+			}
+			v[i] = n
+		}
+		out = append(out, Interface{
+			Name:      strings.TrimSpace(parts[0]),
+			RxBytes:   v[0],
+			RxPackets: v[1],
+			RxErrors:  v[2],
+			RxDropped: v[3],
+			TxBytes:   v[8],
+			TxPackets: v[9],
+			TxErrors:  v[10],
+			TxDropped: v[11],
+		})
+	}
+	return out, s.Err()
+}
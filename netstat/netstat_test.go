@@ -0,0 +1,91 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package netstat
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const netDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:     100       1    0    0    0     0          0         0      100       1    0    0    0     0       0          0
+  eth0:    2000      20    1    2    0     0          0         0     3000      30    3    4    0     0       0          0
+`
+
+func fakeProc(t *testing.T) string {
+	root, err := ioutil.TempDir("", "netstat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pid, ns := range map[string]string{
+		"1":  "net:[1]",
+		"10": "net:[2]",
+		"11": "net:[2]",
+		"12": "net:[1]",
+	} {
+		dir := filepath.Join(root, pid)
+		if err := os.MkdirAll(filepath.Join(dir, "ns"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(dir, "net"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(ns, filepath.Join(dir, "ns", "net")); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "net", "dev"), []byte(netDev), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCollect(t *testing.T) {
+	root := fakeProc(t)
+	defer os.RemoveAll(root)
+
+	// 99 has exited, 12 is in the host namespace
+	stats, err := Collect([]uint64{10, 11, 12, 99}, WithProcRoot(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Namespaces) != 1 || stats.Namespaces[0].ID != "net:[2]" {
+		t.Fatalf("expected only the container namespace, got %+v", stats.Namespaces)
+	}
+	total := stats.Total()
+	expected := Interface{RxBytes: 2000, RxPackets: 20, RxErrors: 1, RxDropped: 2, TxBytes: 3000, TxPackets: 30, TxErrors: 3, TxDropped: 4}
+	if total != expected {
+		t.Fatalf("expected %+v, got %+v", expected, total)
+	}
+
+	stats, err = Collect([]uint64{10, 12}, WithProcRoot(root), WithHostNamespace())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Namespaces) != 2 {
+		t.Fatalf("expected the host namespace to be included, got %+v", stats.Namespaces)
+	}
+}
+
+func TestParseNetDevInvalid(t *testing.T) {
+	if _, err := parseNetDev([]byte("eth0: 1 2 3\n")); err == nil {
+		t.Fatal("expected an error for a short line")
+	}
+}
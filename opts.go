@@ -17,6 +17,7 @@
 package cgroups
 
 import (
+	"github.com/containerd/cgroups/clock"
 	"github.com/pkg/errors"
 )
 
@@ -36,6 +37,21 @@ type InitOpts func(*InitConfig) error
 type InitConfig struct {
 	// InitCheck can be used to check initialization errors from the subsystem
 	InitCheck InitCheck
+	// UpdateExisting makes GetOrCreate apply the resources to a cgroup that
+	// already exists
+	UpdateExisting bool
+	// ClockSource timestamps the metrics returned by Stat
+	ClockSource ClockSource
+	// Pacer paces retries and polling, nil uses the system clock
+	Pacer clock.Clock
+	// AccountingOnly creates cgroups in every subsystem without writing
+	// any of the resource limits
+	AccountingOnly bool
+	// AccountingKnobs turns on the accounting of created cgroups that
+	// the kernel does not enable by default
+	AccountingKnobs bool
+	// Warnings collects what creating the cgroup skipped
+	Warnings *Warnings
 }
 
 func newInitConfig() *InitConfig {
@@ -59,3 +75,84 @@ func RequireDevices(s Subsystem, _ Path, _ error) error {
 	}
 	return ErrIgnoreSubsystem
 }
+
+// WithUpdateExisting makes GetOrCreate update an existing cgroup with the
+// provided resources instead of leaving its current settings in place
+func WithUpdateExisting() InitOpts {
+	return func(c *InitConfig) error {
+		c.UpdateExisting = true
+		return nil
+	}
+}
+
+// WithClockSource sets the clock used to timestamp the metrics returned by
+// Stat, ClockMonotonic by default
+func WithClockSource(clock ClockSource) InitOpts {
+	return func(c *InitConfig) error {
+		c.ClockSource = clock
+		return nil
+	}
+}
+
+// WithPacer sets the clock that paces the retry backoff of Delete, the
+// polling of Freeze, Thaw and ShrinkMemory and the wait for systemd units to
+// be removed, so that tests can drive them with a fake clock
+func WithPacer(c clock.Clock) InitOpts {
+	return func(config *InitConfig) error {
+		config.Pacer = c
+		return nil
+	}
+}
+
+// WithAccountingOnly creates the cgroup purely for accounting: it is created
+// in every subsystem, cpuacct included, so that usage is reported but none of
+// the resource limits are written. Limits can be applied later with Update
+// once the usage is understood.
+func WithAccountingOnly() InitOpts {
+	return func(c *InitConfig) error {
+		c.AccountingOnly = true
+		return nil
+	}
+}
+
+// WithAccountingKnobs turns on accounting that is off by default when the
+// cgroup is created: hierarchical memory accounting through
+// memory.use_hierarchy and kernel memory accounting, which older kernels
+// only enable once a kernel memory limit has been written
+func WithAccountingKnobs() InitOpts {
+	return func(c *InitConfig) error {
+		c.AccountingKnobs = true
+		return nil
+	}
+}
+
+// WithWarnings appends to w what New skipped without failing, such as the
+// subsystems that are not active and were ignored by the InitCheck, so that
+// callers can log them. GetOrCreate with WithUpdateExisting also reports the
+// resources of an existing cgroup that no subsystem of it updated. Only the
+// constructor call appends to w, the cgroup does not keep it.
+func WithWarnings(w *Warnings) InitOpts {
+	return func(c *InitConfig) error {
+		c.Warnings = w
+		return nil
+	}
+}
+
+// AddOpts configures how AddWithOpts and AddTaskWithOpts place a process
+type AddOpts func(*addConfig)
+
+type addConfig struct {
+	subsystems []Name
+}
+
+// WithSubsystems adds the process to the named subsystems of the cgroup
+// only, leaving its placement in the other subsystems unchanged, for setups
+// that intentionally split the placement of processes. Subsystems co-mounted
+// with a named one, like cpu and cpuacct, share its hierarchy and so are
+// always added to as well. Naming a subsystem the cgroup is not active in is
+// an error, and nothing is written.
+func WithSubsystems(names ...Name) AddOpts {
+	return func(c *addConfig) {
+		c.subsystems = append(c.subsystems, names...)
+	}
+}
@@ -17,8 +17,8 @@
 package cgroups
 
 import (
-	"fmt"
 	"path/filepath"
+	"strconv"
 
 	"github.com/pkg/errors"
 )
@@ -39,18 +39,43 @@ func StaticPath(path string) Path {
 // NestedPath will nest the cgroups based on the calling processes cgroup
 // placing its child processes inside its own path
 func NestedPath(suffix string) Path {
-	paths, err := parseCgroupFile("/proc/self/cgroup")
+	paths, err := ParseCgroupFile("/proc/self/cgroup")
 	if err != nil {
 		return errorPath(err)
 	}
 	return existingPath(paths, suffix)
 }
 
+// defaultProcRoot is where procfs is mounted
+const defaultProcRoot = "/proc"
+
+// ProcOpts configures how the cgroup of a process is read from procfs
+type ProcOpts func(*procConfig)
+
+type procConfig struct {
+	root string
+}
+
+// WithProcRoot reads processes from procfs mounted at root instead of /proc,
+// e.g. /host/proc for an agent running in a container with the procfs of
+// the host mounted
+func WithProcRoot(root string) ProcOpts {
+	return func(c *procConfig) {
+		c.root = root
+	}
+}
+
 // PidPath will return the correct cgroup paths for an existing process running inside a cgroup
 // This is commonly used for the Load function to restore an existing container
-func PidPath(pid int) Path {
-	p := fmt.Sprintf("/proc/%d/cgroup", pid)
-	paths, err := parseCgroupFile(p)
+func PidPath(pid int, opts ...ProcOpts) Path {
+	config := procConfig{
+		root: defaultProcRoot,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	p := filepath.Join(config.root, strconv.Itoa(pid), "cgroup")
+	paths, err := ParseCgroupFile(p)
 	if err != nil {
 		return errorPath(errors.Wrapf(err, "parse cgroup file %s", p))
 	}
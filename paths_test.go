@@ -17,6 +17,7 @@
 package cgroups
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -41,7 +42,7 @@ func TestSelfPath(t *testing.T) {
 	} else if err != nil {
 		t.Fatal(err)
 	}
-	paths, err := parseCgroupFile("/proc/self/cgroup")
+	paths, err := ParseCgroupFile("/proc/self/cgroup")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -63,7 +64,7 @@ func TestPidPath(t *testing.T) {
 	} else if err != nil {
 		t.Fatal(err)
 	}
-	paths, err := parseCgroupFile("/proc/self/cgroup")
+	paths, err := ParseCgroupFile("/proc/self/cgroup")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -78,6 +79,44 @@ func TestPidPath(t *testing.T) {
 	}
 }
 
+func TestPidPathProcRoot(t *testing.T) {
+	_, err := v1MountPoint()
+	if err == ErrMountPointNotExist {
+		t.Skip("skipping test that requires cgroup hierarchy")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	root, err := ioutil.TempDir("", "cgroups-procfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	data, err := ioutil.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "1", "cgroup"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := PidPath(os.Getpid())("devices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := PidPath(1, WithProcRoot(root))("devices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != expected {
+		t.Fatalf("expected path %q but received %q", expected, p)
+	}
+	if _, err := PidPath(2, WithProcRoot(root))("devices"); !strings.Contains(err.Error(), filepath.Join(root, "2", "cgroup")) {
+		t.Fatalf("expected an error for the missing process under the proc root but received %v", err)
+	}
+}
+
 func TestRootPath(t *testing.T) {
 	p, err := RootPath(Cpu)
 	if err != nil {
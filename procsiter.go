@@ -0,0 +1,89 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"github.com/containerd/cgroups/internal/cgfs"
+	"github.com/pkg/errors"
+)
+
+// ProcessIterator streams the processes of a cgroup from cgroup.procs
+// without reading them all into memory first, which matters for cgroups
+// with tens of thousands of processes. Iteration can be stopped at any
+// point by calling Close.
+type ProcessIterator struct {
+	subsystem Name
+	it        *cgfs.ProcsIterator
+	process   Process
+}
+
+// IterProcesses returns an iterator over the processes of the cgroup in the
+// subsystem and, when recursive is true, of all its descendants. It is the
+// streaming equivalent of Processes.
+func IterProcesses(c Cgroup, subsystem Name, recursive bool) (*ProcessIterator, error) {
+	cg, ok := c.(*cgroup)
+	if !ok {
+		return nil, errors.Errorf("cgroups: cannot iterate processes of %T", c)
+	}
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	if cg.err != nil {
+		return nil, cg.err
+	}
+	s, ok := cg.getSubsystem(subsystem).(pather)
+	if !ok {
+		return nil, ErrControllerNotActive
+	}
+	sp, err := cg.path(subsystem)
+	if err != nil {
+		return nil, err
+	}
+	return &ProcessIterator{
+		subsystem: subsystem,
+		it:        cgfs.NewProcsIterator(s.Path(sp), recursive),
+	}, nil
+}
+
+// Next advances to the next process, returning false when there are no
+// more processes or an error occurred
+func (it *ProcessIterator) Next() bool {
+	if !it.it.Next() {
+		return false
+	}
+	it.process = Process{
+		Pid:       int(it.it.Pid()),
+		Subsystem: it.subsystem,
+		Path:      it.it.Dir(),
+	}
+	return true
+}
+
+// Process returns the current process
+func (it *ProcessIterator) Process() Process {
+	return it.process
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ProcessIterator) Err() error {
+	return it.it.Err()
+}
+
+// Close stops the iteration and releases the open file, it is safe to call
+// at any time and more than once
+func (it *ProcessIterator) Close() error {
+	return it.it.Close()
+}
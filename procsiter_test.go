@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestIterProcesses(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := control.New("child", &specs.LinuxResources{}); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(mock.root, string(Freezer), "test")
+	for path, procs := range map[string]string{
+		dir:                         "1\n2\n",
+		filepath.Join(dir, "child"): "3\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(path, cgroupProcs), []byte(procs), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it, err := IterProcesses(control, Freezer, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var pids []int
+	for it.Next() {
+		p := it.Process()
+		if p.Subsystem != Freezer {
+			t.Fatalf("unexpected subsystem %s", p.Subsystem)
+		}
+		pids = append(pids, p.Pid)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []int{1, 2, 3}; !reflect.DeepEqual(pids, expected) {
+		t.Fatalf("expected %v, got %v", expected, pids)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	it, err = IterProcesses(control, Freezer, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Next() || !os.IsNotExist(it.Err()) {
+		t.Fatalf("expected not exist error, got %v", it.Err())
+	}
+}
@@ -0,0 +1,154 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// stateVersion is the hierarchy version recorded in the state of v1 cgroups
+const stateVersion = 1
+
+// CgroupState is the minimal state needed to reattach to a cgroup without
+// discovering the hierarchy again
+type CgroupState struct {
+	// Version is the cgroup hierarchy version, always 1
+	Version    int              `json:"version"`
+	Subsystems []SubsystemState `json:"subsystems"`
+}
+
+// SubsystemState records where a subsystem of a cgroup lives
+type SubsystemState struct {
+	Name Name `json:"name"`
+	// Root is the directory the subsystem's hierarchy is mounted at,
+	// e.g. /sys/fs/cgroup/cpu
+	Root string `json:"root"`
+	// Path is the path of the cgroup within the hierarchy
+	Path string `json:"path"`
+}
+
+// MarshalState returns the state of the cgroup so that a restarted process
+// can reattach to it with Restore
+func MarshalState(c Cgroup) ([]byte, error) {
+	cg, ok := c.(*cgroup)
+	if !ok {
+		return nil, errors.Errorf("cgroups: cannot marshal state of %T", c)
+	}
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	if cg.err != nil {
+		return nil, cg.err
+	}
+	state := CgroupState{
+		Version: stateVersion,
+	}
+	for _, s := range pathers(cg.subsystems) {
+		p, err := cg.path(s.Name())
+		if err != nil {
+			return nil, err
+		}
+		state.Subsystems = append(state.Subsystems, SubsystemState{
+			Name: s.Name(),
+			Root: s.Path(""),
+			Path: p,
+		})
+	}
+	return json.Marshal(state)
+}
+
+// Restore reattaches to a cgroup from the state returned by MarshalState.
+// Subsystems whose directory no longer exists are dropped and
+// ErrCgroupDeleted is returned if none are left. Options passed to the
+// subsystem constructors when the cgroup was created, such as
+// IgnoreModules, are not part of the state and are not restored.
+func Restore(data []byte) (Cgroup, error) {
+	var state CgroupState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.Wrap(err, "cgroups: invalid state")
+	}
+	if state.Version != stateVersion {
+		return nil, errors.Errorf("cgroups: unsupported state version %d", state.Version)
+	}
+	var (
+		active []Subsystem
+		paths  = make(map[Name]string)
+	)
+	for _, ss := range state.Subsystems {
+		s, err := newSubsystemAt(ss.Name, ss.Root)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Lstat(s.Path(ss.Path)); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		active = append(active, s)
+		paths[ss.Name] = ss.Path
+	}
+	if len(active) == 0 {
+		return nil, ErrCgroupDeleted
+	}
+	return &cgroup{
+		path: func(name Name) (string, error) {
+			p, ok := paths[name]
+			if !ok {
+				return "", ErrControllerNotActive
+			}
+			return p, nil
+		},
+		subsystems: active,
+	}, nil
+}
+
+// newSubsystem returns the subsystem for the name mounted under root
+func newSubsystem(name Name, root string) (pather, error) {
+	switch name {
+	case Devices:
+		return NewDevices(root), nil
+	case Hugetlb:
+		return NewHugetlb(root)
+	case Freezer:
+		return NewFreezer(root), nil
+	case Pids:
+		return NewPids(root), nil
+	case NetCLS:
+		return NewNetCls(root), nil
+	case NetPrio:
+		return NewNetPrio(root), nil
+	case PerfEvent:
+		return NewPerfEvent(root), nil
+	case Cpuset:
+		return NewCpuset(root), nil
+	case Cpu:
+		return NewCpu(root), nil
+	case Cpuacct:
+		return NewCpuacct(root), nil
+	case Memory:
+		return NewMemory(root), nil
+	case Blkio:
+		return NewBlkio(root), nil
+	case Rdma:
+		return NewRdma(root), nil
+	}
+	// any other name is a named hierarchy such as name=systemd
+	return NewNamed(root, name), nil
+}
@@ -0,0 +1,346 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package runc converts between runc's libcontainer cgroup configuration and
+// the runtime-spec resources used throughout this module, so that projects
+// using both do not need to maintain their own field mapping.
+package runc
+
+import (
+	"path/filepath"
+
+	"github.com/containerd/cgroups"
+	v2 "github.com/containerd/cgroups/v2"
+	"github.com/opencontainers/runc/libcontainer/configs"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// Path returns the cgroups.Path for the libcontainer cgroup. Paths takes
+// precedence over Path, with the deprecated Parent and Name used last.
+func Path(c *configs.Cgroup) cgroups.Path {
+	if len(c.Paths) > 0 {
+		paths := c.Paths
+		return func(name cgroups.Name) (string, error) {
+			p, ok := paths[string(name)]
+			if !ok {
+				return "", cgroups.ErrControllerNotActive
+			}
+			return p, nil
+		}
+	}
+	if c.Path != "" {
+		return cgroups.StaticPath(c.Path)
+	}
+	return cgroups.StaticPath(filepath.Join("/", c.Parent, c.Name))
+}
+
+// FromCgroup returns the path and resources of the libcontainer cgroup
+func FromCgroup(c *configs.Cgroup) (cgroups.Path, *specs.LinuxResources) {
+	return Path(c), ToSpec(c.Resources)
+}
+
+// ToCgroup returns a libcontainer cgroup for the path and resources
+func ToCgroup(path string, resources *specs.LinuxResources) *configs.Cgroup {
+	return &configs.Cgroup{
+		Path:      path,
+		Resources: FromSpec(resources),
+	}
+}
+
+// ToSpec converts libcontainer resources into runtime-spec resources.
+// libcontainer uses zero values for unset fields, these are left nil in the
+// returned resources.
+func ToSpec(r *configs.Resources) *specs.LinuxResources {
+	var s specs.LinuxResources
+	if r == nil {
+		return &s
+	}
+	for _, d := range r.Devices {
+		s.Devices = append(s.Devices, toSpecDevice(d))
+	}
+
+	memory := &specs.LinuxMemory{
+		Limit:            int64Ptr(r.Memory),
+		Reservation:      int64Ptr(r.MemoryReservation),
+		Swap:             int64Ptr(r.MemorySwap),
+		Kernel:           int64Ptr(r.KernelMemory),
+		KernelTCP:        int64Ptr(r.KernelMemoryTCP),
+		Swappiness:       r.MemorySwappiness,
+		DisableOOMKiller: boolPtr(r.OomKillDisable),
+	}
+	if *memory != (specs.LinuxMemory{}) {
+		s.Memory = memory
+	}
+
+	cpu := &specs.LinuxCPU{
+		Shares:          uint64Ptr(r.CpuShares),
+		Quota:           int64Ptr(r.CpuQuota),
+		Period:          uint64Ptr(r.CpuPeriod),
+		RealtimeRuntime: int64Ptr(r.CpuRtRuntime),
+		RealtimePeriod:  uint64Ptr(r.CpuRtPeriod),
+		Cpus:            r.CpusetCpus,
+		Mems:            r.CpusetMems,
+	}
+	if *cpu != (specs.LinuxCPU{}) {
+		s.CPU = cpu
+	}
+
+	if r.PidsLimit != 0 {
+		s.Pids = &specs.LinuxPids{
+			Limit: r.PidsLimit,
+		}
+	}
+
+	blkio := &specs.LinuxBlockIO{
+		Weight:     uint16Ptr(r.BlkioWeight),
+		LeafWeight: uint16Ptr(r.BlkioLeafWeight),
+	}
+	for _, d := range r.BlkioWeightDevice {
+		wd := specs.LinuxWeightDevice{
+			Weight:     uint16Ptr(d.Weight),
+			LeafWeight: uint16Ptr(d.LeafWeight),
+		}
+		wd.Major, wd.Minor = d.Major, d.Minor
+		blkio.WeightDevice = append(blkio.WeightDevice, wd)
+	}
+	blkio.ThrottleReadBpsDevice = toSpecThrottle(r.BlkioThrottleReadBpsDevice)
+	blkio.ThrottleWriteBpsDevice = toSpecThrottle(r.BlkioThrottleWriteBpsDevice)
+	blkio.ThrottleReadIOPSDevice = toSpecThrottle(r.BlkioThrottleReadIOPSDevice)
+	blkio.ThrottleWriteIOPSDevice = toSpecThrottle(r.BlkioThrottleWriteIOPSDevice)
+	if blkio.Weight != nil || blkio.LeafWeight != nil || len(blkio.WeightDevice) > 0 ||
+		len(blkio.ThrottleReadBpsDevice) > 0 || len(blkio.ThrottleWriteBpsDevice) > 0 ||
+		len(blkio.ThrottleReadIOPSDevice) > 0 || len(blkio.ThrottleWriteIOPSDevice) > 0 {
+		s.BlockIO = blkio
+	}
+
+	for _, h := range r.HugetlbLimit {
+		s.HugepageLimits = append(s.HugepageLimits, specs.LinuxHugepageLimit{
+			Pagesize: h.Pagesize,
+			Limit:    h.Limit,
+		})
+	}
+
+	if r.NetClsClassid != 0 || len(r.NetPrioIfpriomap) > 0 {
+		s.Network = &specs.LinuxNetwork{
+			ClassID: uint32Ptr(r.NetClsClassid),
+		}
+		for _, p := range r.NetPrioIfpriomap {
+			s.Network.Priorities = append(s.Network.Priorities, specs.LinuxInterfacePriority{
+				Name:     p.Interface,
+				Priority: uint32(p.Priority),
+			})
+		}
+	}
+	return &s
+}
+
+// FromSpec converts runtime-spec resources into libcontainer resources
+func FromSpec(s *specs.LinuxResources) *configs.Resources {
+	var r configs.Resources
+	if s == nil {
+		return &r
+	}
+	for _, d := range s.Devices {
+		r.Devices = append(r.Devices, fromSpecDevice(d))
+	}
+	if m := s.Memory; m != nil {
+		r.Memory = int64Value(m.Limit)
+		r.MemoryReservation = int64Value(m.Reservation)
+		r.MemorySwap = int64Value(m.Swap)
+		r.KernelMemory = int64Value(m.Kernel)
+		r.KernelMemoryTCP = int64Value(m.KernelTCP)
+		r.MemorySwappiness = m.Swappiness
+		if m.DisableOOMKiller != nil {
+			r.OomKillDisable = *m.DisableOOMKiller
+		}
+	}
+	if c := s.CPU; c != nil {
+		r.CpuShares = uint64Value(c.Shares)
+		r.CpuQuota = int64Value(c.Quota)
+		r.CpuPeriod = uint64Value(c.Period)
+		r.CpuRtRuntime = int64Value(c.RealtimeRuntime)
+		r.CpuRtPeriod = uint64Value(c.RealtimePeriod)
+		r.CpusetCpus = c.Cpus
+		r.CpusetMems = c.Mems
+	}
+	if p := s.Pids; p != nil {
+		r.PidsLimit = p.Limit
+	}
+	if b := s.BlockIO; b != nil {
+		r.BlkioWeight = uint16Value(b.Weight)
+		r.BlkioLeafWeight = uint16Value(b.LeafWeight)
+		for _, d := range b.WeightDevice {
+			r.BlkioWeightDevice = append(r.BlkioWeightDevice,
+				configs.NewWeightDevice(d.Major, d.Minor, uint16Value(d.Weight), uint16Value(d.LeafWeight)))
+		}
+		r.BlkioThrottleReadBpsDevice = fromSpecThrottle(b.ThrottleReadBpsDevice)
+		r.BlkioThrottleWriteBpsDevice = fromSpecThrottle(b.ThrottleWriteBpsDevice)
+		r.BlkioThrottleReadIOPSDevice = fromSpecThrottle(b.ThrottleReadIOPSDevice)
+		r.BlkioThrottleWriteIOPSDevice = fromSpecThrottle(b.ThrottleWriteIOPSDevice)
+	}
+	for _, h := range s.HugepageLimits {
+		r.HugetlbLimit = append(r.HugetlbLimit, &configs.HugepageLimit{
+			Pagesize: h.Pagesize,
+			Limit:    h.Limit,
+		})
+	}
+	if n := s.Network; n != nil {
+		r.NetClsClassid = uint32Value(n.ClassID)
+		for _, p := range n.Priorities {
+			r.NetPrioIfpriomap = append(r.NetPrioIfpriomap, &configs.IfPrioMap{
+				Interface: p.Name,
+				Priority:  int64(p.Priority),
+			})
+		}
+	}
+	return &r
+}
+
+// ToV2 converts libcontainer resources into v2 resources, including the v2
+// only cpu weight
+func ToV2(r *configs.Resources) *v2.Resources {
+	resources := v2.ToResources(ToSpec(r))
+	if r != nil && r.CpuWeight != 0 {
+		if resources.CPU == nil {
+			resources.CPU = &v2.CPU{}
+		}
+		weight := r.CpuWeight
+		resources.CPU.Weight = &weight
+	}
+	return resources
+}
+
+func toSpecDevice(d *configs.DeviceRule) specs.LinuxDeviceCgroup {
+	s := specs.LinuxDeviceCgroup{
+		Allow:  d.Allow,
+		Type:   string(d.Type),
+		Access: string(d.Permissions),
+	}
+	if d.Type == configs.WildcardDevice {
+		s.Type = "a"
+		return s
+	}
+	if major := d.Major; major != configs.Wildcard {
+		s.Major = &major
+	}
+	if minor := d.Minor; minor != configs.Wildcard {
+		s.Minor = &minor
+	}
+	return s
+}
+
+func fromSpecDevice(s specs.LinuxDeviceCgroup) *configs.DeviceRule {
+	d := &configs.DeviceRule{
+		Type:        configs.WildcardDevice,
+		Major:       configs.Wildcard,
+		Minor:       configs.Wildcard,
+		Permissions: configs.DevicePermissions(s.Access),
+		Allow:       s.Allow,
+	}
+	if s.Type != "" {
+		d.Type = configs.DeviceType(s.Type[0])
+	}
+	if s.Major != nil {
+		d.Major = *s.Major
+	}
+	if s.Minor != nil {
+		d.Minor = *s.Minor
+	}
+	return d
+}
+
+func toSpecThrottle(devices []*configs.ThrottleDevice) []specs.LinuxThrottleDevice {
+	var out []specs.LinuxThrottleDevice
+	for _, d := range devices {
+		td := specs.LinuxThrottleDevice{
+			Rate: d.Rate,
+		}
+		td.Major, td.Minor = d.Major, d.Minor
+		out = append(out, td)
+	}
+	return out
+}
+
+func fromSpecThrottle(devices []specs.LinuxThrottleDevice) []*configs.ThrottleDevice {
+	var out []*configs.ThrottleDevice
+	for _, d := range devices {
+		out = append(out, configs.NewThrottleDevice(d.Major, d.Minor, d.Rate))
+	}
+	return out
+}
+
+func int64Ptr(v int64) *int64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func uint64Ptr(v uint64) *uint64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func uint16Ptr(v uint16) *uint16 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	if !v {
+		return nil
+	}
+	return &v
+}
+
+func int64Value(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func uint64Value(v *uint64) uint64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func uint32Value(v *uint32) uint32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func uint16Value(v *uint16) uint16 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,84 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package runc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/containerd/cgroups"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestResourcesRoundTrip(t *testing.T) {
+	swappiness := uint64(10)
+	r := &configs.Resources{
+		Devices: []*configs.DeviceRule{
+			{Type: configs.WildcardDevice, Major: configs.Wildcard, Minor: configs.Wildcard, Permissions: "rwm"},
+			{Type: configs.CharDevice, Major: 1, Minor: 3, Permissions: "rwm", Allow: true},
+			{Type: configs.CharDevice, Major: 136, Minor: configs.Wildcard, Permissions: "rw", Allow: true},
+		},
+		Memory:                     1 << 30,
+		MemoryReservation:          1 << 29,
+		MemorySwap:                 -1,
+		MemorySwappiness:           &swappiness,
+		OomKillDisable:             true,
+		CpuShares:                  512,
+		CpuQuota:                   50000,
+		CpuPeriod:                  100000,
+		CpusetCpus:                 "0-3",
+		CpusetMems:                 "0",
+		PidsLimit:                  100,
+		BlkioWeight:                500,
+		BlkioWeightDevice:          []*configs.WeightDevice{configs.NewWeightDevice(8, 0, 300, 0)},
+		BlkioThrottleReadBpsDevice: []*configs.ThrottleDevice{configs.NewThrottleDevice(8, 0, 1<<20)},
+		HugetlbLimit:               []*configs.HugepageLimit{{Pagesize: "2MB", Limit: 1 << 21}},
+		NetClsClassid:              0x100001,
+		NetPrioIfpriomap:           []*configs.IfPrioMap{{Interface: "eth0", Priority: 5}},
+	}
+	s := ToSpec(r)
+	if *s.Memory.Limit != r.Memory || *s.CPU.Quota != r.CpuQuota || s.Pids.Limit != r.PidsLimit {
+		t.Fatalf("unexpected spec resources %+v", s)
+	}
+	if s.Memory.Kernel != nil || s.CPU.RealtimeRuntime != nil {
+		t.Fatal("unset libcontainer fields must be nil in the spec")
+	}
+	if out := FromSpec(s); !reflect.DeepEqual(out, r) {
+		t.Fatalf("round trip mismatch:\nexpected %+v\nreceived %+v", r, out)
+	}
+}
+
+func TestToV2CPUWeight(t *testing.T) {
+	resources := ToV2(&configs.Resources{CpuWeight: 200})
+	if resources.CPU == nil || resources.CPU.Weight == nil || *resources.CPU.Weight != 200 {
+		t.Fatalf("expected cpu weight of 200 but received %+v", resources.CPU)
+	}
+}
+
+func TestPath(t *testing.T) {
+	p := Path(&configs.Cgroup{Paths: map[string]string{"memory": "/a"}})
+	if path, err := p(cgroups.Memory); err != nil || path != "/a" {
+		t.Fatalf("expected /a but received %q: %v", path, err)
+	}
+	if _, err := p(cgroups.Cpu); err != cgroups.ErrControllerNotActive {
+		t.Fatalf("expected ErrControllerNotActive but received %v", err)
+	}
+	p = Path(&configs.Cgroup{Parent: "system.slice", Name: "test"})
+	if path, _ := p(cgroups.Memory); path != "/system.slice/test" {
+		t.Fatalf("expected /system.slice/test but received %q", path)
+	}
+}
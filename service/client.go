@@ -0,0 +1,136 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"google.golang.org/grpc"
+)
+
+// Client manages cgroups through a broker listening on a unix socket
+type Client struct {
+	conn    *grpc.ClientConn
+	cgroups CgroupsClient
+}
+
+// NewClient connects to the broker listening on the unix socket at address
+func NewClient(ctx context.Context, address string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", addr)
+		}),
+	}, opts...)
+	conn, err := grpc.DialContext(ctx, address, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromConn(conn), nil
+}
+
+// NewClientFromConn returns a new Client using an existing grpc connection
+func NewClientFromConn(conn *grpc.ClientConn) *Client {
+	return &Client{
+		conn:    conn,
+		cgroups: NewCgroupsClient(conn),
+	}
+}
+
+// Create creates a new group at path with the provided resources
+func (c *Client) Create(ctx context.Context, path string, resources *specs.LinuxResources) error {
+	data, err := marshalResources(resources)
+	if err != nil {
+		return err
+	}
+	_, err = c.cgroups.Create(ctx, &CreateRequest{
+		Path:      path,
+		Resources: data,
+	})
+	return err
+}
+
+// Update updates the resources of the group at path
+func (c *Client) Update(ctx context.Context, path string, resources *specs.LinuxResources) error {
+	data, err := marshalResources(resources)
+	if err != nil {
+		return err
+	}
+	_, err = c.cgroups.Update(ctx, &UpdateRequest{
+		Path:      path,
+		Resources: data,
+	})
+	return err
+}
+
+// Stat returns the metrics for the group at path. Only one of the v1 or v2
+// metrics is set, depending on the broker's host
+func (c *Client) Stat(ctx context.Context, path string) (*StatResponse, error) {
+	return c.cgroups.Stat(ctx, &StatRequest{
+		Path: path,
+	})
+}
+
+// Delete removes the group at path
+func (c *Client) Delete(ctx context.Context, path string) error {
+	_, err := c.cgroups.Delete(ctx, &DeleteRequest{
+		Path: path,
+	})
+	return err
+}
+
+// Events returns a stream of memory events for the group at path. The
+// stream ends when ctx is canceled
+func (c *Client) Events(ctx context.Context, path string) (Cgroups_EventsClient, error) {
+	return c.cgroups.Events(ctx, &EventsRequest{
+		Path: path,
+	})
+}
+
+// Subscribe returns a stream of samples for the groups at paths, taken at the
+// provided interval. The broker's default interval is used when it is zero
+func (c *Client) Subscribe(ctx context.Context, interval time.Duration, paths ...string) (Cgroups_SubscribeClient, error) {
+	return c.cgroups.Subscribe(ctx, &SubscribeRequest{
+		Paths:    paths,
+		Interval: interval,
+	})
+}
+
+// SubscribeTo returns a stream of samples for the groups of subscriptions,
+// each taken at its own interval and limited to its controllers
+func (c *Client) SubscribeTo(ctx context.Context, subscriptions ...*Subscription) (Cgroups_SubscribeClient, error) {
+	return c.cgroups.Subscribe(ctx, &SubscribeRequest{
+		Subscriptions: subscriptions,
+	})
+}
+
+// Close closes the connection to the broker
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func marshalResources(resources *specs.LinuxResources) ([]byte, error) {
+	if resources == nil {
+		return nil, nil
+	}
+	return json.Marshal(resources)
+}
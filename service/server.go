@@ -0,0 +1,325 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containerd/cgroups"
+	"github.com/containerd/cgroups/clock"
+	v2 "github.com/containerd/cgroups/v2"
+	ptypes "github.com/gogo/protobuf/types"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultMountpoint = "/sys/fs/cgroup"
+
+var empty = &ptypes.Empty{}
+
+// ServerOpt configures a Server
+type ServerOpt func(*Server)
+
+// WithRoot scopes all requests to the groups under root
+func WithRoot(root string) ServerOpt {
+	return func(s *Server) {
+		s.root = root
+	}
+}
+
+// WithMountpoint sets the unified mountpoint used on cgroups v2 hosts
+func WithMountpoint(mountpoint string) ServerOpt {
+	return func(s *Server) {
+		s.mountpoint = mountpoint
+	}
+}
+
+// WithMode overrides the detected cgroups mode of the host
+func WithMode(mode cgroups.CGMode) ServerOpt {
+	return func(s *Server) {
+		s.mode = mode
+	}
+}
+
+// WithClock sets the clock pacing and timestamping the samples of
+// subscriptions
+func WithClock(c clock.Clock) ServerOpt {
+	return func(s *Server) {
+		s.clock = clock.Or(c)
+	}
+}
+
+// WithSubscribeLimits sets the maximum number of paths of a subscription and
+// the minimum interval between its samples, bounding the reads a single
+// subscriber can cause
+func WithSubscribeLimits(maxPaths int, minInterval time.Duration) ServerOpt {
+	return func(s *Server) {
+		s.maxPaths = maxPaths
+		s.minInterval = minInterval
+	}
+}
+
+// Server implements the CgroupsServer, acting as a privileged broker for
+// clients that cannot write to the cgroup filesystem themselves
+type Server struct {
+	root       string
+	mountpoint string
+	mode       cgroups.CGMode
+	clock      clock.Clock
+	// maxPaths and minInterval limit subscriptions
+	maxPaths    int
+	minInterval time.Duration
+	// events shares the watches of memory events among the streams
+	events *v2.EventMux
+}
+
+// NewServer returns a new Server for the host's cgroups mode
+func NewServer(opts ...ServerOpt) *Server {
+	s := &Server{
+		root:        "/",
+		mountpoint:  defaultMountpoint,
+		mode:        cgroups.Mode(),
+		clock:       clock.Real,
+		maxPaths:    maxPaths,
+		minInterval: minInterval,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	s.events = v2.NewEventMux(v2.WithMuxClock(s.clock))
+	return s
+}
+
+// Register registers the server's service with the grpc server
+func (s *Server) Register(server *grpc.Server) {
+	RegisterCgroupsServer(server, s)
+}
+
+func (s *Server) Create(ctx context.Context, r *CreateRequest) (*ptypes.Empty, error) {
+	group, err := s.group(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := unmarshalResources(r.Resources)
+	if err != nil {
+		return nil, err
+	}
+	switch s.mode {
+	case cgroups.Unified:
+		m, err := v2.NewManager(s.mountpoint, group, v2.ToResources(resources))
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		m.Close()
+	case cgroups.Legacy, cgroups.Hybrid:
+		if _, err := cgroups.New(cgroups.V1, cgroups.StaticPath(group), resources); err != nil {
+			return nil, toStatus(err)
+		}
+	default:
+		return nil, status.Error(codes.Unavailable, "cgroups are not available on this host")
+	}
+	return empty, nil
+}
+
+func (s *Server) Update(ctx context.Context, r *UpdateRequest) (*ptypes.Empty, error) {
+	group, err := s.group(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := unmarshalResources(r.Resources)
+	if err != nil {
+		return nil, err
+	}
+	switch s.mode {
+	case cgroups.Unified:
+		m, err := s.loadUnified(group)
+		if err != nil {
+			return nil, err
+		}
+		defer m.Close()
+		if err := m.Update(v2.ToResources(resources)); err != nil {
+			return nil, toStatus(err)
+		}
+	default:
+		cg, err := s.loadLegacy(group)
+		if err != nil {
+			return nil, err
+		}
+		if err := cg.Update(resources); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+	return empty, nil
+}
+
+func (s *Server) Stat(ctx context.Context, r *StatRequest) (*StatResponse, error) {
+	group, err := s.group(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch s.mode {
+	case cgroups.Unified:
+		m, err := s.loadUnified(group)
+		if err != nil {
+			return nil, err
+		}
+		defer m.Close()
+		metrics, err := m.Stat()
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return &StatResponse{V2: metrics}, nil
+	default:
+		cg, err := s.loadLegacy(group)
+		if err != nil {
+			return nil, err
+		}
+		metrics, err := cg.Stat(cgroups.IgnoreNotExist)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return &StatResponse{V1: metrics}, nil
+	}
+}
+
+func (s *Server) Delete(ctx context.Context, r *DeleteRequest) (*ptypes.Empty, error) {
+	group, err := s.group(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch s.mode {
+	case cgroups.Unified:
+		m, err := s.loadUnified(group)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.Delete(); err != nil {
+			return nil, toStatus(err)
+		}
+	default:
+		cg, err := s.loadLegacy(group)
+		if err != nil {
+			return nil, err
+		}
+		if err := cg.Delete(); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+	return empty, nil
+}
+
+func (s *Server) Events(r *EventsRequest, stream Cgroups_EventsServer) error {
+	group, err := s.group(r.Path)
+	if err != nil {
+		return err
+	}
+	if s.mode != cgroups.Unified {
+		return status.Error(codes.Unimplemented, "events are only supported on cgroups v2 hosts")
+	}
+	m, err := s.loadUnified(group)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	// the watch ends with the stream's context, or with an error when the
+	// group is removed
+	ctx := stream.Context()
+	events, errCh := s.events.Subscribe(ctx, m)
+	for e := range events {
+		if err := stream.Send(&Event{
+			Low:     e.Low,
+			High:    e.High,
+			Max:     e.Max,
+			OOM:     e.OOM,
+			OOMKill: e.OOMKill,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := <-errCh; err != nil {
+		return toStatus(err)
+	}
+	return ctx.Err()
+}
+
+// group returns the cgroup path for the request's path, scoped to the
+// server's root so that clients cannot escape it with ".." elements
+func (s *Server) group(path string) (string, error) {
+	clean := filepath.Clean("/" + path)
+	if clean == "/" {
+		return "", status.Error(codes.InvalidArgument, "path must name a group under the root")
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+func (s *Server) loadUnified(group string, opts ...v2.InitOpts) (*v2.Manager, error) {
+	if _, err := os.Stat(filepath.Join(s.mountpoint, group)); err != nil {
+		return nil, toStatus(err)
+	}
+	m, err := v2.LoadManager(s.mountpoint, group, opts...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return m, nil
+}
+
+func (s *Server) loadLegacy(group string) (cgroups.Cgroup, error) {
+	cg, err := cgroups.Load(cgroups.V1, cgroups.StaticPath(group))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return cg, nil
+}
+
+func unmarshalResources(data []byte) (*specs.LinuxResources, error) {
+	var resources specs.LinuxResources
+	if len(data) == 0 {
+		return &resources, nil
+	}
+	if err := json.Unmarshal(data, &resources); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid resources: %v", err)
+	}
+	return &resources, nil
+}
+
+// toStatus converts the package errors into grpc status errors so that
+// clients can act on the error codes
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	cause := errors.Cause(err)
+	switch {
+	case os.IsNotExist(cause), cause == cgroups.ErrCgroupDeleted, cause == v2.ErrCgroupDeleted:
+		return status.Error(codes.NotFound, err.Error())
+	case os.IsExist(cause):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case os.IsPermission(cause):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case cause == cgroups.ErrHugePageSizeNotSupported, cause == v2.ErrHugePageSizeNotSupported,
+		cause == v2.ErrInvalidGroupPath:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
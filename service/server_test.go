@@ -0,0 +1,341 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/containerd/cgroups"
+	"github.com/containerd/cgroups/clock"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestClient(t *testing.T, mountpoint string, opts ...ServerOpt) (*Client, func()) {
+	dir, err := ioutil.TempDir("", "cgroupd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := filepath.Join(dir, "cgroupd.sock")
+	l, err := net.Listen("unix", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	NewServer(append([]ServerOpt{
+		WithRoot("/brokered"),
+		WithMountpoint(mountpoint),
+		WithMode(cgroups.Unified),
+	}, opts...)...).Register(server)
+	go server.Serve(l)
+
+	client, err := NewClient(context.Background(), address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, func() {
+		client.Close()
+		server.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestServerUnified(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroupd-mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	// the manager enables controllers in all parents of the group
+	for _, dir := range []string{mountpoint, filepath.Join(mountpoint, "brokered")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client, cleanup := newTestClient(t, mountpoint)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := client.Create(ctx, "test", &specs.LinuxResources{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(mountpoint, "brokered", "test")); err != nil {
+		t.Fatalf("group not created under the root: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, "brokered", "test", "cgroup.controllers"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Stat(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.V2 == nil || resp.V1 != nil {
+		t.Fatalf("expected only v2 metrics but received %+v", resp)
+	}
+	if err := client.Delete(ctx, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Stat(ctx, "test"); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for deleted group but received %v", err)
+	}
+}
+
+func TestServerScopesPaths(t *testing.T) {
+	s := NewServer(WithRoot("/brokered"))
+	for path, expected := range map[string]string{
+		"test":          "/brokered/test",
+		"/test/child":   "/brokered/test/child",
+		"../../escape":  "/brokered/escape",
+		"a/../../../b/": "/brokered/b",
+	} {
+		group, err := s.group(path)
+		if err != nil {
+			t.Fatalf("%q: %v", path, err)
+		}
+		if group != expected {
+			t.Errorf("%q: expected %q but received %q", path, expected, group)
+		}
+	}
+	for _, path := range []string{"", "/", ".."} {
+		if _, err := s.group(path); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%q: expected InvalidArgument but received %v", path, err)
+		}
+	}
+}
+
+func TestServerSubscribe(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroupd-mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	for _, group := range []string{"a", "b"} {
+		dir := filepath.Join(mountpoint, "brokered", group)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, value := range map[string]string{
+			"cgroup.controllers": "pids",
+			"pids.current":       "3",
+		} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	client, cleanup := newTestClient(t, mountpoint, WithSubscribeLimits(2, minInterval))
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.Subscribe(ctx, minInterval, "a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		sample, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sample.V2 == nil || sample.V2.Pids == nil || sample.V2.Pids.Current != 3 {
+			t.Fatalf("unexpected sample for %q: %+v", sample.Path, sample)
+		}
+		if sample.Timestamp.IsZero() {
+			t.Fatalf("sample for %q has no timestamp", sample.Path)
+		}
+		seen[sample.Path]++
+	}
+	if seen["a"] != 2 || seen["b"] != 2 {
+		t.Fatalf("expected two samples for each group but received %v", seen)
+	}
+
+	stream, err = client.Subscribe(ctx, time.Millisecond, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a short interval but received %v", err)
+	}
+
+	stream, err = client.Subscribe(ctx, minInterval, "a", "b", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for too many paths but received %v", err)
+	}
+}
+
+func TestServerSubscribeTo(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroupd-mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	for _, group := range []string{"a", "b"} {
+		dir := filepath.Join(mountpoint, "brokered", group)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, value := range map[string]string{
+			"cgroup.controllers": "memory pids",
+			"memory.current":     "4096",
+			"pids.current":       "3",
+		} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	fake := clock.NewFake(time.Now())
+	client, cleanup := newTestClient(t, mountpoint, WithClock(fake), WithSubscribeLimits(2, time.Second))
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.SubscribeTo(ctx,
+		&Subscription{Path: "a", Interval: time.Second, Controllers: []string{"pids"}},
+		&Subscription{Path: "b", Interval: 3 * time.Second},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recv := func() *Sample {
+		sample, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch sample.Path {
+		case "a":
+			if sample.V2.Pids == nil || sample.V2.Memory != nil {
+				t.Fatalf("expected only pids metrics for %q but received %+v", sample.Path, sample.V2)
+			}
+		case "b":
+			if sample.V2.Pids == nil || sample.V2.Memory == nil || sample.V2.Memory.Usage != 4096 {
+				t.Fatalf("expected all metrics for %q but received %+v", sample.Path, sample.V2)
+			}
+		}
+		return sample
+	}
+	seen := make(map[string]int)
+	for i := 0; i < 2; i++ {
+		seen[recv().Path]++
+	}
+	// each group is sampled on its own ticker, b only every third tick of a
+	for i := 1; i <= 3; i++ {
+		fake.BlockUntil(2)
+		fake.Advance(time.Second)
+		seen[recv().Path]++
+		if i == 3 {
+			seen[recv().Path]++
+		}
+	}
+	if seen["a"] != 4 || seen["b"] != 2 {
+		t.Fatalf("expected four samples of a and two of b but received %v", seen)
+	}
+
+	for _, sub := range []*Subscription{
+		{Path: "a", Interval: time.Millisecond},
+		{Path: "a", Controllers: []string{"blkio"}},
+	} {
+		stream, err = client.SubscribeTo(ctx, sub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("expected InvalidArgument for %+v but received %v", sub, err)
+		}
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroupd-mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	dir := filepath.Join(mountpoint, "brokered", "a")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	events := filepath.Join(dir, "memory.events")
+	if err := ioutil.WriteFile(events, []byte("oom 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	client, cleanup := newTestClient(t, mountpoint)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := client.Events(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the watch is set up once the stream is, retry until it sees a change;
+	// a read racing the truncating write may report a zero count first
+	received := make(chan *Event)
+	go func() {
+		defer close(received)
+		for {
+			e, err := stream.Recv()
+			if err != nil || e.OOM != 0 {
+				received <- e
+				return
+			}
+		}
+	}()
+	for i := uint64(1); ; i++ {
+		if err := ioutil.WriteFile(events, []byte("oom "+strconv.FormatUint(i, 10)+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case e := <-received:
+			if e == nil {
+				t.Fatal("expected an oom event before the stream ended")
+			}
+		case <-time.After(50 * time.Millisecond):
+			continue
+		}
+		break
+	}
+
+	// removing the group ends the stream
+	if err := os.Remove(events); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound once the group is removed but received %v", err)
+	}
+}
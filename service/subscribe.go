@@ -0,0 +1,263 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/containerd/cgroups"
+	"github.com/containerd/cgroups/clock"
+	v1stats "github.com/containerd/cgroups/stats/v1"
+	v2 "github.com/containerd/cgroups/v2"
+	v2stats "github.com/containerd/cgroups/v2/stats"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	// minInterval and maxPaths are the default limits of a subscription
+	minInterval = 100 * time.Millisecond
+	maxPaths    = 64
+)
+
+// sampler reads the metrics of a single group for a subscription
+type sampler struct {
+	path     string
+	interval time.Duration
+	stat     func() (*Sample, error)
+	close    func()
+}
+
+// controllers are the names a subscription can filter the metrics of a
+// sample by for each mode
+var (
+	unifiedControllers = map[string]func(*v2stats.Metrics){
+		"cpu":     func(m *v2stats.Metrics) { m.CPU = nil },
+		"memory":  func(m *v2stats.Metrics) { m.Memory, m.MemoryEvents = nil, nil },
+		"pids":    func(m *v2stats.Metrics) { m.Pids = nil },
+		"rdma":    func(m *v2stats.Metrics) { m.Rdma = nil },
+		"io":      func(m *v2stats.Metrics) { m.Io = nil },
+		"hugetlb": func(m *v2stats.Metrics) { m.Hugetlb = nil },
+	}
+	legacyControllers = map[string]func(*v1stats.Metrics){
+		"cpu":     func(m *v1stats.Metrics) { m.CPU = nil },
+		"memory":  func(m *v1stats.Metrics) { m.Memory = nil },
+		"pids":    func(m *v1stats.Metrics) { m.Pids = nil },
+		"rdma":    func(m *v1stats.Metrics) { m.Rdma = nil },
+		"blkio":   func(m *v1stats.Metrics) { m.Blkio = nil },
+		"hugetlb": func(m *v1stats.Metrics) { m.Hugetlb = nil },
+	}
+)
+
+// Subscribe streams samples of every path and subscription of the request.
+// Each group is sampled on its own ticker so that groups subscribed at
+// different intervals do not hold each other back
+func (s *Server) Subscribe(r *SubscribeRequest, stream Cgroups_SubscribeServer) error {
+	subscriptions := make([]*Subscription, 0, len(r.Paths)+len(r.Subscriptions))
+	for _, path := range r.Paths {
+		subscriptions = append(subscriptions, &Subscription{Path: path})
+	}
+	subscriptions = append(subscriptions, r.Subscriptions...)
+	switch {
+	case len(subscriptions) == 0:
+		return status.Error(codes.InvalidArgument, "at least one path is required")
+	case len(subscriptions) > s.maxPaths:
+		return status.Errorf(codes.InvalidArgument, "at most %d paths can be subscribed to", s.maxPaths)
+	}
+	samplers := make([]*sampler, 0, len(subscriptions))
+	defer func() {
+		for _, sm := range samplers {
+			sm.close()
+		}
+	}()
+	for _, sub := range subscriptions {
+		interval, err := s.interval(sub.Interval, r.Interval)
+		if err != nil {
+			return err
+		}
+		sm, err := s.newSampler(sub.Path, sub.Controllers)
+		if err != nil {
+			return err
+		}
+		sm.interval = interval
+		samplers = append(samplers, sm)
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = make(chan error, len(samplers))
+	)
+	for _, sm := range samplers {
+		wg.Add(1)
+		go func(sm *sampler) {
+			defer wg.Done()
+			ticker := clock.NewTicker(s.clock, sm.interval)
+			for {
+				sample, err := sm.stat()
+				if err != nil {
+					// groups may be removed while subscribed, skip them until
+					// the subscriber goes away
+					logrus.WithError(err).WithField("path", sm.path).Debug("unable to sample group")
+				} else {
+					// a stream does not allow concurrent sends
+					mu.Lock()
+					err = stream.Send(sample)
+					mu.Unlock()
+					if err != nil {
+						errs <- err
+						cancel()
+						return
+					}
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.Next():
+				}
+			}
+		}(sm)
+	}
+	wg.Wait()
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// interval returns the interval of a subscription, falling back to the
+// request's and then the server's default when unset
+func (s *Server) interval(intervals ...time.Duration) (time.Duration, error) {
+	for _, interval := range intervals {
+		if interval == 0 {
+			continue
+		}
+		if interval < s.minInterval {
+			return 0, status.Errorf(codes.InvalidArgument, "interval must be at least %s", s.minInterval)
+		}
+		return interval, nil
+	}
+	if defaultInterval < s.minInterval {
+		return s.minInterval, nil
+	}
+	return defaultInterval, nil
+}
+
+// newSampler returns a sampler for the path, keeping the group open for the
+// lifetime of the subscription. When controllers are provided the samples only
+// contain their metrics
+func (s *Server) newSampler(path string, controllers []string) (*sampler, error) {
+	group, err := s.group(path)
+	if err != nil {
+		return nil, err
+	}
+	if s.mode == cgroups.Unified {
+		filter, err := unifiedFilter(controllers)
+		if err != nil {
+			return nil, err
+		}
+		m, err := s.loadUnified(group, v2.WithPersistentFiles())
+		if err != nil {
+			return nil, err
+		}
+		return &sampler{
+			path: path,
+			stat: func() (*Sample, error) {
+				metrics, err := m.Stat()
+				if err != nil {
+					return nil, err
+				}
+				for _, clear := range filter {
+					clear(metrics)
+				}
+				return &Sample{Path: path, Timestamp: s.clock.Now(), V2: metrics}, nil
+			},
+			close: func() { m.Close() },
+		}, nil
+	}
+	filter, err := legacyFilter(controllers)
+	if err != nil {
+		return nil, err
+	}
+	cg, err := s.loadLegacy(group)
+	if err != nil {
+		return nil, err
+	}
+	return &sampler{
+		path: path,
+		stat: func() (*Sample, error) {
+			metrics, err := cg.Stat(cgroups.IgnoreNotExist)
+			if err != nil {
+				return nil, err
+			}
+			for _, clear := range filter {
+				clear(metrics)
+			}
+			return &Sample{Path: path, Timestamp: s.clock.Now(), V1: metrics}, nil
+		},
+		close: func() {},
+	}, nil
+}
+
+// unifiedFilter returns the functions clearing the metrics of every controller
+// not in controllers, nothing is cleared when controllers is empty
+func unifiedFilter(controllers []string) (filter []func(*v2stats.Metrics), err error) {
+	keep := make(map[string]bool, len(controllers))
+	for _, name := range controllers {
+		if _, ok := unifiedControllers[name]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown controller %q", name)
+		}
+		keep[name] = true
+	}
+	if len(keep) == 0 {
+		return nil, nil
+	}
+	for name, clear := range unifiedControllers {
+		if !keep[name] {
+			filter = append(filter, clear)
+		}
+	}
+	return filter, nil
+}
+
+// legacyFilter is unifiedFilter for the metrics of the legacy hierarchies
+func legacyFilter(controllers []string) (filter []func(*v1stats.Metrics), err error) {
+	keep := make(map[string]bool, len(controllers))
+	for _, name := range controllers {
+		if _, ok := legacyControllers[name]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown controller %q", name)
+		}
+		keep[name] = true
+	}
+	if len(keep) == 0 {
+		return nil, nil
+	}
+	for name, clear := range legacyControllers {
+		if !keep[name] {
+			filter = append(filter, clear)
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,164 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// DefaultShrinkStep is the amount the memory limit is lowered by at a time
+	DefaultShrinkStep = 64 << 20
+	// DefaultShrinkInterval is how long to wait for reclaim before retrying
+	// a step the kernel could not satisfy
+	DefaultShrinkInterval = 100 * time.Millisecond
+)
+
+// ShrinkOpts configures ShrinkMemory
+type ShrinkOpts func(*shrinkConfig)
+
+type shrinkConfig struct {
+	step     uint64
+	interval time.Duration
+	clock    clock.Clock
+}
+
+// WithShrinkStep sets the amount the memory limit is lowered by at a time
+func WithShrinkStep(step uint64) ShrinkOpts {
+	return func(c *shrinkConfig) {
+		c.step = step
+	}
+}
+
+// WithShrinkInterval sets how long to wait for reclaim before retrying a
+// step the kernel could not satisfy
+func WithShrinkInterval(interval time.Duration) ShrinkOpts {
+	return func(c *shrinkConfig) {
+		c.interval = interval
+	}
+}
+
+// ShrinkResult reports how far ShrinkMemory got
+type ShrinkResult struct {
+	// Limit is the memory limit in effect when ShrinkMemory returned
+	Limit uint64
+	// Usage is the last memory usage read
+	Usage uint64
+	// Reached is true when the target limit was applied
+	Reached bool
+}
+
+// ShrinkMemory lowers memory.limit_in_bytes of the cgroup to target. Writing
+// a limit below the current usage fails with EBUSY when the kernel cannot
+// reclaim enough memory, so the page cache is dropped with
+// memory.force_empty first and the limit is then lowered by a step at a
+// time, waiting for reclaim whenever a step fails. The shrink continues
+// until the target is reached or ctx is done, in which case the result
+// reports the lowest limit that was applied alongside ctx.Err().
+func ShrinkMemory(ctx context.Context, c Cgroup, target uint64, opts ...ShrinkOpts) (ShrinkResult, error) {
+	config := &shrinkConfig{
+		step:     DefaultShrinkStep,
+		interval: DefaultShrinkInterval,
+	}
+	for _, o := range opts {
+		o(config)
+	}
+	if config.step == 0 {
+		return ShrinkResult{}, errors.New("cgroups: shrink step must be positive")
+	}
+	cg, ok := c.(*cgroup)
+	if !ok {
+		return ShrinkResult{}, errors.Errorf("cgroups: cannot shrink memory of %T", c)
+	}
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	if cg.err != nil {
+		return ShrinkResult{}, cg.err
+	}
+	config.clock = clock.Or(cg.pacer)
+	s := cg.getSubsystem(Memory)
+	if s == nil {
+		return ShrinkResult{}, ErrMemoryNotSupported
+	}
+	sp, err := cg.path(Memory)
+	if err != nil {
+		return ShrinkResult{}, err
+	}
+	return s.(*memoryController).shrink(ctx, sp, target, config)
+}
+
+func (m *memoryController) shrink(ctx context.Context, path string, target uint64, config *shrinkConfig) (ShrinkResult, error) {
+	var (
+		result ShrinkResult
+		err    error
+		file   = filepath.Join(m.Path(path), "memory.limit_in_bytes")
+	)
+	if result.Limit, err = readUint(file); err != nil {
+		return result, err
+	}
+	if result.Usage, err = readUint(filepath.Join(m.Path(path), "memory.usage_in_bytes")); err != nil {
+		return result, err
+	}
+	if result.Usage > target {
+		// force_empty fails with EBUSY when it could not reclaim everything,
+		// whatever it did reclaim still helps the steps below
+		if err := m.forceEmpty(ctx, path); err != nil && !errors.Is(err, unix.EBUSY) {
+			return result, err
+		}
+	}
+	for result.Limit > target {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		next := target
+		if result.Limit-target > config.step {
+			next = result.Limit - config.step
+		}
+		// there is nothing to reclaim above the usage, skip right to it
+		if next > result.Usage {
+			next = result.Usage
+			if next < target {
+				next = target
+			}
+		}
+		err := retryingWriteFile(file, []byte(strconv.FormatUint(next, 10)), defaultFilePerm)
+		if err == nil {
+			result.Limit = next
+			continue
+		}
+		if !errors.Is(err, unix.EBUSY) {
+			return result, err
+		}
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-config.clock.After(config.interval):
+		}
+		if result.Usage, err = readUint(filepath.Join(m.Path(path), "memory.usage_in_bytes")); err != nil {
+			return result, err
+		}
+	}
+	result.Reached = true
+	return result, nil
+}
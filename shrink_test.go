@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestShrinkMemory(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	control, err := New(mock.hierarchy, StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(mock.root, "memory", "test")
+	for name, value := range map[string]string{
+		"memory.limit_in_bytes": "9223372036854771712",
+		"memory.usage_in_bytes": "209715200",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value), defaultFilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := ShrinkMemory(context.Background(), control, 10<<20, WithShrinkStep(64<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Reached || result.Limit != 10<<20 || result.Usage != 200<<20 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "memory.limit_in_bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := strings.TrimSpace(string(data)); v != "10485760" {
+		t.Fatalf("expected limit 10485760, got %s", v)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "memory.force_empty")); err != nil {
+		t.Fatalf("expected memory.force_empty to be written: %v", err)
+	}
+}
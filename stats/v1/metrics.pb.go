@@ -25,17 +25,20 @@ var _ = math.Inf
 const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 
 type Metrics struct {
-	Hugetlb              []*HugetlbStat `protobuf:"bytes,1,rep,name=hugetlb,proto3" json:"hugetlb,omitempty"`
-	Pids                 *PidsStat      `protobuf:"bytes,2,opt,name=pids,proto3" json:"pids,omitempty"`
-	CPU                  *CPUStat       `protobuf:"bytes,3,opt,name=cpu,proto3" json:"cpu,omitempty"`
-	Memory               *MemoryStat    `protobuf:"bytes,4,opt,name=memory,proto3" json:"memory,omitempty"`
-	Blkio                *BlkIOStat     `protobuf:"bytes,5,opt,name=blkio,proto3" json:"blkio,omitempty"`
-	Rdma                 *RdmaStat      `protobuf:"bytes,6,opt,name=rdma,proto3" json:"rdma,omitempty"`
-	Network              []*NetworkStat `protobuf:"bytes,7,rep,name=network,proto3" json:"network,omitempty"`
-	CgroupStats          *CgroupStats   `protobuf:"bytes,8,opt,name=cgroup_stats,json=cgroupStats,proto3" json:"cgroup_stats,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	Hugetlb     []*HugetlbStat `protobuf:"bytes,1,rep,name=hugetlb,proto3" json:"hugetlb,omitempty"`
+	Pids        *PidsStat      `protobuf:"bytes,2,opt,name=pids,proto3" json:"pids,omitempty"`
+	CPU         *CPUStat       `protobuf:"bytes,3,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Memory      *MemoryStat    `protobuf:"bytes,4,opt,name=memory,proto3" json:"memory,omitempty"`
+	Blkio       *BlkIOStat     `protobuf:"bytes,5,opt,name=blkio,proto3" json:"blkio,omitempty"`
+	Rdma        *RdmaStat      `protobuf:"bytes,6,opt,name=rdma,proto3" json:"rdma,omitempty"`
+	Network     []*NetworkStat `protobuf:"bytes,7,rep,name=network,proto3" json:"network,omitempty"`
+	CgroupStats *CgroupStats   `protobuf:"bytes,8,opt,name=cgroup_stats,json=cgroupStats,proto3" json:"cgroup_stats,omitempty"`
+	// time in nanoseconds of the cgroup's clock source, CLOCK_MONOTONIC by
+	// default, at which the metrics were read
+	Timestamp            uint64   `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Metrics) Reset()      { *m = Metrics{} }
@@ -393,17 +396,29 @@ func (m *MemoryEntry) XXX_DiscardUnknown() {
 var xxx_messageInfo_MemoryEntry proto.InternalMessageInfo
 
 type BlkIOStat struct {
-	IoServiceBytesRecursive []*BlkIOEntry `protobuf:"bytes,1,rep,name=io_service_bytes_recursive,json=ioServiceBytesRecursive,proto3" json:"io_service_bytes_recursive,omitempty"`
-	IoServicedRecursive     []*BlkIOEntry `protobuf:"bytes,2,rep,name=io_serviced_recursive,json=ioServicedRecursive,proto3" json:"io_serviced_recursive,omitempty"`
-	IoQueuedRecursive       []*BlkIOEntry `protobuf:"bytes,3,rep,name=io_queued_recursive,json=ioQueuedRecursive,proto3" json:"io_queued_recursive,omitempty"`
-	IoServiceTimeRecursive  []*BlkIOEntry `protobuf:"bytes,4,rep,name=io_service_time_recursive,json=ioServiceTimeRecursive,proto3" json:"io_service_time_recursive,omitempty"`
-	IoWaitTimeRecursive     []*BlkIOEntry `protobuf:"bytes,5,rep,name=io_wait_time_recursive,json=ioWaitTimeRecursive,proto3" json:"io_wait_time_recursive,omitempty"`
-	IoMergedRecursive       []*BlkIOEntry `protobuf:"bytes,6,rep,name=io_merged_recursive,json=ioMergedRecursive,proto3" json:"io_merged_recursive,omitempty"`
-	IoTimeRecursive         []*BlkIOEntry `protobuf:"bytes,7,rep,name=io_time_recursive,json=ioTimeRecursive,proto3" json:"io_time_recursive,omitempty"`
-	SectorsRecursive        []*BlkIOEntry `protobuf:"bytes,8,rep,name=sectors_recursive,json=sectorsRecursive,proto3" json:"sectors_recursive,omitempty"`
-	XXX_NoUnkeyedLiteral    struct{}      `json:"-"`
-	XXX_unrecognized        []byte        `json:"-"`
-	XXX_sizecache           int32         `json:"-"`
+	IoServiceBytesRecursive         []*BlkIOEntry `protobuf:"bytes,1,rep,name=io_service_bytes_recursive,json=ioServiceBytesRecursive,proto3" json:"io_service_bytes_recursive,omitempty"`
+	IoServicedRecursive             []*BlkIOEntry `protobuf:"bytes,2,rep,name=io_serviced_recursive,json=ioServicedRecursive,proto3" json:"io_serviced_recursive,omitempty"`
+	IoQueuedRecursive               []*BlkIOEntry `protobuf:"bytes,3,rep,name=io_queued_recursive,json=ioQueuedRecursive,proto3" json:"io_queued_recursive,omitempty"`
+	IoServiceTimeRecursive          []*BlkIOEntry `protobuf:"bytes,4,rep,name=io_service_time_recursive,json=ioServiceTimeRecursive,proto3" json:"io_service_time_recursive,omitempty"`
+	IoWaitTimeRecursive             []*BlkIOEntry `protobuf:"bytes,5,rep,name=io_wait_time_recursive,json=ioWaitTimeRecursive,proto3" json:"io_wait_time_recursive,omitempty"`
+	IoMergedRecursive               []*BlkIOEntry `protobuf:"bytes,6,rep,name=io_merged_recursive,json=ioMergedRecursive,proto3" json:"io_merged_recursive,omitempty"`
+	IoTimeRecursive                 []*BlkIOEntry `protobuf:"bytes,7,rep,name=io_time_recursive,json=ioTimeRecursive,proto3" json:"io_time_recursive,omitempty"`
+	SectorsRecursive                []*BlkIOEntry `protobuf:"bytes,8,rep,name=sectors_recursive,json=sectorsRecursive,proto3" json:"sectors_recursive,omitempty"`
+	IoServiceBytes                  []*BlkIOEntry `protobuf:"bytes,9,rep,name=io_service_bytes,json=ioServiceBytes,proto3" json:"io_service_bytes,omitempty"`
+	IoServiced                      []*BlkIOEntry `protobuf:"bytes,10,rep,name=io_serviced,json=ioServiced,proto3" json:"io_serviced,omitempty"`
+	IoQueued                        []*BlkIOEntry `protobuf:"bytes,11,rep,name=io_queued,json=ioQueued,proto3" json:"io_queued,omitempty"`
+	IoServiceTime                   []*BlkIOEntry `protobuf:"bytes,12,rep,name=io_service_time,json=ioServiceTime,proto3" json:"io_service_time,omitempty"`
+	IoWaitTime                      []*BlkIOEntry `protobuf:"bytes,13,rep,name=io_wait_time,json=ioWaitTime,proto3" json:"io_wait_time,omitempty"`
+	IoMerged                        []*BlkIOEntry `protobuf:"bytes,14,rep,name=io_merged,json=ioMerged,proto3" json:"io_merged,omitempty"`
+	IoTime                          []*BlkIOEntry `protobuf:"bytes,15,rep,name=io_time,json=ioTime,proto3" json:"io_time,omitempty"`
+	Sectors                         []*BlkIOEntry `protobuf:"bytes,16,rep,name=sectors,proto3" json:"sectors,omitempty"`
+	ThrottleIoServiceBytes          []*BlkIOEntry `protobuf:"bytes,17,rep,name=throttle_io_service_bytes,json=throttleIoServiceBytes,proto3" json:"throttle_io_service_bytes,omitempty"`
+	ThrottleIoServiced              []*BlkIOEntry `protobuf:"bytes,18,rep,name=throttle_io_serviced,json=throttleIoServiced,proto3" json:"throttle_io_serviced,omitempty"`
+	ThrottleIoServiceBytesRecursive []*BlkIOEntry `protobuf:"bytes,19,rep,name=throttle_io_service_bytes_recursive,json=throttleIoServiceBytesRecursive,proto3" json:"throttle_io_service_bytes_recursive,omitempty"`
+	ThrottleIoServicedRecursive     []*BlkIOEntry `protobuf:"bytes,20,rep,name=throttle_io_serviced_recursive,json=throttleIoServicedRecursive,proto3" json:"throttle_io_serviced_recursive,omitempty"`
+	XXX_NoUnkeyedLiteral            struct{}      `json:"-"`
+	XXX_unrecognized                []byte        `json:"-"`
+	XXX_sizecache                   int32         `json:"-"`
 }
 
 func (m *BlkIOStat) Reset()      { *m = BlkIOStat{} }
@@ -680,112 +695,123 @@ func init() {
 }
 
 var fileDescriptor_a17b2d87c332bfaa = []byte{
-	// 1669 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x58, 0x4f, 0x73, 0x1b, 0xb7,
-	0x15, 0x0f, 0xc5, 0x95, 0xc8, 0x7d, 0x94, 0x6c, 0x09, 0xfe, 0xb7, 0x52, 0x1c, 0x51, 0xa1, 0xec,
-	0xd6, 0xad, 0xa7, 0xd2, 0x24, 0xed, 0x78, 0xea, 0x34, 0x99, 0x4e, 0xa4, 0x24, 0x63, 0x4f, 0xab,
-	0x9a, 0x59, 0x4a, 0x93, 0xf6, 0xb4, 0x03, 0x2e, 0xe1, 0x25, 0xac, 0xe5, 0x62, 0x83, 0xc5, 0x52,
-	0x74, 0x4f, 0x3d, 0x74, 0xa6, 0xa7, 0x7e, 0xa0, 0x7e, 0x83, 0x1c, 0x7b, 0xe9, 0x4c, 0x7b, 0xd1,
-	0x34, 0xfc, 0x1c, 0x3d, 0x74, 0x80, 0x87, 0xfd, 0x43, 0xc7, 0xb2, 0xc2, 0xdb, 0xe2, 0xe1, 0xf7,
-	0x7e, 0xef, 0xe1, 0xe1, 0x07, 0xe0, 0x91, 0xf0, 0xab, 0x88, 0xab, 0x71, 0x3e, 0x3c, 0x08, 0xc5,
-	0xe4, 0x30, 0x14, 0x89, 0xa2, 0x3c, 0x61, 0x72, 0x74, 0x18, 0x46, 0x52, 0xe4, 0x69, 0x76, 0x98,
-	0x29, 0xaa, 0xb2, 0xc3, 0xe9, 0x47, 0x87, 0x13, 0xa6, 0x24, 0x0f, 0xb3, 0x83, 0x54, 0x0a, 0x25,
-	0x88, 0xc7, 0xc5, 0x41, 0x85, 0x3e, 0xb0, 0xe8, 0x83, 0xe9, 0x47, 0x3b, 0xb7, 0x23, 0x11, 0x09,
-	0x03, 0x3a, 0xd4, 0x5f, 0x88, 0xef, 0xfd, 0xaf, 0x09, 0xad, 0x13, 0x64, 0x20, 0xbf, 0x85, 0xd6,
-	0x38, 0x8f, 0x98, 0x8a, 0x87, 0x5e, 0x63, 0xaf, 0xf9, 0xa8, 0xf3, 0xf1, 0xc3, 0x83, 0xab, 0xd8,
-	0x0e, 0x9e, 0x21, 0x70, 0xa0, 0xa8, 0xf2, 0x0b, 0x2f, 0xf2, 0x04, 0x9c, 0x94, 0x8f, 0x32, 0x6f,
-	0x65, 0xaf, 0xf1, 0xa8, 0xf3, 0x71, 0xef, 0x6a, 0xef, 0x3e, 0x1f, 0x65, 0xc6, 0xd5, 0xe0, 0xc9,
-	0xa7, 0xd0, 0x0c, 0xd3, 0xdc, 0x6b, 0x1a, 0xb7, 0x0f, 0xaf, 0x76, 0x3b, 0xee, 0x9f, 0x69, 0xaf,
-	0xa3, 0xd6, 0xfc, 0xb2, 0xdb, 0x3c, 0xee, 0x9f, 0xf9, 0xda, 0x8d, 0x7c, 0x0a, 0x6b, 0x13, 0x36,
-	0x11, 0xf2, 0xb5, 0xe7, 0x18, 0x82, 0x07, 0x57, 0x13, 0x9c, 0x18, 0x9c, 0x89, 0x6c, 0x7d, 0xc8,
-	0x53, 0x58, 0x1d, 0xc6, 0xe7, 0x5c, 0x78, 0xab, 0xc6, 0x79, 0xff, 0x6a, 0xe7, 0xa3, 0xf8, 0xfc,
-	0xf9, 0x0b, 0xe3, 0x8b, 0x1e, 0x7a, 0xb9, 0x72, 0x34, 0xa1, 0xde, 0xda, 0x75, 0xcb, 0xf5, 0x47,
-	0x13, 0x8a, 0xcb, 0xd5, 0x78, 0x5d, 0xe7, 0x84, 0xa9, 0x0b, 0x21, 0xcf, 0xbd, 0xd6, 0x75, 0x75,
-	0xfe, 0x03, 0x02, 0xb1, 0xce, 0xd6, 0x8b, 0x3c, 0x83, 0x75, 0x84, 0x04, 0x46, 0x05, 0x5e, 0xdb,
-	0x24, 0xf0, 0x0e, 0x96, 0x63, 0xf3, 0xa9, 0x49, 0x32, 0xbf, 0x13, 0x56, 0x83, 0xde, 0x39, 0x74,
-	0x6a, 0x3b, 0x49, 0x6e, 0xc3, 0x6a, 0x9e, 0xd1, 0x88, 0x79, 0x8d, 0xbd, 0xc6, 0x23, 0xc7, 0xc7,
-	0x01, 0xd9, 0x84, 0xe6, 0x84, 0xce, 0xcc, 0xae, 0x3a, 0xbe, 0xfe, 0x24, 0x1e, 0xb4, 0x5e, 0x52,
-	0x1e, 0x87, 0x89, 0x32, 0x9b, 0xe6, 0xf8, 0xc5, 0x90, 0xec, 0x40, 0x3b, 0xa5, 0x11, 0xcb, 0xf8,
-	0x9f, 0x99, 0xd9, 0x0e, 0xd7, 0x2f, 0xc7, 0xbd, 0x4f, 0xa0, 0x5d, 0x6c, 0xbc, 0x66, 0x08, 0x73,
-	0x29, 0x59, 0xa2, 0x6c, 0xac, 0x62, 0xa8, 0x73, 0x88, 0xf9, 0x84, 0x2b, 0x1b, 0x0f, 0x07, 0xbd,
-	0xbf, 0x35, 0xa0, 0x65, 0xb7, 0x9f, 0xfc, 0xba, 0x9e, 0xe5, 0x3b, 0x0b, 0x7f, 0xdc, 0x3f, 0x3b,
-	0xd3, 0xc8, 0x62, 0x25, 0x47, 0x00, 0x6a, 0x2c, 0x85, 0x52, 0x31, 0x4f, 0xa2, 0xeb, 0x65, 0x7a,
-	0x8a, 0x58, 0xe6, 0xd7, 0xbc, 0x7a, 0xdf, 0x42, 0xbb, 0xa0, 0xd5, 0xb9, 0x2a, 0xa1, 0x68, 0x5c,
-	0xd4, 0xcb, 0x0c, 0xc8, 0x5d, 0x58, 0x3b, 0x67, 0x32, 0x61, 0xb1, 0x5d, 0x82, 0x1d, 0x11, 0x02,
-	0x4e, 0x9e, 0x31, 0x69, 0x4b, 0x66, 0xbe, 0xc9, 0x3e, 0xb4, 0x52, 0x26, 0x03, 0x2d, 0x7f, 0x67,
-	0xaf, 0xf9, 0xc8, 0x39, 0x82, 0xf9, 0x65, 0x77, 0xad, 0xcf, 0xa4, 0x96, 0xf7, 0x5a, 0xca, 0xe4,
-	0x71, 0x9a, 0xf7, 0x66, 0xd0, 0x2e, 0x52, 0xd1, 0x85, 0x4b, 0x99, 0xe4, 0x62, 0x94, 0x15, 0x85,
-	0xb3, 0x43, 0xf2, 0x18, 0xb6, 0x6c, 0x9a, 0x6c, 0x14, 0x14, 0x18, 0xcc, 0x60, 0xb3, 0x9c, 0xe8,
-	0x5b, 0xf0, 0x43, 0xb8, 0x51, 0x81, 0x15, 0x9f, 0x30, 0x9b, 0xd5, 0x46, 0x69, 0x3d, 0xe5, 0x13,
-	0xd6, 0xfb, 0x4f, 0x07, 0xa0, 0x3a, 0x34, 0x7a, 0xbd, 0x21, 0x0d, 0xc7, 0xa5, 0x3e, 0xcc, 0x80,
-	0x6c, 0x43, 0x53, 0x66, 0x36, 0x14, 0x9e, 0x4d, 0x7f, 0x30, 0xf0, 0xb5, 0x8d, 0xfc, 0x04, 0xda,
-	0x32, 0xcb, 0x02, 0x7d, 0x41, 0x60, 0x80, 0xa3, 0xce, 0xfc, 0xb2, 0xdb, 0xf2, 0x07, 0x03, 0x2d,
-	0x3b, 0xbf, 0x25, 0xb3, 0x4c, 0x7f, 0x90, 0x2e, 0x74, 0x26, 0x34, 0x4d, 0xd9, 0x28, 0x78, 0xc9,
-	0x63, 0x54, 0x8e, 0xe3, 0x03, 0x9a, 0xbe, 0xe2, 0xb1, 0xa9, 0xf4, 0x88, 0x4b, 0xf5, 0xda, 0x1c,
-	0x53, 0xc7, 0xc7, 0x01, 0xb9, 0x0f, 0xee, 0x85, 0xe4, 0x8a, 0x0d, 0x69, 0x78, 0x6e, 0x8e, 0xa1,
-	0xe3, 0x57, 0x06, 0xe2, 0x41, 0x3b, 0x8d, 0x82, 0x34, 0x0a, 0x78, 0xe2, 0xb5, 0x70, 0x27, 0xd2,
-	0xa8, 0x1f, 0x3d, 0x4f, 0xc8, 0x0e, 0xb8, 0x38, 0x23, 0x72, 0x65, 0x4e, 0x8f, 0x2e, 0x63, 0xd4,
-	0x8f, 0x5e, 0xe4, 0x8a, 0x6c, 0x1b, 0xaf, 0x97, 0x34, 0x8f, 0x95, 0xe7, 0x16, 0x53, 0x5f, 0xe9,
-	0x21, 0xd9, 0x83, 0xf5, 0x34, 0x0a, 0x26, 0xf4, 0x95, 0x9d, 0x06, 0x4c, 0x33, 0x8d, 0x4e, 0xe8,
-	0x2b, 0x44, 0xec, 0xc3, 0x06, 0x4f, 0x68, 0xa8, 0xf8, 0x94, 0x05, 0x34, 0x11, 0x89, 0xd7, 0x31,
-	0x90, 0xf5, 0xc2, 0xf8, 0x79, 0x22, 0x12, 0xbd, 0xd8, 0x3a, 0x64, 0x1d, 0x59, 0x6a, 0x80, 0x3a,
-	0x8b, 0xa9, 0xc7, 0xc6, 0x22, 0x8b, 0xa9, 0x48, 0xc5, 0x62, 0x20, 0x37, 0xea, 0x2c, 0x06, 0xb0,
-	0x07, 0x9d, 0x3c, 0x61, 0x53, 0x1e, 0x2a, 0x3a, 0x8c, 0x99, 0x77, 0xd3, 0x00, 0xea, 0x26, 0xf2,
-	0x09, 0x6c, 0x8f, 0x39, 0x93, 0x54, 0x86, 0x63, 0x1e, 0xd2, 0x38, 0xc0, 0x2b, 0x31, 0xc0, 0xe3,
-	0xb7, 0x69, 0xf0, 0xf7, 0xea, 0x00, 0x54, 0xc2, 0xef, 0xf5, 0x34, 0x79, 0x02, 0x0b, 0x53, 0x41,
-	0x76, 0x41, 0x53, 0xeb, 0xb9, 0x65, 0x3c, 0xef, 0xd4, 0xa7, 0x07, 0x17, 0x34, 0x45, 0xbf, 0x2e,
-	0x74, 0xcc, 0x29, 0x09, 0x50, 0x48, 0x04, 0xd3, 0x36, 0xa6, 0x63, 0xa3, 0xa6, 0x9f, 0x81, 0x8b,
-	0x00, 0xad, 0xa9, 0x5b, 0x46, 0x33, 0xeb, 0xf3, 0xcb, 0x6e, 0xfb, 0x54, 0x1b, 0xb5, 0xb0, 0xda,
-	0x66, 0xda, 0xcf, 0x32, 0xf2, 0x04, 0x6e, 0x94, 0x50, 0xd4, 0xd8, 0x6d, 0x83, 0xdf, 0x9c, 0x5f,
-	0x76, 0xd7, 0x0b, 0xbc, 0x11, 0xda, 0x7a, 0xe1, 0x63, 0xd4, 0xf6, 0x73, 0xd8, 0x42, 0xbf, 0xba,
-	0xe6, 0xee, 0x98, 0x4c, 0x6e, 0x9a, 0x89, 0x93, 0x4a, 0x78, 0x65, 0xbe, 0x28, 0xbf, 0xbb, 0xb5,
-	0x7c, 0xbf, 0x30, 0x1a, 0xfc, 0x29, 0xa0, 0x4f, 0x50, 0x29, 0xf1, 0x9e, 0x01, 0x61, 0x6e, 0xdf,
-	0x94, 0x72, 0xdc, 0x2f, 0xb2, 0x2d, 0x45, 0xe9, 0xe1, 0x96, 0x18, 0x6b, 0x1f, 0x95, 0xf9, 0xb0,
-	0x60, 0xab, 0xf4, 0xb9, 0x8d, 0x9b, 0x5f, 0xa2, 0xb4, 0x48, 0x1f, 0xd4, 0xb8, 0x50, 0x8b, 0x3b,
-	0x0b, 0x28, 0x54, 0xe3, 0x63, 0x20, 0x25, 0xaa, 0x52, 0xed, 0xfb, 0xb5, 0x85, 0xf6, 0x2b, 0xe9,
-	0x1e, 0xc0, 0x2d, 0x04, 0x2f, 0x0a, 0xf8, 0xbe, 0x41, 0x63, 0xbd, 0x9e, 0xd7, 0x55, 0x5c, 0x16,
-	0xb1, 0x8e, 0xfe, 0xa0, 0xc6, 0xfd, 0x79, 0x85, 0xfd, 0x21, 0xb7, 0x29, 0xf9, 0xee, 0x5b, 0xb8,
-	0x4d, 0xd1, 0xdf, 0xe4, 0x36, 0xe8, 0xee, 0x0f, 0xb8, 0x0d, 0xf6, 0x71, 0x81, 0xad, 0x8b, 0x7d,
-	0xcf, 0x5e, 0x7b, 0x7a, 0xe2, 0xac, 0xa6, 0xf8, 0xdf, 0x14, 0x4f, 0xc7, 0x87, 0xd7, 0x3d, 0x99,
-	0xa8, 0xf5, 0x2f, 0x13, 0x25, 0x5f, 0x17, 0xaf, 0xc7, 0x53, 0x70, 0xb4, 0xca, 0xbd, 0xde, 0x32,
-	0xbe, 0xc6, 0x85, 0x7c, 0x56, 0x3e, 0x09, 0xfb, 0xcb, 0x38, 0x17, 0x2f, 0xc7, 0x00, 0x00, 0xbf,
-	0x02, 0x15, 0xa6, 0xde, 0x83, 0x25, 0x28, 0x8e, 0x36, 0xe6, 0x97, 0x5d, 0xf7, 0x77, 0xc6, 0xf9,
-	0xf4, 0xb8, 0xef, 0xbb, 0xc8, 0x73, 0x1a, 0xa6, 0x3d, 0x06, 0x9d, 0x1a, 0xb0, 0x7a, 0x77, 0x1b,
-	0xb5, 0x77, 0xb7, 0xea, 0x08, 0x56, 0xde, 0xd2, 0x11, 0x34, 0xdf, 0xda, 0x11, 0x38, 0x0b, 0x1d,
-	0x41, 0xef, 0x5f, 0xab, 0xe0, 0x96, 0xad, 0x13, 0xa1, 0xb0, 0xc3, 0x45, 0x90, 0x31, 0x39, 0xe5,
-	0x21, 0x0b, 0x86, 0xaf, 0x15, 0xcb, 0x02, 0xc9, 0xc2, 0x5c, 0x66, 0x7c, 0xca, 0x6c, 0xdb, 0xf9,
-	0xe0, 0x9a, 0x1e, 0x0c, 0x6b, 0x73, 0x8f, 0x8b, 0x01, 0xd2, 0x1c, 0x69, 0x16, 0xbf, 0x20, 0x21,
-	0x7f, 0x84, 0x3b, 0x55, 0x88, 0x51, 0x8d, 0x7d, 0x65, 0x09, 0xf6, 0x5b, 0x25, 0xfb, 0xa8, 0x62,
-	0x3e, 0x85, 0x5b, 0x5c, 0x04, 0xdf, 0xe6, 0x2c, 0x5f, 0xe0, 0x6d, 0x2e, 0xc1, 0xbb, 0xc5, 0xc5,
-	0xd7, 0xc6, 0xbf, 0x62, 0x0d, 0x60, 0xbb, 0x56, 0x12, 0xfd, 0x16, 0xd7, 0xb8, 0x9d, 0x25, 0xb8,
-	0xef, 0x96, 0x39, 0xeb, 0xb7, 0xbb, 0x0a, 0xf0, 0x27, 0xb8, 0xcb, 0x45, 0x70, 0x41, 0xb9, 0x7a,
-	0x93, 0x7d, 0x75, 0xb9, 0x8a, 0x7c, 0x43, 0xb9, 0x5a, 0xa4, 0xc6, 0x8a, 0x4c, 0x98, 0x8c, 0x16,
-	0x2a, 0xb2, 0xb6, 0x5c, 0x45, 0x4e, 0x8c, 0x7f, 0xc5, 0xda, 0x87, 0x2d, 0x2e, 0xde, 0xcc, 0xb5,
-	0xb5, 0x04, 0xe7, 0x4d, 0x2e, 0x16, 0xf3, 0xfc, 0x1a, 0xb6, 0x32, 0x16, 0x2a, 0x21, 0xeb, 0x6a,
-	0x6b, 0x2f, 0xc1, 0xb8, 0x69, 0xdd, 0x4b, 0xca, 0xde, 0x14, 0xa0, 0x9a, 0x27, 0x37, 0x60, 0x45,
-	0xa4, 0xe6, 0xe8, 0xb8, 0xfe, 0x8a, 0x48, 0x75, 0x0f, 0x38, 0xd2, 0xd7, 0x0e, 0x1e, 0x1c, 0xd7,
-	0xb7, 0x23, 0x7d, 0x9e, 0x26, 0xf4, 0x95, 0x28, 0x9a, 0x40, 0x1c, 0x18, 0x2b, 0x4f, 0x84, 0xb4,
-	0x67, 0x07, 0x07, 0xda, 0x3a, 0xa5, 0x71, 0xce, 0x8a, 0x9e, 0xc7, 0x0c, 0x7a, 0x7f, 0x6d, 0x40,
-	0xbb, 0xf8, 0x41, 0x41, 0x3e, 0xab, 0xb7, 0xd1, 0xcd, 0x77, 0xff, 0x7e, 0xd1, 0x4e, 0xb8, 0x98,
-	0xb2, 0xd7, 0x7e, 0x5a, 0xf5, 0xda, 0x3f, 0xda, 0xd9, 0x36, 0xe4, 0x0c, 0xdc, 0xd2, 0x56, 0x5b,
-	0x6d, 0x63, 0x61, 0xb5, 0x5d, 0xe8, 0x8c, 0x43, 0x1a, 0x8c, 0x69, 0x32, 0x8a, 0x19, 0x76, 0x88,
-	0x1b, 0x3e, 0x8c, 0x43, 0xfa, 0x0c, 0x2d, 0x05, 0x40, 0x0c, 0x5f, 0xb1, 0x50, 0x65, 0xa6, 0x28,
-	0x08, 0x78, 0x81, 0x96, 0xde, 0xdf, 0x57, 0xa0, 0x53, 0xfb, 0x0d, 0xa4, 0x7b, 0xe8, 0x84, 0x4e,
-	0x8a, 0x38, 0xe6, 0x5b, 0x77, 0x6c, 0x72, 0x86, 0x77, 0x89, 0xbd, 0xa6, 0x5a, 0x72, 0x66, 0x2e,
-	0x05, 0xf2, 0x01, 0x80, 0x9c, 0x05, 0x29, 0x0d, 0xcf, 0x99, 0xa5, 0x77, 0x7c, 0x57, 0xce, 0xfa,
-	0x68, 0x20, 0xef, 0x83, 0x2b, 0x67, 0x01, 0x93, 0x52, 0xc8, 0xcc, 0xd6, 0xbe, 0x2d, 0x67, 0x5f,
-	0x9a, 0xb1, 0xf5, 0x1d, 0x49, 0xa1, 0x7b, 0x01, 0xbb, 0x07, 0xae, 0x9c, 0x7d, 0x81, 0x06, 0x1d,
-	0x55, 0x15, 0x51, 0xb1, 0xf5, 0x6c, 0xa9, 0x2a, 0xaa, 0xaa, 0xa2, 0x62, 0xeb, 0xe9, 0xaa, 0x7a,
-	0x54, 0x55, 0x46, 0xc5, 0xee, 0xb3, 0xad, 0x6a, 0x51, 0x55, 0x15, 0xd5, 0x2d, 0x7c, 0x6d, 0xd4,
-	0xde, 0x3f, 0x1a, 0xd0, 0xa9, 0xfd, 0x9a, 0xd3, 0x05, 0x4c, 0x64, 0x90, 0xc5, 0x8c, 0xa5, 0xfa,
-	0x27, 0x0d, 0xde, 0xdd, 0x90, 0xc8, 0x81, 0xb5, 0x68, 0xbe, 0x44, 0x06, 0x32, 0x4f, 0x92, 0xe2,
-	0x27, 0x8f, 0xe3, 0xbb, 0x89, 0xf4, 0xd1, 0x60, 0xa7, 0x33, 0x85, 0xe1, 0x9a, 0xc5, 0xf4, 0x00,
-	0x0d, 0xe4, 0x17, 0x40, 0x12, 0x19, 0xe4, 0x09, 0x4f, 0x14, 0x93, 0x32, 0x4f, 0x15, 0x1f, 0x96,
-	0xed, 0xf9, 0x56, 0x22, 0xcf, 0x16, 0x27, 0xc8, 0x7d, 0xc3, 0x66, 0x2f, 0x1b, 0x5b, 0xb2, 0x76,
-	0x22, 0x9f, 0x9b, 0x9b, 0xe3, 0xc8, 0xfb, 0xee, 0xfb, 0xdd, 0xf7, 0xfe, 0xfd, 0xfd, 0xee, 0x7b,
-	0x7f, 0x99, 0xef, 0x36, 0xbe, 0x9b, 0xef, 0x36, 0xfe, 0x39, 0xdf, 0x6d, 0xfc, 0x77, 0xbe, 0xdb,
-	0x18, 0xae, 0x99, 0x3f, 0x23, 0x7e, 0xf9, 0xff, 0x00, 0x00, 0x00, 0xff, 0xff, 0xb9, 0x78, 0x66,
-	0x06, 0xf4, 0x10, 0x00, 0x00,
+	// 1841 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x58, 0x4f, 0x73, 0xdb, 0xc6,
+	0x15, 0x0f, 0x45, 0x58, 0x24, 0x1e, 0xf5, 0x77, 0x25, 0xdb, 0x90, 0xec, 0x88, 0x0a, 0x65, 0xb7,
+	0x6e, 0x3d, 0x95, 0x26, 0x69, 0xc7, 0xd3, 0xa4, 0x49, 0x3b, 0x96, 0x62, 0x8f, 0x35, 0x8d, 0x62,
+	0x06, 0x94, 0x9a, 0xf6, 0x84, 0x01, 0xc1, 0x35, 0xb8, 0x16, 0x09, 0x20, 0x8b, 0x85, 0x44, 0xf7,
+	0xd4, 0x43, 0x67, 0x7a, 0xea, 0x17, 0xe8, 0xa5, 0x9f, 0xa3, 0xdf, 0x20, 0xc7, 0x1e, 0xdb, 0x8b,
+	0xa6, 0xe1, 0x27, 0xe9, 0xec, 0xbe, 0x5d, 0x00, 0xd4, 0x1f, 0x2b, 0xb8, 0xe1, 0xbd, 0xfd, 0xbd,
+	0xdf, 0x7b, 0xfb, 0xf0, 0x5b, 0xec, 0x2e, 0xe0, 0x57, 0x21, 0x13, 0xc3, 0xac, 0xbf, 0x1b, 0xc4,
+	0xe3, 0xbd, 0x20, 0x8e, 0x84, 0xcf, 0x22, 0xca, 0x07, 0x7b, 0x41, 0xc8, 0xe3, 0x2c, 0x49, 0xf7,
+	0x52, 0xe1, 0x8b, 0x74, 0xef, 0xec, 0xe3, 0xbd, 0x31, 0x15, 0x9c, 0x05, 0xe9, 0x6e, 0xc2, 0x63,
+	0x11, 0x13, 0x87, 0xc5, 0xbb, 0x05, 0x7a, 0x57, 0xa3, 0x77, 0xcf, 0x3e, 0xde, 0x5c, 0x0f, 0xe3,
+	0x30, 0x56, 0xa0, 0x3d, 0xf9, 0x84, 0xf8, 0xce, 0x3f, 0x2c, 0x68, 0x1c, 0x21, 0x03, 0xf9, 0x1d,
+	0x34, 0x86, 0x59, 0x48, 0xc5, 0xa8, 0xef, 0xd4, 0xb6, 0xeb, 0x4f, 0x5a, 0x9f, 0x3c, 0xde, 0xbd,
+	0x89, 0x6d, 0xf7, 0x15, 0x02, 0x7b, 0xc2, 0x17, 0xae, 0x89, 0x22, 0xcf, 0xc0, 0x4a, 0xd8, 0x20,
+	0x75, 0xe6, 0xb6, 0x6b, 0x4f, 0x5a, 0x9f, 0x74, 0x6e, 0x8e, 0xee, 0xb2, 0x41, 0xaa, 0x42, 0x15,
+	0x9e, 0x7c, 0x0e, 0xf5, 0x20, 0xc9, 0x9c, 0xba, 0x0a, 0xfb, 0xe8, 0xe6, 0xb0, 0x83, 0xee, 0x89,
+	0x8c, 0xda, 0x6f, 0x4c, 0x2f, 0xda, 0xf5, 0x83, 0xee, 0x89, 0x2b, 0xc3, 0xc8, 0xe7, 0x30, 0x3f,
+	0xa6, 0xe3, 0x98, 0xbf, 0x73, 0x2c, 0x45, 0xf0, 0xe8, 0x66, 0x82, 0x23, 0x85, 0x53, 0x99, 0x75,
+	0x0c, 0xf9, 0x14, 0xee, 0xf4, 0x47, 0xa7, 0x2c, 0x76, 0xee, 0xa8, 0xe0, 0x9d, 0x9b, 0x83, 0xf7,
+	0x47, 0xa7, 0x87, 0xaf, 0x55, 0x2c, 0x46, 0xc8, 0xe9, 0xf2, 0xc1, 0xd8, 0x77, 0xe6, 0x6f, 0x9b,
+	0xae, 0x3b, 0x18, 0xfb, 0x38, 0x5d, 0x89, 0x97, 0x7d, 0x8e, 0xa8, 0x38, 0x8f, 0xf9, 0xa9, 0xd3,
+	0xb8, 0xad, 0xcf, 0x5f, 0x23, 0x10, 0xfb, 0xac, 0xa3, 0xc8, 0x2b, 0x58, 0x40, 0x88, 0xa7, 0x54,
+	0xe0, 0x34, 0xb7, 0x6b, 0xef, 0x67, 0x39, 0x50, 0x8f, 0x92, 0x24, 0x75, 0x5b, 0x41, 0x61, 0x90,
+	0x87, 0x60, 0x0b, 0x36, 0xa6, 0xa9, 0xf0, 0xc7, 0x89, 0x63, 0x6f, 0xd7, 0x9e, 0x58, 0x6e, 0xe1,
+	0xe8, 0x9c, 0x42, 0xab, 0xf4, 0x9e, 0xc9, 0x3a, 0xdc, 0xc9, 0x52, 0x3f, 0xa4, 0x4e, 0x4d, 0x01,
+	0xd1, 0x20, 0x2b, 0x50, 0x1f, 0xfb, 0x13, 0xf5, 0xce, 0x2d, 0x57, 0x3e, 0x12, 0x07, 0x1a, 0x6f,
+	0x7c, 0x36, 0x0a, 0x22, 0xa1, 0x5e, 0xa9, 0xe5, 0x1a, 0x93, 0x6c, 0x42, 0x33, 0xf1, 0x43, 0x9a,
+	0xb2, 0x3f, 0x53, 0xf5, 0xb2, 0x6c, 0x37, 0xb7, 0x3b, 0x9f, 0x41, 0xd3, 0xc8, 0x42, 0x32, 0x04,
+	0x19, 0xe7, 0x34, 0x12, 0x3a, 0x97, 0x31, 0x65, 0x0d, 0x23, 0x36, 0x66, 0x42, 0xe7, 0x43, 0xa3,
+	0xf3, 0xb7, 0x1a, 0x34, 0xb4, 0x38, 0xc8, 0xaf, 0xcb, 0x55, 0xbe, 0xf7, 0xb5, 0x1c, 0x74, 0x4f,
+	0x4e, 0x24, 0xd2, 0xcc, 0x64, 0x1f, 0x40, 0x0c, 0x79, 0x2c, 0xc4, 0x88, 0x45, 0xe1, 0xed, 0x22,
+	0x3e, 0x46, 0x2c, 0x75, 0x4b, 0x51, 0x9d, 0xef, 0xa0, 0x69, 0x68, 0x65, 0xad, 0x22, 0x16, 0xfe,
+	0xc8, 0xf4, 0x4b, 0x19, 0xe4, 0x1e, 0xcc, 0x9f, 0x52, 0x1e, 0xd1, 0x91, 0x9e, 0x82, 0xb6, 0x08,
+	0x01, 0x2b, 0x4b, 0x29, 0xd7, 0x2d, 0x53, 0xcf, 0x64, 0x07, 0x1a, 0x09, 0xe5, 0x9e, 0x5c, 0x1c,
+	0xd6, 0x76, 0xfd, 0x89, 0xb5, 0x0f, 0xd3, 0x8b, 0xf6, 0x7c, 0x97, 0x72, 0x29, 0xfe, 0xf9, 0x84,
+	0xf2, 0x83, 0x24, 0xeb, 0x4c, 0xa0, 0x69, 0x4a, 0x91, 0x8d, 0x4b, 0x28, 0x67, 0xf1, 0x20, 0x35,
+	0x8d, 0xd3, 0x26, 0x79, 0x0a, 0xab, 0xba, 0x4c, 0x3a, 0xf0, 0x0c, 0x06, 0x2b, 0x58, 0xc9, 0x07,
+	0xba, 0x1a, 0xfc, 0x18, 0x96, 0x0a, 0xb0, 0xd4, 0x83, 0xae, 0x6a, 0x31, 0xf7, 0x1e, 0xb3, 0x31,
+	0xed, 0xfc, 0xb7, 0x05, 0x50, 0x2c, 0x29, 0x39, 0xdf, 0xc0, 0x0f, 0x86, 0xb9, 0x3e, 0x94, 0x41,
+	0x36, 0xa0, 0xce, 0x53, 0x9d, 0x0a, 0x57, 0xae, 0xdb, 0xeb, 0xb9, 0xd2, 0x47, 0x7e, 0x02, 0x4d,
+	0x9e, 0xa6, 0x9e, 0xfc, 0x7c, 0x60, 0x82, 0xfd, 0xd6, 0xf4, 0xa2, 0xdd, 0x70, 0x7b, 0x3d, 0x29,
+	0x3b, 0xb7, 0xc1, 0xd3, 0x54, 0x3e, 0x90, 0x36, 0xb4, 0xc6, 0x7e, 0x92, 0xd0, 0x81, 0xf7, 0x86,
+	0x8d, 0x50, 0x39, 0x96, 0x0b, 0xe8, 0x7a, 0xc9, 0x46, 0xaa, 0xd3, 0x03, 0xc6, 0xc5, 0x3b, 0xb5,
+	0x88, 0x2d, 0x17, 0x0d, 0x29, 0xee, 0x73, 0xce, 0x04, 0xed, 0xfb, 0xc1, 0xa9, 0x5a, 0xa4, 0x96,
+	0x5b, 0x38, 0x88, 0x03, 0xcd, 0x24, 0xf4, 0x92, 0xd0, 0x63, 0x91, 0xd3, 0xc0, 0x37, 0x91, 0x84,
+	0xdd, 0xf0, 0x30, 0x22, 0x9b, 0x60, 0xe3, 0x48, 0x9c, 0x09, 0xa7, 0xa9, 0xdb, 0x18, 0x76, 0xc3,
+	0xd7, 0x99, 0x20, 0x1b, 0x2a, 0xea, 0x8d, 0x9f, 0x8d, 0x84, 0x5e, 0x2f, 0x8d, 0x24, 0x7c, 0x29,
+	0x4d, 0xb2, 0x0d, 0x0b, 0x49, 0xe8, 0x8d, 0xfd, 0xb7, 0x7a, 0x18, 0xb0, 0xcc, 0x24, 0x3c, 0xf2,
+	0xdf, 0x22, 0x62, 0x07, 0x16, 0x59, 0xe4, 0x07, 0x82, 0x9d, 0x51, 0xcf, 0x8f, 0xe2, 0xc8, 0x69,
+	0x29, 0xc8, 0x82, 0x71, 0x3e, 0x8f, 0xe2, 0x48, 0x4e, 0xb6, 0x0c, 0x59, 0x40, 0x96, 0x12, 0xa0,
+	0xcc, 0xa2, 0xfa, 0xb1, 0x38, 0xcb, 0xa2, 0x3a, 0x52, 0xb0, 0x28, 0xc8, 0x52, 0x99, 0x45, 0x01,
+	0xb6, 0xa1, 0x95, 0x45, 0xf4, 0x8c, 0x05, 0xc2, 0xef, 0x8f, 0xa8, 0xb3, 0xac, 0x00, 0x65, 0x17,
+	0xf9, 0x0c, 0x36, 0x86, 0x8c, 0x72, 0x9f, 0x07, 0x43, 0x16, 0xf8, 0x23, 0x0f, 0x3f, 0x98, 0x1e,
+	0x2e, 0xbf, 0x15, 0x85, 0xbf, 0x5f, 0x06, 0xa0, 0x12, 0xbe, 0x92, 0xc3, 0xe4, 0x19, 0xcc, 0x0c,
+	0x79, 0xe9, 0xb9, 0x9f, 0xe8, 0xc8, 0x55, 0x15, 0x79, 0xb7, 0x3c, 0xdc, 0x3b, 0xf7, 0x13, 0x8c,
+	0x6b, 0x43, 0x4b, 0xad, 0x12, 0x0f, 0x85, 0x44, 0xb0, 0x6c, 0xe5, 0x3a, 0x50, 0x6a, 0xfa, 0x19,
+	0xd8, 0x08, 0x90, 0x9a, 0x5a, 0x53, 0x9a, 0x59, 0x98, 0x5e, 0xb4, 0x9b, 0xc7, 0xd2, 0x29, 0x85,
+	0xd5, 0x54, 0xc3, 0x6e, 0x9a, 0x92, 0x67, 0xb0, 0x94, 0x43, 0x51, 0x63, 0xeb, 0x0a, 0xbf, 0x32,
+	0xbd, 0x68, 0x2f, 0x18, 0xbc, 0x12, 0xda, 0x82, 0x89, 0x91, 0x16, 0xf9, 0x39, 0xac, 0x62, 0x5c,
+	0x59, 0x73, 0x77, 0x55, 0x25, 0xcb, 0x6a, 0xe0, 0xa8, 0x10, 0x5e, 0x5e, 0x2f, 0xca, 0xef, 0x5e,
+	0xa9, 0xde, 0x2f, 0x95, 0x06, 0x7f, 0x0a, 0x18, 0xe3, 0x15, 0x4a, 0xbc, 0xaf, 0x40, 0x58, 0xdb,
+	0xb7, 0xb9, 0x1c, 0x77, 0x4c, 0xb5, 0xb9, 0x28, 0x1d, 0x7c, 0x25, 0xca, 0xdb, 0x45, 0x65, 0x3e,
+	0x86, 0xe5, 0x32, 0x48, 0xea, 0x73, 0x03, 0x5f, 0x7e, 0x8e, 0x92, 0x22, 0x7d, 0x54, 0xe2, 0x42,
+	0x2d, 0x6e, 0xce, 0xa0, 0x50, 0x8d, 0x4f, 0x81, 0xe4, 0xa8, 0x42, 0xb5, 0x0f, 0x4a, 0x13, 0xed,
+	0x16, 0xd2, 0xdd, 0x85, 0x35, 0x04, 0xcf, 0x0a, 0xf8, 0xa1, 0x42, 0x63, 0xbf, 0x0e, 0xcb, 0x2a,
+	0xce, 0x9b, 0x58, 0x46, 0x7f, 0x58, 0xe2, 0x7e, 0x5e, 0x60, 0xaf, 0x72, 0xab, 0x96, 0x6f, 0x5d,
+	0xc3, 0xad, 0x9a, 0x7e, 0x99, 0x5b, 0xa1, 0xdb, 0x57, 0xb8, 0x15, 0xf6, 0xa9, 0xc1, 0x96, 0xc5,
+	0xbe, 0xad, 0x3f, 0x7b, 0x72, 0xe0, 0xa4, 0xf0, 0x93, 0xdf, 0x98, 0xad, 0xe3, 0xa3, 0xdb, 0x36,
+	0x54, 0xd4, 0xfa, 0x8b, 0x48, 0xf0, 0x77, 0x66, 0xf7, 0xf8, 0x14, 0x2c, 0xa9, 0x72, 0xa7, 0x53,
+	0x25, 0x56, 0x85, 0x90, 0x2f, 0xf2, 0x2d, 0x61, 0xa7, 0x4a, 0xb0, 0xd9, 0x39, 0x7a, 0x00, 0xf8,
+	0xe4, 0x89, 0x20, 0x71, 0x1e, 0x55, 0xa0, 0xd8, 0x5f, 0x9c, 0x5e, 0xb4, 0xed, 0xdf, 0xab, 0xe0,
+	0xe3, 0x83, 0xae, 0x6b, 0x23, 0xcf, 0x71, 0x90, 0x74, 0x28, 0xb4, 0x4a, 0xc0, 0x62, 0xdf, 0xad,
+	0x95, 0xf6, 0xdd, 0xe2, 0x44, 0x30, 0x77, 0xcd, 0x89, 0xa0, 0x7e, 0xed, 0x89, 0xc0, 0x9a, 0x39,
+	0x11, 0x74, 0xfe, 0xb9, 0x08, 0x76, 0x7e, 0xb0, 0x22, 0x3e, 0x6c, 0xb2, 0xd8, 0x4b, 0x29, 0x3f,
+	0x63, 0x01, 0xf5, 0xfa, 0xef, 0x04, 0x4d, 0x3d, 0x4e, 0x83, 0x8c, 0xa7, 0xec, 0x8c, 0xea, 0x43,
+	0xe9, 0xa3, 0x5b, 0x4e, 0x68, 0xd8, 0x9b, 0xfb, 0x2c, 0xee, 0x21, 0xcd, 0xbe, 0x64, 0x71, 0x0d,
+	0x09, 0xf9, 0x23, 0xdc, 0x2d, 0x52, 0x0c, 0x4a, 0xec, 0x73, 0x15, 0xd8, 0xd7, 0x72, 0xf6, 0x41,
+	0xc1, 0x7c, 0x0c, 0x6b, 0x2c, 0xf6, 0xbe, 0xcb, 0x68, 0x36, 0xc3, 0x5b, 0xaf, 0xc0, 0xbb, 0xca,
+	0xe2, 0x6f, 0x54, 0x7c, 0xc1, 0xea, 0xc1, 0x46, 0xa9, 0x25, 0x72, 0x2f, 0x2e, 0x71, 0x5b, 0x15,
+	0xb8, 0xef, 0xe5, 0x35, 0xcb, 0xbd, 0xbb, 0x48, 0xf0, 0x27, 0xb8, 0xc7, 0x62, 0xef, 0xdc, 0x67,
+	0xe2, 0x32, 0xfb, 0x9d, 0x6a, 0x1d, 0xf9, 0xd6, 0x67, 0x62, 0x96, 0x1a, 0x3b, 0x32, 0xa6, 0x3c,
+	0x9c, 0xe9, 0xc8, 0x7c, 0xb5, 0x8e, 0x1c, 0xa9, 0xf8, 0x82, 0xb5, 0x0b, 0xab, 0x2c, 0xbe, 0x5c,
+	0x6b, 0xa3, 0x02, 0xe7, 0x32, 0x8b, 0x67, 0xeb, 0xfc, 0x06, 0x56, 0x53, 0x1a, 0x88, 0x98, 0x97,
+	0xd5, 0xd6, 0xac, 0xc0, 0xb8, 0xa2, 0xc3, 0x0b, 0xca, 0xaf, 0x61, 0xe5, 0xb2, 0x92, 0x1d, 0xbb,
+	0x02, 0xe3, 0xd2, 0xac, 0x7e, 0xc9, 0x0b, 0x68, 0x95, 0x64, 0xeb, 0x40, 0x05, 0x2a, 0x28, 0xc4,
+	0x4a, 0x9e, 0x83, 0x9d, 0x6b, 0xd4, 0x69, 0x55, 0x20, 0x69, 0x1a, 0x65, 0x92, 0xaf, 0x60, 0xf9,
+	0x92, 0x20, 0x9d, 0x85, 0x0a, 0x44, 0x8b, 0x33, 0x32, 0x24, 0x2f, 0x61, 0xa1, 0xac, 0x3e, 0x67,
+	0xb1, 0xda, 0xc4, 0x8c, 0xe6, 0xf4, 0xc4, 0x50, 0x6a, 0xce, 0x52, 0xb5, 0x89, 0xa1, 0xc0, 0xc8,
+	0x17, 0xd0, 0xd0, 0xba, 0x72, 0x96, 0x2b, 0x10, 0xcc, 0xa3, 0x9a, 0xc8, 0x6f, 0xa1, 0xa1, 0x55,
+	0xe0, 0xac, 0x54, 0x08, 0x37, 0x41, 0x72, 0xa1, 0x9b, 0xd3, 0xb5, 0x77, 0x45, 0x3a, 0xab, 0x55,
+	0x16, 0xba, 0xa1, 0x39, 0x9c, 0x95, 0xd0, 0x1f, 0x60, 0xfd, 0x9a, 0x04, 0x03, 0x87, 0x54, 0xe0,
+	0x26, 0x57, 0xb8, 0x07, 0x84, 0xc3, 0xce, 0x8d, 0x85, 0x97, 0xd6, 0xd3, 0x5a, 0x85, 0x34, 0xed,
+	0xeb, 0xa7, 0x50, 0x2c, 0x2f, 0x06, 0x5b, 0xd7, 0xcd, 0xa5, 0x94, 0x6e, 0xbd, 0x42, 0xba, 0x07,
+	0x57, 0x67, 0x95, 0xa7, 0xea, 0x9c, 0x01, 0x14, 0x50, 0xb2, 0x04, 0x73, 0x71, 0xa2, 0x36, 0x41,
+	0xdb, 0x9d, 0x8b, 0x13, 0x79, 0x9b, 0x1b, 0xc8, 0x03, 0x04, 0x6e, 0x81, 0xb6, 0xab, 0x2d, 0xb9,
+	0x33, 0x8e, 0xfd, 0xb7, 0xb1, 0xb9, 0xce, 0xa1, 0xa1, 0xbc, 0x2c, 0x8a, 0xb9, 0xde, 0x05, 0xd1,
+	0x90, 0xde, 0x33, 0x7f, 0x94, 0x51, 0x73, 0x7b, 0x51, 0x46, 0xe7, 0xaf, 0x35, 0x68, 0x9a, 0x1f,
+	0x07, 0x52, 0x9b, 0xc5, 0x85, 0xb8, 0xfe, 0xfe, 0xff, 0x14, 0x32, 0x48, 0x6b, 0x4b, 0xc7, 0xc8,
+	0x9f, 0x1c, 0xe6, 0xd6, 0xfc, 0xa3, 0x83, 0xf5, 0xd5, 0x9a, 0x82, 0x9d, 0xfb, 0x4a, 0xb3, 0xad,
+	0xcd, 0xcc, 0xb6, 0x0d, 0xad, 0x61, 0xe0, 0x7b, 0x43, 0x3f, 0x1a, 0x8c, 0x28, 0xde, 0xf5, 0x16,
+	0x5d, 0x18, 0x06, 0xfe, 0x2b, 0xf4, 0x18, 0x40, 0xdc, 0x7f, 0x4b, 0x03, 0x91, 0x3a, 0xf5, 0x1c,
+	0xf0, 0x1a, 0x3d, 0x9d, 0xbf, 0xcf, 0x41, 0xab, 0xf4, 0xaf, 0x43, 0xde, 0x86, 0x23, 0x7f, 0x6c,
+	0xf2, 0xa8, 0x67, 0x79, 0xf7, 0xe2, 0x13, 0xbd, 0x20, 0xf0, 0xc0, 0xd1, 0xe0, 0x13, 0xd4, 0xf6,
+	0x87, 0x00, 0x7c, 0xe2, 0x25, 0x7e, 0x70, 0x4a, 0x35, 0xbd, 0xe5, 0xda, 0x7c, 0xd2, 0x45, 0x07,
+	0x79, 0x00, 0x36, 0x9f, 0x78, 0x94, 0x73, 0xb9, 0x3a, 0xb1, 0xf7, 0x4d, 0x3e, 0x79, 0xa1, 0x6c,
+	0x1d, 0x3b, 0xe0, 0xb1, 0x3c, 0xd5, 0xeb, 0x77, 0x60, 0xf3, 0xc9, 0x97, 0xe8, 0x90, 0x59, 0x85,
+	0xc9, 0x8a, 0x97, 0xc8, 0x86, 0x28, 0xb2, 0x8a, 0x22, 0x2b, 0x5e, 0x22, 0x6d, 0x51, 0xce, 0x2a,
+	0xf2, 0xac, 0x78, 0x8f, 0x6c, 0x8a, 0x52, 0x56, 0x51, 0x64, 0x35, 0xbf, 0x5e, 0x4c, 0xd6, 0xce,
+	0xbf, 0x6a, 0xd0, 0x2a, 0xfd, 0xb5, 0x91, 0x0d, 0x8c, 0xb8, 0x97, 0x8e, 0x28, 0x4d, 0xe4, 0xcf,
+	0x09, 0x3c, 0x85, 0x41, 0xc4, 0x7b, 0xda, 0x23, 0xf9, 0x22, 0xee, 0xf1, 0x2c, 0x8a, 0xcc, 0xcf,
+	0x0b, 0xcb, 0xb5, 0x23, 0xee, 0xa2, 0x43, 0x0f, 0xa7, 0x02, 0xd3, 0xd5, 0xcd, 0x70, 0x0f, 0x1d,
+	0xe4, 0x17, 0x40, 0x22, 0xee, 0x65, 0x11, 0x8b, 0x04, 0xe5, 0x3c, 0x4b, 0x04, 0xeb, 0xe7, 0x17,
+	0xed, 0xd5, 0x88, 0x9f, 0xcc, 0x0e, 0x90, 0x87, 0x8a, 0x4d, 0x7f, 0xb8, 0x75, 0xcb, 0x9a, 0x11,
+	0x3f, 0x54, 0xdf, 0xe3, 0x7d, 0xe7, 0xfb, 0x1f, 0xb6, 0x3e, 0xf8, 0xcf, 0x0f, 0x5b, 0x1f, 0xfc,
+	0x65, 0xba, 0x55, 0xfb, 0x7e, 0xba, 0x55, 0xfb, 0xf7, 0x74, 0xab, 0xf6, 0xbf, 0xe9, 0x56, 0xad,
+	0x3f, 0xaf, 0x7e, 0x3a, 0xfe, 0xf2, 0xff, 0x03, 0x00, 0x47, 0x6b, 0x50, 0x95, 0xdc, 0x14, 0x00,
+	0x00,
 }
 
 func (m *Metrics) Marshal() (dAtA []byte, err error) {
@@ -887,6 +913,11 @@ func (m *Metrics) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n6
 	}
+	if m.Timestamp != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintMetrics(dAtA, i, uint64(m.Timestamp))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1511,6 +1542,160 @@ func (m *BlkIOStat) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.IoServiceBytes) > 0 {
+		for _, msg := range m.IoServiceBytes {
+			dAtA[i] = 0x4a
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.IoServiced) > 0 {
+		for _, msg := range m.IoServiced {
+			dAtA[i] = 0x52
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.IoQueued) > 0 {
+		for _, msg := range m.IoQueued {
+			dAtA[i] = 0x5a
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.IoServiceTime) > 0 {
+		for _, msg := range m.IoServiceTime {
+			dAtA[i] = 0x62
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.IoWaitTime) > 0 {
+		for _, msg := range m.IoWaitTime {
+			dAtA[i] = 0x6a
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.IoMerged) > 0 {
+		for _, msg := range m.IoMerged {
+			dAtA[i] = 0x72
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.IoTime) > 0 {
+		for _, msg := range m.IoTime {
+			dAtA[i] = 0x7a
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Sectors) > 0 {
+		for _, msg := range m.Sectors {
+			dAtA[i] = 0x82
+			i++
+			dAtA[i] = 0x1
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.ThrottleIoServiceBytes) > 0 {
+		for _, msg := range m.ThrottleIoServiceBytes {
+			dAtA[i] = 0x8a
+			i++
+			dAtA[i] = 0x1
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.ThrottleIoServiced) > 0 {
+		for _, msg := range m.ThrottleIoServiced {
+			dAtA[i] = 0x92
+			i++
+			dAtA[i] = 0x1
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.ThrottleIoServiceBytesRecursive) > 0 {
+		for _, msg := range m.ThrottleIoServiceBytesRecursive {
+			dAtA[i] = 0x9a
+			i++
+			dAtA[i] = 0x1
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.ThrottleIoServicedRecursive) > 0 {
+		for _, msg := range m.ThrottleIoServicedRecursive {
+			dAtA[i] = 0xa2
+			i++
+			dAtA[i] = 0x1
+			i++
+			i = encodeVarintMetrics(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1811,6 +1996,9 @@ func (m *Metrics) Size() (n int) {
 		l = m.CgroupStats.Size()
 		n += 1 + l + sovMetrics(uint64(l))
 	}
+	if m.Timestamp != 0 {
+		n += 1 + sovMetrics(uint64(m.Timestamp))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2131,6 +2319,78 @@ func (m *BlkIOStat) Size() (n int) {
 			n += 1 + l + sovMetrics(uint64(l))
 		}
 	}
+	if len(m.IoServiceBytes) > 0 {
+		for _, e := range m.IoServiceBytes {
+			l = e.Size()
+			n += 1 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.IoServiced) > 0 {
+		for _, e := range m.IoServiced {
+			l = e.Size()
+			n += 1 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.IoQueued) > 0 {
+		for _, e := range m.IoQueued {
+			l = e.Size()
+			n += 1 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.IoServiceTime) > 0 {
+		for _, e := range m.IoServiceTime {
+			l = e.Size()
+			n += 1 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.IoWaitTime) > 0 {
+		for _, e := range m.IoWaitTime {
+			l = e.Size()
+			n += 1 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.IoMerged) > 0 {
+		for _, e := range m.IoMerged {
+			l = e.Size()
+			n += 1 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.IoTime) > 0 {
+		for _, e := range m.IoTime {
+			l = e.Size()
+			n += 1 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.Sectors) > 0 {
+		for _, e := range m.Sectors {
+			l = e.Size()
+			n += 2 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.ThrottleIoServiceBytes) > 0 {
+		for _, e := range m.ThrottleIoServiceBytes {
+			l = e.Size()
+			n += 2 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.ThrottleIoServiced) > 0 {
+		for _, e := range m.ThrottleIoServiced {
+			l = e.Size()
+			n += 2 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.ThrottleIoServiceBytesRecursive) > 0 {
+		for _, e := range m.ThrottleIoServiceBytesRecursive {
+			l = e.Size()
+			n += 2 + l + sovMetrics(uint64(l))
+		}
+	}
+	if len(m.ThrottleIoServicedRecursive) > 0 {
+		for _, e := range m.ThrottleIoServicedRecursive {
+			l = e.Size()
+			n += 2 + l + sovMetrics(uint64(l))
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2305,6 +2565,7 @@ func (this *Metrics) String() string {
 		`Rdma:` + strings.Replace(fmt.Sprintf("%v", this.Rdma), "RdmaStat", "RdmaStat", 1) + `,`,
 		`Network:` + strings.Replace(fmt.Sprintf("%v", this.Network), "NetworkStat", "NetworkStat", 1) + `,`,
 		`CgroupStats:` + strings.Replace(fmt.Sprintf("%v", this.CgroupStats), "CgroupStats", "CgroupStats", 1) + `,`,
+		`Timestamp:` + fmt.Sprintf("%v", this.Timestamp) + `,`,
 		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
 		`}`,
 	}, "")
@@ -2448,6 +2709,18 @@ func (this *BlkIOStat) String() string {
 		`IoMergedRecursive:` + strings.Replace(fmt.Sprintf("%v", this.IoMergedRecursive), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
 		`IoTimeRecursive:` + strings.Replace(fmt.Sprintf("%v", this.IoTimeRecursive), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
 		`SectorsRecursive:` + strings.Replace(fmt.Sprintf("%v", this.SectorsRecursive), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`IoServiceBytes:` + strings.Replace(fmt.Sprintf("%v", this.IoServiceBytes), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`IoServiced:` + strings.Replace(fmt.Sprintf("%v", this.IoServiced), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`IoQueued:` + strings.Replace(fmt.Sprintf("%v", this.IoQueued), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`IoServiceTime:` + strings.Replace(fmt.Sprintf("%v", this.IoServiceTime), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`IoWaitTime:` + strings.Replace(fmt.Sprintf("%v", this.IoWaitTime), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`IoMerged:` + strings.Replace(fmt.Sprintf("%v", this.IoMerged), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`IoTime:` + strings.Replace(fmt.Sprintf("%v", this.IoTime), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`Sectors:` + strings.Replace(fmt.Sprintf("%v", this.Sectors), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`ThrottleIoServiceBytes:` + strings.Replace(fmt.Sprintf("%v", this.ThrottleIoServiceBytes), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`ThrottleIoServiced:` + strings.Replace(fmt.Sprintf("%v", this.ThrottleIoServiced), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`ThrottleIoServiceBytesRecursive:` + strings.Replace(fmt.Sprintf("%v", this.ThrottleIoServiceBytesRecursive), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
+		`ThrottleIoServicedRecursive:` + strings.Replace(fmt.Sprintf("%v", this.ThrottleIoServicedRecursive), "BlkIOEntry", "BlkIOEntry", 1) + `,`,
 		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
 		`}`,
 	}, "")
@@ -2848,6 +3121,25 @@ func (m *Metrics) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetrics(dAtA[iNdEx:])
@@ -4769,6 +5061,414 @@ func (m *BlkIOStat) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IoServiceBytes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IoServiceBytes = append(m.IoServiceBytes, &BlkIOEntry{})
+			if err := m.IoServiceBytes[len(m.IoServiceBytes)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IoServiced", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IoServiced = append(m.IoServiced, &BlkIOEntry{})
+			if err := m.IoServiced[len(m.IoServiced)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IoQueued", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IoQueued = append(m.IoQueued, &BlkIOEntry{})
+			if err := m.IoQueued[len(m.IoQueued)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IoServiceTime", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IoServiceTime = append(m.IoServiceTime, &BlkIOEntry{})
+			if err := m.IoServiceTime[len(m.IoServiceTime)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IoWaitTime", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IoWaitTime = append(m.IoWaitTime, &BlkIOEntry{})
+			if err := m.IoWaitTime[len(m.IoWaitTime)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IoMerged", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IoMerged = append(m.IoMerged, &BlkIOEntry{})
+			if err := m.IoMerged[len(m.IoMerged)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IoTime", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IoTime = append(m.IoTime, &BlkIOEntry{})
+			if err := m.IoTime[len(m.IoTime)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sectors", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Sectors = append(m.Sectors, &BlkIOEntry{})
+			if err := m.Sectors[len(m.Sectors)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 17:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThrottleIoServiceBytes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ThrottleIoServiceBytes = append(m.ThrottleIoServiceBytes, &BlkIOEntry{})
+			if err := m.ThrottleIoServiceBytes[len(m.ThrottleIoServiceBytes)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 18:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThrottleIoServiced", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ThrottleIoServiced = append(m.ThrottleIoServiced, &BlkIOEntry{})
+			if err := m.ThrottleIoServiced[len(m.ThrottleIoServiced)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 19:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThrottleIoServiceBytesRecursive", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ThrottleIoServiceBytesRecursive = append(m.ThrottleIoServiceBytesRecursive, &BlkIOEntry{})
+			if err := m.ThrottleIoServiceBytesRecursive[len(m.ThrottleIoServiceBytesRecursive)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 20:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ThrottleIoServicedRecursive", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthMetrics
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ThrottleIoServicedRecursive = append(m.ThrottleIoServicedRecursive, &BlkIOEntry{})
+			if err := m.ThrottleIoServicedRecursive[len(m.ThrottleIoServicedRecursive)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetrics(dAtA[iNdEx:])
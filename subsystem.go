@@ -19,6 +19,7 @@ package cgroups
 import (
 	"fmt"
 
+	"github.com/containerd/cgroups/clock"
 	v1 "github.com/containerd/cgroups/stats/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 )
@@ -84,11 +85,31 @@ type deleter interface {
 	Delete(path string) error
 }
 
+// pacedDeleter is a deleter whose waits are paced by the cgroup's pacer
+type pacedDeleter interface {
+	deleter
+	deletePaced(path string, pacer clock.Clock) error
+}
+
 type stater interface {
 	Subsystem
 	Stat(path string, stats *v1.Metrics) error
 }
 
+// attachPreparer is implemented by subsystems that must prepare a cgroup
+// before processes can be attached to it
+type attachPreparer interface {
+	Subsystem
+	prepareAttach(path string) error
+}
+
+// accountingEnabler is implemented by subsystems with accounting that is
+// not enabled by default
+type accountingEnabler interface {
+	Subsystem
+	enableAccounting(path string) error
+}
+
 type updater interface {
 	Subsystem
 	Update(path string, resources *specs.LinuxResources) error
@@ -0,0 +1,91 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// Swap exchanges the processes of cgroups a and b. Both cgroups are frozen
+// while the processes are migrated, so that none of them run or fork in the
+// wrong cgroup halfway through, and are thawed again once the swap completes
+// or fails. If a process cannot be moved the processes already moved are
+// moved back before the error is returned. Only the processes directly in
+// each cgroup are swapped, descendant cgroups stay where they are.
+// ErrFreezerNotSupported is returned when the freezer subsystem is not
+// available.
+func Swap(a, b Cgroup) (err error) {
+	if err := a.Freeze(); err != nil {
+		return err
+	}
+	defer func() {
+		if terr := a.Thaw(); err == nil {
+			err = terr
+		}
+	}()
+	if err := b.Freeze(); err != nil {
+		return err
+	}
+	defer func() {
+		if terr := b.Thaw(); err == nil {
+			err = terr
+		}
+	}()
+	ours, err := a.Processes(Freezer, false)
+	if err != nil {
+		return err
+	}
+	theirs, err := b.Processes(Freezer, false)
+	if err != nil {
+		return err
+	}
+	if moved, err := migrate(ours, b); err != nil {
+		return rollback(err, moved, a)
+	}
+	if moved, err := migrate(theirs, a); err != nil {
+		return rollback(rollback(err, moved, b), ours, a)
+	}
+	return nil
+}
+
+// migrate adds processes to c, skipping processes that have exited. It
+// returns the processes that were moved.
+func migrate(processes []Process, c Cgroup) ([]Process, error) {
+	var moved []Process
+	for _, p := range processes {
+		if err := c.Add(p); err != nil {
+			if errors.Is(err, unix.ESRCH) {
+				continue
+			}
+			// a process that failed may have been added to some of the
+			// subsystems already
+			return append(moved, p), err
+		}
+		moved = append(moved, p)
+	}
+	return moved, nil
+}
+
+// rollback moves the processes back to c after the swap failed with err,
+// returning err along with any failure to move them back
+func rollback(err error, processes []Process, c Cgroup) error {
+	if _, rerr := migrate(processes, c); rerr != nil {
+		return errors.Wrapf(err, "cgroups: unable to roll back the swap: %v", rerr)
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestSwap(t *testing.T) {
+	mock, err := newMock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.delete()
+	blue, err := New(mock.hierarchy, StaticPath("blue"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	green, err := New(mock.hierarchy, StaticPath("green"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := blue.Add(Process{Pid: 1234}); err != nil {
+		t.Fatal(err)
+	}
+	if err := green.Add(Process{Pid: 5678}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Swap(blue, green); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range Subsystems() {
+		if err := checkPid(mock, filepath.Join(string(s), "blue"), 5678); err != nil {
+			t.Fatal(err)
+		}
+		if err := checkPid(mock, filepath.Join(string(s), "green"), 1234); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []Cgroup{blue, green} {
+		if state := c.State(); state != Thawed {
+			t.Fatalf("expected %q but received %q", Thawed, state)
+		}
+	}
+}
+
+// swapCgroup records the processes of a cgroup for the swap, refusing to add
+// the processes in refuse
+type swapCgroup struct {
+	Cgroup
+	procs  []Process
+	refuse map[int]bool
+}
+
+var errRefused = errors.New("refused")
+
+func (c *swapCgroup) Freeze() error { return nil }
+func (c *swapCgroup) Thaw() error   { return nil }
+
+func (c *swapCgroup) Processes(Name, bool) ([]Process, error) {
+	return append([]Process(nil), c.procs...), nil
+}
+
+func (c *swapCgroup) Add(p Process) error {
+	if c.refuse[p.Pid] {
+		return errRefused
+	}
+	c.procs = append(c.procs, p)
+	return nil
+}
+
+func TestSwapRollback(t *testing.T) {
+	blue := &swapCgroup{procs: []Process{{Pid: 1}, {Pid: 2}}}
+	green := &swapCgroup{procs: []Process{{Pid: 3}, {Pid: 4}}}
+	// moving the second process of green fails after the processes of blue
+	// and the first of green were moved
+	blue.refuse = map[int]bool{4: true}
+	if err := Swap(blue, green); err != errRefused {
+		t.Fatalf("expected the refused add to be returned, got %v", err)
+	}
+	// the mock keeps the processes it had, the rollback adds the moved
+	// processes back to their cgroup, including the one that failed as it
+	// may be in some of the subsystems already
+	if expected := []Process{{Pid: 1}, {Pid: 2}, {Pid: 3}, {Pid: 1}, {Pid: 2}}; !reflect.DeepEqual(blue.procs, expected) {
+		t.Fatalf("expected blue to receive %v, got %v", expected, blue.procs)
+	}
+	if expected := []Process{{Pid: 3}, {Pid: 4}, {Pid: 1}, {Pid: 2}, {Pid: 3}, {Pid: 4}}; !reflect.DeepEqual(green.procs, expected) {
+		t.Fatalf("expected green to receive %v, got %v", expected, green.procs)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/containerd/cgroups/clock"
+	"github.com/containerd/cgroups/internal/cgfs"
 	systemdDbus "github.com/coreos/go-systemd/v22/dbus"
 	"github.com/godbus/dbus/v5"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
@@ -120,13 +122,18 @@ func (s *SystemdController) Create(path string, _ *specs.LinuxResources) error {
 	ch := make(chan string)
 	_, err = conn.StartTransientUnit(name, "replace", properties, ch)
 	if err != nil {
-		return err
+		return cgfs.UnitError(err)
 	}
-	<-ch
-	return nil
+	return cgfs.JobResult(name, <-ch)
 }
 
 func (s *SystemdController) Delete(path string) error {
+	return s.deletePaced(path, clock.Real)
+}
+
+// deletePaced stops the unit and waits for systemd to remove it, polling on
+// pacer
+func (s *SystemdController) deletePaced(path string, pacer clock.Clock) error {
 	conn, err := systemdDbus.New()
 	if err != nil {
 		return err
@@ -136,10 +143,12 @@ func (s *SystemdController) Delete(path string) error {
 	ch := make(chan string)
 	_, err = conn.StopUnit(name, "replace", ch)
 	if err != nil {
+		return cgfs.UnitError(err)
+	}
+	if err := cgfs.JobResult(name, <-ch); err != nil {
 		return err
 	}
-	<-ch
-	return nil
+	return cgfs.WaitUnitRemoved(conn, name, cgfs.UnitRemoveTimeout, pacer)
 }
 
 func newProperty(name string, units interface{}) systemdDbus.Property {
@@ -0,0 +1,236 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxID bounds the ids accepted by ParseCPUSet so a malformed range cannot
+// allocate unbounded memory, it is well above the kernel's NR_CPUS limit
+const maxID = 1 << 16
+
+// CPUSet is a sorted set of cpu (or NUMA node) ids in the kernel's list format
+// as used by cpuset.cpus, cpuset.mems and sysfs, e.g. "0-3,8,10-11".
+// The sets returned by the package are sorted without duplicates, sets built
+// as plain slices are normalized by each operation.
+type CPUSet []int
+
+// NewCPUSet returns a set of the provided ids
+func NewCPUSet(ids ...int) CPUSet {
+	s := make(CPUSet, 0, len(ids))
+	s = append(s, ids...)
+	return s.normalize()
+}
+
+// ParseCPUSet parses a list such as "0-3,8,10-11". Ranges may select a number
+// of ids out of every group of ids like the kernel's "0-10:2/4", which is
+// 0-1,4-5,8-9. Whitespace around the list and its entries is ignored and an
+// empty string is an empty set.
+func ParseCPUSet(list string) (CPUSet, error) {
+	var out CPUSet
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return out, nil
+	}
+	for _, r := range strings.Split(list, ",") {
+		r = strings.TrimSpace(r)
+		used, group := 1, 1
+		bounds := strings.SplitN(r, "-", 2)
+		if len(bounds) == 2 {
+			var err error
+			if bounds[1], used, group, err = parseStride(bounds[1]); err != nil {
+				return nil, fmt.Errorf("topology: invalid list entry %q: %v", r, err)
+			}
+		}
+		start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("topology: invalid list entry %q: %v", r, err)
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, fmt.Errorf("topology: invalid list entry %q: %v", r, err)
+			}
+		}
+		if start < 0 || end < start || end > maxID {
+			return nil, fmt.Errorf("topology: invalid list entry %q", r)
+		}
+		for i := start; i <= end; i += group {
+			for j := i; j < i+used && j <= end; j++ {
+				out = append(out, j)
+			}
+		}
+	}
+	return out.normalize(), nil
+}
+
+// parseStride splits the ":used/group" suffix off the end of a range,
+// returning 1/1 when there is none
+func parseStride(end string) (string, int, int, error) {
+	parts := strings.SplitN(end, ":", 2)
+	if len(parts) == 1 {
+		return end, 1, 1, nil
+	}
+	sizes := strings.SplitN(parts[1], "/", 2)
+	if len(sizes) != 2 {
+		return "", 0, 0, fmt.Errorf("missing group size")
+	}
+	used, err := strconv.Atoi(strings.TrimSpace(sizes[0]))
+	if err != nil {
+		return "", 0, 0, err
+	}
+	group, err := strconv.Atoi(strings.TrimSpace(sizes[1]))
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if used <= 0 || group <= 0 || used > group {
+		return "", 0, 0, fmt.Errorf("invalid stride %d/%d", used, group)
+	}
+	return parts[0], used, group, nil
+}
+
+// MustParseCPUSet is like ParseCPUSet but panics if the list is invalid
+func MustParseCPUSet(list string) CPUSet {
+	s, err := ParseCPUSet(list)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String formats the set in the kernel's list format, collapsing
+// consecutive ids into ranges
+func (s CPUSet) String() string {
+	s = s.sorted()
+	var parts []string
+	for i := 0; i < len(s); {
+		j := i
+		for j+1 < len(s) && s[j+1] == s[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(s[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", s[i], s[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// Len returns the number of ids in the set
+func (s CPUSet) Len() int {
+	return len(s.sorted())
+}
+
+// IsEmpty returns true if the set has no ids
+func (s CPUSet) IsEmpty() bool {
+	return len(s) == 0
+}
+
+// Contains returns true if id is in the set
+func (s CPUSet) Contains(id int) bool {
+	s = s.sorted()
+	i := sort.SearchInts(s, id)
+	return i < len(s) && s[i] == id
+}
+
+// Equal returns true if both sets have the same ids
+func (s CPUSet) Equal(o CPUSet) bool {
+	s, o = s.sorted(), o.sorted()
+	if len(s) != len(o) {
+		return false
+	}
+	for i := range s {
+		if s[i] != o[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns the ids in either set
+func (s CPUSet) Union(o CPUSet) CPUSet {
+	out := make(CPUSet, 0, len(s)+len(o))
+	out = append(out, s...)
+	out = append(out, o...)
+	return out.normalize()
+}
+
+// Intersect returns the ids in both sets
+func (s CPUSet) Intersect(o CPUSet) CPUSet {
+	s, o = s.sorted(), o.sorted()
+	var out CPUSet
+	for i, j := 0, 0; i < len(s) && j < len(o); {
+		switch {
+		case s[i] < o[j]:
+			i++
+		case s[i] > o[j]:
+			j++
+		default:
+			out = append(out, s[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// Subtract returns the ids of s that are not in o
+func (s CPUSet) Subtract(o CPUSet) CPUSet {
+	s, o = s.sorted(), o.sorted()
+	var out CPUSet
+	j := 0
+	for _, id := range s {
+		for j < len(o) && o[j] < id {
+			j++
+		}
+		if j < len(o) && o[j] == id {
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+// sorted returns s if it is sorted without duplicates, or a normalized copy
+// so that the caller's slice is left alone
+func (s CPUSet) sorted() CPUSet {
+	for i := 1; i < len(s); i++ {
+		if s[i] <= s[i-1] {
+			return append(CPUSet(nil), s...).normalize()
+		}
+	}
+	return s
+}
+
+// normalize sorts the set and removes duplicate ids
+func (s CPUSet) normalize() CPUSet {
+	sort.Ints(s)
+	out := s[:0]
+	for i, id := range s {
+		if i > 0 && id == s[i-1] {
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package topology
+
+import "testing"
+
+func TestParseCPUSet(t *testing.T) {
+	for _, tc := range []struct {
+		list     string
+		expected string
+		err      bool
+	}{
+		{list: "", expected: ""},
+		{list: "0-3,8,10-11\n", expected: "0-3,8,10-11"},
+		{list: "3, 1 ,2,2", expected: "1-3"},
+		{list: "7-7", expected: "7"},
+		{list: "3-1", err: true},
+		{list: "-1", err: true},
+		{list: "a", err: true},
+		{list: "0-1000000000", err: true},
+		{list: "0-10:2/4", expected: "0-1,4-5,8-9"},
+		{list: "0-7:1/2,1", expected: "0-2,4,6"},
+		{list: "0-9:3/3", expected: "0-9"},
+		{list: "0-10:4/2", err: true},
+		{list: "0-10:0/2", err: true},
+		{list: "0-10:2", err: true},
+		{list: "3:1/2", err: true},
+	} {
+		s, err := ParseCPUSet(tc.list)
+		if tc.err {
+			if err == nil {
+				t.Errorf("expected error parsing %q", tc.list)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", tc.list, err)
+			continue
+		}
+		if s.String() != tc.expected {
+			t.Errorf("expected %q for %q but received %q", tc.expected, tc.list, s.String())
+		}
+	}
+}
+
+func TestCPUSetOperations(t *testing.T) {
+	var (
+		a = MustParseCPUSet("0-7")
+		b = MustParseCPUSet("4-11")
+	)
+	for _, tc := range []struct {
+		name     string
+		result   CPUSet
+		expected string
+	}{
+		{"union", a.Union(b), "0-11"},
+		{"intersect", a.Intersect(b), "4-7"},
+		{"subtract", a.Subtract(b), "0-3"},
+		{"subtract all", a.Subtract(a), ""},
+	} {
+		if tc.result.String() != tc.expected {
+			t.Errorf("%s: expected %q but received %q", tc.name, tc.expected, tc.result.String())
+		}
+	}
+	if !a.Contains(7) || a.Contains(8) {
+		t.Error("unexpected Contains result")
+	}
+	if !NewCPUSet(3, 1, 2).Equal(MustParseCPUSet("1-3")) {
+		t.Error("expected sets to be equal")
+	}
+}
+
+func TestCPUSetUnsorted(t *testing.T) {
+	var (
+		a = CPUSet{7, 3, 5, 3, 1}
+		b = CPUSet{5, 1, 9}
+	)
+	for _, tc := range []struct {
+		name     string
+		result   CPUSet
+		expected string
+	}{
+		{"string", a, "1,3,5,7"},
+		{"union", a.Union(b), "1,3,5,7,9"},
+		{"intersect", a.Intersect(b), "1,5"},
+		{"subtract", a.Subtract(b), "3,7"},
+	} {
+		if tc.result.String() != tc.expected {
+			t.Errorf("%s: expected %q but received %q", tc.name, tc.expected, tc.result.String())
+		}
+	}
+	if !a.Contains(1) || !a.Contains(7) || a.Contains(2) {
+		t.Error("unexpected Contains result")
+	}
+	if a.Len() != 4 || !a.Equal(NewCPUSet(1, 3, 5, 7)) {
+		t.Errorf("expected %v to equal 1,3,5,7", a)
+	}
+	if a[0] != 7 || a[3] != 3 {
+		t.Errorf("expected the operations to leave the slice alone but it is %v", []int(a))
+	}
+}
@@ -0,0 +1,135 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package topology
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+)
+
+// DefaultHotplugInterval is how often WatchOnline checks the online cpus and
+// NUMA nodes
+const DefaultHotplugInterval = time.Second
+
+// HotplugEvent is a change of the online cpus or NUMA nodes
+type HotplugEvent struct {
+	// Online are the cpus online after the change
+	Online CPUSet
+	// Added are the cpus that came online
+	Added CPUSet
+	// Removed are the cpus that went offline
+	Removed CPUSet
+	// Nodes are the NUMA nodes online after the change
+	Nodes CPUSet
+	// AddedNodes are the NUMA nodes that came online
+	AddedNodes CPUSet
+	// RemovedNodes are the NUMA nodes that went offline
+	RemovedNodes CPUSet
+}
+
+// WatchOpts configures WatchOnline
+type WatchOpts func(*watchConfig)
+
+type watchConfig struct {
+	clock clock.Clock
+}
+
+// WithWatchClock sets the clock pacing the polling of WatchOnline
+func WithWatchClock(c clock.Clock) WatchOpts {
+	return func(config *watchConfig) {
+		config.clock = c
+	}
+}
+
+// WatchOnline sends an event every time cpus or NUMA nodes go online or
+// offline until ctx is done. Both are polled every interval,
+// DefaultHotplugInterval when it is zero. An error reading them is sent on
+// the error channel and ends the watch, both channels are closed when the
+// watch ends.
+func WatchOnline(ctx context.Context, interval time.Duration, opts ...WatchOpts) (<-chan HotplugEvent, <-chan error) {
+	return WatchOnlineFrom(ctx, defaultRoot, interval, opts...)
+}
+
+// WatchOnlineFrom watches the online cpus and NUMA nodes below root, which
+// is usually /sys/devices/system, see WatchOnline
+func WatchOnlineFrom(ctx context.Context, root string, interval time.Duration, opts ...WatchOpts) (<-chan HotplugEvent, <-chan error) {
+	if interval <= 0 {
+		interval = DefaultHotplugInterval
+	}
+	var config watchConfig
+	for _, o := range opts {
+		o(&config)
+	}
+	var (
+		events = make(chan HotplugEvent)
+		errCh  = make(chan error, 1)
+		path   = filepath.Join(root, "cpu", "online")
+	)
+	go func() {
+		defer close(events)
+		defer close(errCh)
+		online, err := readList(path)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		nodes, err := OnlineNodesFrom(root)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		ticker := clock.NewTicker(config.clock, interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.Next():
+			}
+			current, err := readList(path)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			currentNodes, err := OnlineNodesFrom(root)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if current.Equal(online) && currentNodes.Equal(nodes) {
+				continue
+			}
+			e := HotplugEvent{
+				Online:       current,
+				Added:        current.Subtract(online),
+				Removed:      online.Subtract(current),
+				Nodes:        currentNodes,
+				AddedNodes:   currentNodes.Subtract(nodes),
+				RemovedNodes: nodes.Subtract(currentNodes),
+			}
+			online, nodes = current, currentNodes
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, errCh
+}
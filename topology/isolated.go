@@ -0,0 +1,112 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package topology
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// cmdlinePath is read when the kernel does not expose the isolated
+// and nohz_full cpus in sysfs
+var cmdlinePath = "/proc/cmdline"
+
+// readIsolated returns the cpus isolated from the scheduler through isolcpus
+// and the cpus running without the scheduler tick through nohz_full
+func readIsolated(root string) (isolated, nohzFull CPUSet, err error) {
+	isolated, isolatedErr := readList(filepath.Join(root, "cpu", "isolated"))
+	nohzFull, nohzErr := readNohzFull(filepath.Join(root, "cpu", "nohz_full"))
+	if isolatedErr == nil && nohzErr == nil {
+		return isolated, nohzFull, nil
+	}
+	for _, err := range []error{isolatedErr, nohzErr} {
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+	}
+	data, err := ioutil.ReadFile(cmdlinePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return isolated, nohzFull, nil
+		}
+		return nil, nil, err
+	}
+	cmdIsolated, cmdNohzFull, err := parseCmdline(string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	if isolatedErr != nil {
+		isolated = cmdIsolated
+	}
+	if nohzErr != nil {
+		nohzFull = cmdNohzFull
+	}
+	return isolated, nohzFull, nil
+}
+
+// readNohzFull reads the nohz_full cpus, older kernels report "(null)"
+// when nohz_full is not configured
+func readNohzFull(path string) (CPUSet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if s := strings.TrimSpace(string(data)); s != "(null)" {
+		return ParseCPUSet(s)
+	}
+	return nil, nil
+}
+
+// parseCmdline parses the isolcpus and nohz_full kernel parameters.
+// isolcpus may be prefixed with flags, e.g. "isolcpus=nohz,domain,2-3".
+func parseCmdline(cmdline string) (isolated, nohzFull CPUSet, err error) {
+	for _, param := range strings.Fields(cmdline) {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "isolcpus":
+			var list []string
+			for _, v := range strings.Split(kv[1], ",") {
+				if v != "" && (v[0] < '0' || v[0] > '9') {
+					// skip flags such as nohz, domain and managed_irq
+					continue
+				}
+				list = append(list, v)
+			}
+			if isolated, err = ParseCPUSet(strings.Join(list, ",")); err != nil {
+				return nil, nil, errors.Wrap(err, "parse isolcpus")
+			}
+		case "nohz_full":
+			if nohzFull, err = ParseCPUSet(kv[1]); err != nil {
+				return nil, nil, errors.Wrap(err, "parse nohz_full")
+			}
+		}
+	}
+	return isolated, nohzFull, nil
+}
+
+// Housekeeping returns the online cpus that are neither isolated nor
+// running in nohz_full mode, the cpus general purpose workloads should use
+func (t *Topology) Housekeeping() CPUSet {
+	return t.Online().Subtract(t.Isolated).Subtract(t.NohzFull)
+}
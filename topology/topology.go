@@ -0,0 +1,231 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package topology reads the cpu and NUMA topology of the host from sysfs
+// to help build cpuset.cpus and cpuset.mems values.
+package topology
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const defaultRoot = "/sys/devices/system"
+
+// CPU is a single online logical cpu
+type CPU struct {
+	ID int
+	// Node is the NUMA node the cpu belongs to
+	Node int
+	// Package is the physical package (socket) id
+	Package int
+	// Core is the core id within the package
+	Core int
+	// Siblings are the hardware threads sharing the core, including the cpu itself
+	Siblings CPUSet
+}
+
+// Node is a NUMA node with online cpus
+type Node struct {
+	ID   int
+	CPUs CPUSet
+}
+
+// Topology of the online cpus and NUMA nodes of the host
+type Topology struct {
+	CPUs  []CPU
+	Nodes []Node
+	// Isolated are the cpus removed from scheduler balancing with isolcpus
+	Isolated CPUSet
+	// NohzFull are the cpus running without the scheduler tick with nohz_full
+	NohzFull CPUSet
+}
+
+// Load reads the topology of the host
+func Load() (*Topology, error) {
+	return LoadFrom(defaultRoot)
+}
+
+// LoadFrom reads the topology from root, which is usually /sys/devices/system
+func LoadFrom(root string) (*Topology, error) {
+	online, err := readList(filepath.Join(root, "cpu", "online"))
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := readNodes(root, online)
+	if err != nil {
+		return nil, err
+	}
+	cpuNode := make(map[int]int)
+	for _, n := range nodes {
+		for _, c := range n.CPUs {
+			cpuNode[c] = n.ID
+		}
+	}
+	isolated, nohzFull, err := readIsolated(root)
+	if err != nil {
+		return nil, err
+	}
+	t := &Topology{
+		Nodes:    nodes,
+		Isolated: isolated,
+		NohzFull: nohzFull,
+	}
+	for _, id := range online {
+		cpu := CPU{
+			ID:   id,
+			Node: cpuNode[id],
+		}
+		dir := filepath.Join(root, "cpu", "cpu"+strconv.Itoa(id), "topology")
+		if cpu.Core, err = readInt(filepath.Join(dir, "core_id")); err != nil {
+			return nil, err
+		}
+		if cpu.Package, err = readInt(filepath.Join(dir, "physical_package_id")); err != nil {
+			return nil, err
+		}
+		if cpu.Siblings, err = readList(filepath.Join(dir, "thread_siblings_list")); err != nil {
+			return nil, err
+		}
+		if len(cpu.Siblings) == 0 {
+			cpu.Siblings = NewCPUSet(id)
+		}
+		t.CPUs = append(t.CPUs, cpu)
+	}
+	return t, nil
+}
+
+// OnlineNodes returns the ids of the online NUMA nodes of the host
+func OnlineNodes() (CPUSet, error) {
+	return OnlineNodesFrom(defaultRoot)
+}
+
+// OnlineNodesFrom returns the ids of the online NUMA nodes below root, which
+// is usually /sys/devices/system. Hosts without NUMA support have the single
+// node 0.
+func OnlineNodesFrom(root string) (CPUSet, error) {
+	ids, err := readList(filepath.Join(root, "node", "online"))
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return NewCPUSet(0), nil
+		}
+		return nil, err
+	}
+	return ids, nil
+}
+
+// readNodes returns the online NUMA nodes, or a single node 0 with all
+// online cpus on hosts without NUMA support
+func readNodes(root string, online CPUSet) ([]Node, error) {
+	ids, err := readList(filepath.Join(root, "node", "online"))
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return []Node{{ID: 0, CPUs: online}}, nil
+		}
+		return nil, err
+	}
+	var nodes []Node
+	for _, id := range ids {
+		cpus, err := readList(filepath.Join(root, "node", "node"+strconv.Itoa(id), "cpulist"))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, Node{
+			ID:   id,
+			CPUs: cpus.Intersect(online),
+		})
+	}
+	return nodes, nil
+}
+
+// Online returns all online cpus
+func (t *Topology) Online() CPUSet {
+	out := make(CPUSet, len(t.CPUs))
+	for i, c := range t.CPUs {
+		out[i] = c.ID
+	}
+	return out
+}
+
+// NodeCPUs returns the online cpus of a NUMA node
+func (t *Topology) NodeCPUs(node int) (CPUSet, error) {
+	for _, n := range t.Nodes {
+		if n.ID == node {
+			return NewCPUSet(n.CPUs...), nil
+		}
+	}
+	return nil, errors.Errorf("topology: numa node %d is not online", node)
+}
+
+// PrimaryThreads filters cpus down to a single hardware thread per core,
+// removing SMT siblings. The lowest numbered thread of each core is kept.
+func (t *Topology) PrimaryThreads(cpus CPUSet) CPUSet {
+	siblings := make(map[int]CPUSet)
+	for _, c := range t.CPUs {
+		siblings[c.ID] = c.Siblings
+	}
+	var (
+		out  CPUSet
+		seen = make(map[int]struct{})
+	)
+	for _, id := range cpus {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		out = append(out, id)
+		for _, s := range siblings[id] {
+			seen[s] = struct{}{}
+		}
+	}
+	return out
+}
+
+// NodeCpuset returns the cpuset.cpus value selecting the cpus of a NUMA node,
+// optionally excluding SMT siblings so only one thread per core is used
+func (t *Topology) NodeCpuset(node int, excludeSiblings bool) (string, error) {
+	cpus, err := t.NodeCPUs(node)
+	if err != nil {
+		return "", err
+	}
+	if excludeSiblings {
+		cpus = t.PrimaryThreads(cpus)
+	}
+	return cpus.String(), nil
+}
+
+func readList(path string) (CPUSet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := ParseCPUSet(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", path)
+	}
+	return ids, nil
+}
+
+func readInt(path string) (int, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
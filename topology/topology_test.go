@@ -0,0 +1,205 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package topology
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newFakeSysfs creates two NUMA nodes with two cores of two threads each,
+// threads of a core are numbered n and n+4
+func newFakeSysfs(t *testing.T) string {
+	root, err := ioutil.TempDir("", "topology")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"cpu/online":         "0-7",
+		"node/online":        "0-1",
+		"node/node0/cpulist": "0-1,4-5",
+		"node/node1/cpulist": "2-3,6-7",
+	}
+	for i := 0; i < 8; i++ {
+		core := i % 4
+		dir := fmt.Sprintf("cpu/cpu%d/topology/", i)
+		files[dir+"core_id"] = fmt.Sprint(core % 2)
+		files[dir+"physical_package_id"] = fmt.Sprint(core / 2)
+		files[dir+"thread_siblings_list"] = fmt.Sprintf("%d,%d", core, core+4)
+	}
+	for name, value := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLoadFrom(t *testing.T) {
+	root := newFakeSysfs(t)
+	defer os.RemoveAll(root)
+
+	topo, err := LoadFrom(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topo.CPUs) != 8 || len(topo.Nodes) != 2 {
+		t.Fatalf("expected 8 cpus and 2 nodes but received %d and %d", len(topo.CPUs), len(topo.Nodes))
+	}
+	if cpu := topo.CPUs[6]; cpu.Node != 1 || cpu.Package != 1 || !cpu.Siblings.Equal(NewCPUSet(2, 6)) {
+		t.Errorf("unexpected topology for cpu 6: %+v", cpu)
+	}
+	for _, tc := range []struct {
+		node            int
+		excludeSiblings bool
+		expected        string
+	}{
+		{0, false, "0-1,4-5"},
+		{0, true, "0-1"},
+		{1, true, "2-3"},
+	} {
+		cpuset, err := topo.NodeCpuset(tc.node, tc.excludeSiblings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cpuset != tc.expected {
+			t.Errorf("expected cpuset %q for node %d but received %q", tc.expected, tc.node, cpuset)
+		}
+	}
+	if _, err := topo.NodeCpuset(2, false); err == nil {
+		t.Error("expected error for offline node")
+	}
+}
+
+func TestLoadFromWithoutNUMA(t *testing.T) {
+	root := newFakeSysfs(t)
+	defer os.RemoveAll(root)
+	if err := os.RemoveAll(filepath.Join(root, "node")); err != nil {
+		t.Fatal(err)
+	}
+	topo, err := LoadFrom(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topo.Nodes) != 1 || len(topo.Nodes[0].CPUs) != 8 {
+		t.Fatalf("expected a single node with all cpus but received %+v", topo.Nodes)
+	}
+}
+
+func TestIsolated(t *testing.T) {
+	root := newFakeSysfs(t)
+	defer os.RemoveAll(root)
+	cmdline := filepath.Join(root, "cmdline")
+	if err := ioutil.WriteFile(cmdline, []byte("ro quiet isolcpus=managed_irq,domain,6-7 nohz_full=5-7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(p string) { cmdlinePath = p }(cmdlinePath)
+	cmdlinePath = cmdline
+
+	topo, err := LoadFrom(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topo.Isolated.String() != "6-7" || topo.NohzFull.String() != "5-7" {
+		t.Fatalf("unexpected isolated %q and nohz_full %q", topo.Isolated, topo.NohzFull)
+	}
+
+	// sysfs takes precedence over the kernel command line
+	if err := ioutil.WriteFile(filepath.Join(root, "cpu", "isolated"), []byte("7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "cpu", "nohz_full"), []byte("(null)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if topo, err = LoadFrom(root); err != nil {
+		t.Fatal(err)
+	}
+	if topo.Housekeeping().String() != "0-6" {
+		t.Errorf("expected housekeeping cpus 0-6 but received %q", topo.Housekeeping())
+	}
+}
+
+func TestWatchOnline(t *testing.T) {
+	root := newFakeSysfs(t)
+	defer os.RemoveAll(root)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errCh := WatchOnlineFrom(ctx, root, time.Millisecond)
+	// let the watch read the initial online cpus before changing them
+	time.Sleep(20 * time.Millisecond)
+	if err := ioutil.WriteFile(filepath.Join(root, "cpu", "online"), []byte("0-5,7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e.Online.String() != "0-5,7" || !e.Removed.Equal(NewCPUSet(6)) || !e.Added.IsEmpty() {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the hotplug event")
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "node", "online"), []byte("0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if !e.RemovedNodes.Equal(NewCPUSet(1)) || !e.Removed.IsEmpty() || e.Nodes.String() != "0" {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the node hotplug event")
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestOnlineNodes(t *testing.T) {
+	root := newFakeSysfs(t)
+	defer os.RemoveAll(root)
+
+	nodes, err := OnlineNodesFrom(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes.String() != "0-1" {
+		t.Fatalf("expected nodes 0-1, got %s", nodes)
+	}
+	if err := os.RemoveAll(filepath.Join(root, "node")); err != nil {
+		t.Fatal(err)
+	}
+	if nodes, err = OnlineNodesFrom(root); err != nil {
+		t.Fatal(err)
+	}
+	if nodes.String() != "0" {
+		t.Fatalf("expected node 0 without NUMA support, got %s", nodes)
+	}
+}
@@ -21,7 +21,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -30,7 +29,8 @@ import (
 	"syscall"
 	"time"
 
-	units "github.com/docker/go-units"
+	"github.com/containerd/cgroups/clock"
+	"github.com/containerd/cgroups/internal/cgfs"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/sys/unix"
 )
@@ -43,6 +43,9 @@ var (
 
 const unifiedMountpoint = "/sys/fs/cgroup"
 
+// hugePagesDir is a var so that the tests can discover sizes from a fake directory
+var hugePagesDir = "/sys/kernel/mm/hugepages"
+
 // CGMode is the cgroups mode of the host system
 type CGMode int
 
@@ -144,12 +147,12 @@ func defaults(root string) ([]Subsystem, error) {
 }
 
 // remove will remove a cgroup path handling EAGAIN and EBUSY errors and
-// retrying the remove after a exp timeout
-func remove(path string) error {
+// retrying the remove after a exp timeout paced by clk
+func remove(path string, clk clock.Clock) error {
 	delay := 10 * time.Millisecond
 	for i := 0; i < 5; i++ {
 		if i != 0 {
-			time.Sleep(delay)
+			clock.Sleep(clk, delay)
 			delay *= 2
 		}
 		if err := os.RemoveAll(path); err == nil {
@@ -161,7 +164,7 @@ func remove(path string) error {
 
 // readPids will read all the pids of processes in a cgroup by the provided path
 func readPids(path string, subsystem Name) ([]Process, error) {
-	f, err := os.Open(filepath.Join(path, cgroupProcs))
+	f, err := openFile(filepath.Join(path, cgroupProcs), os.O_RDONLY, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +195,7 @@ func readPids(path string, subsystem Name) ([]Process, error) {
 
 // readTasksPids will read all the pids of tasks in a cgroup by the provided path
 func readTasksPids(path string, subsystem Name) ([]Task, error) {
-	f, err := os.Open(filepath.Join(path, cgroupTasks))
+	f, err := openFile(filepath.Join(path, cgroupTasks), os.O_RDONLY, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -220,28 +223,14 @@ func readTasksPids(path string, subsystem Name) ([]Task, error) {
 	return out, nil
 }
 
-func hugePageSizes() ([]string, error) {
-	var (
-		pageSizes []string
-		sizeList  = []string{"B", "KB", "MB", "GB", "TB", "PB"}
-	)
-	files, err := ioutil.ReadDir("/sys/kernel/mm/hugepages")
-	if err != nil {
-		return nil, err
-	}
-	for _, st := range files {
-		nameArray := strings.Split(st.Name(), "-")
-		pageSize, err := units.RAMInBytes(nameArray[1])
-		if err != nil {
-			return nil, err
-		}
-		pageSizes = append(pageSizes, units.CustomSize("%g%s", float64(pageSize), 1024.0, sizeList))
-	}
-	return pageSizes, nil
+// HugePageSizes returns the hugepage sizes supported by the host, as used in
+// the hugetlb file names (e.g. "2MB", "1GB"), discovered from /sys/kernel/mm/hugepages
+func HugePageSizes() ([]string, error) {
+	return cgfs.HugePageSizes(hugePagesDir)
 }
 
 func readUint(path string) (uint64, error) {
-	v, err := ioutil.ReadFile(path)
+	v, err := readBounded(path)
 	if err != nil {
 		return 0, err
 	}
@@ -280,7 +269,11 @@ func parseKV(raw string) (string, uint64, error) {
 	}
 }
 
-func parseCgroupFile(path string) (map[string]string, error) {
+// ParseCgroupFile parses a /proc/<pid>/cgroup file, which may be read from
+// any procfs root, and returns the path of the process's group keyed by
+// subsystem, e.g. "memory" or "name=systemd". The entry of the unified
+// hierarchy, which has no subsystem, is left out.
+func ParseCgroupFile(path string) (map[string]string, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -374,6 +367,20 @@ func initializeSubsystem(s Subsystem, path Path, resources *specs.LinuxResources
 	return nil
 }
 
+// enableAccounting turns on the accounting of the subsystem that is off by
+// default, if it has any
+func enableAccounting(s Subsystem, path Path) error {
+	e, ok := s.(accountingEnabler)
+	if !ok {
+		return nil
+	}
+	p, err := path(s.Name())
+	if err != nil {
+		return err
+	}
+	return e.enableAccounting(p)
+}
+
 func cleanPath(path string) string {
 	if path == "" {
 		return ""
@@ -389,7 +396,10 @@ func retryingWriteFile(path string, data []byte, mode os.FileMode) error {
 	// Retry writes on EINTR; see:
 	//    https://github.com/golang/go/issues/38033
 	for {
-		err := ioutil.WriteFile(path, data, mode)
+		err := writeFile(path, data, mode)
+		if !errors.Is(err, syscall.EINTR) {
+			audit(path, data, err)
+		}
 		if err == nil {
 			return nil
 		} else if !errors.Is(err, syscall.EINTR) {
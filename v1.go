@@ -22,6 +22,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // V1 returns all the groups in the default cgroups mountpoint in a single hierarchy
@@ -30,6 +32,19 @@ func V1() ([]Subsystem, error) {
 	if err != nil {
 		return nil, err
 	}
+	return v1Root(root)
+}
+
+// V1Root returns a hierarchy of the groups mounted under root instead of the
+// host's cgroups mountpoint, so that tests can target a scratch hierarchy
+// such as a private cgroup root mounted inside a user namespace
+func V1Root(root string) Hierarchy {
+	return func() ([]Subsystem, error) {
+		return v1Root(root)
+	}
+}
+
+func v1Root(root string) ([]Subsystem, error) {
 	subsystems, err := defaults(root)
 	if err != nil {
 		return nil, err
@@ -44,6 +59,87 @@ func V1() ([]Subsystem, error) {
 	return enabled, nil
 }
 
+// V1WithRoots returns the V1 hierarchy with the subsystems in roots mounted at
+// the given directories instead of below the common cgroups mountpoint. This
+// supports setups where controllers are mounted with different prefixes or
+// co-mounted under a combined name, e.g.
+//
+//	V1WithRoots(map[Name]string{
+//		Cpu:     "/sys/fs/cgroup/cpu,cpuacct",
+//		Cpuacct: "/sys/fs/cgroup/cpu,cpuacct",
+//	})
+func V1WithRoots(roots map[Name]string) Hierarchy {
+	return func() ([]Subsystem, error) {
+		var subsystems []Subsystem
+		root, err := v1MountPoint()
+		switch {
+		case err == nil:
+			if subsystems, err = v1Root(root); err != nil {
+				return nil, err
+			}
+		case err != ErrMountPointNotExist || len(roots) == 0:
+			return nil, err
+		}
+		var out []Subsystem
+		for _, s := range subsystems {
+			if _, ok := roots[s.Name()]; !ok {
+				out = append(out, s)
+			}
+		}
+		for name, dir := range roots {
+			s, err := newSubsystemAt(name, dir)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+}
+
+// newSubsystemAt returns the subsystem for the name whose hierarchy is
+// mounted at dir
+func newSubsystemAt(name Name, dir string) (pather, error) {
+	s, err := newSubsystem(name, filepath.Dir(dir))
+	if err != nil {
+		return nil, err
+	}
+	switch c := s.(type) {
+	case *blkioController:
+		c.root = dir
+	case *cpuController:
+		c.root = dir
+	case *cpuacctController:
+		c.root = dir
+	case *cpusetController:
+		c.root = dir
+	case *devicesController:
+		c.root = dir
+	case *freezerController:
+		c.root = dir
+	case *hugetlbController:
+		c.root = dir
+	case *memoryController:
+		c.root = dir
+	case *netclsController:
+		c.root = dir
+	case *netprioController:
+		c.root = dir
+	case *PerfEventController:
+		c.root = dir
+	case *pidsController:
+		c.root = dir
+	case *rdmaController:
+		c.root = dir
+	case *namedController:
+		// named hierarchies are addressed by their name below the root
+		if filepath.Base(dir) != string(name) {
+			return nil, errors.Errorf("cgroups: named hierarchy %q must be mounted at a directory of the same name, not %q", name, dir)
+		}
+	}
+	return s, nil
+}
+
 // v1MountPoint returns the mount point where the cgroup
 // mountpoints are mounted in a single hiearchy
 func v1MountPoint() (string, error) {
@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestV1WithRoots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-roots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var (
+		comounted = filepath.Join(dir, "cpu,cpuacct")
+		pids      = filepath.Join(dir, "prefix", "pids")
+	)
+	hierarchy := V1WithRoots(map[Name]string{
+		Cpu:     comounted,
+		Cpuacct: comounted,
+		Pids:    pids,
+	})
+	subsystems, err := hierarchy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := make(map[Name]string)
+	for _, s := range pathers(subsystems) {
+		roots[s.Name()] = s.Path("")
+	}
+	for name, expected := range map[Name]string{Cpu: comounted, Cpuacct: comounted, Pids: pids} {
+		if roots[name] != expected {
+			t.Errorf("expected %s to be at %s, got %s", name, expected, roots[name])
+		}
+	}
+
+	control, err := New(StaticHierarchy(subsystemsNamed(subsystems, Cpu, Cpuacct, Pids)...), StaticPath("test"), &specs.LinuxResources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := control.Add(Process{Pid: 1234}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{comounted, pids} {
+		if _, err := os.Stat(filepath.Join(p, "test", cgroupProcs)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewSubsystemAtNamed(t *testing.T) {
+	if _, err := newSubsystemAt("systemd", "/sys/fs/cgroup/other"); err == nil {
+		t.Fatal("expected an error for a named hierarchy at a differently named directory")
+	}
+	s, err := newSubsystemAt("systemd", "/sys/fs/cgroup/systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := s.Path("test"); p != "/sys/fs/cgroup/systemd/test" {
+		t.Fatalf("unexpected path %s", p)
+	}
+}
+
+func subsystemsNamed(subsystems []Subsystem, names ...Name) []Subsystem {
+	var out []Subsystem
+	for _, s := range subsystems {
+		for _, n := range names {
+			if s.Name() == n {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/cgroups/internal/cgfs"
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+// AbandonSystemd abandons the systemd scope of the cgroup. systemd stops
+// managing the processes of an abandoned scope, it no longer kills them when
+// the scope is stopped and only removes the scope once they have all exited,
+// so that shims can outlive the process that created their scope. The cgroup
+// can then be managed directly through the filesystem with AdoptSystemd.
+func (c *Manager) AbandonSystemd() error {
+	unit := systemdUnitFromPath(c.path)
+	if !strings.HasSuffix(unit, ".scope") {
+		return errors.Errorf("cgroups: only scopes can be abandoned, not %s", unit)
+	}
+	conn, err := dbus.SystemBusPrivate()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.Auth([]dbus.Auth{dbus.AuthExternal(strconv.Itoa(os.Getuid()))}); err != nil {
+		return err
+	}
+	if err := conn.Hello(); err != nil {
+		return err
+	}
+	_, span := c.config.startSpan(context.Background(), "systemd.AbandonScope", c.path)
+	err = conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1").
+		Call("org.freedesktop.systemd1.Manager.AbandonScope", 0, unit).Err
+	span.End(err)
+	return cgfs.UnitError(err)
+}
+
+// AdoptSystemd returns a manager for the cgroup of an abandoned systemd
+// scope that manages it directly through the filesystem instead of through
+// systemd. ErrCgroupDeleted is returned if the scope's cgroup was already
+// removed.
+func AdoptSystemd(slice, group string, opts ...InitOpts) (*Manager, error) {
+	return adopt(defaultCgroup2Path, slice, group, opts...)
+}
+
+func adopt(mountpoint, slice, group string, opts ...InitOpts) (*Manager, error) {
+	if slice == "" {
+		slice = defaultSlice
+	}
+	m, err := LoadManager(mountpoint, filepath.Join("/", slice, group), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(m.path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrCgroupDeleted
+		}
+		return nil, err
+	}
+	return m, nil
+}
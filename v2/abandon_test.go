@@ -0,0 +1,52 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdopt(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-adopt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := os.MkdirAll(filepath.Join(mountpoint, defaultSlice, "shim.scope"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m, err := adopt(mountpoint, "", "shim.scope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(mountpoint, defaultSlice, "shim.scope"); m.path != expected {
+		t.Fatalf("expected %s, got %s", expected, m.path)
+	}
+	if _, err := adopt(mountpoint, "", "gone.scope"); err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted, got %v", err)
+	}
+}
+
+func TestAbandonSystemdNotScope(t *testing.T) {
+	m := &Manager{path: filepath.Join(defaultCgroup2Path, defaultSlice, "test.slice")}
+	if err := m.AbandonSystemd(); err == nil {
+		t.Fatal("expected abandoning a slice to fail")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// annotationPrefix namespaces the extended attributes holding annotations
+const annotationPrefix = "user.cgroups."
+
+// SetAnnotation attaches the key and value to the cgroup so that other
+// processes can discover them, e.g. the owner or container id of the
+// cgroup. Annotations are stored in user extended attributes of the cgroup
+// directory, which cgroupfs supports since Linux 5.7. On older kernels
+// ErrAnnotationsNotSupported is returned.
+func (c *Manager) SetAnnotation(key, value string) error {
+	if err := verifyAnnotationKey(key); err != nil {
+		return err
+	}
+	return annotationError(unix.Lsetxattr(c.path, annotationPrefix+key, []byte(value), 0), key)
+}
+
+// Annotation returns the value of the annotation key, false is returned if
+// the cgroup has no such annotation
+func (c *Manager) Annotation(key string) (string, bool, error) {
+	if err := verifyAnnotationKey(key); err != nil {
+		return "", false, err
+	}
+	value, err := getxattr(c.path, annotationPrefix+key)
+	switch {
+	case err == nil:
+		return value, true, nil
+	case err == unix.ENODATA:
+		return "", false, nil
+	}
+	return "", false, annotationError(err, key)
+}
+
+// Annotations returns all annotations of the cgroup
+func (c *Manager) Annotations() (map[string]string, error) {
+	names, err := listxattr(c.path)
+	if err != nil {
+		return nil, annotationError(err, "")
+	}
+	out := make(map[string]string)
+	for _, name := range names {
+		if !strings.HasPrefix(name, annotationPrefix) {
+			continue
+		}
+		value, err := getxattr(c.path, name)
+		if err != nil {
+			if err == unix.ENODATA {
+				// removed since it was listed
+				continue
+			}
+			return nil, annotationError(err, name)
+		}
+		out[strings.TrimPrefix(name, annotationPrefix)] = value
+	}
+	return out, nil
+}
+
+// RemoveAnnotation removes the annotation key from the cgroup, removing an
+// annotation that does not exist is not an error
+func (c *Manager) RemoveAnnotation(key string) error {
+	if err := verifyAnnotationKey(key); err != nil {
+		return err
+	}
+	err := unix.Lremovexattr(c.path, annotationPrefix+key)
+	if err == unix.ENODATA {
+		return nil
+	}
+	return annotationError(err, key)
+}
+
+func verifyAnnotationKey(key string) error {
+	if key == "" || strings.IndexByte(key, 0) >= 0 {
+		return ErrInvalidAnnotation
+	}
+	return nil
+}
+
+func annotationError(err error, key string) error {
+	switch err {
+	case nil:
+		return nil
+	case unix.ENOENT:
+		return ErrCgroupDeleted
+	case unix.ENOTSUP:
+		err = ErrAnnotationsNotSupported
+	}
+	if key == "" {
+		return errors.Wrap(err, "annotations")
+	}
+	return errors.Wrapf(err, "annotation %q", key)
+}
+
+// getxattr returns the value of the extended attribute name of path,
+// without following symlinks
+func getxattr(path, name string) (string, error) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	for err == nil {
+		buf := make([]byte, size)
+		var n int
+		if n, err = unix.Lgetxattr(path, name, buf); err == nil {
+			return string(buf[:n]), nil
+		}
+		if err != unix.ERANGE {
+			break
+		}
+		// the value grew since its size was read
+		size, err = unix.Lgetxattr(path, name, nil)
+	}
+	return "", err
+}
+
+// listxattr returns the names of the extended attributes of path, without
+// following symlinks
+func listxattr(path string) ([]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	for err == nil {
+		if size == 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size)
+		var n int
+		if n, err = unix.Llistxattr(path, buf); err == nil {
+			return strings.Split(strings.TrimRight(string(buf[:n]), "\x00"), "\x00"), nil
+		}
+		if err != unix.ERANGE {
+			break
+		}
+		size, err = unix.Llistxattr(path, nil)
+	}
+	return nil, err
+}
@@ -0,0 +1,79 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+func TestAnnotations(t *testing.T) {
+	c := shrinkTestManager(t, nil)
+	defer os.RemoveAll(c.path)
+	if err := unix.Lsetxattr(c.path, "user.probe", []byte("1"), 0); err != nil {
+		t.Skipf("skipping test that requires user extended attributes: %v", err)
+	}
+
+	if err := c.SetAnnotation("owner", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetAnnotation("container", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	value, ok, err := c.Annotation("owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || value != "alice" {
+		t.Fatalf("expected owner alice but received %q, %v", value, ok)
+	}
+	if err := c.RemoveAnnotation("owner"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveAnnotation("owner"); err != nil {
+		t.Fatalf("expected removing a missing annotation to succeed but received %v", err)
+	}
+	if _, ok, err := c.Annotation("owner"); err != nil || ok {
+		t.Fatalf("expected no owner annotation but received %v, %v", ok, err)
+	}
+	annotations, err := c.Annotations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// attributes outside of the annotation namespace are left out
+	expected := map[string]string{"container": "abc123"}
+	if !reflect.DeepEqual(annotations, expected) {
+		t.Fatalf("expected %v but received %v", expected, annotations)
+	}
+	if err := c.SetAnnotation("", "x"); err != ErrInvalidAnnotation {
+		t.Fatalf("expected ErrInvalidAnnotation but received %v", err)
+	}
+}
+
+func TestAnnotationsNotSupported(t *testing.T) {
+	err := annotationError(unix.EOPNOTSUPP, "owner")
+	if errors.Cause(err) != ErrAnnotationsNotSupported {
+		t.Fatalf("expected ErrAnnotationsNotSupported but received %v", err)
+	}
+	if KindOf(err) != KindUnsupported {
+		t.Fatalf("expected an unsupported error but received %v", KindOf(err))
+	}
+}
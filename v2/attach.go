@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// AttachSelf moves the calling process, including all of its threads, into
+// the cgroup
+func (c *Manager) AttachSelf() error {
+	return c.AddProc(uint64(os.Getpid()))
+}
+
+// AttachCurrentThread locks the calling goroutine to its OS thread and moves
+// only that thread into the cgroup. The cgroup must be of type "threaded"
+// and within the same threaded domain as the rest of the process.
+//
+// On success the goroutine is left locked to the thread; letting it exit
+// while locked causes the runtime to terminate the thread. On failure the
+// thread is unlocked again.
+func (c *Manager) AttachCurrentThread() error {
+	runtime.LockOSThread()
+	v := Value{
+		filename: cgroupThreads,
+		value:    int64(unix.Gettid()),
+	}
+	if err := writeValues(c.path, []Value{v}); err != nil {
+		runtime.UnlockOSThread()
+		return addError(c.path, err)
+	}
+	return nil
+}
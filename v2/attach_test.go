@@ -0,0 +1,72 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestAttachSelf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-attach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &Manager{path: dir}
+	if err := m.AttachSelf(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, cgroupProcs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != strconv.Itoa(os.Getpid()) {
+		t.Fatalf("expected pid %d to be attached, got %q", os.Getpid(), data)
+	}
+}
+
+func TestAttachCurrentThread(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-attach")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &Manager{path: dir}
+	errCh := make(chan error, 1)
+	go func() {
+		if err := m.AttachCurrentThread(); err != nil {
+			errCh <- err
+			return
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, cgroupThreads))
+		if err == nil && string(data) != strconv.Itoa(unix.Gettid()) {
+			t.Errorf("expected thread %d to be attached, got %q", unix.Gettid(), data)
+		}
+		errCh <- err
+	}()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
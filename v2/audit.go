@@ -0,0 +1,45 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io"
+
+	"github.com/containerd/cgroups/internal/cgfs"
+)
+
+// AuditRecord describes a single write made to a cgroup interface file
+type AuditRecord = cgfs.AuditRecord
+
+// AuditSink receives a record of every file write performed by the cgroups
+// and v2 packages
+type AuditSink = cgfs.AuditSink
+
+// SetAuditSink sets the sink that every file write is recorded to, a nil
+// sink disables the audit trail. The sink is shared with the cgroups package.
+func SetAuditSink(sink AuditSink) {
+	cgfs.SetAuditSink(sink)
+}
+
+// NewAuditWriter returns a sink that writes each record to w as a line of JSON
+func NewAuditWriter(w io.Writer) AuditSink {
+	return cgfs.NewAuditWriter(w)
+}
+
+func audit(path string, data []byte, err error) {
+	cgfs.Audit(path, data, err)
+}
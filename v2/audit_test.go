@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var records []AuditRecord
+	SetAuditSink(func(r AuditRecord) {
+		records = append(records, r)
+	})
+	defer SetAuditSink(nil)
+
+	if err := writeValues(dir, []Value{
+		{filename: "pids.max", value: int64(10)},
+		{filename: "missing/memory.max", value: "max"},
+	}); err == nil {
+		t.Fatal("expected the write to a missing directory to fail")
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records but received %d", len(records))
+	}
+	if r := records[0]; r.Path != filepath.Join(dir, "pids.max") || r.Value != "10" || r.Error != "" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r := records[1]; r.Value != "max" || r.Error == "" {
+		t.Errorf("expected a failed write to be recorded but received %+v", r)
+	}
+
+	// enabling controllers is recorded too
+	if err := ioutil.WriteFile(filepath.Join(dir, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	records = nil
+	m := &Manager{unifiedMountpoint: dir, path: filepath.Join(dir, "child")}
+	if err := m.ToggleControllers([]string{"pids"}, Enable); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Path != filepath.Join(dir, subtreeControl) || records[0].Value != "+pids" {
+		t.Fatalf("expected the subtree control write to be recorded, got %+v", records)
+	}
+}
@@ -0,0 +1,238 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// batchConcurrency bounds the groups of a batch created at once
+const batchConcurrency = 16
+
+// NewBatch creates sibling groups with the names below the group parent,
+// all with the same resources, and returns their managers in the order of
+// names. It is intended for batch schedulers that start hundreds of tasks at
+// once: the options are parsed once, the controllers are enabled in the
+// subtree_control files of the parent and its ancestors once, and the groups
+// are created in parallel. The parent is created with empty resources, like
+// NewManager, if it does not exist.
+//
+// Interceptors are called for the parent when it is created and for every
+// group, concurrently. Groups that already exist are reported as failures.
+// If any group cannot be created the groups that were created, and the
+// parent if it was created, are removed again and a *MultiError with every
+// failure is returned.
+func NewBatch(mountpoint, parent string, names []string, resources *Resources, opts ...InitOpts) ([]*Manager, error) {
+	if resources == nil {
+		return nil, errors.New("resources reference is nil")
+	}
+	if err := VerifyGroupPath(parent); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") || seen[name] {
+			return nil, errors.Wrapf(ErrInvalidGroupPath, "invalid or duplicate name %q", name)
+		}
+		seen[name] = true
+	}
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	parentPath := filepath.Join(mountpoint, parent)
+	created, err := createParent(parentPath, config)
+	if err != nil {
+		return nil, err
+	}
+	b := &batch{
+		mountpoint: mountpoint,
+		config:     config,
+		// any child path enables the controllers of the parent and its
+		// ancestors, the child itself is not written
+		toggler:  newManager(mountpoint, filepath.Join(parentPath, names[0]), config),
+		enabled:  make(map[string]bool),
+		managers: make([]*Manager, len(names)),
+		warnings: make([]Warnings, len(names)),
+		errs:     make([]error, len(names)),
+	}
+	if err := b.enable(resources); err != nil {
+		removeDirs(created)
+		return nil, err
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, batchConcurrency)
+	)
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, path string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			b.managers[i], b.errs[i] = b.create(path, resources, &b.warnings[i])
+		}(i, filepath.Join(parentPath, name))
+	}
+	wg.Wait()
+
+	var errs MultiError
+	for i, err := range b.errs {
+		if err != nil {
+			errs.add(filepath.Join(parentPath, names[i]), err)
+		}
+	}
+	if err := errs.errorOrNil(); err != nil {
+		for _, m := range b.managers {
+			if m != nil {
+				os.Remove(m.path)
+			}
+		}
+		removeDirs(created)
+		return nil, err
+	}
+	if config.Warnings != nil {
+		for _, w := range b.warnings {
+			*config.Warnings = append(*config.Warnings, w...)
+		}
+	}
+	return b.managers, nil
+}
+
+// createParent creates the parent of a batch and its missing ancestors through
+// the interceptors, returning the directories it created from the deepest up
+func createParent(path string, config *InitConfig) ([]string, error) {
+	var created []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		created = append(created, dir)
+	}
+	if len(created) == 0 {
+		return nil, nil
+	}
+	op := &Operation{
+		Op:        OpNew,
+		Path:      path,
+		Resources: &Resources{},
+		Warnings:  config.Warnings,
+	}
+	if err := config.intercept(op, func() error {
+		return os.MkdirAll(path, defaultDirPerm)
+	}); err != nil {
+		removeDirs(created)
+		return nil, err
+	}
+	return created, nil
+}
+
+// removeDirs removes the empty directories in order, ignoring errors
+func removeDirs(dirs []string) {
+	for _, dir := range dirs {
+		os.Remove(dir)
+	}
+}
+
+// batch holds the state shared by the groups of a NewBatch call
+type batch struct {
+	mountpoint string
+	config     *InitConfig
+	toggler    *Manager
+
+	// mu guards enabled, the controllers enabled so far
+	mu      sync.Mutex
+	enabled map[string]bool
+
+	managers []*Manager
+	warnings []Warnings
+	errs     []error
+}
+
+// enable enables the controllers needed by the resources that are not
+// enabled yet
+func (b *batch) enable(resources *Resources) error {
+	controllers := resources.EnabledControllers()
+	if b.config.AccountingOnly {
+		var err error
+		if controllers, err = b.toggler.accountingControllers(resources); err != nil {
+			return err
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var missing []string
+	for _, c := range controllers {
+		if !b.enabled[c] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if err := b.toggler.ToggleControllers(missing, Enable); err != nil {
+		return err
+	}
+	for _, c := range missing {
+		b.enabled[c] = true
+	}
+	return nil
+}
+
+// create creates a single group of the batch through the interceptors
+func (b *batch) create(path string, resources *Resources, w *Warnings) (*Manager, error) {
+	var (
+		m  *Manager
+		op = &Operation{
+			Op:        OpNew,
+			Path:      path,
+			Resources: resources,
+			Warnings:  w,
+		}
+	)
+	if err := b.config.intercept(op, func() error {
+		// interceptors may have replaced the resources with resources
+		// that need other controllers
+		if err := b.enable(op.Resources); err != nil {
+			return err
+		}
+		if err := os.Mkdir(path, defaultDirPerm); err != nil {
+			return err
+		}
+		if err := initialize(path, op.Resources, b.config, w, op.deadline); err != nil {
+			os.Remove(path)
+			return err
+		}
+		m = newManager(b.mountpoint, path, b.config)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,126 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewBatch(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	for _, dir := range []string{mountpoint, filepath.Join(mountpoint, "batch")} {
+		if err := os.MkdirAll(dir, defaultDirPerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, subtreeControl), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var names []string
+	for i := 0; i < 50; i++ {
+		names = append(names, fmt.Sprintf("task-%d", i))
+	}
+	var warnings Warnings
+	managers, err := NewBatch(mountpoint, "/batch", names, &Resources{Pids: &Pids{Max: 10}}, WithWarnings(&warnings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(managers) != len(names) {
+		t.Fatalf("expected %d managers but received %d", len(names), len(managers))
+	}
+	for i, m := range managers {
+		if m.path != filepath.Join(mountpoint, "batch", names[i]) {
+			t.Fatalf("expected managers in the order of the names, got %q for %q", m.path, names[i])
+		}
+		if v := readTestFile(t, m, "pids.max"); v != "10" {
+			t.Fatalf("expected pids.max of 10 in %s but received %q", names[i], v)
+		}
+	}
+	data, err := ioutil.ReadFile(filepath.Join(mountpoint, "batch", subtreeControl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := strings.TrimSpace(string(data)); v != "+pids" {
+		t.Fatalf("expected the pids controller to be enabled for the batch, got %q", v)
+	}
+}
+
+func TestNewBatchRollback(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := os.MkdirAll(filepath.Join(mountpoint, "batch", "b"), defaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewBatch(mountpoint, "/batch", []string{"a", "b", "c"}, &Resources{})
+	merr, ok := err.(*MultiError)
+	if !ok || len(merr.Errors) != 1 || merr.Errors[0].Path != filepath.Join(mountpoint, "batch", "b") {
+		t.Fatalf("expected a single failure for the existing group but received %v", err)
+	}
+	for _, name := range []string{"a", "c"} {
+		if _, err := os.Stat(filepath.Join(mountpoint, "batch", name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed again but received %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(mountpoint, "batch", "b")); err != nil {
+		t.Fatalf("expected the existing group to be left alone: %v", err)
+	}
+
+	for _, names := range [][]string{{"a", "a"}, {"a/b"}, {".."}, {""}} {
+		if _, err := NewBatch(mountpoint, "/batch", names, &Resources{}); KindOf(err) != KindInvalidInput {
+			t.Fatalf("expected invalid names %q to be rejected but received %v", names, err)
+		}
+	}
+}
+
+func TestNewBatchParent(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+
+	var paths []string
+	record := func(op *Operation, next func() error) error {
+		paths = append(paths, op.Path)
+		return next()
+	}
+	// without subtree_control files the controllers cannot be enabled
+	_, err = NewBatch(mountpoint, "/new/batch", []string{"a"}, &Resources{Pids: &Pids{Max: 10}}, WithInterceptors(record))
+	if err == nil {
+		t.Fatal("expected enabling the controllers to fail")
+	}
+	if len(paths) != 1 || paths[0] != filepath.Join(mountpoint, "new", "batch") {
+		t.Fatalf("expected the interceptor to be called for the parent but received %v", paths)
+	}
+	if _, err := os.Stat(filepath.Join(mountpoint, "new")); !os.IsNotExist(err) {
+		t.Fatalf("expected the created parent to be removed again but received %v", err)
+	}
+}
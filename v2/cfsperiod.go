@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultCFSPeriod is the kernel's default cpu.max period in
+	// microseconds
+	DefaultCFSPeriod = 100000
+	// MinCFSPeriod and MaxCFSPeriod are the bounds the kernel enforces on
+	// the cpu.max period in microseconds
+	MinCFSPeriod = 1000
+	MaxCFSPeriod = 1000000
+	// MinCFSQuota is the smallest cpu.max quota the kernel accepts in
+	// microseconds
+	MinCFSQuota = 1000
+)
+
+// CFSPeriodOpts configures CFSBandwidth
+type CFSPeriodOpts func(*cfsPeriodConfig)
+
+type cfsPeriodConfig struct {
+	latency time.Duration
+}
+
+// WithThrottleLatency shortens the period so that a group that used up its
+// quota waits at most d for the next period, which bounds the latency added
+// by throttling for latency-sensitive workloads. The quota is spread over
+// more, shorter periods, so bursts are throttled sooner but for less time.
+// The period is never made longer than the default by this option.
+func WithThrottleLatency(d time.Duration) CFSPeriodOpts {
+	return func(c *cfsPeriodConfig) {
+		c.latency = d
+	}
+}
+
+// CFSBandwidth returns the cpu.max quota and period in microseconds that
+// limit a group to cpus, for example 0.5 for half a cpu. The default period
+// of 100ms is used unless it is shortened by WithThrottleLatency, and it is
+// lengthened when needed for the quota to reach the kernel's minimum of 1ms,
+// staying within the kernel's bounds of 1ms to 1s. Limits too small to be
+// expressed within those bounds return ErrInvalidCPULimit.
+//
+// The result can be written with NewCPUMax(&quota, &period), or set as the
+// Quota and Period of the v1 cpu resources.
+func CFSBandwidth(cpus float64, opts ...CFSPeriodOpts) (quota int64, period uint64, err error) {
+	if cpus <= 0 || math.IsInf(cpus, 0) || math.IsNaN(cpus) {
+		return 0, 0, errors.Wrapf(ErrInvalidCPULimit, "%v cpus", cpus)
+	}
+	var config cfsPeriodConfig
+	for _, o := range opts {
+		o(&config)
+	}
+	period = DefaultCFSPeriod
+	if config.latency > 0 {
+		if us := uint64(config.latency / time.Microsecond); us < period {
+			period = us
+		}
+		if period < MinCFSPeriod {
+			period = MinCFSPeriod
+		}
+	}
+	// the quota has to be at least the kernel's minimum
+	min := math.Ceil(MinCFSQuota / cpus)
+	if min > MaxCFSPeriod {
+		return 0, 0, errors.Wrapf(ErrInvalidCPULimit, "%v cpus is below the minimum quota of %dus per %dus", cpus, MinCFSQuota, MaxCFSPeriod)
+	}
+	if uint64(min) > period {
+		period = uint64(min)
+	}
+	q := math.Round(cpus * float64(period))
+	if q >= math.MaxInt64 {
+		return 0, 0, errors.Wrapf(ErrInvalidCPULimit, "%v cpus", cpus)
+	}
+	quota = int64(q)
+	if quota < MinCFSQuota {
+		quota = MinCFSQuota
+	}
+	return quota, period, nil
+}
@@ -0,0 +1,58 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCFSBandwidth(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cpus   float64
+		opts   []CFSPeriodOpts
+		quota  int64
+		period uint64
+	}{
+		{name: "default", cpus: 2, quota: 200000, period: 100000},
+		{name: "fraction", cpus: 0.25, quota: 25000, period: 100000},
+		{name: "minimum quota", cpus: 0.005, quota: 1000, period: 200000},
+		{name: "latency", cpus: 4, opts: []CFSPeriodOpts{WithThrottleLatency(10 * time.Millisecond)}, quota: 40000, period: 10000},
+		{name: "latency below minimum period", cpus: 4, opts: []CFSPeriodOpts{WithThrottleLatency(time.Microsecond)}, quota: 4000, period: 1000},
+		{name: "latency above default", cpus: 1, opts: []CFSPeriodOpts{WithThrottleLatency(time.Second)}, quota: 100000, period: 100000},
+		{name: "latency against minimum quota", cpus: 0.1, opts: []CFSPeriodOpts{WithThrottleLatency(5 * time.Millisecond)}, quota: 1000, period: 10000},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			quota, period, err := CFSBandwidth(tc.cpus, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if quota != tc.quota || period != tc.period {
+				t.Fatalf("expected %d %d, got %d %d", tc.quota, tc.period, quota, period)
+			}
+			if period < MinCFSPeriod || period > MaxCFSPeriod || quota < MinCFSQuota {
+				t.Fatalf("%d %d is outside of the kernel's bounds", quota, period)
+			}
+		})
+	}
+	for _, cpus := range []float64{0, -1, 0.0005} {
+		if _, _, err := CFSBandwidth(cpus); KindOf(err) != KindInvalidInput {
+			t.Fatalf("expected %v cpus to be invalid, got %v", cpus, err)
+		}
+	}
+}
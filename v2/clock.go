@@ -0,0 +1,75 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/containerd/cgroups/v2/stats"
+	"golang.org/x/sys/unix"
+)
+
+// ClockSource is the clock used to timestamp metrics
+type ClockSource int
+
+const (
+	// ClockMonotonic is CLOCK_MONOTONIC, which does not advance while the
+	// system is suspended
+	ClockMonotonic ClockSource = iota
+	// ClockBoottime is CLOCK_BOOTTIME, which includes time suspended
+	ClockBoottime
+)
+
+// now returns the current time of the clock in nanoseconds
+func (c ClockSource) now() uint64 {
+	id := unix.CLOCK_MONOTONIC
+	if c == ClockBoottime {
+		id = unix.CLOCK_BOOTTIME
+	}
+	var ts unix.Timespec
+	if err := unix.ClockGettime(int32(id), &ts); err != nil {
+		return 0
+	}
+	return uint64(ts.Nano())
+}
+
+// clock returns the clock source of the config, nil uses ClockMonotonic
+func (c *InitConfig) clock() ClockSource {
+	if c == nil {
+		return ClockMonotonic
+	}
+	return c.ClockSource
+}
+
+// pacer returns the clock pacing the manager, nil uses the system clock
+func (c *InitConfig) pacer() clock.Clock {
+	if c == nil {
+		return clock.Real
+	}
+	return clock.Or(c.Pacer)
+}
+
+// Interval returns the time elapsed between two metrics from their
+// timestamps, which unlike the wall clock are unaffected by NTP adjustments.
+// It returns 0 if either has no timestamp or they are out of order.
+func Interval(prev, cur *stats.Metrics) time.Duration {
+	if prev.Timestamp == 0 || cur.Timestamp <= prev.Timestamp {
+		return 0
+	}
+	return time.Duration(cur.Timestamp - prev.Timestamp)
+}
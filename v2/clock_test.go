@@ -0,0 +1,63 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestStatTimestamp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-clock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, controllersFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManager(filepath.Dir(dir), "/"+filepath.Base(dir), WithClockSource(ClockBoottime))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, err := m.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	cur, err := m.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev.Timestamp == 0 {
+		t.Fatal("expected metrics to be timestamped")
+	}
+	if d := Interval(prev, cur); d < time.Millisecond {
+		t.Fatalf("expected at least 1ms between samples, got %s", d)
+	}
+	if d := Interval(cur, prev); d != 0 {
+		t.Fatalf("expected 0 for samples out of order, got %s", d)
+	}
+	if d := Interval(&stats.Metrics{}, cur); d != 0 {
+		t.Fatalf("expected 0 without a timestamp, got %s", d)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// Clone returns a deep copy of the resources that shares no memory with r
+func (r *Resources) Clone() *Resources {
+	if r == nil {
+		return nil
+	}
+	out := &Resources{}
+	if r.CPU != nil {
+		cpu := *r.CPU
+		cpu.Weight = cloneUint64(r.CPU.Weight)
+		out.CPU = &cpu
+	}
+	if r.Memory != nil {
+		out.Memory = &Memory{
+			Swap: cloneInt64(r.Memory.Swap),
+			Max:  cloneInt64(r.Memory.Max),
+			Low:  cloneInt64(r.Memory.Low),
+			High: cloneInt64(r.Memory.High),
+		}
+	}
+	if r.Pids != nil {
+		pids := *r.Pids
+		out.Pids = &pids
+	}
+	if r.IO != nil {
+		out.IO = &IO{BFQ: r.IO.BFQ}
+		if r.IO.Max != nil {
+			out.IO.Max = append([]Entry{}, r.IO.Max...)
+		}
+	}
+	if r.RDMA != nil {
+		out.RDMA = &RDMA{}
+		if r.RDMA.Limit != nil {
+			out.RDMA.Limit = append([]RDMAEntry{}, r.RDMA.Limit...)
+		}
+	}
+	if r.HugeTlb != nil {
+		hugetlb := append(HugeTlb{}, *r.HugeTlb...)
+		out.HugeTlb = &hugetlb
+	}
+	if r.Devices != nil {
+		out.Devices = make([]specs.LinuxDeviceCgroup, len(r.Devices))
+		for i, d := range r.Devices {
+			d.Major = cloneInt64(d.Major)
+			d.Minor = cloneInt64(d.Minor)
+			out.Devices[i] = d
+		}
+	}
+	return out
+}
+
+func cloneInt64(v *int64) *int64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func cloneUint64(v *uint64) *uint64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/containerd/cgroups/v2/stats"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func TestResourcesClone(t *testing.T) {
+	var (
+		weight = uint64(100)
+		max    = int64(1 << 20)
+		major  = int64(8)
+	)
+	r := &Resources{
+		CPU:     &CPU{Weight: &weight, Max: "max 100000"},
+		Memory:  &Memory{Max: &max},
+		Pids:    &Pids{Max: 10},
+		IO:      &IO{Max: []Entry{{Type: ReadBPS, Major: 8, Rate: 1}}},
+		RDMA:    &RDMA{Limit: []RDMAEntry{{Device: "mlx4_0", HcaHandles: 2}}},
+		HugeTlb: &HugeTlb{{HugePageSize: "2MB", Limit: 1}},
+		Devices: []specs.LinuxDeviceCgroup{{Allow: true, Major: &major}},
+	}
+	c := r.Clone()
+	if !reflect.DeepEqual(r, c) {
+		t.Fatalf("expected the clone to equal the resources: %+v", c)
+	}
+	*c.CPU.Weight = 1
+	*c.Memory.Max = 1
+	c.Pids.Max = 1
+	c.IO.Max[0].Rate = 2
+	c.RDMA.Limit[0].HcaHandles = 1
+	(*c.HugeTlb)[0].Limit = 2
+	*c.Devices[0].Major = 1
+	if weight != 100 || max != 1<<20 || major != 8 || r.Pids.Max != 10 || r.IO.Max[0].Rate != 1 ||
+		r.RDMA.Limit[0].HcaHandles != 2 || (*r.HugeTlb)[0].Limit != 1 {
+		t.Fatalf("modifying the clone changed the resources: %+v", r)
+	}
+	if (*Resources)(nil).Clone() != nil {
+		t.Fatal("expected a nil clone of nil resources")
+	}
+}
+
+func TestMetricsClone(t *testing.T) {
+	m := &stats.Metrics{
+		Pids:   &stats.PidsStat{Current: 1},
+		Memory: &stats.MemoryStat{Usage: 4096},
+	}
+	c := m.Clone()
+	if !reflect.DeepEqual(m, c) {
+		t.Fatalf("expected the clone to equal the metrics: %+v", c)
+	}
+	c.Pids.Current = 2
+	c.Memory.Usage = 0
+	if m.Pids.Current != 1 || m.Memory.Usage != 4096 {
+		t.Fatalf("modifying the clone changed the metrics: %+v", m)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// nonSettingFiles are writable interface files that trigger an action or
+// hold state rather than configure the cgroup
+var nonSettingFiles = map[string]struct{}{
+	cgroupProcs:      {},
+	"cgroup.threads": {},
+	"cgroup.kill":    {},
+	"cgroup.freeze":  {},
+	"memory.reclaim": {},
+	"memory.peak":    {},
+}
+
+// Difference is a setting that differs between two cgroups
+type Difference struct {
+	// File is the interface file of the setting, e.g. "memory.max"
+	File string
+	// A and B are the trimmed contents of the file in each cgroup
+	A string
+	B string
+	// MissingA and MissingB are true when the cgroup has no such file,
+	// usually because the controller is not enabled for it
+	MissingA bool
+	MissingB bool
+}
+
+// Settings returns the trimmed contents of the cgroup's writable interface
+// files keyed by file name, leaving out files such as cgroup.procs and
+// memory.reclaim that do not hold configuration. The result can be shipped
+// elsewhere and compared with CompareSettings.
+func (c *Manager) Settings() (map[string]string, error) {
+	entries, err := ioutil.ReadDir(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrCgroupDeleted
+		}
+		return nil, err
+	}
+	out := make(map[string]string)
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Mode().IsRegular() || e.Mode().Perm()&0200 == 0 || !isSetting(name) {
+			continue
+		}
+		data, err := c.readFile(name)
+		if err != nil {
+			// files such as memory.oom.group are write only on some
+			// kernels and controllers can be disabled while reading
+			if os.IsNotExist(errors.Cause(err)) || errors.Is(err, syscall.EINVAL) || os.IsPermission(errors.Cause(err)) {
+				continue
+			}
+			return nil, err
+		}
+		out[name] = strings.TrimSpace(string(data))
+	}
+	return out, nil
+}
+
+// isSetting returns true if the writable file name configures the cgroup
+func isSetting(name string) bool {
+	if _, ok := nonSettingFiles[name]; ok {
+		return false
+	}
+	// pressure files are writable to register triggers
+	return !strings.HasSuffix(name, ".pressure")
+}
+
+// Compare returns the settings that differ between the cgroups of a and b,
+// ordered by file name. Two cgroups configured identically have no
+// differences.
+func Compare(a, b *Manager) ([]Difference, error) {
+	sa, err := a.Settings()
+	if err != nil {
+		return nil, errors.Wrapf(err, "read settings of %s", a.path)
+	}
+	sb, err := b.Settings()
+	if err != nil {
+		return nil, errors.Wrapf(err, "read settings of %s", b.path)
+	}
+	return CompareSettings(sa, sb), nil
+}
+
+// CompareSettings returns the differences between two results of Settings,
+// ordered by file name, so that cgroups on different hosts can be compared
+func CompareSettings(a, b map[string]string) []Difference {
+	var out []Difference
+	for name, va := range a {
+		vb, ok := b[name]
+		if !ok {
+			out = append(out, Difference{File: name, A: va, MissingB: true})
+			continue
+		}
+		if va != vb {
+			out = append(out, Difference{File: name, A: va, B: vb})
+		}
+	}
+	for name, vb := range b {
+		if _, ok := a[name]; !ok {
+			out = append(out, Difference{File: name, B: vb, MissingA: true})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].File < out[j].File
+	})
+	return out
+}
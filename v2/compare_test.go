@@ -0,0 +1,85 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func compareTestManager(t *testing.T, files map[string]string, readOnly ...string) *Manager {
+	c := shrinkTestManager(t, files)
+	for _, name := range readOnly {
+		if err := os.Chmod(filepath.Join(c.path, name), 0444); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func TestCompare(t *testing.T) {
+	a := compareTestManager(t, map[string]string{
+		"memory.max":     "max\n",
+		"cpu.weight":     "100\n",
+		"pids.max":       "10\n",
+		"cgroup.procs":   "1\n2\n",
+		"memory.current": "4096\n",
+		"cpu.pressure":   "some avg10=0.00\n",
+	}, "memory.current")
+	defer os.RemoveAll(a.path)
+	b := compareTestManager(t, map[string]string{
+		"memory.max":     "max\n",
+		"cpu.weight":     "200\n",
+		"io.max":         "8:0 rbps=1024\n",
+		"cgroup.procs":   "3\n",
+		"memory.current": "8192\n",
+	}, "memory.current")
+	defer os.RemoveAll(b.path)
+
+	diff, err := Compare(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Difference{
+		{File: "cpu.weight", A: "100", B: "200"},
+		{File: "io.max", B: "8:0 rbps=1024", MissingA: true},
+		{File: "pids.max", A: "10", MissingB: true},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("expected differences %+v, got %+v", expected, diff)
+	}
+
+	if diff, err := Compare(a, a); err != nil || len(diff) != 0 {
+		t.Fatalf("expected a cgroup to match itself, got %+v %v", diff, err)
+	}
+}
+
+func TestCompareDeleted(t *testing.T) {
+	a := shrinkTestManager(t, nil)
+	defer os.RemoveAll(a.path)
+	dir, err := ioutil.TempDir("", "cgroups-compare")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(dir)
+	if _, err := Compare(a, &Manager{path: dir}); KindOf(err) != KindNotFound {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
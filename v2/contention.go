@@ -0,0 +1,203 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// PSIWindow selects the averaging window of pressure stall information
+type PSIWindow int
+
+const (
+	// PSIAvg10 is the average over the last 10 seconds, reacting quickly
+	// to changes in contention
+	PSIAvg10 PSIWindow = iota
+	// PSIAvg60 is the average over the last 60 seconds
+	PSIAvg60
+	// PSIAvg300 is the average over the last 300 seconds, smoothing out
+	// short bursts
+	PSIAvg300
+)
+
+// Avg returns the average of the window
+func (d PSIData) Avg(w PSIWindow) float64 {
+	switch w {
+	case PSIAvg60:
+		return d.Avg60
+	case PSIAvg300:
+		return d.Avg300
+	}
+	return d.Avg10
+}
+
+// pressureResources are the resources with pressure stall information
+var pressureResources = []string{"cpu", "memory", "io"}
+
+// RankOpts configures RankByPressure
+type RankOpts func(*rankConfig)
+
+type rankConfig struct {
+	window  PSIWindow
+	weights map[string]float64
+}
+
+// WithPressureWindow sets the averaging window the groups are ranked by,
+// PSIAvg10 by default
+func WithPressureWindow(w PSIWindow) RankOpts {
+	return func(c *rankConfig) {
+		c.window = w
+	}
+}
+
+// WithPressureWeights sets how much the pressure of each resource counts
+// towards the contention score, all resources count equally by default. A
+// weight of 0 leaves the resource out, for example to balance cpu bound
+// work by cpu pressure alone.
+func WithPressureWeights(cpu, memory, io float64) RankOpts {
+	return func(c *rankConfig) {
+		c.weights = map[string]float64{
+			"cpu":    cpu,
+			"memory": memory,
+			"io":     io,
+		}
+	}
+}
+
+// PressureRank is a group ranked by RankByPressure
+type PressureRank struct {
+	// Name of the group within its parent
+	Name string
+	// Score is the contention of the group, the weighted average of the
+	// share of time its tasks stalled on each resource, from 0 to 200
+	Score float64
+	// Pressure is the pressure stall information the score was computed
+	// from, keyed by resource. Resources without a pressure file in the
+	// group, such as those of controllers not enabled for it, are absent.
+	Pressure map[string]PSIStats
+}
+
+// ContentionScore returns the contention score of pressure stall information
+// keyed by resource. The score of each resource is the share of time some
+// tasks stalled plus the share of time all of them stalled at once, so that
+// a stall of the whole group counts twice, and the resources are averaged by
+// their weights. Resources missing from pressure are left out of the average.
+func ContentionScore(pressure map[string]PSIStats, window PSIWindow, weights map[string]float64) float64 {
+	var sum, total float64
+	for resource, p := range pressure {
+		weight := 1.0
+		if weights != nil {
+			weight = weights[resource]
+		}
+		if weight <= 0 {
+			continue
+		}
+		sum += weight * (p.Some.Avg(window) + p.Full.Avg(window))
+		total += weight
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// RankByPressure ranks the children of the parent group under the mountpoint
+// by their contention score, most contended first, as a hint for
+// re-placing or stealing work from the groups at the front to those at the
+// back. Groups with the same score are ordered by name. Children removed
+// while ranking are skipped. ErrPressureNotSupported is returned when none
+// of the children has pressure stall information.
+func RankByPressure(mountpoint, parent string, opts ...RankOpts) ([]PressureRank, error) {
+	if err := VerifyGroupPath(parent); err != nil {
+		return nil, err
+	}
+	var config rankConfig
+	for _, o := range opts {
+		o(&config)
+	}
+	dir := filepath.Join(mountpoint, parent)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		ranks     []PressureRank
+		supported bool
+	)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		pressure, err := readPressure(filepath.Join(dir, e.Name()))
+		if err != nil {
+			if os.IsNotExist(errors.Cause(err)) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "rank %s", e.Name())
+		}
+		if len(pressure) > 0 {
+			supported = true
+		}
+		ranks = append(ranks, PressureRank{
+			Name:     e.Name(),
+			Score:    ContentionScore(pressure, config.window, config.weights),
+			Pressure: pressure,
+		})
+	}
+	if len(ranks) > 0 && !supported {
+		return nil, ErrPressureNotSupported
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		if ranks[i].Score != ranks[j].Score {
+			return ranks[i].Score > ranks[j].Score
+		}
+		return ranks[i].Name < ranks[j].Name
+	})
+	return ranks, nil
+}
+
+// readPressure reads the pressure stall information of the group at path,
+// skipping the resources it has no pressure file for. An error satisfying
+// os.IsNotExist is returned when the group itself does not exist.
+func readPressure(path string) (map[string]PSIStats, error) {
+	out := make(map[string]PSIStats, len(pressureResources))
+	for _, resource := range pressureResources {
+		file := resource + ".pressure"
+		data, err := readBounded(filepath.Join(path, file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				if _, serr := os.Stat(path); serr != nil {
+					return nil, serr
+				}
+				continue
+			}
+			if errors.Is(err, unix.EOPNOTSUPP) {
+				// PSI is disabled for the whole system
+				continue
+			}
+			return nil, err
+		}
+		out[resource] = parsePSI(data, file)
+	}
+	return out, nil
+}
@@ -0,0 +1,103 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePressure(t *testing.T, dir, resource string, some, full string) {
+	data := "some avg10=" + some + " avg60=0.00 avg300=0.00 total=0\n" +
+		"full avg10=" + full + " avg60=0.00 avg300=0.00 total=0\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, resource+".pressure"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRankByPressure(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-contention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	parent := filepath.Join(mountpoint, "parent")
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.MkdirAll(filepath.Join(parent, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePressure(t, filepath.Join(parent, "a"), "cpu", "10.00", "0.00")
+	writePressure(t, filepath.Join(parent, "a"), "memory", "0.00", "0.00")
+	writePressure(t, filepath.Join(parent, "b"), "cpu", "1.00", "0.00")
+	writePressure(t, filepath.Join(parent, "b"), "memory", "20.00", "10.00")
+	// c only has cpu pressure, its missing memory pressure is left out
+	writePressure(t, filepath.Join(parent, "c"), "cpu", "4.00", "0.00")
+
+	ranks, err := RankByPressure(mountpoint, "/parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		name  string
+		score float64
+	}{
+		{"b", 15.5},
+		{"a", 5},
+		{"c", 4},
+	}
+	if len(ranks) != len(expected) {
+		t.Fatalf("expected %d ranks, got %+v", len(expected), ranks)
+	}
+	for i, e := range expected {
+		if ranks[i].Name != e.name || ranks[i].Score != e.score {
+			t.Fatalf("expected rank %d to be %s with %v, got %s with %v", i, e.name, e.score, ranks[i].Name, ranks[i].Score)
+		}
+	}
+	if _, ok := ranks[2].Pressure["memory"]; ok {
+		t.Fatal("expected no memory pressure for c")
+	}
+
+	ranks, err = RankByPressure(mountpoint, "/parent", WithPressureWeights(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranks[0].Name != "a" || ranks[1].Name != "c" || ranks[2].Name != "b" {
+		t.Fatalf("expected the groups to be ranked by cpu pressure, got %+v", ranks)
+	}
+
+	if err := os.Mkdir(filepath.Join(mountpoint, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(mountpoint, "empty", "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RankByPressure(mountpoint, "/empty"); err != ErrPressureNotSupported {
+		t.Fatalf("expected ErrPressureNotSupported, got %v", err)
+	}
+}
+
+func TestPSIWindow(t *testing.T) {
+	d := PSIData{Avg10: 1, Avg60: 2, Avg300: 3}
+	for w, expected := range map[PSIWindow]float64{PSIAvg10: 1, PSIAvg60: 2, PSIAvg300: 3} {
+		if v := d.Avg(w); v != expected {
+			t.Fatalf("expected %v for window %d, got %v", expected, w, v)
+		}
+	}
+}
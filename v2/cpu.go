@@ -18,39 +18,39 @@ package v2
 
 import (
 	"math"
-	"strconv"
-	"strings"
+
+	"github.com/containerd/cgroups/topology"
+	"github.com/pkg/errors"
 )
 
 type CPUMax string
 
+// NewCPUMax returns the cpu.max value for quota and period, see FormatCPUMax
 func NewCPUMax(quota *int64, period *uint64) CPUMax {
-	max := "max"
-	if quota != nil {
-		max = strconv.FormatInt(*quota, 10)
-	}
-	return CPUMax(strings.Join([]string{max, strconv.FormatUint(*period, 10)}, " "))
+	return CPUMax(FormatCPUMax(quota, period))
 }
 
 type CPU struct {
-	Weight *uint64
-	Max    CPUMax
-	Cpus   string
-	Mems   string
+	Weight *uint64 `json:"weight,omitempty"`
+	Max    CPUMax  `json:"max,omitempty"`
+	Cpus   string  `json:"cpus,omitempty"`
+	Mems   string  `json:"mems,omitempty"`
 }
 
+// extractQuotaAndPeriod returns the quota, math.MaxInt64 for "max", and the
+// period, 0 when it is left out
 func (c CPUMax) extractQuotaAndPeriod() (int64, uint64) {
 	var (
-		quota  int64
-		period uint64
+		quota   int64 = math.MaxInt64
+		period  uint64
+		q, p, _ = ParseCPUMax(string(c))
 	)
-	values := strings.Split(string(c), " ")
-	if values[0] == "max" {
-		quota = math.MaxInt64
-	} else {
-		quota, _ = strconv.ParseInt(values[0], 10, 64)
+	if q != nil {
+		quota = *q
+	}
+	if p != nil {
+		period = *p
 	}
-	period, _ = strconv.ParseUint(values[1], 10, 64)
 	return quota, period
 }
 
@@ -81,3 +81,23 @@ func (r *CPU) Values() (o []Value) {
 	}
 	return o
 }
+
+// validate returns ErrMemsOffline if Mems includes NUMA nodes that are not
+// online, writing them to cpuset.mems fails with EINVAL
+func (r *CPU) validate() error {
+	if r.Mems == "" {
+		return nil
+	}
+	mems, err := topology.ParseCPUSet(r.Mems)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidFormat, "cpuset.mems %q", r.Mems)
+	}
+	online, err := topology.OnlineNodesFrom(sysfsRoot)
+	if err != nil {
+		return err
+	}
+	if offline := mems.Subtract(online); !offline.IsEmpty() {
+		return errors.Wrapf(ErrMemsOffline, "nodes %s", offline)
+	}
+	return nil
+}
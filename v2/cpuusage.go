@@ -0,0 +1,120 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+// CPUUtilization is the CPU time consumed by a cgroup over an interval,
+// normalized by the length of that interval. Values are expressed in CPUs,
+// so 1.0 is one fully busy CPU and 2.5 is two and a half.
+type CPUUtilization struct {
+	Total  float64
+	User   float64
+	System float64
+}
+
+// Percent returns the total utilization as a percentage of one CPU
+func (u CPUUtilization) Percent() float64 {
+	return u.Total * 100
+}
+
+// PercentOfLimit returns the total utilization as a percentage of the
+// cpu.max limit. It returns 0 when the cgroup is not limited.
+func (u CPUUtilization) PercentOfLimit(max CPUMax) float64 {
+	if len(strings.Fields(string(max))) != 2 {
+		return 0
+	}
+	quota, period := max.extractQuotaAndPeriod()
+	if quota <= 0 || quota == math.MaxInt64 || period == 0 {
+		return 0
+	}
+	return u.Total / (float64(quota) / float64(period)) * 100
+}
+
+// CPUUsage computes the utilization between two samples of cgroup metrics
+// taken elapsed apart. cpu.stat reports all usage in microseconds.
+// ErrNilMetrics is returned when either sample is nil.
+func CPUUsage(prev, cur *stats.Metrics, elapsed time.Duration) (CPUUtilization, error) {
+	if elapsed <= 0 {
+		return CPUUtilization{}, ErrInvalidInterval
+	}
+	if prev == nil || cur == nil {
+		return CPUUtilization{}, ErrNilMetrics
+	}
+	var a, b stats.CPUStat
+	if prev.CPU != nil {
+		a = *prev.CPU
+	}
+	if cur.CPU != nil {
+		b = *cur.CPU
+	}
+	if b.UsageUsec < a.UsageUsec || b.UserUsec < a.UserUsec || b.SystemUsec < a.SystemUsec {
+		return CPUUtilization{}, ErrCounterReset
+	}
+	usec := float64(elapsed.Nanoseconds()) / float64(time.Microsecond)
+	return CPUUtilization{
+		Total:  float64(b.UsageUsec-a.UsageUsec) / usec,
+		User:   float64(b.UserUsec-a.UserUsec) / usec,
+		System: float64(b.SystemUsec-a.SystemUsec) / usec,
+	}, nil
+}
+
+// Throttling describes how much a cgroup was held back by its cpu.max limit
+// over an interval
+type Throttling struct {
+	// Periods is the fraction of enforcement periods in which the cgroup
+	// was throttled, between 0 and 1
+	Periods float64
+	// Time is the share of the interval the cgroup spent throttled
+	Time float64
+}
+
+// ThrottleRatio computes the throttling between two samples of cgroup
+// metrics taken elapsed apart from the nr_periods, nr_throttled and
+// throttled_usec counters of cpu.stat. A Periods value consistently above
+// a few percent is the usual signal that the CPU limit is set too low.
+// ErrNilMetrics is returned when either sample is nil.
+func ThrottleRatio(prev, cur *stats.Metrics, elapsed time.Duration) (Throttling, error) {
+	if elapsed <= 0 {
+		return Throttling{}, ErrInvalidInterval
+	}
+	if prev == nil || cur == nil {
+		return Throttling{}, ErrNilMetrics
+	}
+	var a, b stats.CPUStat
+	if prev.CPU != nil {
+		a = *prev.CPU
+	}
+	if cur.CPU != nil {
+		b = *cur.CPU
+	}
+	if b.NrPeriods < a.NrPeriods || b.NrThrottled < a.NrThrottled || b.ThrottledUsec < a.ThrottledUsec {
+		return Throttling{}, ErrCounterReset
+	}
+	var t Throttling
+	if periods := b.NrPeriods - a.NrPeriods; periods > 0 {
+		t.Periods = float64(b.NrThrottled-a.NrThrottled) / float64(periods)
+	}
+	t.Time = float64(b.ThrottledUsec-a.ThrottledUsec) / (float64(elapsed.Nanoseconds()) / float64(time.Microsecond))
+	return t, nil
+}
@@ -0,0 +1,69 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestCPUUsage(t *testing.T) {
+	prev := &stats.Metrics{CPU: &stats.CPUStat{UsageUsec: 1e6, UserUsec: 5e5, SystemUsec: 5e5}}
+	cur := &stats.Metrics{CPU: &stats.CPUStat{UsageUsec: 2e6, UserUsec: 1e6, SystemUsec: 1e6}}
+	u, err := CPUUsage(prev, cur, 4*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Total != 0.25 || u.User != 0.125 || u.System != 0.125 {
+		t.Fatalf("unexpected utilization %+v", u)
+	}
+	for max, want := range map[CPUMax]float64{
+		"50000 100000": 50,
+		"max 100000":   0,
+		"":             0,
+	} {
+		if p := u.PercentOfLimit(max); p != want {
+			t.Errorf("%q: expected %v%% of limit, got %v", max, want, p)
+		}
+	}
+	if _, err := CPUUsage(cur, prev, time.Second); err != ErrCounterReset {
+		t.Fatalf("expected ErrCounterReset, got %v", err)
+	}
+	if _, err := CPUUsage(nil, cur, time.Second); err != ErrNilMetrics {
+		t.Fatalf("expected ErrNilMetrics, got %v", err)
+	}
+}
+
+func TestThrottleRatio(t *testing.T) {
+	prev := &stats.Metrics{CPU: &stats.CPUStat{NrPeriods: 100, NrThrottled: 10, ThrottledUsec: 1e5}}
+	cur := &stats.Metrics{CPU: &stats.CPUStat{NrPeriods: 200, NrThrottled: 35, ThrottledUsec: 6e5}}
+	r, err := ThrottleRatio(prev, cur, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Periods != 0.25 || r.Time != 0.05 {
+		t.Fatalf("unexpected throttling %+v", r)
+	}
+	if _, err := ThrottleRatio(cur, prev, time.Second); err != ErrCounterReset {
+		t.Fatalf("expected ErrCounterReset, got %v", err)
+	}
+	if _, err := ThrottleRatio(prev, nil, time.Second); err != ErrNilMetrics {
+		t.Fatalf("expected ErrNilMetrics, got %v", err)
+	}
+}
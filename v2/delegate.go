@@ -0,0 +1,85 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// kernelDelegateFile lists the files that must be owned by a delegatee
+var kernelDelegateFile = "/sys/kernel/cgroup/delegate"
+
+// defaultDelegateFiles are used on kernels without kernelDelegateFile
+var defaultDelegateFiles = []string{
+	cgroupProcs,
+	cgroupThreads,
+	subtreeControl,
+}
+
+// delegateFiles returns the files in a group that a delegatee must own
+func delegateFiles() []string {
+	f, err := os.Open(kernelDelegateFile)
+	if err != nil {
+		return defaultDelegateFiles
+	}
+	defer f.Close()
+	var files []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if name := strings.TrimSpace(s.Text()); name != "" {
+			files = append(files, name)
+		}
+	}
+	if s.Err() != nil || len(files) == 0 {
+		return defaultDelegateFiles
+	}
+	return files
+}
+
+// delegate chowns the group and its delegatable files to the owner. Files
+// that do not exist on the running kernel are skipped.
+func delegate(path string, owner *Owner) error {
+	if owner == nil {
+		return nil
+	}
+	if err := os.Chown(path, owner.UID, owner.GID); err != nil {
+		return err
+	}
+	for _, name := range delegateFiles() {
+		if err := os.Chown(filepath.Join(path, name), owner.UID, owner.GID); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyFileModes chmods the interface files of the group, skipping files that
+// do not exist on the running kernel with a warning
+func applyFileModes(path string, modes map[string]os.FileMode, warnings *Warnings) error {
+	for name, mode := range modes {
+		if err := os.Chmod(filepath.Join(path, name), mode); err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+			warnings.add(name, "file mode not applied, the file does not exist")
+		}
+	}
+	return nil
+}
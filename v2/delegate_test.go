@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestNewManagerWithOwner(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("chown requires root")
+	}
+	mountpoint, err := ioutil.TempDir("", "cgroups-delegate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	path := filepath.Join(mountpoint, "delegated")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{subtreeControl, filepath.Join("delegated", cgroupProcs), filepath.Join("delegated", "memory.max")} {
+		if err := ioutil.WriteFile(filepath.Join(mountpoint, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewManager(mountpoint, "/delegated", &Resources{}, WithOwner(1000, 1001)); err != nil {
+		t.Fatal(err)
+	}
+	for name, delegated := range map[string]bool{
+		"":          true,
+		cgroupProcs: true,
+		// interface files for the resources stay owned by the parent
+		"memory.max": false,
+	} {
+		fi, err := os.Stat(filepath.Join(path, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		st := fi.Sys().(*syscall.Stat_t)
+		if owned := st.Uid == 1000 && st.Gid == 1001; owned != delegated {
+			t.Errorf("%q: expected delegated %v but owned by %d:%d", name, delegated, st.Uid, st.Gid)
+		}
+	}
+}
+
+func TestNewManagerWithFileModes(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-modes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	path := filepath.Join(mountpoint, "supervised")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{subtreeControl, filepath.Join("supervised", cgroupProcs)} {
+		if err := ioutil.WriteFile(filepath.Join(mountpoint, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewManager(mountpoint, "/supervised", &Resources{}, WithFileModes(map[string]os.FileMode{
+		cgroupProcs:      0664,
+		"cgroup.threads": 0664,
+	})); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(filepath.Join(path, cgroupProcs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := fi.Mode().Perm(); mode != 0664 {
+		t.Fatalf("expected cgroup.procs mode 0664 but received %o", mode)
+	}
+	for _, name := range []string{"../cgroup.procs", ".", "..", ""} {
+		if _, err := NewManager(mountpoint, "/supervised", &Resources{}, WithFileModes(map[string]os.FileMode{
+			name: 0666,
+		})); err == nil {
+			t.Fatalf("expected an error for the file mode of %q", name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"github.com/containerd/cgroups/internal/cgfs"
+	perrors "github.com/pkg/errors"
+)
+
+// ErrorKind categorizes the errors returned by the package so that callers
+// can decide how to handle them without matching on error strings
+type ErrorKind = cgfs.ErrorKind
+
+const (
+	// KindUnknown is any error that does not fit another kind
+	KindUnknown = cgfs.KindUnknown
+	// KindNotFound is returned when the cgroup, a controller's file or the
+	// cgroup filesystem does not exist
+	KindNotFound = cgfs.KindNotFound
+	// KindPermissionDenied is returned when the caller cannot modify the cgroup
+	KindPermissionDenied = cgfs.KindPermissionDenied
+	// KindUnsupported is returned when the kernel does not support a
+	// controller or feature
+	KindUnsupported = cgfs.KindUnsupported
+	// KindBusy is returned for transient failures that may succeed if the
+	// operation is retried, such as removing a cgroup that still has tasks
+	KindBusy = cgfs.KindBusy
+	// KindInvalidInput is returned when a value is rejected as invalid
+	KindInvalidInput = cgfs.KindInvalidInput
+)
+
+// KindOf returns the kind of err, looking through wrapped errors
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return KindUnknown
+	}
+	cause := perrors.Cause(err)
+	switch cause {
+	case ErrCgroupDeleted, ErrMountPointNotExist, ErrNoCgroupMountDestination, ErrDying, ErrProcessNotFound:
+		return KindNotFound
+	case ErrFrozen, ErrRateLimited:
+		return KindBusy
+	case ErrFreezerNotSupported, ErrMemoryNotSupported, ErrPidsNotSupported, ErrCPUNotSupported,
+		ErrHugePageSizeNotSupported, ErrPressureNotSupported, ErrDeleteFromFD, ErrAnnotationsNotSupported:
+		return KindUnsupported
+	case ErrInvalidPid, ErrInvalidFormat, ErrInvalidGroupPath, ErrInvalidInterval, ErrNilMetrics,
+		ErrMemsOffline, ErrPolicyViolation, ErrInvalidPriority, ErrInvalidAnnotation, ErrInvalidCPULimit:
+		return KindInvalidInput
+	}
+	if _, ok := cause.(*UnknownDevicesError); ok {
+		return KindInvalidInput
+	}
+	if m, ok := cause.(*MultiError); ok {
+		return m.kind()
+	}
+	return cgfs.KindOf(err)
+}
+
+// IsRetryable returns true if err is transient and the operation that
+// returned it can be retried
+func IsRetryable(err error) bool {
+	return KindOf(err) == KindBusy
+}
+
+// kind returns the kind shared by all the errors, KindUnknown when they
+// differ
+func (e *MultiError) kind() ErrorKind {
+	if len(e.Errors) == 0 {
+		return KindUnknown
+	}
+	kind := KindOf(e.Errors[0])
+	for _, err := range e.Errors[1:] {
+		if KindOf(err) != kind {
+			return KindUnknown
+		}
+	}
+	return kind
+}
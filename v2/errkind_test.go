@@ -0,0 +1,47 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestKindOf(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		kind ErrorKind
+	}{
+		{ErrInvalidGroupPath, KindInvalidInput},
+		{&UnknownDevicesError{Devices: []string{"/dev/sdz"}}, KindInvalidInput},
+		{errors.Wrap(ErrPidsNotSupported, "update"), KindUnsupported},
+		{&os.PathError{Op: "write", Path: "io.max", Err: syscall.ENODEV}, KindUnsupported},
+		{errors.Wrapf(&os.PathError{Op: "remove", Path: "test", Err: syscall.EBUSY}, "cgroups: unable to remove path %q", "test"), KindBusy},
+		{errors.Wrap(ErrFrozen, "write cgroup.procs"), KindBusy},
+		{errors.Wrap(ErrMemsOffline, "nodes 1"), KindInvalidInput},
+		{errors.Wrap(ErrPolicyViolation, "memory.max"), KindInvalidInput},
+		{errors.Wrap(ErrRateLimited, "update"), KindBusy},
+		{errors.Wrap(ErrDying, "write cgroup.procs"), KindNotFound},
+	} {
+		if kind := KindOf(tc.err); kind != tc.kind {
+			t.Errorf("expected %v to be %s but received %s", tc.err, tc.kind, kind)
+		}
+	}
+}
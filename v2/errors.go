@@ -19,6 +19,8 @@ package v2
 import (
 	"errors"
 	"os"
+
+	"github.com/containerd/cgroups/internal/cgfs"
 )
 
 var (
@@ -29,11 +31,63 @@ var (
 	ErrMemoryNotSupported       = errors.New("cgroups: memory cgroup (v2) not supported on this system")
 	ErrPidsNotSupported         = errors.New("cgroups: pids cgroup (v2) not supported on this system")
 	ErrCPUNotSupported          = errors.New("cgroups: cpu cgroup (v2) not supported on this system")
+	ErrPressureNotSupported     = errors.New("cgroups: cgroup.pressure not supported on this system")
 	ErrCgroupDeleted            = errors.New("cgroups: cgroup deleted")
 	ErrNoCgroupMountDestination = errors.New("cgroups: cannot find cgroup mount destination")
 	ErrInvalidGroupPath         = errors.New("cgroups: invalid group path")
+	ErrHugePageSizeNotSupported = errors.New("cgroups: hugepage size not supported on this system")
+	ErrUnexpectedFileType       = cgfs.ErrUnexpectedFileType
+	ErrInvalidInterval          = errors.New("cgroups: sample interval must be greater than 0")
+	ErrCounterReset             = errors.New("cgroups: counter decreased between samples")
+	ErrNilMetrics               = errors.New("cgroups: metrics reference is nil")
+	ErrFrozen                   = errors.New("cgroups: cgroup is frozen, thaw it before adding processes")
+	ErrDying                    = errors.New("cgroups: cgroup is being removed")
+	ErrUnitExists               = cgfs.ErrUnitExists
+	ErrUnitMasked               = cgfs.ErrUnitMasked
+	ErrDependencyFailed         = cgfs.ErrDependencyFailed
+	ErrJobFailed                = cgfs.ErrJobFailed
+	ErrTimeout                  = errors.New("cgroups: operation timed out")
+	ErrMemsOffline              = errors.New("cgroups: cpuset.mems includes offline NUMA nodes")
+	ErrPolicyViolation          = errors.New("cgroups: resources violate the policy")
+	ErrRateLimited              = errors.New("cgroups: rate limit of changes exceeded")
+	ErrInvalidPriority          = errors.New("cgroups: priority must be greater than 0")
+	ErrProcessNotFound          = errors.New("cgroups: process does not exist")
+	ErrInvalidAnnotation        = errors.New("cgroups: annotation key must not be empty or contain NUL bytes")
+	ErrAnnotationsNotSupported  = errors.New("cgroups: annotations require extended attributes on cgroupfs, supported since Linux 5.7")
+	ErrMuxClosed                = errors.New("cgroups: event mux is closed")
+	ErrInvalidCPULimit          = errors.New("cgroups: cpu limit cannot be expressed as a cfs quota and period")
+	ErrDeleteFromFD             = errors.New("cgroups: a cgroup opened from an fd cannot be deleted through it")
 )
 
+// StateError is returned when the kernel refused to move a process into a
+// cgroup because of the cgroup's state. errors.Cause returns the state,
+// ErrFrozen or ErrDying, while errors.Is and errors.As also match the
+// kernel's error.
+type StateError struct {
+	// State is ErrFrozen or ErrDying
+	State error
+	Err   error
+}
+
+func (e *StateError) Error() string {
+	return e.State.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the kernel's error
+func (e *StateError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the state for github.com/pkg/errors
+func (e *StateError) Cause() error {
+	return e.State
+}
+
+// Is matches the state
+func (e *StateError) Is(target error) bool {
+	return target == e.State
+}
+
 // ErrorHandler is a function that handles and acts on errors
 type ErrorHandler func(err error) error
 
@@ -0,0 +1,331 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultReplaySize is the number of events an EventMux keeps per group
+	// for late subscribers
+	DefaultReplaySize = 16
+	// DefaultReplayWindow is how long an EventMux replays an event to late
+	// subscribers
+	DefaultReplayWindow = 30 * time.Second
+)
+
+// EventMuxOpts configures an EventMux
+type EventMuxOpts func(*eventMuxConfig)
+
+type eventMuxConfig struct {
+	replaySize   int
+	replayWindow time.Duration
+	watchOpts    []WatchOpts
+	clock        clock.Clock
+}
+
+// WithReplay sets the number of events kept per group and how long they are
+// replayed to subscribers attaching after them. A size of 0 disables replay.
+func WithReplay(size int, window time.Duration) EventMuxOpts {
+	return func(c *eventMuxConfig) {
+		c.replaySize = size
+		c.replayWindow = window
+	}
+}
+
+// WithEventWatchOpts sets the options of the watchers of memory.events
+func WithEventWatchOpts(opts ...WatchOpts) EventMuxOpts {
+	return func(c *eventMuxConfig) {
+		c.watchOpts = opts
+	}
+}
+
+// WithMuxClock sets the clock timestamping events and bounding the replay
+// window, it also paces the polling of the watchers
+func WithMuxClock(c clock.Clock) EventMuxOpts {
+	return func(config *eventMuxConfig) {
+		config.clock = c
+	}
+}
+
+// MuxEvent is a memory event delivered by an EventMux
+type MuxEvent struct {
+	Event
+	// Time is when the event was observed
+	Time time.Time
+	// Replayed is true for events observed before the subscriber attached
+	Replayed bool
+}
+
+// EventMux shares a single watch of memory.events per group among any
+// number of subscribers. It keeps the recent events of every watched group
+// in a bounded replay buffer, so that a subscriber attaching shortly after
+// an OOM still observes it, e.g. a watchdog that starts after the container
+// it monitors.
+//
+// Events are delivered to each subscriber through a channel buffered by the
+// replay size. A subscriber that does not keep up misses events, the
+// counters of the next event it receives include the missed ones.
+type EventMux struct {
+	config eventMuxConfig
+
+	mu     sync.Mutex
+	groups map[string]*muxGroup
+	closed bool
+}
+
+// NewEventMux returns an EventMux watching no groups
+func NewEventMux(opts ...EventMuxOpts) *EventMux {
+	config := eventMuxConfig{
+		replaySize:   DefaultReplaySize,
+		replayWindow: DefaultReplayWindow,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	config.clock = clock.Or(config.clock)
+	return &EventMux{
+		config: config,
+		groups: make(map[string]*muxGroup),
+	}
+}
+
+// muxGroup is a watched group and its subscribers
+type muxGroup struct {
+	cancel      context.CancelFunc
+	replay      []MuxEvent
+	subscribers map[*muxSubscriber]struct{}
+	// watched is true when the group was watched with Watch, otherwise it
+	// is only watched while it has subscribers
+	watched bool
+}
+
+type muxSubscriber struct {
+	ch    chan MuxEvent
+	errCh chan error
+	// done is closed by the mux when it closes ch and errCh
+	done chan struct{}
+}
+
+// close delivers err, if it is not nil, and closes the channels of the
+// subscriber, x.mu must be held
+func (s *muxSubscriber) close(err error) {
+	if err != nil {
+		s.errCh <- err
+	}
+	close(s.ch)
+	close(s.errCh)
+	close(s.done)
+}
+
+// Watch starts watching the memory events of the group of m, recording them
+// for later subscribers. Watching a group that is already watched is a
+// no-op. The group is watched until Unwatch or Close is called or it is
+// removed.
+func (x *EventMux) Watch(m *Manager) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g, err := x.watch(m)
+	if err != nil {
+		return err
+	}
+	g.watched = true
+	return nil
+}
+
+func (x *EventMux) watch(m *Manager) (*muxGroup, error) {
+	if x.closed {
+		return nil, ErrMuxClosed
+	}
+	if g, ok := x.groups[m.path]; ok {
+		return g, nil
+	}
+	opts := append([]WatchOpts{WithWatchClock(x.config.clock)}, x.config.watchOpts...)
+	w, err := NewWatcher(m.path, []string{"memory.events"}, opts...)
+	if err != nil {
+		return nil, watchError(m.path, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &muxGroup{
+		cancel:      cancel,
+		subscribers: make(map[*muxSubscriber]struct{}),
+	}
+	x.groups[m.path] = g
+	go x.run(ctx, m.path, g, w)
+	return g, nil
+}
+
+// Unwatch stops watching the group of m, closing the channels of its
+// subscribers
+func (x *EventMux) Unwatch(m *Manager) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if g, ok := x.groups[m.path]; ok {
+		x.remove(m.path, g, nil)
+	}
+}
+
+// Close stops watching all groups, closing the channels of all subscribers
+func (x *EventMux) Close() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.closed = true
+	for path, g := range x.groups {
+		x.remove(path, g, nil)
+	}
+}
+
+// Subscribe delivers the memory events of the group of m until ctx is done,
+// starting the watch of the group if it is not watched yet. A watch started
+// by Subscribe ends with the last of its subscribers. Events recorded
+// within the replay window are delivered first, marked as replayed. An
+// error is delivered if the watch of the group fails, wrapping
+// ErrCgroupDeleted when the group is removed. Both channels are closed once
+// ctx is done, the group is no longer watched or an error was delivered.
+func (x *EventMux) Subscribe(ctx context.Context, m *Manager) (<-chan MuxEvent, <-chan error) {
+	s := &muxSubscriber{
+		ch:    make(chan MuxEvent, x.config.replaySize+1),
+		errCh: make(chan error, 1),
+		done:  make(chan struct{}),
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	g, err := x.watch(m)
+	if err != nil {
+		s.close(err)
+		return s.ch, s.errCh
+	}
+	cutoff := x.config.clock.Now().Add(-x.config.replayWindow)
+	for _, e := range g.replay {
+		if e.Time.After(cutoff) {
+			e.Replayed = true
+			s.ch <- e
+		}
+	}
+	g.subscribers[s] = struct{}{}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.done:
+			// closed by the mux, nothing to unsubscribe
+			return
+		}
+		x.mu.Lock()
+		defer x.mu.Unlock()
+		if _, ok := g.subscribers[s]; !ok {
+			return
+		}
+		delete(g.subscribers, s)
+		s.close(nil)
+		if len(g.subscribers) == 0 && !g.watched && x.groups[m.path] == g {
+			x.remove(m.path, g, nil)
+		}
+	}()
+	return s.ch, s.errCh
+}
+
+// run delivers the events of a group until the watch fails or is canceled
+func (x *EventMux) run(ctx context.Context, path string, g *muxGroup, w *Watcher) {
+	defer w.Close()
+	for {
+		changes, err := w.Wait(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			x.mu.Lock()
+			if x.groups[path] == g {
+				x.remove(path, g, watchError(path, err))
+			}
+			x.mu.Unlock()
+			return
+		}
+		for _, change := range changes {
+			event, err := parseMemoryEvents(path, change.New)
+			if err != nil {
+				continue
+			}
+			x.publish(g, MuxEvent{
+				Event: event,
+				Time:  x.config.clock.Now(),
+			})
+		}
+	}
+}
+
+// publish records the event for replay and delivers it to the subscribers
+func (x *EventMux) publish(g *muxGroup, e MuxEvent) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if size := x.config.replaySize; size > 0 {
+		if len(g.replay) == size {
+			copy(g.replay, g.replay[1:])
+			g.replay = g.replay[:size-1]
+		}
+		g.replay = append(g.replay, e)
+	}
+	for s := range g.subscribers {
+		select {
+		case s.ch <- e:
+		default:
+			// the subscriber is not keeping up
+		}
+	}
+}
+
+// remove stops watching the group, delivering err to its subscribers if
+// it is not nil, x.mu must be held
+func (x *EventMux) remove(path string, g *muxGroup, err error) {
+	g.cancel()
+	delete(x.groups, path)
+	for s := range g.subscribers {
+		s.close(err)
+	}
+	g.subscribers = nil
+}
+
+// watchError returns ErrCgroupDeleted for a watch failing because the group
+// no longer exists
+func watchError(path string, err error) error {
+	if os.IsNotExist(errors.Cause(err)) {
+		return errors.Wrapf(ErrCgroupDeleted, "%s", path)
+	}
+	return err
+}
+
+// parseMemoryEvents parses the contents of memory.events
+func parseMemoryEvents(path, data string) (Event, error) {
+	out := make(map[string]interface{})
+	if err := parseKVStats(strings.NewReader(data), path, out); err != nil {
+		return Event{}, err
+	}
+	return Event{
+		Low:     getUint64Value("low", out),
+		High:    getUint64Value("high", out),
+		Max:     getUint64Value("max", out),
+		OOM:     getUint64Value("oom", out),
+		OOMKill: getUint64Value("oom_kill", out),
+	}, nil
+}
@@ -0,0 +1,214 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func writeMemoryEvents(t *testing.T, dir string, oom uint64) {
+	data := []byte("low 0\nhigh 0\nmax 0\noom " + strconv.FormatUint(oom, 10) + "\noom_kill " + strconv.FormatUint(oom, 10) + "\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "memory.events"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receiveMuxEvent(t *testing.T, ch <-chan MuxEvent) MuxEvent {
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("expected an event, channel closed")
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return MuxEvent{}
+}
+
+func TestEventMuxReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-eventmux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMemoryEvents(t, dir, 0)
+
+	x := NewEventMux(WithEventWatchOpts(WithPolling(), WithPollInterval(time.Millisecond, 10*time.Millisecond)))
+	defer x.Close()
+	m := &Manager{path: dir}
+	if err := x.Watch(m); err != nil {
+		t.Fatal(err)
+	}
+
+	// the OOM happens before anyone subscribes
+	writeMemoryEvents(t, dir, 1)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		x.mu.Lock()
+		n := len(x.groups[dir].replay)
+		x.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the event to be recorded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, errCh := x.Subscribe(ctx, m)
+	e := receiveMuxEvent(t, ch)
+	if !e.Replayed || e.OOM != 1 || e.OOMKill != 1 {
+		t.Fatalf("expected replayed OOM event, got %+v", e)
+	}
+
+	writeMemoryEvents(t, dir, 2)
+	e = receiveMuxEvent(t, ch)
+	if e.Replayed || e.OOM != 2 {
+		t.Fatalf("expected live OOM event, got %+v", e)
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("expected events channel to be closed")
+	}
+	if err, ok := <-errCh; ok {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestEventMuxReplayLimits(t *testing.T) {
+	x := NewEventMux(WithReplay(2, time.Minute))
+	g := &muxGroup{}
+	now := time.Now()
+	for i := uint64(1); i <= 3; i++ {
+		x.publish(g, MuxEvent{Event: Event{OOM: i}, Time: now})
+	}
+	if len(g.replay) != 2 || g.replay[0].OOM != 2 || g.replay[1].OOM != 3 {
+		t.Fatalf("expected the last 2 events to be kept, got %+v", g.replay)
+	}
+
+	dir, err := ioutil.TempDir("", "cgroups-eventmux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMemoryEvents(t, dir, 0)
+	m := &Manager{path: dir}
+	if err := x.Watch(m); err != nil {
+		t.Fatal(err)
+	}
+	defer x.Close()
+	x.mu.Lock()
+	x.groups[dir].replay = []MuxEvent{
+		{Event: Event{OOM: 1}, Time: now.Add(-2 * time.Minute)},
+		{Event: Event{OOM: 2}, Time: now},
+	}
+	x.mu.Unlock()
+
+	ch, _ := x.Subscribe(context.Background(), m)
+	e := receiveMuxEvent(t, ch)
+	if e.OOM != 2 {
+		t.Fatalf("expected events outside the window to be skipped, got %+v", e)
+	}
+}
+
+func TestEventMuxRemoved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-eventmux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMemoryEvents(t, dir, 0)
+
+	x := NewEventMux(WithEventWatchOpts(WithPolling(), WithPollInterval(time.Millisecond, 10*time.Millisecond)))
+	defer x.Close()
+	m := &Manager{path: dir}
+	ch, errCh := x.Subscribe(context.Background(), m)
+	if err := os.Remove(filepath.Join(dir, "memory.events")); err != nil {
+		t.Fatal(err)
+	}
+	// drain the events first, the error has to still be delivered
+	timeout := time.After(5 * time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-ch:
+		case <-timeout:
+			t.Fatal("timed out waiting for the events channel to be closed")
+		}
+	}
+	err, ok := <-errCh
+	if !ok || errors.Cause(err) != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted once the group is removed, got %v %v", err, ok)
+	}
+
+	x.Close()
+	_, errCh = x.Subscribe(context.Background(), m)
+	if err := <-errCh; err != ErrMuxClosed {
+		t.Fatalf("expected ErrMuxClosed, got %v", err)
+	}
+}
+
+func TestEventMuxSubscriptionEndsWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-eventmux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeMemoryEvents(t, dir, 0)
+
+	x := NewEventMux(WithEventWatchOpts(WithPolling()))
+	defer x.Close()
+	m := &Manager{path: dir}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, _ := x.Subscribe(ctx, m)
+	cancel()
+	for range ch {
+	}
+	x.mu.Lock()
+	n := len(x.groups)
+	x.mu.Unlock()
+	if n != 0 {
+		t.Fatal("expected the watch to end with its last subscriber")
+	}
+
+	if err := x.Watch(m); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	ch, _ = x.Subscribe(ctx, m)
+	cancel()
+	for range ch {
+	}
+	x.mu.Lock()
+	n = len(x.groups)
+	x.mu.Unlock()
+	if n != 1 {
+		t.Fatal("expected a watched group to stay watched without subscribers")
+	}
+}
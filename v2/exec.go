@@ -0,0 +1,139 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// shimScript moves the shell into the cgroup by writing "0" to the
+// cgroup.procs file passed as $0, then replaces itself with the command
+const shimScript = `echo 0 > "$0" && exec "$@"`
+
+var (
+	shimShell = "/bin/sh"
+
+	cgroupFDOnce      sync.Once
+	cgroupFDSupported bool
+)
+
+// Exec starts the named program with the given arguments inside the cgroup
+// and returns the running command. The caller is responsible for waiting on it.
+func (c *Manager) Exec(name string, arg ...string) (*exec.Cmd, error) {
+	cmd := exec.Command(name, arg...)
+	if err := c.Start(cmd); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// Start starts cmd so that the new process begins execution inside the
+// cgroup, without the window in which it runs in the parent's cgroup that
+// a Start followed by AddProc would leave.
+//
+// On kernels supporting clone3 with CLONE_INTO_CGROUP (5.7+) the process is
+// created directly in the cgroup. On older kernels, where a seccomp filter
+// denies clone3, or when built with a Go toolchain lacking
+// SysProcAttr.CgroupFD, the command is run through a
+// /bin/sh shim that attaches itself to the cgroup before exec'ing the
+// program; in that case the program is started with its path as argv[0].
+//
+// Start does not go through the configured interceptors as the pid is not
+// known before the process is running.
+func (c *Manager) Start(cmd *exec.Cmd) error {
+	if cmd.Process != nil {
+		return errors.New("exec: already started")
+	}
+	if kernelSupportsCgroupFD() {
+		if ok, err := startCgroupFD(cmd, c.path); ok {
+			return err
+		}
+	}
+	return startShim(cmd, c.path)
+}
+
+// startShim wraps cmd in a shell that attaches itself to the cgroup at path
+// before exec'ing the original program
+func startShim(cmd *exec.Cmd, path string) error {
+	if cmd.Path == "" {
+		return errors.New("exec: no command")
+	}
+	origPath, origArgs := cmd.Path, cmd.Args
+	defer func() {
+		cmd.Path, cmd.Args = origPath, origArgs
+	}()
+	args := []string{"sh", "-c", shimScript, filepath.Join(path, cgroupProcs), origPath}
+	if len(origArgs) > 1 {
+		args = append(args, origArgs[1:]...)
+	}
+	cmd.Path, cmd.Args = shimShell, args
+	return cmd.Start()
+}
+
+// kernelSupportsCgroupFD reports whether the running kernel is at least 5.7,
+// the first release supporting CLONE_INTO_CGROUP, and clone3 may be called.
+// Seccomp profiles of container runtimes commonly deny clone3 with ENOSYS or
+// EPERM, which a failed Start cannot recover from as an exec.Cmd cannot be
+// started twice, so clone3 is probed up front.
+func kernelSupportsCgroupFD() bool {
+	cgroupFDOnce.Do(func() {
+		var uts unix.Utsname
+		if err := unix.Uname(&uts); err != nil {
+			return
+		}
+		major, minor, ok := parseKernelVersion(string(bytes.TrimRight(uts.Release[:], "\x00")))
+		cgroupFDSupported = ok && (major > 5 || (major == 5 && minor >= 7)) && clone3Allowed()
+	})
+	return cgroupFDSupported
+}
+
+// clone3Allowed calls clone3 without arguments, which the kernel rejects with
+// EINVAL while a seccomp filter denying the call fails with another error
+func clone3Allowed() bool {
+	_, _, errno := unix.Syscall(unix.SYS_CLONE3, 0, 0, 0)
+	return errno == unix.EINVAL
+}
+
+// parseKernelVersion returns the major and minor numbers of a release string
+// such as "5.10.0-21-amd64"
+func parseKernelVersion(release string) (int, int, bool) {
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor := parts[1]
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minor = minor[:i]
+	}
+	n, err := strconv.Atoi(minor)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, n, true
+}
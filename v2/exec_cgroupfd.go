@@ -0,0 +1,54 @@
+//go:build go1.20
+// +build go1.20
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// startCgroupFD starts cmd with clone3 placing the child directly into the
+// cgroup at path. It returns false if the cgroup could not be opened so that
+// the caller can fall back to the shim. Whether clone3 may be called at all
+// is probed by kernelSupportsCgroupFD before cmd is started, as cmd cannot
+// be started again through the shim once Start failed.
+func startCgroupFD(cmd *exec.Cmd, path string) (bool, error) {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return false, nil
+	}
+	defer unix.Close(fd)
+
+	orig := cmd.SysProcAttr
+	attr := &syscall.SysProcAttr{}
+	if orig != nil {
+		copied := *orig
+		attr = &copied
+	}
+	attr.UseCgroupFD = true
+	attr.CgroupFD = fd
+	cmd.SysProcAttr = attr
+	defer func() {
+		cmd.SysProcAttr = orig
+	}()
+	return true, cmd.Start()
+}
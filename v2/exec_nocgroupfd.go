@@ -0,0 +1,28 @@
+//go:build !go1.20
+// +build !go1.20
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import "os/exec"
+
+// startCgroupFD always falls back to the shim as SysProcAttr.CgroupFD
+// requires go1.20
+func startCgroupFD(_ *exec.Cmd, _ string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containerd/cgroups/cgroupstest"
+)
+
+func TestParseKernelVersion(t *testing.T) {
+	for release, want := range map[string][2]int{
+		"5.7.0":           {5, 7},
+		"5.10.0-21-amd64": {5, 10},
+		"6.1+":            {6, 1},
+		"4.19":            {4, 19},
+	} {
+		major, minor, ok := parseKernelVersion(release)
+		if !ok || major != want[0] || minor != want[1] {
+			t.Errorf("%s: got %d.%d (%v), want %d.%d", release, major, minor, ok, want[0], want[1])
+		}
+	}
+	if _, _, ok := parseKernelVersion("garbage"); ok {
+		t.Error("expected invalid release to fail")
+	}
+}
+
+func TestManagerStart(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	group := filepath.Join(sandbox.Group, "exec")
+	m, err := NewManager(sandbox.Mountpoint, group, &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Delete()
+
+	for name, start := range map[string]func(*testing.T) (string, error){
+		"cgroupfd": func(t *testing.T) (string, error) {
+			if !kernelSupportsCgroupFD() {
+				t.Skip("kernel does not support CLONE_INTO_CGROUP")
+			}
+			return run(t, m.Start)
+		},
+		"shim": func(t *testing.T) (string, error) {
+			return run(t, func(cmd *exec.Cmd) error {
+				return startShim(cmd, m.path)
+			})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out, err := start(t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "0::" + group; !strings.Contains(out, want) {
+				t.Fatalf("expected %q in %q", want, out)
+			}
+		})
+	}
+}
+
+func run(t *testing.T, start func(*exec.Cmd) error) (string, error) {
+	var buf bytes.Buffer
+	cmd := exec.Command("cat", "/proc/self/cgroup")
+	cmd.Stdout = &buf
+	if err := start(cmd); err != nil {
+		return "", err
+	}
+	if cmd.Args[0] != "cat" {
+		t.Fatalf("expected command args to be restored, got %v", cmd.Args)
+	}
+	err := cmd.Wait()
+	return buf.String(), err
+}
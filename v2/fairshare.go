@@ -0,0 +1,197 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultWeight is the cpu.weight and io.weight of groups the caller
+	// did not configure
+	defaultWeight = 100
+	minWeight     = 1
+	maxWeight     = 10000
+)
+
+// ShareAllocation are the settings planned for one sibling group
+type ShareAllocation struct {
+	CPUWeight uint64
+	IOWeight  uint64
+	// MemoryLow is the memory protected for the group, 0 when no memory
+	// capacity was given
+	MemoryLow uint64
+}
+
+// PlanFairShare computes the settings of sibling groups from their relative
+// priorities, keyed by the same names. cpu.weight and io.weight are
+// proportional to the priorities and average the default weight of 100, so
+// that siblings left out of the plan keep their relative share. memoryLow is
+// the parent's memory to protect, it is divided among the siblings in
+// proportion to their priorities.
+func PlanFairShare(priorities map[string]uint64, memoryLow uint64) (map[string]ShareAllocation, error) {
+	var sum float64
+	for name, p := range priorities {
+		if p == 0 {
+			return nil, errors.Wrapf(ErrInvalidPriority, "group %q", name)
+		}
+		sum += float64(p)
+	}
+	out := make(map[string]ShareAllocation, len(priorities))
+	for name, p := range priorities {
+		share := float64(p) / sum
+		weight := uint64(math.Round(share * defaultWeight * float64(len(priorities))))
+		if weight < minWeight {
+			weight = minWeight
+		}
+		if weight > maxWeight {
+			weight = maxWeight
+		}
+		out[name] = ShareAllocation{
+			CPUWeight: weight,
+			IOWeight:  weight,
+			MemoryLow: uint64(share * float64(memoryLow)),
+		}
+	}
+	return out, nil
+}
+
+// ApplyFairShare plans the settings of the children of the parent group
+// under the mountpoint with PlanFairShare and writes them as a set: when a
+// write fails, the settings already written are restored before the error
+// is returned. Files of controllers not enabled for a child are skipped.
+//
+// The settings of every child are written as an OpUpdate through the
+// interceptors of the options, with cpu.weight and memory.low as its
+// resources so that a policy can check or clamp them. The restores carry no
+// resources, policies leave the previous settings alone. When a write fails
+// a *MultiError is returned with that failure and those of the restores.
+func ApplyFairShare(mountpoint, parent string, priorities map[string]uint64, memoryLow uint64, opts ...InitOpts) (map[string]ShareAllocation, error) {
+	if err := VerifyGroupPath(parent); err != nil {
+		return nil, err
+	}
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := PlanFairShare(priorities, memoryLow)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(plan))
+	for name := range plan {
+		if name != filepath.Base(name) {
+			return nil, errors.Wrapf(ErrInvalidGroupPath, "sibling %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type share struct {
+		path      string
+		resources *Resources
+		// ioWeight is written alongside the resources, which have no
+		// field for io.weight
+		ioWeight []Value
+		old      []Value
+	}
+	shares := make([]share, 0, len(names))
+	for _, name := range names {
+		var (
+			a = plan[name]
+			s = share{
+				path:      filepath.Join(mountpoint, parent, name),
+				resources: &Resources{},
+			}
+			files = []string{"cpu.weight", "io.weight"}
+		)
+		if memoryLow > 0 {
+			files = append(files, "memory.low")
+		}
+		for _, filename := range files {
+			data, err := readBounded(filepath.Join(s.path, filename))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+			old := strings.TrimSpace(string(data))
+			switch filename {
+			case "cpu.weight":
+				weight := a.CPUWeight
+				s.resources.CPU = &CPU{Weight: &weight}
+			case "io.weight":
+				// io.weight lists the per device weights after the default
+				old = strings.SplitN(old, "\n", 2)[0]
+				s.ioWeight = []Value{{filename: filename, value: "default " + strconv.FormatUint(a.IOWeight, 10)}}
+			case "memory.low":
+				low := int64(a.MemoryLow)
+				s.resources.Memory = &Memory{Low: &low}
+			}
+			s.old = append(s.old, Value{filename: filename, value: old})
+		}
+		shares = append(shares, s)
+	}
+	for i, s := range shares {
+		op := &Operation{
+			Op:        OpUpdate,
+			Path:      s.path,
+			Resources: s.resources,
+			Warnings:  config.Warnings,
+		}
+		if err := config.intercept(op, func() error {
+			var values []Value
+			if op.Resources != nil {
+				values = op.Resources.Values()
+			}
+			return config.writeValues(s.path, append(values, s.ioWeight...), op.Warnings, op.deadline)
+		}); err != nil {
+			errs := &MultiError{}
+			errs.add(s.path, errors.Wrap(err, "apply fair share"))
+			// the failed child may be partially written as well, every
+			// setting is restored on its own on a best effort basis and
+			// the failures are returned with the error
+			for _, done := range shares[:i+1] {
+				restore := &Operation{
+					Op:       OpUpdate,
+					Path:     done.path,
+					Warnings: config.Warnings,
+				}
+				if err := config.intercept(restore, func() error {
+					for _, v := range done.old {
+						if err := config.writeValues(done.path, []Value{v}, restore.Warnings, restore.deadline); err != nil {
+							errs.add(done.path, errors.Wrapf(err, "restore %s", v.filename))
+						}
+					}
+					return nil
+				}); err != nil {
+					errs.add(done.path, errors.Wrap(err, "restore"))
+				}
+			}
+			return nil, errs
+		}
+	}
+	return plan, nil
+}
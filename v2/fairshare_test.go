@@ -0,0 +1,173 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanFairShare(t *testing.T) {
+	plan, err := PlanFairShare(map[string]uint64{"web": 3, "batch": 1}, 4<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if web, batch := plan["web"], plan["batch"]; web.CPUWeight != 150 || batch.CPUWeight != 50 ||
+		web.IOWeight != 150 || web.MemoryLow != 3<<30 || batch.MemoryLow != 1<<30 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	plan, err = PlanFairShare(map[string]uint64{"a": 1, "b": 1000000}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan["a"].CPUWeight != minWeight || plan["b"].CPUWeight != 200 || plan["a"].MemoryLow != 0 {
+		t.Fatalf("expected weights to be kept within range, got %+v", plan)
+	}
+	if _, err := PlanFairShare(map[string]uint64{"a": 0}, 0); KindOf(err) != KindInvalidInput {
+		t.Fatalf("expected a zero priority to be rejected, got %v", err)
+	}
+}
+
+func TestApplyFairShare(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-fairshare")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	files := map[string]string{
+		"parent/web/cpu.weight":   "100\n",
+		"parent/web/io.weight":    "default 100\n8:16 50\n",
+		"parent/web/memory.low":   "0\n",
+		"parent/batch/cpu.weight": "100\n",
+		"parent/batch/memory.low": "0\n",
+	}
+	for name, value := range files {
+		path := filepath.Join(mountpoint, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		data, err := ioutil.ReadFile(filepath.Join(mountpoint, "parent", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if _, err := ApplyFairShare(mountpoint, "/parent", map[string]uint64{"web": 3, "batch": 1}, 4096); err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{
+		"web/cpu.weight":   "150",
+		"web/io.weight":    "default 150",
+		"web/memory.low":   "3072",
+		"batch/cpu.weight": "50",
+		"batch/memory.low": "1024",
+	} {
+		if v := read(name); v != expected {
+			t.Fatalf("expected %s to be %q, got %q", name, expected, v)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(mountpoint, "parent", "batch", "io.weight")); !os.IsNotExist(err) {
+		t.Fatalf("expected io.weight to be skipped for batch, got %v", err)
+	}
+
+	// the weights are written through the policy
+	policy := WithPolicy(BoundsPolicy(Bounds{CPUWeight: &Range{Min: 1, Max: 120}}, PolicyClamp))
+	if _, err := ApplyFairShare(mountpoint, "/parent", map[string]uint64{"web": 3, "batch": 1}, 4096, policy); err != nil {
+		t.Fatal(err)
+	}
+	if v := read("web/cpu.weight"); v != "120" {
+		t.Fatalf("expected web/cpu.weight to be clamped to 120, got %q", v)
+	}
+	if v := read("web/io.weight"); v != "default 150" {
+		t.Fatalf("expected web/io.weight to be left to the plan, got %q", v)
+	}
+
+	// a symlink planted in place of a file is rejected before anything
+	// is written
+	if err := os.Remove(filepath.Join(mountpoint, "parent", "web", "memory.low")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/proc/version", filepath.Join(mountpoint, "parent", "web", "memory.low")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyFairShare(mountpoint, "/parent", map[string]uint64{"web": 1, "batch": 1}, 4096); err == nil {
+		t.Fatal("expected reading the symlinked memory.low to fail")
+	}
+	if v := read("batch/cpu.weight"); v != "50" {
+		t.Fatalf("expected batch/cpu.weight to be left alone, got %q", v)
+	}
+	if err := os.Remove(filepath.Join(mountpoint, "parent", "web", "memory.low")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, "parent", "web", "memory.low"), []byte("3072\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failing write restores the settings already written, and the
+	// failures of the restores are returned with it
+	var (
+		errWrite   = errors.New("write failed")
+		errRestore = errors.New("restore failed")
+		webPath    = filepath.Join(mountpoint, "parent", "web")
+		batchPath  = filepath.Join(mountpoint, "parent", "batch")
+	)
+	fail := WithInterceptors(func(op *Operation, next func() error) error {
+		switch {
+		case op.Path == webPath && op.Resources != nil:
+			// fail after writing so that web is restored as well
+			if err := next(); err != nil {
+				return err
+			}
+			return errWrite
+		case op.Path == batchPath && op.Resources == nil:
+			next()
+			return errRestore
+		}
+		return next()
+	})
+	_, err = ApplyFairShare(mountpoint, "/parent", map[string]uint64{"web": 1, "batch": 1}, 4096, fail)
+	merr, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("expected a *MultiError but received %v", err)
+	}
+	if len(merr.Errors) != 2 || merr.Errors[0].Path != webPath || merr.Errors[1].Path != batchPath {
+		t.Fatalf("expected the write and restore failures but received %v", err)
+	}
+	if !errors.Is(err, errWrite) || !errors.Is(err, errRestore) {
+		t.Fatalf("expected the write and restore errors to match but received %v", err)
+	}
+	for name, expected := range map[string]string{
+		"batch/cpu.weight": "50",
+		"batch/memory.low": "1024",
+		"web/cpu.weight":   "120",
+		"web/io.weight":    "default 150",
+		"web/memory.low":   "3072",
+	} {
+		if v := read(name); v != expected {
+			t.Fatalf("expected %s to be restored to %q, got %q", name, expected, v)
+		}
+	}
+}
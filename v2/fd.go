@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// NewManagerFromFD returns a manager for the cgroup directory open as fd,
+// for example one received over SCM_RIGHTS from a privileged broker. The
+// files of the cgroup are accessed through /proc/self/fd, so the cgroup does
+// not need to be reachable by path in the caller's mount namespace. fd is
+// duplicated and remains owned by the caller, Close must be called to
+// release the duplicate.
+//
+// The root of the hierarchy cannot be reached through the fd, so
+// RootControllers reports the controllers of the cgroup itself and
+// ToggleControllers does not write to any ancestor. The cgroup itself cannot
+// be removed, Delete and DeleteRecursive return ErrDeleteFromFD, children
+// created with NewChild can. Children
+// access their files through the same fd and must not be used after Close.
+func NewManagerFromFD(fd int, opts ...InitOpts) (*Manager, error) {
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	var st unix.Statfs_t
+	if err := unix.Fstatfs(fd, &st); err != nil {
+		return nil, err
+	}
+	if st.Type != unix.CGROUP2_SUPER_MAGIC {
+		return nil, errors.Errorf("cgroups: fd %d is not on a cgroup2 filesystem", fd)
+	}
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return nil, err
+	}
+	if stat.Mode&unix.S_IFMT != unix.S_IFDIR {
+		return nil, ErrUnexpectedFileType
+	}
+	dup, err := unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join("/proc/self/fd", strconv.Itoa(dup))
+	m := newManager(path, path, config)
+	m.dir = os.NewFile(uintptr(dup), path)
+	return m, nil
+}
+
+// openDir opens the directory of the cgroup. The directory of a manager
+// created from an fd is opened through the fd, its path is a magic link that
+// the no-follow open rejects with ELOOP.
+func (c *Manager) openDir() (*os.File, error) {
+	if c.dir == nil {
+		return openDir(c.path)
+	}
+	fd, err := unix.Openat(int(c.dir.Fd()), ".", unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "openat", Path: c.path, Err: err}
+	}
+	return os.NewFile(uintptr(fd), c.path), nil
+}
+
+// deleted returns true if the cgroup was removed. The fd of a manager
+// created from an fd keeps its directory, and the magic link to it, alive
+// after the removal, but the kernel no longer resolves the files in it.
+func (c *Manager) deleted() bool {
+	if c.dir == nil {
+		_, err := os.Lstat(c.path)
+		return os.IsNotExist(err)
+	}
+	var st unix.Stat_t
+	return unix.Fstatat(int(c.dir.Fd()), cgroupProcs, &st, unix.AT_SYMLINK_NOFOLLOW) == unix.ENOENT
+}
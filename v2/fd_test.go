@@ -0,0 +1,129 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containerd/cgroups/cgroupstest"
+	"golang.org/x/sys/unix"
+)
+
+func TestNewManagerFromFD(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	group := sandbox.Path()
+	fd, err := unix.Open(group, unix.O_DIRECTORY|unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManagerFromFD(fd)
+	// the manager holds its own duplicate of the fd
+	unix.Close(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	child, err := m.NewChild("fd", &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer child.Delete()
+	if err := child.Freeze(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(group, "fd", cgroupFreeze))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := strings.TrimSpace(string(data)); v != "1" {
+		t.Fatalf("expected the child to be frozen through the fd, got %s", v)
+	}
+	if _, err := m.Controllers(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(); err != ErrDeleteFromFD {
+		t.Fatalf("expected ErrDeleteFromFD, got %v", err)
+	}
+	if err := m.DeleteRecursive(); err != ErrDeleteFromFD {
+		t.Fatalf("expected ErrDeleteFromFD, got %v", err)
+	}
+	if _, err := os.Stat(group); err != nil {
+		t.Fatalf("expected the group to be kept: %v", err)
+	}
+}
+
+func TestNewManagerFromFDStat(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	byPath, err := NewManager(sandbox.Mountpoint, filepath.Join(sandbox.Group, "stat"), &Resources{HugeTlb: &HugeTlb{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer byPath.Delete()
+	expected, err := byPath.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := unix.Open(byPath.path, unix.O_DIRECTORY|unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManagerFromFD(fd)
+	unix.Close(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	metrics, err := m.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.Hugetlb) != len(expected.Hugetlb) {
+		t.Fatalf("expected the hugetlb stats %v through the fd, got %v", expected.Hugetlb, metrics.Hugetlb)
+	}
+
+	if err := byPath.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat(); err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted once the group is removed, got %v", err)
+	}
+}
+
+func TestNewManagerFromFDNotCgroup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-fd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fd, err := unix.Open(dir, unix.O_DIRECTORY|unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(fd)
+	if _, err := NewManagerFromFD(fd); err == nil {
+		t.Fatal("expected a directory outside of cgroup2 to be rejected")
+	}
+}
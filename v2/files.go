@@ -0,0 +1,146 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/containerd/cgroups/internal/cgfs"
+	"golang.org/x/sys/unix"
+)
+
+// persistentFiles are the files kept open when PersistentFiles is set
+var persistentFiles = map[string]struct{}{
+	"memory.current": {},
+	"cpu.stat":       {},
+	"pids.current":   {},
+}
+
+// fileCache keeps cgroup files open and reads them with pread
+type fileCache struct {
+	mu     sync.Mutex
+	path   string
+	files  map[string]*os.File
+	buf    []byte
+	closed bool
+}
+
+func newFileCache(path string) *fileCache {
+	return &fileCache{
+		path:  path,
+		files: make(map[string]*os.File),
+		buf:   make([]byte, 4096),
+	}
+}
+
+// read returns the full contents of the file, reopening it once if the
+// cached descriptor has gone stale. os.ErrClosed is returned once the cache
+// is closed.
+func (f *fileCache) read(name string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, os.ErrClosed
+	}
+	for i := 0; ; i++ {
+		fd, err := f.open(name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := f.pread(fd)
+		if err == nil {
+			return data, nil
+		}
+		f.invalidate(name)
+		if !isStale(err) {
+			return nil, &os.PathError{Op: "pread", Path: filepath.Join(f.path, name), Err: err}
+		}
+		if i > 0 {
+			// the cgroup was removed while the file was open
+			return nil, &os.PathError{Op: "pread", Path: filepath.Join(f.path, name), Err: unix.ENOENT}
+		}
+	}
+}
+
+func (f *fileCache) open(name string) (*os.File, error) {
+	if fd, ok := f.files[name]; ok {
+		return fd, nil
+	}
+	fd, err := openFile(filepath.Join(f.path, name), os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	f.files[name] = fd
+	return fd, nil
+}
+
+func (f *fileCache) pread(fd *os.File) ([]byte, error) {
+	for {
+		n, err := unix.Pread(int(fd.Fd()), f.buf, 0)
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return nil, err
+		}
+		if n < len(f.buf) {
+			out := make([]byte, n)
+			copy(out, f.buf[:n])
+			return out, nil
+		}
+		if max := cgfs.MaxReadSize(); int64(len(f.buf)) > max {
+			out := make([]byte, n)
+			copy(out, f.buf[:n])
+			return cgfs.Truncate(out, max, fd.Name()), nil
+		}
+		// the file did not fit, grow the buffer and read it again
+		f.buf = make([]byte, len(f.buf)*2)
+	}
+}
+
+func (f *fileCache) invalidate(name string) {
+	if fd, ok := f.files[name]; ok {
+		fd.Close()
+		delete(f.files, name)
+	}
+}
+
+// close releases all open files
+func (f *fileCache) close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	var lastErr error
+	for name, fd := range f.files {
+		if err := fd.Close(); err != nil {
+			lastErr = err
+		}
+		delete(f.files, name)
+	}
+	return lastErr
+}
+
+// isStale returns true when the open file no longer refers to a live cgroup file
+func isStale(err error) bool {
+	switch err {
+	case unix.ESTALE, unix.ENOENT, unix.ENODEV:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPersistentFilesStat(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroups-v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "test")
+	if err := os.MkdirAll(path, defaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{
+		controllersFile:  "cpu memory pids",
+		"cpu.stat":       "usage_usec 100\nuser_usec 60\nsystem_usec 40",
+		"memory.stat":    "anon 4096",
+		"memory.current": "8192",
+		"pids.current":   "3",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(path, name), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := LoadManager(root, "/test", WithPersistentFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	for _, expected := range []uint64{8192, 16384} {
+		if err := ioutil.WriteFile(filepath.Join(path, "memory.current"), []byte(fmt.Sprint(expected)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		stats, err := m.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Memory.Usage != expected {
+			t.Errorf("expected memory usage %d but received %d", expected, stats.Memory.Usage)
+		}
+		if stats.CPU.UsageUsec != 100 {
+			t.Errorf("expected cpu usage 100 but received %d", stats.CPU.UsageUsec)
+		}
+		if stats.Pids.Current != 3 {
+			t.Errorf("expected 3 pids but received %d", stats.Pids.Current)
+		}
+	}
+	if len(m.files.files) != len(persistentFiles) {
+		t.Errorf("expected %d open files but received %d", len(persistentFiles), len(m.files.files))
+	}
+}
@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	cgroupEvents = "cgroup.events"
+
+	quiescePollInterval = time.Millisecond
+	maxQuiescePoll      = 50 * time.Millisecond
+)
+
+// RunFrozen freezes the cgroup, waits until every process in it is frozen and
+// runs fn before thawing it again. It is intended for checkpoint tooling that
+// must quiesce a cgroup before dumping it.
+//
+// The cgroup is thawed and ctx's error returned if the processes do not
+// quiesce before ctx is done. The cgroup is always thawed after fn returns,
+// the error from fn takes precedence over an error thawing. fn must not
+// change the freezer state of the cgroup through the manager.
+func (c *Manager) RunFrozen(ctx context.Context, fn func() error) error {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	ctx, span := c.config.startSpan(ctx, "cgroups.RunFrozen", c.path)
+	err := c.runFrozen(ctx, fn)
+	span.End(err)
+	return err
+}
+
+func (c *Manager) runFrozen(ctx context.Context, fn func() error) error {
+	// the processes are waited for outside of the operation, its deadline
+	// only bounds the write
+	op := &Operation{
+		Op:    OpFreeze,
+		Path:  c.path,
+		State: Frozen,
+	}
+	if err := c.config.intercept(op, func() error {
+		return c.config.writeValues(c.path, op.State.Values(), nil, op.deadline)
+	}); err != nil {
+		return err
+	}
+	if err := c.waitFrozen(ctx); err != nil {
+		if terr := c.thaw(ctx); terr != nil {
+			return errors.Wrapf(err, "failed to thaw after quiescing failed: %v", terr)
+		}
+		return err
+	}
+	err := fn()
+	if terr := c.thaw(ctx); terr != nil && err == nil {
+		err = terr
+	}
+	return err
+}
+
+// waitFrozen polls cgroup.events until the kernel reports all processes in
+// the cgroup as frozen
+func (c *Manager) waitFrozen(ctx context.Context) error {
+	interval := quiescePollInterval
+	for {
+		out := make(map[string]interface{})
+		if err := readKVStatsFile(c.path, cgroupEvents, out); err != nil {
+			return err
+		}
+		if getUint64Value("frozen", out) == 1 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "cgroup did not quiesce")
+		case <-c.config.pacer().After(interval):
+		}
+		if interval *= 2; interval > maxQuiescePoll {
+			interval = maxQuiescePoll
+		}
+	}
+}
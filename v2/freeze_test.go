@@ -0,0 +1,129 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newFreezeTestManager(t *testing.T, events string, opts ...InitOpts) (*Manager, func()) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-freeze")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{
+		cgroupFreeze: "0",
+		cgroupEvents: events,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(mountpoint, name), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := LoadManager(mountpoint, "/", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, func() { os.RemoveAll(mountpoint) }
+}
+
+func TestRunFrozen(t *testing.T) {
+	m, cleanup := newFreezeTestManager(t, "populated 1\nfrozen 1\n")
+	defer cleanup()
+
+	var state State
+	expected := errors.New("dump failed")
+	err := m.RunFrozen(context.Background(), func() error {
+		var err error
+		if state, err = fetchState(m.path); err != nil {
+			return err
+		}
+		return expected
+	})
+	if err != expected {
+		t.Fatalf("expected the callback error but received %v", err)
+	}
+	if state != Frozen {
+		t.Fatalf("expected the callback to run frozen but the state was %q", state)
+	}
+	if state, _ := fetchState(m.path); state != Thawed {
+		t.Fatalf("expected the cgroup to be thawed but it is %q", state)
+	}
+}
+
+func TestRunFrozenTimeout(t *testing.T) {
+	m, cleanup := newFreezeTestManager(t, "populated 1\nfrozen 0\n")
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	called := false
+	err := m.RunFrozen(ctx, func() error {
+		called = true
+		return nil
+	})
+	if err == nil || called {
+		t.Fatalf("expected a timeout without running the callback: %v", err)
+	}
+	if state, _ := fetchState(m.path); state != Thawed {
+		t.Fatalf("expected the cgroup to be thawed after the timeout but it is %q", state)
+	}
+}
+
+func TestRunFrozenIntercepted(t *testing.T) {
+	var states []State
+	record := func(op *Operation, next func() error) error {
+		if op.Op == OpFreeze {
+			states = append(states, op.State)
+		}
+		return next()
+	}
+	m, cleanup := newFreezeTestManager(t, "populated 1\nfrozen 1\n", WithInterceptors(record))
+	defer cleanup()
+
+	if err := m.RunFrozen(context.Background(), func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 2 || states[0] != Frozen || states[1] != Thawed {
+		t.Fatalf("expected the freeze and thaw to be intercepted but received %v", states)
+	}
+
+	errDenied := errors.New("denied")
+	m, cleanup = newFreezeTestManager(t, "populated 1\nfrozen 1\n", WithInterceptors(func(op *Operation, next func() error) error {
+		if op.Op == OpFreeze && op.State == Frozen {
+			return errDenied
+		}
+		return next()
+	}))
+	defer cleanup()
+	called := false
+	err := m.RunFrozen(context.Background(), func() error {
+		called = true
+		return nil
+	})
+	if err != errDenied || called {
+		t.Fatalf("expected the interceptor to abort the freeze but received %v", err)
+	}
+	if state, _ := fetchState(m.path); state != Thawed {
+		t.Fatalf("expected the cgroup to stay thawed but it is %q", state)
+	}
+}
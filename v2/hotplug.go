@@ -0,0 +1,126 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"time"
+
+	"github.com/containerd/cgroups/topology"
+)
+
+// sysfsRoot is a var so that the tests can watch a fake sysfs
+var sysfsRoot = "/sys/devices/system"
+
+// HotplugOpts configures WatchHotplug
+type HotplugOpts func(*hotplugConfig)
+
+type hotplugConfig struct {
+	interval    time.Duration
+	reapplyCpus bool
+	cpus        topology.CPUSet
+	reapplyMems bool
+	mems        topology.CPUSet
+}
+
+// WithHotplugInterval sets how often the online cpus and NUMA nodes are
+// checked
+func WithHotplugInterval(interval time.Duration) HotplugOpts {
+	return func(c *hotplugConfig) {
+		c.interval = interval
+	}
+}
+
+// WithReapplyCpus writes cpus restricted to the online cpus to cpuset.cpus
+// after every hotplug event, so that cpus are used again once they come
+// back online. Nothing is written when none of cpus are online.
+func WithReapplyCpus(cpus topology.CPUSet) HotplugOpts {
+	return func(c *hotplugConfig) {
+		c.reapplyCpus = true
+		c.cpus = cpus
+	}
+}
+
+// WithReapplyMems writes mems restricted to the online NUMA nodes to
+// cpuset.mems after every hotplug event, so that nodes are used again once
+// they come back online. Nothing is written when none of mems are online.
+func WithReapplyMems(mems topology.CPUSet) HotplugOpts {
+	return func(c *hotplugConfig) {
+		c.reapplyMems = true
+		c.mems = mems
+	}
+}
+
+// WatchHotplug sends an event every time cpus or NUMA nodes go online or
+// offline until ctx is done, so that callers managing cpuset.cpus and
+// cpuset.mems can re-validate them. An error, reading the online cpus and
+// nodes or re-applying the cpuset, is sent on the error channel and ends the
+// watch, both channels are closed when the watch ends.
+func (c *Manager) WatchHotplug(ctx context.Context, opts ...HotplugOpts) (<-chan topology.HotplugEvent, <-chan error) {
+	config := &hotplugConfig{
+		interval: topology.DefaultHotplugInterval,
+	}
+	for _, o := range opts {
+		o(config)
+	}
+	var (
+		events = make(chan topology.HotplugEvent)
+		errCh  = make(chan error, 1)
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	online, onlineErrs := topology.WatchOnlineFrom(ctx, sysfsRoot, config.interval, topology.WithWatchClock(c.config.pacer()))
+	go func() {
+		defer close(events)
+		defer close(errCh)
+		defer cancel()
+		for e := range online {
+			if err := c.reapplyOnline(config, e); err != nil {
+				errCh <- err
+				return
+			}
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := <-onlineErrs; err != nil {
+			errCh <- err
+		}
+	}()
+	return events, errCh
+}
+
+// reapplyOnline writes the requested cpus and nodes that are online with
+// Update, so that the writes go through the interceptors and policy
+func (c *Manager) reapplyOnline(config *hotplugConfig, e topology.HotplugEvent) error {
+	var cpu CPU
+	if config.reapplyCpus {
+		if cpus := config.cpus.Intersect(e.Online); !cpus.IsEmpty() {
+			cpu.Cpus = cpus.String()
+		}
+	}
+	if config.reapplyMems {
+		if mems := config.mems.Intersect(e.Nodes); !mems.IsEmpty() {
+			cpu.Mems = mems.String()
+		}
+	}
+	if cpu.Cpus == "" && cpu.Mems == "" {
+		return nil
+	}
+	return c.Update(&Resources{CPU: &cpu})
+}
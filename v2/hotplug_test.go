@@ -0,0 +1,172 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerd/cgroups/topology"
+	"github.com/pkg/errors"
+)
+
+// fakeSysfs points sysfsRoot at a temporary directory with the online cpus
+// and NUMA nodes, the returned func restores it
+func fakeSysfs(t *testing.T, cpus, nodes string) (string, func()) {
+	root, err := ioutil.TempDir("", "cgroups-hotplug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{"cpu/online": cpus, "node/online": nodes} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := sysfsRoot
+	sysfsRoot = root
+	return root, func() {
+		sysfsRoot = old
+		os.RemoveAll(root)
+	}
+}
+
+func TestWatchHotplugReapply(t *testing.T) {
+	root, cleanup := fakeSysfs(t, "0-3", "0")
+	defer cleanup()
+	online := filepath.Join(root, "cpu", "online")
+
+	c := shrinkTestManager(t, map[string]string{"cpuset.cpus": "2-3\n"})
+	defer os.RemoveAll(c.path)
+	// the events are sent after the writes, reading ops once an event
+	// was received does not race with the interceptor
+	var ops []string
+	c.config = &InitConfig{
+		Interceptors: []Interceptor{func(op *Operation, next func() error) error {
+			ops = append(ops, string(op.Op)+" "+op.Resources.CPU.Cpus)
+			return next()
+		}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errCh := c.WatchHotplug(ctx,
+		WithHotplugInterval(time.Millisecond),
+		WithReapplyCpus(topology.NewCPUSet(2, 3)),
+	)
+	next := func(cpus string) topology.HotplugEvent {
+		if err := ioutil.WriteFile(online, []byte(cpus+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case e := <-events:
+			return e
+		case err := <-errCh:
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the hotplug event")
+		}
+		return topology.HotplugEvent{}
+	}
+	// let the watch read the initial online cpus before changing them
+	time.Sleep(20 * time.Millisecond)
+	if e := next("0-2"); !e.Removed.Equal(topology.NewCPUSet(3)) {
+		t.Fatalf("expected cpu 3 to be removed, got %+v", e)
+	}
+	if v := readTestFile(t, c, "cpuset.cpus"); v != "2" {
+		t.Fatalf("expected cpuset.cpus 2 after cpu 3 went offline, got %q", v)
+	}
+	// cpus 2 and 3 offline leaves the last applied value in place
+	next("0-1")
+	if v := readTestFile(t, c, "cpuset.cpus"); v != "2" {
+		t.Fatalf("expected cpuset.cpus to be left at 2, got %q", v)
+	}
+	next("0-3")
+	if v := readTestFile(t, c, "cpuset.cpus"); v != "2-3" {
+		t.Fatalf("expected cpuset.cpus 2-3 after the cpus came back, got %q", v)
+	}
+	if expected := []string{"update 2", "update 2-3"}; !reflect.DeepEqual(ops, expected) {
+		t.Fatalf("expected the writes %v to go through the interceptors, got %v", expected, ops)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchHotplugReapplyMems(t *testing.T) {
+	root, cleanup := fakeSysfs(t, "0-3", "0-1")
+	defer cleanup()
+
+	c := shrinkTestManager(t, map[string]string{"cpuset.mems": "0-1\n"})
+	defer os.RemoveAll(c.path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errCh := c.WatchHotplug(ctx,
+		WithHotplugInterval(time.Millisecond),
+		WithReapplyMems(topology.NewCPUSet(0, 1)),
+	)
+	// let the watch read the initial online nodes before changing them
+	time.Sleep(20 * time.Millisecond)
+	if err := ioutil.WriteFile(filepath.Join(root, "node", "online"), []byte("0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if !e.RemovedNodes.Equal(topology.NewCPUSet(1)) {
+			t.Fatalf("expected node 1 to be removed, got %+v", e)
+		}
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the hotplug event")
+	}
+	if v := readTestFile(t, c, "cpuset.mems"); v != "0" {
+		t.Fatalf("expected cpuset.mems 0 after node 1 went offline, got %q", v)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestCPUValidateMems(t *testing.T) {
+	_, cleanup := fakeSysfs(t, "0-3", "0")
+	defer cleanup()
+
+	if err := (&CPU{Mems: "0"}).validate(); err != nil {
+		t.Fatal(err)
+	}
+	err := (&CPU{Mems: "0-1"}).validate()
+	if errors.Cause(err) != ErrMemsOffline {
+		t.Fatalf("expected ErrMemsOffline, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "nodes 1") {
+		t.Fatalf("expected the offline node in the error, got %v", err)
+	}
+	if err := setResources(os.TempDir(), &Resources{CPU: &CPU{Mems: "1"}}); errors.Cause(err) != ErrMemsOffline {
+		t.Fatalf("expected setResources to refuse offline nodes, got %v", err)
+	}
+}
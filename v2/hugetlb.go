@@ -16,13 +16,21 @@
 
 package v2
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/containerd/cgroups/internal/cgfs"
+	"github.com/pkg/errors"
+)
+
+// hugePagesDir is a var so that the tests can discover sizes from a fake directory
+var hugePagesDir = "/sys/kernel/mm/hugepages"
 
 type HugeTlb []HugeTlbEntry
 
 type HugeTlbEntry struct {
-	HugePageSize string
-	Limit        uint64
+	HugePageSize string `json:"page_size"`
+	Limit        uint64 `json:"limit"`
 }
 
 func (r *HugeTlb) Values() (o []Value) {
@@ -35,3 +43,31 @@ func (r *HugeTlb) Values() (o []Value) {
 
 	return o
 }
+
+// validate returns ErrHugePageSizeNotSupported if any of the entries use a
+// pagesize that is not supported by the host
+func (r *HugeTlb) validate() error {
+	if len(*r) == 0 {
+		return nil
+	}
+	sizes, err := HugePageSizes()
+	if err != nil {
+		return err
+	}
+	supported := make(map[string]struct{}, len(sizes))
+	for _, s := range sizes {
+		supported[s] = struct{}{}
+	}
+	for _, e := range *r {
+		if _, ok := supported[e.HugePageSize]; !ok {
+			return errors.Wrapf(ErrHugePageSizeNotSupported, "hugepage size %q", e.HugePageSize)
+		}
+	}
+	return nil
+}
+
+// HugePageSizes returns the hugepage sizes supported by the host, as used in
+// the hugetlb file names (e.g. "2MB", "1GB"), discovered from /sys/kernel/mm/hugepages
+func HugePageSizes() ([]string, error) {
+	return cgfs.HugePageSizes(hugePagesDir)
+}
@@ -18,12 +18,62 @@ package v2
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
+func fakeHugePagesDir(t *testing.T, sizes ...string) func() {
+	dir, err := ioutil.TempDir("", "hugetlb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sizes {
+		if err := os.Mkdir(filepath.Join(dir, "hugepages-"+s), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orig := hugePagesDir
+	hugePagesDir = dir
+	return func() {
+		hugePagesDir = orig
+		os.RemoveAll(dir)
+	}
+}
+
+func TestHugePageSizes(t *testing.T) {
+	defer fakeHugePagesDir(t, "2048kB", "1048576kB")()
+	sizes, err := HugePageSizes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"1GB", "2MB"}; !reflect.DeepEqual(sizes, expected) {
+		t.Fatalf("expected %v but received %v", expected, sizes)
+	}
+}
+
+func TestHugetlbUnsupportedSize(t *testing.T) {
+	defer fakeHugePagesDir(t, "2048kB")()
+	c := shrinkTestManager(t, nil)
+	defer os.RemoveAll(c.path)
+	hugeTlb := HugeTlb{
+		{HugePageSize: "2MB", Limit: 1024},
+		{HugePageSize: "1GB", Limit: 1024},
+	}
+	err := setResources(c.path, &Resources{HugeTlb: &hugeTlb})
+	if errors.Cause(err) != ErrHugePageSizeNotSupported {
+		t.Fatalf("expected ErrHugePageSizeNotSupported but received %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(c.path, "hugetlb.2MB.max")); !os.IsNotExist(err) {
+		t.Fatalf("expected no limit to be written before the invalid one: %v", err)
+	}
+}
+
 func TestCgroupv2HugetlbStats(t *testing.T) {
 	checkCgroupControllerSupported(t, "hugetlb")
 	checkCgroupMode(t)
@@ -0,0 +1,109 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// Call is the kind of system call an Observer is notified of
+type Call string
+
+const (
+	// CallRead is a read of a cgroup interface file
+	CallRead Call = "read"
+	// CallWrite is a write to a cgroup interface file
+	CallWrite Call = "write"
+	// CallRetry is a write retried after being interrupted
+	CallRetry Call = "retry"
+	// CallDbus is a call made to systemd over dbus
+	CallDbus Call = "dbus"
+	// CallThrottled is an operation delayed or refused by a rate limit,
+	// the duration is the time it waited
+	CallThrottled Call = "throttled"
+)
+
+// Observer is notified after each file or dbus call made by the package with
+// the name of the file or dbus method, the time it took and its result
+type Observer func(call Call, name string, d time.Duration, err error)
+
+var observer atomic.Value
+
+// SetObserver sets the observer notified of the package's calls,
+// a nil observer disables the instrumentation
+func SetObserver(o Observer) {
+	observer.Store(o)
+}
+
+func observe(call Call, name string, start time.Time, err error) {
+	o, _ := observer.Load().(Observer)
+	if o == nil {
+		return
+	}
+	o(call, name, time.Since(start), err)
+}
+
+// latencyBuckets are the upper bounds of the latency histogram
+var latencyBuckets = []struct {
+	name string
+	max  time.Duration
+}{
+	{"10us", 10 * time.Microsecond},
+	{"100us", 100 * time.Microsecond},
+	{"1ms", time.Millisecond},
+	{"10ms", 10 * time.Millisecond},
+	{"100ms", 100 * time.Millisecond},
+	{"1s", time.Second},
+	{"inf", 1<<63 - 1},
+}
+
+// ExpvarObserver returns an observer that publishes the count, errors and a
+// latency histogram of each kind of call as an expvar map under name.
+// Like expvar.Publish it panics if name is already in use.
+func ExpvarObserver(name string) Observer {
+	root := expvar.NewMap(name)
+	for _, call := range []Call{CallRead, CallWrite, CallRetry, CallDbus, CallThrottled} {
+		m := new(expvar.Map).Init()
+		m.Add("count", 0)
+		m.Add("errors", 0)
+		latency := new(expvar.Map).Init()
+		for _, b := range latencyBuckets {
+			latency.Add(b.name, 0)
+		}
+		m.Set("latency", latency)
+		root.Set(string(call), m)
+	}
+	return func(call Call, _ string, d time.Duration, err error) {
+		m, ok := root.Get(string(call)).(*expvar.Map)
+		if !ok {
+			return
+		}
+		m.Add("count", 1)
+		if err != nil {
+			m.Add("errors", 1)
+		}
+		latency := m.Get("latency").(*expvar.Map)
+		for _, b := range latencyBuckets {
+			if d <= b.max {
+				latency.Add(b.name, 1)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"expvar"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestObserver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-observer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var calls []string
+	SetObserver(func(call Call, name string, _ time.Duration, err error) {
+		calls = append(calls, string(call)+" "+name)
+	})
+	defer SetObserver(nil)
+
+	if err := writeValues(dir, []Value{{filename: "pids.max", value: int64(10)}}); err != nil {
+		t.Fatal(err)
+	}
+	m := newManager(dir, dir, &InitConfig{})
+	if _, err := m.readFile("pids.max"); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "write pids.max" || calls[1] != "read pids.max" {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+}
+
+func TestExpvarObserver(t *testing.T) {
+	o := ExpvarObserver("cgroups-test-observer")
+	o(CallRead, "memory.stat", 50*time.Microsecond, nil)
+	o(CallRead, "memory.stat", 2*time.Second, os.ErrNotExist)
+
+	read := expvar.Get("cgroups-test-observer").(*expvar.Map).Get(string(CallRead)).(*expvar.Map)
+	if v := read.Get("count").String(); v != "2" {
+		t.Errorf("expected 2 reads but received %s", v)
+	}
+	if v := read.Get("errors").String(); v != "1" {
+		t.Errorf("expected 1 error but received %s", v)
+	}
+	latency := read.Get("latency").(*expvar.Map)
+	for bucket, expected := range map[string]string{"100us": "1", "inf": "1", "10us": "0"} {
+		if v := latency.Get(bucket).String(); v != expected {
+			t.Errorf("expected %s in the %s bucket but received %s", expected, bucket, v)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+// Op is a manager operation
+type Op string
+
+const (
+	OpNew     Op = "new"
+	OpUpdate  Op = "update"
+	OpAddProc Op = "add-proc"
+	OpDelete  Op = "delete"
+	OpFreeze  Op = "freeze"
+)
+
+// Operation describes the manager operation an interceptor is called for
+type Operation struct {
+	Op Op
+	// Path is the absolute path of the group
+	Path string
+	// Resources are set for OpNew and OpUpdate, interceptors can replace
+	// them before calling next to change what is written
+	Resources *Resources
+	// Pid is set for OpAddProc
+	Pid uint64
+	// State is the freezer state written by OpFreeze
+	State State
+	// Warnings collects what the operation skipped or approximated, it is
+	// nil when the caller did not ask for warnings
+	Warnings *Warnings
+
+	// deadline is the write timeout of the operation, set by intercept
+	deadline deadline
+}
+
+// Interceptor is called around a manager operation. It must call next to run
+// the operation, or the rest of the chain, and can act on the operation
+// before and after it runs. Returning an error without calling next aborts
+// the operation, which is how policies are enforced.
+type Interceptor func(op *Operation, next func() error) error
+
+// intercept runs fn through the configured interceptors. The write timeout
+// starts with the operation, fn bounds its writes with op.deadline.
+func (c *InitConfig) intercept(op *Operation, fn func() error) error {
+	op.deadline = newDeadline(c.timeouts().Write)
+	if c == nil || len(c.Interceptors) == 0 {
+		return fn()
+	}
+	next := fn
+	for i := len(c.Interceptors) - 1; i >= 0; i-- {
+		interceptor, n := c.Interceptors[i], next
+		next = func() error {
+			return interceptor(op, n)
+		}
+	}
+	return next()
+}
@@ -0,0 +1,74 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-intercept")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(op *Operation, next func() error) error {
+			calls = append(calls, name+" pre "+string(op.Op))
+			err := next()
+			calls = append(calls, name+" post "+string(op.Op))
+			return err
+		}
+	}
+	errDenied := errors.New("denied")
+	deny := func(op *Operation, next func() error) error {
+		if op.Op == OpAddProc && op.Pid == 1 {
+			return errDenied
+		}
+		return next()
+	}
+	m, err := NewManager(mountpoint, "/test", &Resources{}, WithInterceptors(record("a"), record("b"), deny))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddProc(1); err != errDenied {
+		t.Fatalf("expected the add to be denied but received %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.path, cgroupProcs)); !os.IsNotExist(err) {
+		t.Fatalf("expected %s not to be written: %v", cgroupProcs, err)
+	}
+	if err := m.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"a pre new", "b pre new", "b post new", "a post new",
+		"a pre add-proc", "b pre add-proc", "b post add-proc", "a post add-proc",
+		"a pre delete", "b pre delete", "b post delete", "a post delete",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %v but received %v", expected, calls)
+	}
+}
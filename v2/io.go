@@ -16,7 +16,13 @@
 
 package v2
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/containerd/cgroups/device"
+)
 
 type IOType string
 
@@ -28,23 +34,30 @@ const (
 )
 
 type BFQ struct {
-	Weight uint16
+	Weight uint16 `json:"weight,omitempty"`
 }
 
 type Entry struct {
-	Type  IOType
-	Major int64
-	Minor int64
-	Rate  uint64
+	Type IOType `json:"type"`
+	// Device is the path of the block device, e.g. /dev/nvme0n1, it is
+	// resolved to the Major and Minor numbers when the limit is applied
+	Device string `json:"device,omitempty"`
+	Major  int64  `json:"major"`
+	Minor  int64  `json:"minor"`
+	// Rate is the limit, math.MaxUint64 removes it
+	Rate uint64 `json:"rate"`
 }
 
 func (e Entry) String() string {
+	if e.Rate == math.MaxUint64 {
+		return fmt.Sprintf("%d:%d %s=max", e.Major, e.Minor, e.Type)
+	}
 	return fmt.Sprintf("%d:%d %s=%d", e.Major, e.Minor, e.Type, e.Rate)
 }
 
 type IO struct {
-	BFQ BFQ
-	Max []Entry
+	BFQ BFQ     `json:"bfq"`
+	Max []Entry `json:"max,omitempty"`
 }
 
 func (i *IO) Values() (o []Value) {
@@ -62,3 +75,37 @@ func (i *IO) Values() (o []Value) {
 	}
 	return o
 }
+
+// UnknownDevicesError is returned when io limits are set for device paths
+// that do not exist or are not block devices
+type UnknownDevicesError struct {
+	Devices []string
+}
+
+func (e *UnknownDevicesError) Error() string {
+	return "cgroups: unknown io devices: " + strings.Join(e.Devices, ", ")
+}
+
+// resolve returns a copy of the io resources with the Major and Minor numbers
+// of entries set by Device path filled in
+func (i *IO) resolve() (*IO, error) {
+	var (
+		out     = &IO{BFQ: i.BFQ}
+		unknown []string
+	)
+	for _, e := range i.Max {
+		if e.Device != "" {
+			typ, major, minor, err := device.Number(e.Device)
+			if err != nil || typ != device.Block {
+				unknown = append(unknown, e.Device)
+				continue
+			}
+			e.Major, e.Minor = major, minor
+		}
+		out.Max = append(out.Max, e)
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownDevicesError{Devices: unknown}
+	}
+	return out, nil
+}
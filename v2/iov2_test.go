@@ -19,7 +19,10 @@ package v2
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
+
+	"github.com/containerd/cgroups/device"
 )
 
 func TestCgroupv2IOController(t *testing.T) {
@@ -46,3 +49,42 @@ func TestCgroupv2IOController(t *testing.T) {
 
 	checkFileContent(t, c.path, "io.max", "8:0 rbps=max wbps=max riops=120 wiops=max")
 }
+
+func TestIOResolveDevicePath(t *testing.T) {
+	typ, major, minor, err := device.Number("/dev/loop0")
+	if err != nil || typ != device.Block {
+		t.Skip("/dev/loop0 is not available")
+	}
+	io := &IO{Max: []Entry{
+		{Device: "/dev/loop0", Type: ReadBPS, Rate: 100},
+		{Major: 8, Minor: 0, Type: WriteBPS, Rate: 200},
+	}}
+	resolved, err := io.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := resolved.Max[0]; e.Major != major || e.Minor != minor {
+		t.Fatalf("expected /dev/loop0 to resolve to %d:%d but received %d:%d", major, minor, e.Major, e.Minor)
+	}
+	if e := resolved.Max[1]; e.Major != 8 || e.Minor != 0 {
+		t.Fatalf("expected the entry set by number to be kept but received %v", e)
+	}
+	if io.Max[0].Major != 0 {
+		t.Fatal("expected the resources not to be modified")
+	}
+}
+
+func TestIOUnknownDevices(t *testing.T) {
+	io := &IO{Max: []Entry{
+		{Device: "/dev/does-not-exist", Type: ReadBPS, Rate: 100},
+		{Device: "/dev/null", Type: ReadBPS, Rate: 100},
+	}}
+	_, err := io.resolve()
+	uerr, ok := err.(*UnknownDevicesError)
+	if !ok {
+		t.Fatalf("expected an *UnknownDevicesError but received %v", err)
+	}
+	if expected := []string{"/dev/does-not-exist", "/dev/null"}; !reflect.DeepEqual(uerr.Devices, expected) {
+		t.Fatalf("expected unknown devices %v but received %v", expected, uerr.Devices)
+	}
+}
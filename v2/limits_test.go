@@ -0,0 +1,32 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseKVStatsSkipsInvalidLines(t *testing.T) {
+	out := make(map[string]interface{})
+	if err := parseKVStats(bytes.NewBufferString("anon 1\ngarbage\nfile 2\n"), "memory.stat", out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || out["anon"] != uint64(1) || out["file"] != uint64(2) {
+		t.Fatalf("expected the valid lines to be parsed but received %v", out)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+// LoadAll returns managers for every group at or below the group root under
+// the mountpoint for which filter returns true, keyed by their group path.
+// A nil filter loads all of them. The options are parsed once and shared by
+// all returned managers.
+func LoadAll(mountpoint, root string, filter func(group string) bool, opts ...InitOpts) (map[string]*Manager, error) {
+	if err := VerifyGroupPath(root); err != nil {
+		return nil, err
+	}
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]*Manager)
+	if err := walkGroups(filepath.Join(mountpoint, root), root, func(group string) {
+		if filter == nil || filter(group) {
+			out[group] = newManager(mountpoint, filepath.Join(mountpoint, group), config)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StatTree returns the metrics of every group at or below the group root
+// under the mountpoint, keyed by their group path. It continues past groups
+// that cannot be read, such as groups removed during the walk, returning the
+// metrics that were read alongside a *MultiError with each failure.
+func StatTree(mountpoint, root string, opts ...InitOpts) (map[string]*stats.Metrics, error) {
+	managers, err := LoadAll(mountpoint, root, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		out  = make(map[string]*stats.Metrics, len(managers))
+		errs MultiError
+	)
+	groups := make([]string, 0, len(managers))
+	for group := range managers {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		m := managers[group]
+		metrics, err := m.Stat()
+		if err != nil {
+			errs.add(m.path, err)
+			continue
+		}
+		out[group] = metrics
+	}
+	return out, errs.errorOrNil()
+}
+
+// walkGroups calls fn with the group path of every directory at or below
+// dir, where dir is the directory of group. A missing dir is skipped.
+func walkGroups(dir, group string, fn func(group string)) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	fn(group)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if err := walkGroups(filepath.Join(dir, e.Name()), filepath.Join(group, e.Name()), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
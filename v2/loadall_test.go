@@ -0,0 +1,60 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAll(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-loadall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	for _, group := range []string{"/parent/a/x", "/parent/b", "/other"} {
+		if err := os.MkdirAll(filepath.Join(mountpoint, group), defaultDirPerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := LoadAll(mountpoint, "/parent", func(group string) bool {
+		return group != "/parent"
+	}, WithPersistentFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 managers, got %v", all)
+	}
+	for _, group := range []string{"/parent/a", "/parent/a/x", "/parent/b"} {
+		m, ok := all[group]
+		if !ok {
+			t.Fatalf("expected a manager for %s", group)
+		}
+		if m.path != filepath.Join(mountpoint, group) || m.files == nil {
+			t.Fatalf("unexpected manager for %s: %+v", group, m)
+		}
+	}
+
+	if all, err := LoadAll(mountpoint, "/missing", nil); err != nil || len(all) != 0 {
+		t.Fatalf("expected no managers for a missing root, got %v (%v)", all, err)
+	}
+}
@@ -18,15 +18,21 @@ package v2
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"io"
 	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/containerd/cgroups/clock"
+	"github.com/containerd/cgroups/internal/cgfs"
 	"github.com/containerd/cgroups/v2/stats"
 	systemdDbus "github.com/coreos/go-systemd/v22/dbus"
 	"github.com/godbus/dbus/v5"
@@ -48,23 +54,26 @@ var (
 )
 
 type Event struct {
-	Low     uint64
-	High    uint64
-	Max     uint64
-	OOM     uint64
-	OOMKill uint64
+	Low     uint64 `json:"low"`
+	High    uint64 `json:"high"`
+	Max     uint64 `json:"max"`
+	OOM     uint64 `json:"oom"`
+	OOMKill uint64 `json:"oom_kill"`
 }
 
 // Resources for a cgroups v2 unified hierarchy
+//
+// Fields that are left out of the JSON encoding of the resources, see
+// MarshalResources, are nil or zero and are not written to the cgroup.
 type Resources struct {
-	CPU     *CPU
-	Memory  *Memory
-	Pids    *Pids
-	IO      *IO
-	RDMA    *RDMA
-	HugeTlb *HugeTlb
+	CPU     *CPU     `json:"cpu,omitempty"`
+	Memory  *Memory  `json:"memory,omitempty"`
+	Pids    *Pids    `json:"pids,omitempty"`
+	IO      *IO      `json:"io,omitempty"`
+	RDMA    *RDMA    `json:"rdma,omitempty"`
+	HugeTlb *HugeTlb `json:"hugetlb,omitempty"`
 	// When len(Devices) is zero, devices are not controlled
-	Devices []specs.LinuxDeviceCgroup
+	Devices []specs.LinuxDeviceCgroup `json:"devices,omitempty"`
 }
 
 // Values returns the raw filenames and values that
@@ -121,34 +130,47 @@ type Value struct {
 	value    interface{}
 }
 
-// write the value to the full, absolute path, of a unified hierarchy
-func (c *Value) write(path string, perm os.FileMode) error {
-	var data []byte
+// bytes returns the value as it is written to the file
+func (c *Value) bytes() ([]byte, error) {
 	switch t := c.value.(type) {
 	case uint64:
-		data = []byte(strconv.FormatUint(t, 10))
+		return []byte(strconv.FormatUint(t, 10)), nil
 	case uint16:
-		data = []byte(strconv.FormatUint(uint64(t), 10))
+		return []byte(strconv.FormatUint(uint64(t), 10)), nil
 	case int64:
-		data = []byte(strconv.FormatInt(t, 10))
+		return []byte(strconv.FormatInt(t, 10)), nil
 	case []byte:
-		data = t
+		return t, nil
 	case string:
-		data = []byte(t)
+		return []byte(t), nil
 	case CPUMax:
-		data = []byte(t)
-	default:
-		return ErrInvalidFormat
+		return []byte(t), nil
+	}
+	return nil, ErrInvalidFormat
+}
+
+// write the value to the full, absolute path, of a unified hierarchy
+func (c *Value) write(path string, perm os.FileMode) error {
+	data, err := c.bytes()
+	if err != nil {
+		return err
 	}
 
 	// Retry writes on EINTR; see:
 	//    https://github.com/golang/go/issues/38033
 	for {
-		err := ioutil.WriteFile(
+		start := time.Now()
+		err := writeFile(
 			filepath.Join(path, c.filename),
 			data,
 			perm,
 		)
+		if errors.Is(err, syscall.EINTR) {
+			observe(CallRetry, c.filename, start, err)
+		} else {
+			observe(CallWrite, c.filename, start, err)
+			audit(filepath.Join(path, c.filename), data, err)
+		}
 		if err == nil {
 			return nil
 		} else if !errors.Is(err, syscall.EINTR) {
@@ -166,52 +188,266 @@ func writeValues(path string, values []Value) error {
 	return nil
 }
 
-func NewManager(mountpoint string, group string, resources *Resources) (*Manager, error) {
+func NewManager(mountpoint string, group string, resources *Resources, opts ...InitOpts) (*Manager, error) {
 	if resources == nil {
 		return nil, errors.New("resources reference is nil")
 	}
 	if err := VerifyGroupPath(group); err != nil {
 		return nil, err
 	}
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return nil, err
+	}
 	path := filepath.Join(mountpoint, group)
+	var (
+		m  *Manager
+		op = &Operation{
+			Op:        OpNew,
+			Path:      path,
+			Resources: resources,
+			Warnings:  config.Warnings,
+		}
+	)
+	if err := config.intercept(op, func() (err error) {
+		m, err = createManager(mountpoint, path, op.Resources, config, op.deadline)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func createManager(mountpoint, path string, resources *Resources, config *InitConfig, dl deadline) (*Manager, error) {
 	if err := os.MkdirAll(path, defaultDirPerm); err != nil {
 		return nil, err
 	}
-	m := Manager{
-		unifiedMountpoint: mountpoint,
-		path:              path,
+	m := newManager(mountpoint, path, config)
+	controllers := resources.EnabledControllers()
+	if config.AccountingOnly {
+		var err error
+		if controllers, err = m.accountingControllers(resources); err != nil {
+			os.Remove(path)
+			return nil, err
+		}
 	}
-	if err := m.ToggleControllers(resources.EnabledControllers(), Enable); err != nil {
+	if err := m.ToggleControllers(controllers, Enable); err != nil {
 		// clean up cgroup dir on failure
 		os.Remove(path)
 		return nil, err
 	}
-	if err := setResources(path, resources); err != nil {
+	if err := initialize(path, resources, config, config.Warnings, dl); err != nil {
 		os.Remove(path)
 		return nil, err
 	}
-	return &m, nil
+	return m, nil
 }
 
-func LoadManager(mountpoint string, group string) (*Manager, error) {
+// initialize writes the resources, ownership and file modes of a newly
+// created group whose controllers are enabled, adding warnings to w and
+// stopping the writes at dl
+func initialize(path string, resources *Resources, config *InitConfig, w *Warnings, dl deadline) error {
+	if !config.AccountingOnly {
+		if err := config.setResources(path, resources, w, dl); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range resources.Values() {
+			w.add(v.filename, "not written, the group is accounting only")
+		}
+	}
+	if err := delegate(path, config.Owner); err != nil {
+		return err
+	}
+	return applyFileModes(path, config.FileModes, w)
+}
+
+// accountingControllers returns the controllers enabled for a group created
+// with WithAccountingOnly, those of the resources or all the controllers
+// available at the root when the resources have none
+func (c *Manager) accountingControllers(resources *Resources) ([]string, error) {
+	if controllers := resources.EnabledControllers(); len(controllers) > 0 {
+		return controllers, nil
+	}
+	return c.RootControllers()
+}
+
+// GetOrCreate loads the group if it exists and creates it with the resources
+// otherwise, so it can be called any number of times for the same group.
+// The resources are only applied to an existing group when
+// WithUpdateExisting is provided, while WithOwner and WithFileModes are
+// applied to it in either case.
+func GetOrCreate(mountpoint string, group string, resources *Resources, opts ...InitOpts) (*Manager, error) {
 	if err := VerifyGroupPath(group); err != nil {
 		return nil, err
 	}
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return nil, err
+	}
 	path := filepath.Join(mountpoint, group)
-	return &Manager{
-		unifiedMountpoint: mountpoint,
-		path:              path,
-	}, nil
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return NewManager(mountpoint, group, resources, opts...)
+		}
+		return nil, err
+	}
+	m := newManager(mountpoint, path, config)
+	if err := delegate(path, config.Owner); err != nil {
+		return nil, err
+	}
+	if err := applyFileModes(path, config.FileModes, config.Warnings); err != nil {
+		return nil, err
+	}
+	if config.UpdateExisting && resources != nil {
+		if err := m.ToggleControllers(resources.EnabledControllers(), Enable); err != nil {
+			return nil, err
+		}
+		if !config.AccountingOnly {
+			if err := m.Update(resources, WithUpdateWarnings(config.Warnings)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
 }
 
+func LoadManager(mountpoint string, group string, opts ...InitOpts) (*Manager, error) {
+	if err := VerifyGroupPath(group); err != nil {
+		return nil, err
+	}
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(mountpoint, group)
+	return newManager(mountpoint, path, config), nil
+}
+
+// Manager manages a cgroup in the unified hierarchy
+//
+// A Manager is safe for concurrent use. Concurrent Updates are applied one
+// after the other, never interleaved, and freezer state changes made by
+// Freeze, Thaw and RunFrozen are serialized. Stat may run concurrently with
+// any other method; it observes each file either before or after a
+// concurrent write to it, not a consistent snapshot across files.
 type Manager struct {
 	unifiedMountpoint string
 	path              string
+	config            *InitConfig
+	files             *fileCache
+	// dir holds the directory of a manager created from an fd open
+	dir *os.File
+	// limiter applies the configured rate limit
+	limiter *rateLimiter
+
+	// updateMu serializes Update
+	updateMu sync.Mutex
+	// stateMu serializes freezer state changes
+	stateMu sync.Mutex
+	// pending holds the files whose reads by Stat timed out and are still
+	// blocked
+	pending pendingReads
+}
+
+func newManager(mountpoint, path string, config *InitConfig) *Manager {
+	m := &Manager{
+		unifiedMountpoint: mountpoint,
+		path:              path,
+		config:            config.forManager(),
+	}
+	if config.PersistentFiles {
+		m.files = newFileCache(path)
+	}
+	if config.RateLimit != nil {
+		m.limiter = newRateLimiter(*config.RateLimit)
+	}
+	return m
+}
+
+// Close releases any files held open by the manager
+func (c *Manager) Close() error {
+	var err error
+	if c.files != nil {
+		err = c.files.close()
+	}
+	if c.dir != nil {
+		if cerr := c.dir.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// readFile reads a file of the cgroup, using the open file when it is
+// kept open by the manager
+func (c *Manager) readFile(name string) (data []byte, err error) {
+	start := time.Now()
+	defer func() {
+		observe(CallRead, name, start, err)
+	}()
+	if c.files != nil {
+		if _, ok := persistentFiles[name]; ok {
+			data, err := c.files.read(name)
+			if err != os.ErrClosed {
+				return data, err
+			}
+		}
+	}
+	timeout := c.config.timeouts().Read
+	if timeout <= 0 {
+		return readBounded(filepath.Join(c.path, name))
+	}
+	var out []byte
+	if err := withTimeout(timeout, func() (err error) {
+		out, err = readBounded(filepath.Join(c.path, name))
+		return err
+	}); err != nil {
+		// out is not read on errors, a timed out read may still set it
+		return nil, err
+	}
+	return out, nil
+}
+
+// resolveResources returns the resources with the io limits set by device path
+// resolved, the provided resources are not modified
+func resolveResources(resources *Resources) (*Resources, error) {
+	if resources == nil || resources.IO == nil {
+		return resources, nil
+	}
+	io, err := resources.IO.resolve()
+	if err != nil {
+		return nil, err
+	}
+	out := *resources
+	out.IO = io
+	return &out, nil
 }
 
 func setResources(path string, resources *Resources) error {
+	return (*InitConfig)(nil).setResources(path, resources, nil, deadline{})
+}
+
+// setResources writes the resources to the group at path, applying the
+// unwritable policy of the config, adding warnings to w and stopping the
+// writes at dl
+func (c *InitConfig) setResources(path string, resources *Resources, w *Warnings, dl deadline) error {
+	resources, err := resolveResources(resources)
+	if err != nil {
+		return err
+	}
 	if resources != nil {
-		if err := writeValues(path, resources.Values()); err != nil {
+		if resources.HugeTlb != nil {
+			if err := resources.HugeTlb.validate(); err != nil {
+				return err
+			}
+		}
+		if resources.CPU != nil {
+			if err := resources.CPU.validate(); err != nil {
+				return err
+			}
+		}
+		if err := c.writeValues(path, resources.Values(), w, dl); err != nil {
 			return err
 		}
 		if err := setDevices(path, resources.Devices); err != nil {
@@ -222,7 +458,7 @@ func setResources(path string, resources *Resources) error {
 }
 
 func (c *Manager) RootControllers() ([]string, error) {
-	b, err := ioutil.ReadFile(filepath.Join(c.unifiedMountpoint, controllersFile))
+	b, err := readBounded(filepath.Join(c.unifiedMountpoint, controllersFile))
 	if err != nil {
 		return nil, err
 	}
@@ -230,7 +466,7 @@ func (c *Manager) RootControllers() ([]string, error) {
 }
 
 func (c *Manager) Controllers() ([]string, error) {
-	b, err := ioutil.ReadFile(filepath.Join(c.path, controllersFile))
+	b, err := readBounded(filepath.Join(c.path, controllersFile))
 	if err != nil {
 		return nil, err
 	}
@@ -277,7 +513,7 @@ func (c *Manager) ToggleControllers(controllers []string, t ControllerToggle) er
 }
 
 func (c *Manager) writeSubtreeControl(filePath string, controllers []string, t ControllerToggle) error {
-	f, err := os.OpenFile(filePath, os.O_WRONLY, 0)
+	f, err := openFile(filePath, os.O_WRONLY, 0)
 	if err != nil {
 		return err
 	}
@@ -288,39 +524,216 @@ func (c *Manager) writeSubtreeControl(filePath string, controllers []string, t C
 	case Disable:
 		controllers = toggleFunc(controllers, "-")
 	}
-	_, err = f.WriteString(strings.Join(controllers, " "))
+	data := strings.Join(controllers, " ")
+	_, err = f.WriteString(data)
+	audit(filePath, []byte(data), err)
 	return err
 }
 
-func (c *Manager) NewChild(name string, resources *Resources) (*Manager, error) {
+// NewChild creates the group name below the cgroup with the resources. Of
+// the options only WithUpdateWarnings applies, it collects what creating the
+// child skipped or approximated.
+func (c *Manager) NewChild(name string, resources *Resources, opts ...UpdateOpts) (*Manager, error) {
+	var config updateConfig
+	for _, o := range opts {
+		o(&config)
+	}
 	if strings.HasPrefix(name, "/") {
 		return nil, errors.New("name must be relative")
 	}
 	path := filepath.Join(c.path, name)
-	if err := os.MkdirAll(path, defaultDirPerm); err != nil {
+	op := &Operation{
+		Op:        OpNew,
+		Path:      path,
+		Resources: resources,
+		Warnings:  config.warnings,
+	}
+	if err := c.config.intercept(op, func() error {
+		if err := os.MkdirAll(path, defaultDirPerm); err != nil {
+			return err
+		}
+		if err := c.config.setResources(path, op.Resources, op.Warnings, op.deadline); err != nil {
+			// clean up cgroup dir on failure
+			os.Remove(path)
+			return err
+		}
+		if c.config != nil {
+			if err := delegate(path, c.config.Owner); err != nil {
+				os.Remove(path)
+				return err
+			}
+			if err := applyFileModes(path, c.config.FileModes, op.Warnings); err != nil {
+				os.Remove(path)
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
 		return nil, err
 	}
-	if err := setResources(path, resources); err != nil {
-		// clean up cgroup dir on failure
-		os.Remove(path)
-		return nil, err
+	return newManager(c.unifiedMountpoint, path, c.config), nil
+}
+
+// Update updates the resources of the cgroup
+func (c *Manager) Update(resources *Resources, opts ...UpdateOpts) error {
+	var config updateConfig
+	for _, o := range opts {
+		o(&config)
 	}
-	return &Manager{
-		unifiedMountpoint: c.unifiedMountpoint,
-		path:              path,
-	}, nil
+	if err := c.throttle("update"); err != nil {
+		return err
+	}
+	c.updateMu.Lock()
+	defer c.updateMu.Unlock()
+	resources, err := resolveResources(resources)
+	if err != nil {
+		return err
+	}
+	_, span := c.config.startSpan(context.Background(), "cgroups.Update", c.path)
+	if resources != nil {
+		span.SetAttribute(AttributeControllers, touchedControllers(resources.Values()))
+	}
+	op := &Operation{
+		Op:        OpUpdate,
+		Path:      c.path,
+		Resources: resources,
+		Warnings:  config.warnings,
+	}
+	err = c.config.intercept(op, func() error {
+		// interceptors can replace the resources, such as policies
+		// clamping them
+		if err := c.config.setResources(c.path, op.Resources, op.Warnings, op.deadline); err != nil {
+			return err
+		}
+		if config.verify && op.Resources != nil {
+			return verifyValues(c.path, op.Resources.Values())
+		}
+		return nil
+	})
+	span.End(err)
+	return err
 }
 
+// AddProc moves the process into the cgroup. ErrFrozen or ErrDying is
+// returned when the kernel refuses the move because of the cgroup's state.
 func (c *Manager) AddProc(pid uint64) error {
 	v := Value{
 		filename: cgroupProcs,
 		value:    pid,
 	}
-	return writeValues(c.path, []Value{v})
+	return c.config.intercept(&Operation{
+		Op:   OpAddProc,
+		Path: c.path,
+		Pid:  pid,
+	}, func() error {
+		if err := writeValues(c.path, []Value{v}); err != nil {
+			return addError(c.path, err)
+		}
+		return nil
+	})
 }
 
+// addError returns ErrFrozen or ErrDying when the state of the cgroup at path
+// is why the kernel refused to move a process into it, so that callers can
+// thaw and retry or give up. Other errors are returned unchanged.
+func addError(path string, err error) error {
+	if errors.Is(err, unix.ENODEV) {
+		return &StateError{State: ErrDying, Err: err}
+	}
+	if !errors.Is(err, unix.EBUSY) && !os.IsNotExist(errors.Cause(err)) {
+		return err
+	}
+	if _, serr := os.Lstat(path); os.IsNotExist(serr) {
+		return &StateError{State: ErrDying, Err: err}
+	}
+	// EBUSY is mostly the kernel enforcing that processes are only in
+	// leaves, it is only reported as ErrFrozen when the group is frozen
+	if state, _ := fetchState(path); state == Frozen && errors.Is(err, unix.EBUSY) {
+		return &StateError{State: ErrFrozen, Err: err}
+	}
+	return err
+}
+
+// Delete removes the cgroup. Deleting a cgroup that no longer exists is not
+// an error. ErrDeleteFromFD is returned for a manager created with
+// NewManagerFromFD.
 func (c *Manager) Delete() error {
-	return remove(c.path)
+	if c.dir != nil {
+		return ErrDeleteFromFD
+	}
+	return c.config.intercept(&Operation{
+		Op:   OpDelete,
+		Path: c.path,
+	}, func() error {
+		c.Close()
+		return remove(c.path, c.config.pacer())
+	})
+}
+
+// DeleteRecursive removes the group and all of its children, deepest first.
+// It continues past groups that cannot be removed, leaving their parents in
+// place, and returns a *MultiError with the failure of every such group.
+// ErrDeleteFromFD is returned for a manager created with NewManagerFromFD.
+func (c *Manager) DeleteRecursive() error {
+	if c.dir != nil {
+		return ErrDeleteFromFD
+	}
+	return c.config.intercept(&Operation{
+		Op:   OpDelete,
+		Path: c.path,
+	}, func() error {
+		c.Close()
+		var errs MultiError
+		clk := c.config.pacer()
+		removeChildren(c.path, clk, &errs)
+		if len(errs.Errors) == 0 {
+			if err := remove(c.path, clk); err != nil && !os.IsNotExist(errors.Cause(err)) {
+				errs.add(c.path, err)
+			}
+		}
+		return errs.errorOrNil()
+	})
+}
+
+// removeChildren removes the children of path deepest first, recording the
+// groups that cannot be removed. It returns false if any could not be.
+func removeChildren(path string, clk clock.Clock, errs *MultiError) bool {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true
+		}
+		errs.add(path, err)
+		return false
+	}
+	ok := true
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		child := filepath.Join(path, e.Name())
+		if !removeChildren(child, clk, errs) {
+			ok = false
+			continue
+		}
+		if err := remove(child, clk); err != nil && !os.IsNotExist(errors.Cause(err)) {
+			errs.add(child, err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// AddProcs moves every process into the cgroup, continuing past processes
+// that cannot be moved. The failures are returned as a *MultiError.
+func (c *Manager) AddProcs(pids ...uint64) error {
+	var errs MultiError
+	for _, pid := range pids {
+		if err := c.AddProc(pid); err != nil {
+			errs.add(c.path, errors.Wrapf(err, "pid %d", pid))
+		}
+	}
+	return errs.errorOrNil()
 }
 
 func (c *Manager) Procs(recursive bool) ([]uint64, error) {
@@ -354,17 +767,50 @@ var singleValueFiles = []string{
 	"pids.max",
 }
 
+// Stat returns the current metrics of the cgroup. The metrics are freshly
+// allocated on each call and never reused by the manager. ErrCgroupDeleted
+// is returned if the cgroup no longer exists.
+//
+// While the cgroup is frozen, fields read from files that do not return
+// within Timeouts.FrozenRead are left zero rather than hanging the caller,
+// see StatStale.
 func (c *Manager) Stat() (*stats.Metrics, error) {
+	metrics, _, err := c.StatStale()
+	return metrics, err
+}
+
+// StatStale returns the metrics of the cgroup like Stat, along with the
+// interface files that could not be read in time while the cgroup was
+// frozen. The fields read from stale files are zero.
+func (c *Manager) StatStale() (*stats.Metrics, []string, error) {
+	_, span := c.config.startSpan(context.Background(), "cgroups.Stat", c.path)
+	r := c.newStatReader()
+	metrics, err := c.stat(span, r)
+	// report a deletion that raced with reading the stats as such instead
+	// of the errors or partial stats it caused
+	if c.deleted() {
+		metrics, err = nil, ErrCgroupDeleted
+	}
+	span.End(err)
+	if err != nil {
+		return nil, nil, err
+	}
+	return metrics, r.stale, nil
+}
+
+func (c *Manager) stat(span Span, r *statReader) (*stats.Metrics, error) {
+	timestamp := c.config.clock().now()
 	controllers, err := c.Controllers()
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttribute(AttributeControllers, strings.Join(controllers, ","))
 	out := make(map[string]interface{})
 	for _, controller := range controllers {
 		switch controller {
 		case "cpu", "memory":
-			if err := readKVStatsFile(c.path, controller+".stat", out); err != nil {
-				if os.IsNotExist(err) {
+			if err := r.kv(controller+".stat", out, c.readKVStats); err != nil {
+				if os.IsNotExist(err) || err == errStale {
 					continue
 				}
 				return nil, err
@@ -372,20 +818,24 @@ func (c *Manager) Stat() (*stats.Metrics, error) {
 		}
 	}
 	for _, name := range singleValueFiles {
-		if err := readSingleFile(c.path, name, out); err != nil {
-			if os.IsNotExist(err) {
+		if err := r.kv(name, out, c.readSingleFile); err != nil {
+			if os.IsNotExist(err) || err == errStale {
 				continue
 			}
 			return nil, err
 		}
 	}
 	memoryEvents := make(map[string]interface{})
-	if err := readKVStatsFile(c.path, "memory.events", memoryEvents); err != nil {
-		if !os.IsNotExist(err) {
+	if err := r.kv("memory.events", memoryEvents, func(file string, out map[string]interface{}) error {
+		return readKVStatsFile(c.path, file, out)
+	}); err != nil {
+		if !os.IsNotExist(err) && err != errStale {
 			return nil, err
 		}
 	}
-	var metrics stats.Metrics
+	metrics := stats.Metrics{
+		Timestamp: timestamp,
+	}
 
 	metrics.Pids = &stats.PidsStat{
 		Current: getPidValue("pids.current", out),
@@ -431,10 +881,10 @@ func (c *Manager) Stat() (*stats.Metrics, error) {
 		Pglazyfreed:           getUint64Value("pglazyfreed", out),
 		ThpFaultAlloc:         getUint64Value("thp_fault_alloc", out),
 		ThpCollapseAlloc:      getUint64Value("thp_collapse_alloc", out),
-		Usage:                 getStatFileContentUint64(filepath.Join(c.path, "memory.current")),
-		UsageLimit:            getStatFileContentUint64(filepath.Join(c.path, "memory.max")),
-		SwapUsage:             getStatFileContentUint64(filepath.Join(c.path, "memory.swap.current")),
-		SwapLimit:             getStatFileContentUint64(filepath.Join(c.path, "memory.swap.max")),
+		Usage:                 r.uint64("memory.current", c.readStatUint64),
+		UsageLimit:            r.uint64("memory.max", c.readStatFileUint64),
+		SwapUsage:             r.uint64("memory.swap.current", c.readStatFileUint64),
+		SwapLimit:             r.uint64("memory.swap.max", c.readStatFileUint64),
 	}
 	if len(memoryEvents) > 0 {
 		metrics.MemoryEvents = &stats.MemoryEvents{
@@ -445,12 +895,28 @@ func (c *Manager) Stat() (*stats.Metrics, error) {
 			OomKill: getUint64Value("oom_kill", memoryEvents),
 		}
 	}
-	metrics.Io = &stats.IOStat{Usage: readIoStats(c.path)}
+	ioUsage, _ := r.value("io.stat", func() interface{} {
+		return readIoStats(c.path)
+	}).([]*stats.IOEntry)
+	metrics.Io = &stats.IOStat{Usage: ioUsage}
+	rdmaCurrent, _ := r.value("rdma.current", func() interface{} {
+		return rdmaStats(filepath.Join(c.path, "rdma.current"))
+	}).([]*stats.RdmaEntry)
+	rdmaLimit, _ := r.value("rdma.max", func() interface{} {
+		return rdmaStats(filepath.Join(c.path, "rdma.max"))
+	}).([]*stats.RdmaEntry)
 	metrics.Rdma = &stats.RdmaStat{
-		Current: rdmaStats(filepath.Join(c.path, "rdma.current")),
-		Limit:   rdmaStats(filepath.Join(c.path, "rdma.max")),
+		Current: rdmaCurrent,
+		Limit:   rdmaLimit,
 	}
-	metrics.Hugetlb = readHugeTlbStats(c.path)
+	metrics.Hugetlb, _ = r.value("hugetlb", func() interface{} {
+		dir, err := c.openDir()
+		if err != nil {
+			return []*stats.HugeTlbStat{}
+		}
+		defer dir.Close()
+		return readHugeTlbStats(dir, c.path)
+	}).([]*stats.HugeTlbStat)
 
 	return &metrics, nil
 }
@@ -483,13 +949,8 @@ func getPidValue(key string, out map[string]interface{}) uint64 {
 	return 0
 }
 
-func readSingleFile(path string, file string, out map[string]interface{}) error {
-	f, err := os.Open(filepath.Join(path, file))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	data, err := ioutil.ReadAll(f)
+func (c *Manager) readSingleFile(file string, out map[string]interface{}) error {
+	data, err := c.readFile(file)
 	if err != nil {
 		return err
 	}
@@ -504,36 +965,97 @@ func readSingleFile(path string, file string, out map[string]interface{}) error
 	return nil
 }
 
-func readKVStatsFile(path string, file string, out map[string]interface{}) error {
-	f, err := os.Open(filepath.Join(path, file))
+func readKVStatsFile(path string, file string, out map[string]interface{}) (err error) {
+	start := time.Now()
+	defer func() {
+		observe(CallRead, file, start, err)
+	}()
+	data, err := readBounded(filepath.Join(path, file))
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	return parseKVStats(bytes.NewReader(data), filepath.Join(path, file), out)
+}
 
-	s := bufio.NewScanner(f)
+func (c *Manager) readKVStats(file string, out map[string]interface{}) error {
+	data, err := c.readFile(file)
+	if err != nil {
+		return err
+	}
+	return parseKVStats(bytes.NewReader(data), filepath.Join(c.path, file), out)
+}
+
+func parseKVStats(r io.Reader, path string, out map[string]interface{}) error {
+	s := bufio.NewScanner(r)
 	for s.Scan() {
 		name, value, err := parseKV(s.Text())
 		if err != nil {
-			return errors.Wrapf(err, "error while parsing %s (line=%q)", filepath.Join(path, file), s.Text())
+			logrus.Warnf("cgroups: skipping invalid line while parsing %s (line=%q): %v", path, s.Text(), err)
+			continue
 		}
 		out[name] = value
 	}
 	return s.Err()
 }
 
+// readStatUint64 returns the value of a single value stat file, 0 if it
+// cannot be read
+func (c *Manager) readStatUint64(file string) uint64 {
+	data, err := c.readFile(file)
+	if err != nil {
+		return 0
+	}
+	return parseStatFileContentUint64(data, filepath.Join(c.path, file))
+}
+
+// readStatFileUint64 reads the file directly, bypassing the persistent files
+// and read timeouts of readStatUint64
+func (c *Manager) readStatFileUint64(file string) uint64 {
+	return getStatFileContentUint64(filepath.Join(c.path, file))
+}
+
 func (c *Manager) Freeze() error {
-	return c.freeze(c.path, Frozen)
+	if err := c.throttle("freeze"); err != nil {
+		return err
+	}
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	_, span := c.config.startSpan(context.Background(), "cgroups.Freeze", c.path)
+	err := c.setState(Frozen)
+	span.End(err)
+	return err
 }
 
 func (c *Manager) Thaw() error {
-	return c.freeze(c.path, Thawed)
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.thaw(context.Background())
+}
+
+func (c *Manager) thaw(ctx context.Context) error {
+	_, span := c.config.startSpan(ctx, "cgroups.Thaw", c.path)
+	err := c.setState(Thawed)
+	span.End(err)
+	return err
+}
+
+// setState runs freeze for the state as an OpFreeze operation
+func (c *Manager) setState(state State) error {
+	op := &Operation{
+		Op:    OpFreeze,
+		Path:  c.path,
+		State: state,
+	}
+	return c.config.intercept(op, func() error {
+		return c.freeze(c.path, op.State, op.deadline)
+	})
 }
 
-func (c *Manager) freeze(path string, state State) error {
+// freeze writes the state until the kernel reports it, giving up at dl
+func (c *Manager) freeze(path string, state State, dl deadline) error {
 	values := state.Values()
 	for {
-		if err := writeValues(path, values); err != nil {
+		if err := c.config.writeValues(path, values, nil, dl); err != nil {
 			return err
 		}
 		current, err := fetchState(path)
@@ -543,6 +1065,9 @@ func (c *Manager) freeze(path string, state State) error {
 		if current == state {
 			return nil
 		}
+		if err := dl.check(); err != nil {
+			return err
+		}
 		time.Sleep(1 * time.Millisecond)
 	}
 }
@@ -658,10 +1183,14 @@ func setDevices(path string, devices []specs.LinuxDeviceCgroup) error {
 	return nil
 }
 
-func NewSystemd(slice, group string, pid int, resources *Resources) (*Manager, error) {
+func NewSystemd(slice, group string, pid int, resources *Resources, opts ...InitOpts) (*Manager, error) {
 	if slice == "" {
 		slice = defaultSlice
 	}
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return &Manager{}, err
+	}
 	path := filepath.Join(defaultCgroup2Path, slice, group)
 	conn, err := systemdDbus.New()
 	if err != nil {
@@ -676,6 +1205,12 @@ func NewSystemd(slice, group string, pid int, resources *Resources) (*Manager, e
 		newSystemdProperty("CPUAccounting", true),
 		newSystemdProperty("IOAccounting", true),
 	}
+	if config.AccountingOnly {
+		// systemd enables the controllers for the accounting properties,
+		// none of the resources are set as properties or written
+		properties = append(properties, newSystemdProperty("TasksAccounting", true))
+		resources = &Resources{}
+	}
 
 	// if we create a slice, the parent is defined via a Wants=
 	if strings.HasSuffix(group, ".slice") {
@@ -690,62 +1225,87 @@ func NewSystemd(slice, group string, pid int, resources *Resources) (*Manager, e
 		properties = append(properties, newSystemdProperty("PIDs", []uint32{uint32(pid)}))
 	}
 
-	if resources.Memory != nil && *resources.Memory.Max != 0 {
-		properties = append(properties,
-			newSystemdProperty("MemoryMax", uint64(*resources.Memory.Max)))
+	// If we can delegate, we add the property back in
+	if canDelegate {
+		properties = append(properties, newSystemdProperty("Delegate", true))
 	}
 
-	if resources.CPU != nil && *resources.CPU.Weight != 0 {
-		properties = append(properties,
-			newSystemdProperty("CPUWeight", *resources.CPU.Weight))
+	op := &Operation{
+		Op:        OpNew,
+		Path:      path,
+		Resources: resources,
+		Warnings:  config.Warnings,
 	}
-
-	if resources.CPU != nil && resources.CPU.Max != "" {
-		quota, period := resources.CPU.Max.extractQuotaAndPeriod()
-		// cpu.cfs_quota_us and cpu.cfs_period_us are controlled by systemd.
-		// corresponds to USEC_INFINITY in systemd
-		// if USEC_INFINITY is provided, CPUQuota is left unbound by systemd
-		// always setting a property value ensures we can apply a quota and remove it later
-		cpuQuotaPerSecUSec := uint64(math.MaxUint64)
-		if quota > 0 {
-			// systemd converts CPUQuotaPerSecUSec (microseconds per CPU second) to CPUQuota
-			// (integer percentage of CPU) internally.  This means that if a fractional percent of
-			// CPU is indicated by Resources.CpuQuota, we need to round up to the nearest
-			// 10ms (1% of a second) such that child cgroups can set the cpu.cfs_quota_us they expect.
-			cpuQuotaPerSecUSec = uint64(quota*1000000) / period
-			if cpuQuotaPerSecUSec%10000 != 0 {
-				cpuQuotaPerSecUSec = ((cpuQuotaPerSecUSec / 10000) + 1) * 10000
-			}
-		}
-		properties = append(properties,
-			newSystemdProperty("CPUQuotaPerSecUSec", cpuQuotaPerSecUSec))
+	if err := config.intercept(op, func() error {
+		// interceptors can replace the resources, such as policies
+		// clamping them
+		return startSystemd(conn, group, path, properties, op, config)
+	}); err != nil {
+		return &Manager{}, err
 	}
+	return newManager(defaultCgroup2Path, path, config), nil
+}
 
-	// If we can delegate, we add the property back in
-	if canDelegate {
-		properties = append(properties, newSystemdProperty("Delegate", true))
+// startSystemd starts the transient unit of group with the properties and
+// the resources of op, writing the resources systemd has no properties for
+func startSystemd(conn *systemdDbus.Conn, group, path string, properties []systemdDbus.Property, op *Operation, config *InitConfig) error {
+	resourceProperties := SystemdProperties(op.Resources)
+	if config.RuncCompat {
+		resourceProperties = RuncSystemdProperties(op.Resources)
 	}
-
-	if resources.Pids != nil && resources.Pids.Max > 0 {
-		properties = append(properties,
-			newSystemdProperty("TasksAccounting", true),
-			newSystemdProperty("TasksMax", uint64(resources.Pids.Max)))
+	var report PropertyReport
+	if version, err := SystemdVersion(conn); err == nil {
+		resourceProperties, report = FilterSystemdProperties(version, resourceProperties)
+	} else {
+		logrus.Warnf("cgroups: unable to read the systemd version, assuming all properties are supported: %v", err)
 	}
+	properties = append(properties, resourceProperties...)
 
 	statusChan := make(chan string, 1)
-	if _, err := conn.StartTransientUnit(group, "replace", properties, statusChan); err == nil {
+	_, span := config.startSpan(context.Background(), "systemd.StartTransientUnit", path)
+	start := time.Now()
+	err := withTimeout(config.timeouts().Dbus, func() error {
+		_, err := conn.StartTransientUnit(group, "replace", properties, statusChan)
+		return err
+	})
+	observe(CallDbus, "StartTransientUnit", start, err)
+	span.End(err)
+	if err == nil {
 		select {
-		case <-statusChan:
+		case result := <-statusChan:
+			if err := cgfs.JobResult(group, result); err != nil {
+				return err
+			}
 		case <-time.After(time.Second):
+			// the completion signal can be lost, check that the job did
+			// not fail before continuing
+			if err := unitActive(conn, group); err != nil {
+				return err
+			}
 			logrus.Warnf("Timed out while waiting for StartTransientUnit(%s) completion signal from dbus. Continuing...", group)
 		}
 	} else if !isUnitExists(err) {
-		return &Manager{}, err
+		return cgfs.UnitError(err)
 	}
-
-	return &Manager{
-		path: path,
-	}, nil
+	if config.RuncCompat {
+		// runc writes all the resources after starting the unit to apply the
+		// settings that systemd does not have properties for
+		if err := config.setResources(path, op.Resources, op.Warnings, op.deadline); err != nil {
+			return err
+		}
+	} else if err := config.writeValues(path, directValues(report, op.Resources), op.Warnings, op.deadline); err != nil {
+		return err
+	}
+	for _, p := range report.Skipped {
+		op.Warnings.add(p, "property not supported by systemd %d", report.Version)
+	}
+	for _, f := range report.Direct {
+		op.Warnings.add(f, "written directly, systemd %d has no property for it", report.Version)
+	}
+	if len(report.Skipped) > 0 && config.PropertyReport != nil {
+		config.PropertyReport(report)
+	}
+	return nil
 }
 
 func LoadSystemd(slice, group string) (*Manager, error) {
@@ -753,11 +1313,12 @@ func LoadSystemd(slice, group string) (*Manager, error) {
 		slice = defaultSlice
 	}
 	group = filepath.Join(defaultCgroup2Path, slice, group)
-	return &Manager{
-		path: group,
-	}, nil
+	return newManager(defaultCgroup2Path, group, &InitConfig{}), nil
 }
 
+// DeleteSystemd stops the systemd unit of the cgroup and waits for systemd
+// to unload it, resetting the unit if it failed, so that a unit with the same
+// name can be started as soon as it returns.
 func (c *Manager) DeleteSystemd() error {
 	conn, err := systemdDbus.New()
 	if err != nil {
@@ -765,13 +1326,46 @@ func (c *Manager) DeleteSystemd() error {
 	}
 	defer conn.Close()
 	group := systemdUnitFromPath(c.path)
-	ch := make(chan string)
-	_, err = conn.StopUnit(group, "replace", ch)
-	if err != nil {
-		return err
-	}
-	<-ch
-	return nil
+	// the result is buffered so that a job completing after the timeout
+	// does not block the connection
+	ch := make(chan string, 1)
+	return c.config.intercept(&Operation{
+		Op:   OpDelete,
+		Path: c.path,
+	}, func() error {
+		timeout := c.config.timeouts().Dbus
+		_, span := c.config.startSpan(context.Background(), "systemd.StopUnit", c.path)
+		start := time.Now()
+		// a call that times out returns once the connection is closed
+		err := withTimeout(timeout, func() error {
+			_, err := conn.StopUnit(group, "replace", ch)
+			return err
+		})
+		observe(CallDbus, "StopUnit", start, err)
+		span.End(err)
+		if err == ErrTimeout {
+			return err
+		} else if err != nil {
+			return cgfs.UnitError(err)
+		}
+		// the job result is waited for here rather than in withTimeout, as
+		// nothing is sent on ch once the connection is closed
+		var expired <-chan time.Time
+		if timeout > 0 {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			expired = timer.C
+		}
+		select {
+		case result := <-ch:
+			if err := cgfs.JobResult(group, result); err != nil {
+				return err
+			}
+		case <-expired:
+			return ErrTimeout
+		}
+		return cgfs.WaitUnitRemoved(conn, group, cgfs.UnitRemoveTimeout, c.config.pacer())
+	})
 }
 
 func newSystemdProperty(name string, units interface{}) systemdDbus.Property {
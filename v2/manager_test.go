@@ -0,0 +1,246 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containerd/cgroups/cgroupstest"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+func TestGetOrCreate(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-getorcreate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	maxPath := filepath.Join(mountpoint, "test", "pids.max")
+	readMax := func() string {
+		data, err := ioutil.ReadFile(maxPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if _, err := GetOrCreate(mountpoint, "/test", &Resources{Pids: &Pids{Max: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	if max := readMax(); max != "10" {
+		t.Fatalf("expected pids.max of 10 but received %q", max)
+	}
+	updated := &Resources{Pids: &Pids{Max: 20}}
+	if _, err := GetOrCreate(mountpoint, "/test", updated); err != nil {
+		t.Fatal(err)
+	}
+	if max := readMax(); max != "10" {
+		t.Fatalf("expected the existing pids.max to be kept but received %q", max)
+	}
+	if _, err := GetOrCreate(mountpoint, "/test", updated, WithUpdateExisting()); err != nil {
+		t.Fatal(err)
+	}
+	if max := readMax(); max != "20" {
+		t.Fatalf("expected pids.max to be updated to 20 but received %q", max)
+	}
+	if _, err := GetOrCreate(mountpoint, "/test", nil, WithFileModes(map[string]os.FileMode{"pids.max": 0664})); err != nil {
+		t.Fatal(err)
+	}
+	st, err := os.Stat(maxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := st.Mode().Perm(); mode != 0664 {
+		t.Fatalf("expected the file mode to be applied to the existing group but received %v", mode)
+	}
+}
+
+func TestManagerConcurrentUse(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-concurrent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(mountpoint, "/test", &Resources{}, WithPersistentFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		controllersFile:  "pids memory",
+		"pids.current":   "1",
+		"memory.current": "4096",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(m.path, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const iterations = 100
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 8*iterations)
+	)
+	run := func(fn func(i int) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if err := fn(i); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		run(func(int) error {
+			_, err := m.Stat()
+			return err
+		})
+		run(func(i int) error {
+			return m.Update(&Resources{Pids: &Pids{Max: int64(i + 1)}}, WithVerify())
+		})
+		run(func(i int) error {
+			if i%2 == 0 {
+				return m.Freeze()
+			}
+			return m.Thaw()
+		})
+	}
+	run(func(i int) error {
+		if i == iterations/2 {
+			return m.Close()
+		}
+		return nil
+	})
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestManagerSandbox(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	m, err := NewManager(sandbox.Mountpoint, filepath.Join(sandbox.Group, "test"), &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatDeleted(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	m, err := NewManager(sandbox.Mountpoint, filepath.Join(sandbox.Group, "deleted"), &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// remove the cgroup behind the back of the manager
+	if err := os.Remove(m.path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat(); err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.Delete(); err != nil {
+			t.Fatalf("expected deleting a removed cgroup to succeed: %v", err)
+		}
+	}
+}
+
+func TestAddError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-adderror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	busy := &os.PathError{Op: "write", Path: filepath.Join(dir, cgroupProcs), Err: unix.EBUSY}
+
+	if err := addError(dir, busy); err != busy {
+		t.Fatalf("expected EBUSY to be returned unchanged for a thawed cgroup, got %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, cgroupFreeze), []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := addError(dir, busy); errors.Cause(err) != ErrFrozen || !errors.Is(err, unix.EBUSY) {
+		t.Fatalf("expected ErrFrozen wrapping EBUSY, got %v", err)
+	}
+	if err := addError(filepath.Join(dir, "removed"), busy); errors.Cause(err) != ErrDying {
+		t.Fatalf("expected ErrDying for a removed cgroup, got %v", err)
+	}
+	nodev := &os.PathError{Op: "write", Path: filepath.Join(dir, cgroupProcs), Err: unix.ENODEV}
+	if err := addError(dir, nodev); errors.Cause(err) != ErrDying {
+		t.Fatalf("expected ErrDying, got %v", err)
+	}
+}
+
+func TestNewManagerAccountingOnly(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-accounting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, controllersFile), []byte("cpu memory pids\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readSubtree := func() string {
+		data, err := ioutil.ReadFile(filepath.Join(mountpoint, subtreeControl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(data))
+	}
+
+	if _, err := NewManager(mountpoint, "/limited", &Resources{Pids: &Pids{Max: 10}}, WithAccountingOnly()); err != nil {
+		t.Fatal(err)
+	}
+	if v := readSubtree(); v != "+pids" {
+		t.Fatalf("expected the pids controller to be enabled, got %q", v)
+	}
+	if _, err := os.Stat(filepath.Join(mountpoint, "limited", "pids.max")); !os.IsNotExist(err) {
+		t.Fatalf("expected no limit to be written, got %v", err)
+	}
+
+	if _, err := NewManager(mountpoint, "/all", &Resources{}, WithAccountingOnly()); err != nil {
+		t.Fatal(err)
+	}
+	if v := readSubtree(); v != "+cpu +memory +pids" {
+		t.Fatalf("expected all root controllers to be enabled, got %q", v)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseMax parses the contents of a file holding a single limit or "max",
+// such as memory.max or pids.max. "max" is returned as -1.
+func ParseMax(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "max" {
+		return -1, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidFormat, "limit %q", s)
+	}
+	return v, nil
+}
+
+// FormatMax formats a limit for files such as memory.max or pids.max,
+// negative limits are formatted as "max"
+func FormatMax(v int64) string {
+	if v < 0 {
+		return "max"
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// ParseCPUMax parses the contents of cpu.max, "$MAX $PERIOD" where $MAX is
+// the quota in microseconds or "max". A nil quota is returned for "max" and
+// a nil period when only the quota is given.
+func ParseCPUMax(s string) (quota *int64, period *uint64, err error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, nil, errors.Wrapf(ErrInvalidFormat, "cpu.max %q", s)
+	}
+	if fields[0] != "max" {
+		q, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil || q <= 0 {
+			return nil, nil, errors.Wrapf(ErrInvalidFormat, "cpu.max quota %q", fields[0])
+		}
+		quota = &q
+	}
+	if len(fields) == 2 {
+		p, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil || p == 0 {
+			return nil, nil, errors.Wrapf(ErrInvalidFormat, "cpu.max period %q", fields[1])
+		}
+		period = &p
+	}
+	return quota, period, nil
+}
+
+// FormatCPUMax formats cpu.max, a nil quota as "max". A nil period leaves it
+// out so that writing the value changes the quota and keeps the current
+// period.
+func FormatCPUMax(quota *int64, period *uint64) string {
+	max := "max"
+	if quota != nil && *quota > 0 {
+		max = strconv.FormatInt(*quota, 10)
+	}
+	if period == nil {
+		return max
+	}
+	return max + " " + strconv.FormatUint(*period, 10)
+}
+
+// ParseIOMax parses the contents of io.max, a line per device such as
+// "8:16 rbps=2097152 wbps=max riops=max wiops=120", into an entry for every
+// limit. Limits of "max" have a Rate of math.MaxUint64.
+func ParseIOMax(s string) ([]Entry, error) {
+	var out []Entry
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var major, minor int64
+		dev := strings.SplitN(fields[0], ":", 2)
+		if len(dev) != 2 {
+			return nil, errors.Wrapf(ErrInvalidFormat, "io.max device %q", fields[0])
+		}
+		var err error
+		if major, err = strconv.ParseInt(dev[0], 10, 64); err != nil {
+			return nil, errors.Wrapf(ErrInvalidFormat, "io.max device %q", fields[0])
+		}
+		if minor, err = strconv.ParseInt(dev[1], 10, 64); err != nil {
+			return nil, errors.Wrapf(ErrInvalidFormat, "io.max device %q", fields[0])
+		}
+		for _, f := range fields[1:] {
+			kv := strings.SplitN(f, "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.Wrapf(ErrInvalidFormat, "io.max limit %q", f)
+			}
+			rate := uint64(math.MaxUint64)
+			if kv[1] != "max" {
+				if rate, err = strconv.ParseUint(kv[1], 10, 64); err != nil {
+					return nil, errors.Wrapf(ErrInvalidFormat, "io.max limit %q", f)
+				}
+			}
+			out = append(out, Entry{
+				Type:  IOType(kv[0]),
+				Major: major,
+				Minor: minor,
+				Rate:  rate,
+			})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseCPUMax(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		quota  int64
+		period uint64
+		out    string
+	}{
+		{"max 100000", -1, 100000, "max 100000"},
+		{"50000 100000\n", 50000, 100000, "50000 100000"},
+		{"200000", 200000, 0, "200000"},
+		{"max", -1, 0, "max"},
+	} {
+		quota, period, err := ParseCPUMax(tc.in)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.in, err)
+		}
+		if (quota == nil) != (tc.quota < 0) || (quota != nil && *quota != tc.quota) {
+			t.Fatalf("%q: unexpected quota %v", tc.in, quota)
+		}
+		if (period == nil) != (tc.period == 0) || (period != nil && *period != tc.period) {
+			t.Fatalf("%q: unexpected period %v", tc.in, period)
+		}
+		if out := FormatCPUMax(quota, period); out != tc.out {
+			t.Fatalf("%q: expected %q to be formatted, got %q", tc.in, tc.out, out)
+		}
+	}
+	for _, in := range []string{"", "max max", "-1 100000", "1000 0", "1 2 3"} {
+		if _, _, err := ParseCPUMax(in); KindOf(err) != KindInvalidInput {
+			t.Fatalf("%q: expected an invalid input error, got %v", in, err)
+		}
+	}
+}
+
+func TestParseMax(t *testing.T) {
+	if v, err := ParseMax("max\n"); err != nil || v != -1 {
+		t.Fatalf("expected max to be -1, got %d %v", v, err)
+	}
+	if v, err := ParseMax("4096"); err != nil || v != 4096 {
+		t.Fatalf("expected 4096, got %d %v", v, err)
+	}
+	if _, err := ParseMax("4k"); KindOf(err) != KindInvalidInput {
+		t.Fatalf("expected an invalid input error, got %v", err)
+	}
+	if FormatMax(-1) != "max" || FormatMax(4096) != "4096" {
+		t.Fatal("unexpected formatting of limits")
+	}
+}
+
+func TestParseIOMax(t *testing.T) {
+	entries, err := ParseIOMax("8:16 rbps=2097152 wbps=max\n259:0 riops=120\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Entry{
+		{Type: ReadBPS, Major: 8, Minor: 16, Rate: 2097152},
+		{Type: WriteBPS, Major: 8, Minor: 16, Rate: math.MaxUint64},
+		{Type: ReadIOPS, Major: 259, Minor: 0, Rate: 120},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, entries)
+	}
+	if s := entries[1].String(); s != "8:16 wbps=max" {
+		t.Fatalf("expected the unlimited entry to be formatted as max, got %q", s)
+	}
+	if _, err := ParseIOMax("8 rbps=1"); KindOf(err) != KindInvalidInput {
+		t.Fatalf("expected an invalid input error, got %v", err)
+	}
+}
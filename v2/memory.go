@@ -17,35 +17,35 @@
 package v2
 
 type Memory struct {
-	Swap *int64
-	Max  *int64
-	Low  *int64
-	High *int64
+	Swap *int64 `json:"swap,omitempty"`
+	Max  *int64 `json:"max,omitempty"`
+	Low  *int64 `json:"low,omitempty"`
+	High *int64 `json:"high,omitempty"`
 }
 
 func (r *Memory) Values() (o []Value) {
 	if r.Swap != nil {
 		o = append(o, Value{
 			filename: "memory.swap.max",
-			value:    *r.Swap,
+			value:    FormatMax(*r.Swap),
 		})
 	}
 	if r.Max != nil {
 		o = append(o, Value{
 			filename: "memory.max",
-			value:    *r.Max,
+			value:    FormatMax(*r.Max),
 		})
 	}
 	if r.Low != nil {
 		o = append(o, Value{
 			filename: "memory.low",
-			value:    *r.Low,
+			value:    FormatMax(*r.Low),
 		})
 	}
 	if r.High != nil {
 		o = append(o, Value{
 			filename: "memory.high",
-			value:    *r.High,
+			value:    FormatMax(*r.High),
 		})
 	}
 	return o
@@ -0,0 +1,144 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"path/filepath"
+	"time"
+)
+
+// MemoryHighStallThreshold is the share of time tasks of a cgroup may be
+// stalled on memory while throttled by memory.high before MemoryHighAdvice
+// suggests raising the limit rather than reclaiming
+var MemoryHighStallThreshold = 0.1
+
+// MemoryHighSample is a point in time reading of the files needed to
+// observe memory.high throttling
+type MemoryHighSample struct {
+	Time time.Time
+	// High is the "high" counter of memory.events, the number of times
+	// the cgroup was throttled and put into direct reclaim by memory.high
+	High uint64
+	// Current and Limit are the values of memory.current and memory.high,
+	// Limit is math.MaxUint64 when memory.high is "max"
+	Current uint64
+	Limit   uint64
+	// Pressure is the memory pressure stall information of the cgroup
+	Pressure PSIStats
+}
+
+// SampleMemoryHigh reads the memory.high throttling state of the cgroup
+func (c *Manager) SampleMemoryHigh() (MemoryHighSample, error) {
+	sample := MemoryHighSample{
+		Time: c.config.pacer().Now(),
+	}
+	events := make(map[string]interface{})
+	if err := readKVStatsFile(c.path, "memory.events", events); err != nil {
+		return sample, err
+	}
+	sample.High, _ = events["high"].(uint64)
+	var err error
+	if sample.Current, err = c.readUint64("memory.current"); err != nil {
+		return sample, err
+	}
+	if sample.Limit, err = c.readUint64("memory.high"); err != nil {
+		return sample, err
+	}
+	if sample.Pressure, err = c.PSI("memory"); err != nil {
+		return sample, err
+	}
+	return sample, nil
+}
+
+// readUint64 reads a single value file of the cgroup, "max" is returned as
+// math.MaxUint64
+func (c *Manager) readUint64(name string) (uint64, error) {
+	data, err := c.readFile(name)
+	if err != nil {
+		return 0, err
+	}
+	return parseStatFileContentUint64(data, filepath.Join(c.path, name)), nil
+}
+
+// MemoryHighAdvice is the action suggested for a cgroup throttled by
+// memory.high
+type MemoryHighAdvice int
+
+const (
+	// AdviceNone is returned when the cgroup is not being throttled
+	AdviceNone MemoryHighAdvice = iota
+	// AdviceReclaim is returned when the cgroup is throttled but its tasks
+	// are rarely stalled, the kernel is reclaiming cold memory cheaply and
+	// proactive reclaim can keep the cgroup below memory.high
+	AdviceReclaim
+	// AdviceRaiseHigh is returned when the cgroup is throttled and its tasks
+	// spend a significant share of time stalled on memory, the working set
+	// does not fit below memory.high
+	AdviceRaiseHigh
+)
+
+func (a MemoryHighAdvice) String() string {
+	switch a {
+	case AdviceReclaim:
+		return "reclaim"
+	case AdviceRaiseHigh:
+		return "raise-high"
+	}
+	return "none"
+}
+
+// MemoryHighStatus describes the memory.high throttling of a cgroup between
+// two samples
+type MemoryHighStatus struct {
+	// Throttled is true when memory.high was hit during the interval
+	Throttled bool
+	// Events is the number of memory.high events during the interval
+	Events uint64
+	// Stall is the share of the interval at least one task of the cgroup
+	// was stalled on memory
+	Stall float64
+	// Advice is the suggested action for a memory QoS controller
+	Advice MemoryHighAdvice
+}
+
+// MemoryHighThrottling compares two samples and reports whether the cgroup
+// was throttled by memory.high in between, along with advice on whether
+// raising memory.high or reclaiming memory is the better response
+func MemoryHighThrottling(prev, cur MemoryHighSample) (MemoryHighStatus, error) {
+	elapsed := cur.Time.Sub(prev.Time)
+	if elapsed <= 0 {
+		return MemoryHighStatus{}, ErrInvalidInterval
+	}
+	if cur.High < prev.High || cur.Pressure.Some.Total < prev.Pressure.Some.Total {
+		return MemoryHighStatus{}, ErrCounterReset
+	}
+	status := MemoryHighStatus{
+		Events: cur.High - prev.High,
+		Stall:  float64(cur.Pressure.Some.Total-prev.Pressure.Some.Total) / (float64(elapsed.Nanoseconds()) / float64(time.Microsecond)),
+	}
+	status.Throttled = status.Events > 0
+	switch {
+	case !status.Throttled || cur.Limit == math.MaxUint64:
+		status.Advice = AdviceNone
+	case status.Stall >= MemoryHighStallThreshold:
+		status.Advice = AdviceRaiseHigh
+	default:
+		status.Advice = AdviceReclaim
+	}
+	return status, nil
+}
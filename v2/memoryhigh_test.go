@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePSI(t *testing.T) {
+	data := []byte("some avg10=1.50 avg60=0.25 avg300=0.00 total=12345\nfull avg10=0.50 avg60=0.00 avg300=0.00 total=678\ninvalid\n")
+	psi := parsePSI(data, "memory.pressure")
+	expected := PSIStats{
+		Some: PSIData{Avg10: 1.5, Avg60: 0.25, Total: 12345},
+		Full: PSIData{Avg10: 0.5, Total: 678},
+	}
+	if psi != expected {
+		t.Fatalf("expected %+v, got %+v", expected, psi)
+	}
+}
+
+func TestSampleMemoryHigh(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-memoryhigh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"memory.events":   "low 0\nhigh 7\nmax 0\noom 0\noom_kill 0\n",
+		"memory.current":  "4096\n",
+		"memory.high":     "max\n",
+		"memory.pressure": "some avg10=0.00 avg60=0.00 avg300=0.00 total=10\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=5\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := &Manager{path: dir}
+	sample, err := m.SampleMemoryHigh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.High != 7 || sample.Current != 4096 || sample.Limit != math.MaxUint64 || sample.Pressure.Some.Total != 10 {
+		t.Fatalf("unexpected sample %+v", sample)
+	}
+}
+
+func TestMemoryHighThrottling(t *testing.T) {
+	now := time.Now()
+	sample := func(at time.Duration, high, stall uint64) MemoryHighSample {
+		return MemoryHighSample{
+			Time:     now.Add(at),
+			High:     high,
+			Limit:    1 << 30,
+			Pressure: PSIStats{Some: PSIData{Total: stall}},
+		}
+	}
+	for _, test := range []struct {
+		name   string
+		prev   MemoryHighSample
+		cur    MemoryHighSample
+		advice MemoryHighAdvice
+	}{
+		{"idle", sample(0, 5, 0), sample(time.Second, 5, 0), AdviceNone},
+		{"cheap reclaim", sample(0, 5, 0), sample(time.Second, 9, 1000), AdviceReclaim},
+		{"stalled", sample(0, 5, 0), sample(time.Second, 9, 500000), AdviceRaiseHigh},
+	} {
+		status, err := MemoryHighThrottling(test.prev, test.cur)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if status.Advice != test.advice {
+			t.Errorf("%s: expected advice %s, got %s (%+v)", test.name, test.advice, status.Advice, status)
+		}
+	}
+	if _, err := MemoryHighThrottling(sample(time.Second, 0, 0), sample(0, 0, 0)); err != ErrInvalidInterval {
+		t.Fatalf("expected ErrInvalidInterval, got %v", err)
+	}
+}
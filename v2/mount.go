@@ -0,0 +1,319 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// MountOption is a cgroup2 mount option
+type MountOption string
+
+const (
+	// MountNsDelegate treats cgroup namespaces as delegation boundaries
+	MountNsDelegate MountOption = "nsdelegate"
+	// MountMemoryRecursiveProt applies memory.min and memory.low protection
+	// recursively to the subtree of a cgroup
+	MountMemoryRecursiveProt MountOption = "memory_recursiveprot"
+	// MountMemoryLocalEvents reports only the cgroup's own events in
+	// memory.events instead of those of its subtree
+	MountMemoryLocalEvents MountOption = "memory_localevents"
+	// MountFavorDynMods makes controller and process migrations cheaper at
+	// the cost of making forks and exits more expensive
+	MountFavorDynMods MountOption = "favordynmods"
+)
+
+// constants of the mount API, not yet in golang.org/x/sys
+const (
+	fsopenCloexec       = 0x1
+	fsconfigSetFlag     = 0x0
+	fsconfigCmdCreate   = 0x6
+	fsmountCloexec      = 0x1
+	mountAttrNosuid     = 0x2
+	mountAttrNodev      = 0x4
+	mountAttrNoexec     = 0x8
+	moveMountFEmptyPath = 0x4
+)
+
+// NewMount creates a detached cgroup2 mount with the options through the
+// mount API (fsopen, fsconfig and fsmount) and returns the root directory of
+// the mount. The mount is not attached anywhere, it can be used through the
+// returned file, for example with NewManagerFromFD, or attached with
+// AttachMount. It is useful for tooling that sets up hierarchies in fresh
+// namespaces without a visible mountpoint. Linux 5.2 or newer is required.
+//
+// The options belong to the filesystem, which all cgroup2 mounts share, and
+// a mount made from the initial cgroup namespace replaces them. The options
+// of the existing cgroup2 mounts are therefore kept and the provided ones
+// turned on in addition, use SetMountOption to turn an option off.
+func NewMount(options ...MountOption) (*os.File, error) {
+	current, err := currentMountOptions()
+	if err != nil {
+		return nil, err
+	}
+	options = mergeMountOptions(current, options)
+	fsname, err := unix.BytePtrFromString("cgroup2")
+	if err != nil {
+		return nil, err
+	}
+	fsfd, _, errno := unix.Syscall(unix.SYS_FSOPEN, uintptr(unsafe.Pointer(fsname)), fsopenCloexec, 0)
+	if errno != 0 {
+		return nil, os.NewSyscallError("fsopen", errno)
+	}
+	defer unix.Close(int(fsfd))
+	for _, o := range options {
+		key, err := unix.BytePtrFromString(string(o))
+		if err != nil {
+			return nil, err
+		}
+		if _, _, errno := unix.Syscall6(unix.SYS_FSCONFIG, fsfd, fsconfigSetFlag, uintptr(unsafe.Pointer(key)), 0, 0, 0); errno != 0 {
+			return nil, errors.Wrapf(os.NewSyscallError("fsconfig", errno), "cgroups: mount option %s", o)
+		}
+	}
+	if _, _, errno := unix.Syscall6(unix.SYS_FSCONFIG, fsfd, fsconfigCmdCreate, 0, 0, 0, 0); errno != 0 {
+		return nil, os.NewSyscallError("fsconfig", errno)
+	}
+	mfd, _, errno := unix.Syscall(unix.SYS_FSMOUNT, fsfd, fsmountCloexec, mountAttrNosuid|mountAttrNodev|mountAttrNoexec)
+	if errno != 0 {
+		return nil, os.NewSyscallError("fsmount", errno)
+	}
+	return os.NewFile(mfd, "cgroup2"), nil
+}
+
+// AttachMount attaches a mount returned by NewMount at target
+func AttachMount(mount *os.File, target string) error {
+	empty, err := unix.BytePtrFromString("")
+	if err != nil {
+		return err
+	}
+	to, err := unix.BytePtrFromString(target)
+	if err != nil {
+		return err
+	}
+	cwd := unix.AT_FDCWD
+	if _, _, errno := unix.Syscall6(unix.SYS_MOVE_MOUNT, mount.Fd(), uintptr(unsafe.Pointer(empty)),
+		uintptr(cwd), uintptr(unsafe.Pointer(to)), moveMountFEmptyPath, 0); errno != 0 {
+		return os.NewSyscallError("move_mount", errno)
+	}
+	return nil
+}
+
+// mountinfo is a var so that the tests can read the mounts from a fake file
+var mountinfo = "/proc/self/mountinfo"
+
+// MountOptions returns the options the cgroup2 filesystem mounted at
+// mountpoint was mounted with. Options such as nsdelegate and
+// memory_recursiveprot change how delegation and memory protection behave,
+// so callers may have to account for them. ErrMountPointNotExist is
+// returned if no cgroup2 filesystem is mounted at mountpoint.
+func MountOptions(mountpoint string) ([]MountOption, error) {
+	_, options, err := readMount(mountpoint)
+	return options, err
+}
+
+// currentMountOptions returns the options of the cgroup2 filesystem from any
+// of its mounts, or none if it is not mounted
+func currentMountOptions() ([]MountOption, error) {
+	_, options, err := readMount("")
+	if err == ErrMountPointNotExist {
+		return nil, nil
+	}
+	return options, err
+}
+
+// mergeMountOptions returns the current options with the added ones turned
+// on, leaving out those that are not cgroup2 options
+func mergeMountOptions(current, added []MountOption) []MountOption {
+	var out []MountOption
+	for _, o := range append(current, added...) {
+		// seclabel is reported by SELinux, it is not a cgroup2 option
+		if o != "seclabel" && !HasMountOption(out, o) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// mountFlags are the per mount options of mountinfo that are preserved
+// when remounting
+var mountFlags = map[string]uintptr{
+	"ro":         unix.MS_RDONLY,
+	"nosuid":     unix.MS_NOSUID,
+	"nodev":      unix.MS_NODEV,
+	"noexec":     unix.MS_NOEXEC,
+	"noatime":    unix.MS_NOATIME,
+	"nodiratime": unix.MS_NODIRATIME,
+	"relatime":   unix.MS_RELATIME,
+}
+
+// readMount returns the per mount flags and the superblock options of the
+// cgroup2 filesystem mounted at mountpoint, or of its first mount when
+// mountpoint is empty
+func readMount(mountpoint string) (uintptr, []MountOption, error) {
+	f, err := os.Open(mountinfo)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+	var (
+		flags   uintptr
+		options []MountOption
+		found   bool
+		s       = bufio.NewScanner(f)
+	)
+	for s.Scan() {
+		fields := strings.Split(s.Text(), " ")
+		if len(fields) < 10 {
+			// broken mountinfo?
+			continue
+		}
+		if fields[len(fields)-3] != "cgroup2" {
+			continue
+		}
+		if mountpoint != "" && unescapeMountinfo(fields[4]) != filepath.Clean(mountpoint) {
+			continue
+		}
+		// the last mount at the mountpoint is the visible one
+		found, flags, options = true, 0, nil
+		for _, opt := range strings.Split(fields[5], ",") {
+			flags |= mountFlags[opt]
+		}
+		for _, opt := range strings.Split(fields[len(fields)-1], ",") {
+			if opt == "rw" || opt == "ro" {
+				continue
+			}
+			options = append(options, MountOption(opt))
+		}
+		if mountpoint == "" {
+			break
+		}
+	}
+	if err := s.Err(); err != nil {
+		return 0, nil, err
+	}
+	if !found {
+		return 0, nil, ErrMountPointNotExist
+	}
+	return flags, options, nil
+}
+
+// Remount turns on the options of the cgroup2 filesystem mounted at
+// mountpoint, keeping the options it already has. The options apply to the
+// filesystem rather than the mount, so they change for every mount of the
+// hierarchy; use SetMountOption to turn an option off. The per mount flags
+// of mountpoint, such as nosuid, are preserved. It requires CAP_SYS_ADMIN
+// in the initial user namespace; the kernel also refuses to change
+// nsdelegate from inside a cgroup namespace.
+func Remount(mountpoint string, options ...MountOption) error {
+	flags, current, err := readMount(mountpoint)
+	if err != nil {
+		return err
+	}
+	return remount(mountpoint, flags, mergeMountOptions(current, options))
+}
+
+// remount replaces the options of the cgroup2 filesystem mounted at
+// mountpoint, the options that are not listed are turned off
+func remount(mountpoint string, flags uintptr, options []MountOption) error {
+	data := make([]string, len(options))
+	for i, o := range options {
+		data[i] = string(o)
+	}
+	if err := unix.Mount("", mountpoint, "", flags|unix.MS_REMOUNT, strings.Join(data, ",")); err != nil {
+		switch err {
+		case unix.EBUSY:
+			return errors.Wrapf(err, "cgroups: cgroup2 at %s is busy, retry the remount", mountpoint)
+		case unix.EPERM:
+			return errors.Wrapf(err, "cgroups: remounting cgroup2 at %s requires CAP_SYS_ADMIN in the initial namespaces", mountpoint)
+		case unix.EINVAL:
+			return errors.Wrapf(err, "cgroups: the kernel does not support the cgroup2 options %s", strings.Join(data, ","))
+		}
+		return errors.Wrapf(err, "cgroups: remount cgroup2 at %s", mountpoint)
+	}
+	return nil
+}
+
+// SetMountOption turns a single option of the cgroup2 filesystem mounted at
+// mountpoint on or off, keeping its other options, see Remount
+func SetMountOption(mountpoint string, option MountOption, enabled bool) error {
+	flags, current, err := readMount(mountpoint)
+	if err != nil {
+		return err
+	}
+	if HasMountOption(current, option) == enabled {
+		return nil
+	}
+	return remount(mountpoint, flags, toggleMountOption(current, option, enabled))
+}
+
+func toggleMountOption(options []MountOption, option MountOption, enabled bool) []MountOption {
+	var out []MountOption
+	for _, o := range options {
+		// seclabel is reported by SELinux, it is not a cgroup2 option
+		if o != option && o != "seclabel" {
+			out = append(out, o)
+		}
+	}
+	if enabled {
+		out = append(out, option)
+	}
+	return out
+}
+
+// MountOptions returns the options of the cgroup2 filesystem the manager's
+// cgroup is on, see MountOptions
+func (c *Manager) MountOptions() ([]MountOption, error) {
+	return MountOptions(c.unifiedMountpoint)
+}
+
+// HasMountOption returns true if option is in options
+func HasMountOption(options []MountOption, option MountOption) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeMountinfo decodes the octal escapes of spaces, tabs, newlines and
+// backslashes in the paths of mountinfo
+func unescapeMountinfo(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,160 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+func newTestMount(t *testing.T, options ...MountOption) *os.File {
+	if os.Geteuid() != 0 {
+		t.Skip("mounting cgroup2 requires root")
+	}
+	mount, err := NewMount(options...)
+	if err != nil {
+		if errors.Is(err, unix.ENOSYS) {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	return mount
+}
+
+func TestNewMount(t *testing.T) {
+	// no options are added so that the host's cgroup2 options are unchanged
+	mount := newTestMount(t)
+	defer mount.Close()
+
+	m, err := NewManagerFromFD(int(mount.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if _, err := m.Controllers(); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := ioutil.TempDir("", "cgroups-mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(target)
+	if err := AttachMount(mount, target); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Unmount(target, unix.MNT_DETACH)
+	if _, err := os.Stat(filepath.Join(target, controllersFile)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewMountInvalidOption(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("mounting cgroup2 requires root")
+	}
+	if _, err := NewMount("invalid"); err == nil {
+		t.Fatal("expected an invalid mount option to be rejected")
+	}
+}
+
+func TestMountOptions(t *testing.T) {
+	f, err := ioutil.TempFile("", "cgroups-mountinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	const data = `25 30 0:22 / /sys/fs/cgroup rw,nosuid,nodev,noexec,relatime shared:4 - cgroup2 cgroup2 rw,nsdelegate,memory_recursiveprot
+40 30 0:38 / /run/my\040cgroup rw,relatime - cgroup2 cgroup2 rw,favordynmods
+41 30 0:39 / /sys/fs/cgroup/cpu rw,relatime - cgroup cgroup rw,cpu
+`
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer func(path string) { mountinfo = path }(mountinfo)
+	mountinfo = f.Name()
+
+	options, err := MountOptions("/sys/fs/cgroup/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !HasMountOption(options, MountNsDelegate) || !HasMountOption(options, MountMemoryRecursiveProt) || HasMountOption(options, MountFavorDynMods) {
+		t.Fatalf("unexpected options %v", options)
+	}
+	options, err = MountOptions("/run/my cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options) != 1 || options[0] != MountFavorDynMods {
+		t.Fatalf("unexpected options %v", options)
+	}
+	if _, err := MountOptions("/sys/fs/cgroup/cpu"); err != ErrMountPointNotExist {
+		t.Fatalf("expected ErrMountPointNotExist for a v1 mount, got %v", err)
+	}
+
+	// new mounts keep the options of the filesystem
+	current, err := currentMountOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := mergeMountOptions(current, []MountOption{MountFavorDynMods, MountNsDelegate})
+	if len(merged) != 3 || merged[0] != MountNsDelegate || merged[1] != MountMemoryRecursiveProt || merged[2] != MountFavorDynMods {
+		t.Fatalf("unexpected merged options %v", merged)
+	}
+}
+
+func TestSetMountOption(t *testing.T) {
+	f, err := ioutil.TempFile("", "cgroups-mountinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("25 30 0:22 / /sys/fs/cgroup rw,nosuid,nodev,noexec,relatime shared:4 - cgroup2 cgroup2 rw,seclabel,nsdelegate\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer func(path string) { mountinfo = path }(mountinfo)
+	mountinfo = f.Name()
+
+	flags, options, err := readMount("/sys/fs/cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := uintptr(unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC | unix.MS_RELATIME); flags != expected {
+		t.Fatalf("expected flags %#x, got %#x", expected, flags)
+	}
+	// nothing to change, the filesystem is not remounted
+	if err := SetMountOption("/sys/fs/cgroup", MountNsDelegate, true); err != nil {
+		t.Fatal(err)
+	}
+	toggled := toggleMountOption(options, MountFavorDynMods, true)
+	if len(toggled) != 2 || toggled[0] != MountNsDelegate || toggled[1] != MountFavorDynMods {
+		t.Fatalf("unexpected options %v", toggled)
+	}
+	if toggled := toggleMountOption(options, MountNsDelegate, false); len(toggled) != 0 {
+		t.Fatalf("expected no options, got %v", toggled)
+	}
+	if err := Remount("/run/missing"); err != ErrMountPointNotExist {
+		t.Fatalf("expected ErrMountPointNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GroupError is the failure of an operation on one group of an operation
+// spanning many groups or processes
+type GroupError struct {
+	// Path is the absolute path of the group
+	Path string
+	Err  error
+}
+
+func (e *GroupError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *GroupError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the underlying error for github.com/pkg/errors
+func (e *GroupError) Cause() error {
+	return e.Err
+}
+
+// MultiError is returned by operations that continue past failures, such as
+// DeleteRecursive, StatTree and AddProcs, with every failure that occurred.
+// errors.Is and errors.As match any of the errors.
+type MultiError struct {
+	Errors []*GroupError
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("cgroups: %d errors: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Is returns true if any of the errors is target
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target
+func (e *MultiError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add records the error of the group at path
+func (e *MultiError) add(path string, err error) {
+	e.Errors = append(e.Errors, &GroupError{
+		Path: path,
+		Err:  err,
+	})
+}
+
+// errorOrNil returns nil when no errors were recorded so that a nil
+// *MultiError is never returned as a non-nil error
+func (e *MultiError) errorOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
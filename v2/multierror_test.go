@@ -0,0 +1,153 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/containerd/cgroups/cgroupstest"
+	"github.com/pkg/errors"
+)
+
+func TestMultiError(t *testing.T) {
+	var errs MultiError
+	if errs.errorOrNil() != nil {
+		t.Fatal("expected no error without failures")
+	}
+	errs.add("/a", errors.Wrap(ErrCgroupDeleted, "stat"))
+	errs.add("/b", &os.PathError{Op: "rmdir", Path: "/b", Err: syscall.ENOENT})
+	err := errs.errorOrNil()
+	if !errors.Is(err, ErrCgroupDeleted) {
+		t.Fatalf("expected %v to match ErrCgroupDeleted", err)
+	}
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "/b" {
+		t.Fatalf("expected %v to contain the path error", err)
+	}
+	var groupErr *GroupError
+	if !errors.As(err, &groupErr) || groupErr.Path != "/a" {
+		t.Fatalf("expected the first group error, got %v", groupErr)
+	}
+	if kind := KindOf(err); kind != KindNotFound {
+		t.Fatalf("expected errors of one kind to share it, got %s", kind)
+	}
+	errs.add("/c", ErrInvalidPid)
+	if kind := KindOf(err); kind != KindUnknown {
+		t.Fatalf("expected errors of different kinds to be unknown, got %s", kind)
+	}
+}
+
+func TestAddProcs(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	m, err := NewManager(sandbox.Mountpoint, filepath.Join(sandbox.Group, "add"), &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Delete()
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+
+	err = m.AddProcs(1<<22+1, uint64(cmd.Process.Pid))
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 1 {
+		t.Fatalf("expected a single failure for the missing pid, got %v", err)
+	}
+	procs, err := m.Procs(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(procs) != 1 || procs[0] != uint64(cmd.Process.Pid) {
+		t.Fatalf("expected the existing process to be moved, got %v", procs)
+	}
+}
+
+func TestDeleteRecursive(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	group := filepath.Join(sandbox.Group, "parent")
+	m, err := NewManager(sandbox.Mountpoint, group, &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range []string{"a", "b", "b/c"} {
+		if _, err := NewManager(sandbox.Mountpoint, filepath.Join(group, child), &Resources{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	busy, err := LoadManager(sandbox.Mountpoint, filepath.Join(group, "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := busy.AddProc(uint64(cmd.Process.Pid)); err != nil {
+		cmd.Process.Kill()
+		t.Fatal(err)
+	}
+
+	err = m.DeleteRecursive()
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 1 || multi.Errors[0].Path != busy.path {
+		t.Fatalf("expected only %s to fail, got %v", busy.path, err)
+	}
+	if _, err := os.Stat(filepath.Join(m.path, "a")); !os.IsNotExist(err) {
+		t.Fatalf("expected the idle child to be removed, got %v", err)
+	}
+
+	cmd.Process.Kill()
+	cmd.Wait()
+	if err := m.DeleteRecursive(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(m.path); !os.IsNotExist(err) {
+		t.Fatalf("expected the group to be removed, got %v", err)
+	}
+}
+
+func TestStatTree(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	group := filepath.Join(sandbox.Group, "tree")
+	for _, g := range []string{group, filepath.Join(group, "child")} {
+		m, err := NewManager(sandbox.Mountpoint, g, &Resources{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer m.Delete()
+	}
+	metrics, err := StatTree(sandbox.Mountpoint, group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 2 || metrics[filepath.Join(group, "child")] == nil {
+		t.Fatalf("expected the metrics of both groups, got %v", metrics)
+	}
+}
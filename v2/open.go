@@ -0,0 +1,49 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+
+	"github.com/containerd/cgroups/internal/cgfs"
+)
+
+func openFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	return cgfs.OpenFile(path, flag, perm)
+}
+
+func openDir(path string) (*os.File, error) {
+	return cgfs.OpenDir(path)
+}
+
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	return cgfs.WriteFile(path, data, perm)
+}
+
+// WriteFile writes value to the file name in the cgroup directory open as
+// dirFd, with the same safety and error handling the package applies to the
+// files it manages: the file is opened relative to the directory without
+// following symlinks, anything but a regular file is rejected with
+// ErrUnexpectedFileType, writes interrupted by EINTR are retried and the
+// write is reported to the audit sink. Errors from the kernel are returned
+// as *os.PathError so they can be classified with KindOf.
+//
+// It is meant for tooling that must write files the package does not model.
+// name must be a single path element.
+func WriteFile(dirFd int, name string, value []byte) error {
+	return cgfs.WriteFileAt(dirFd, name, value)
+}
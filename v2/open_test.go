@@ -0,0 +1,59 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestOpenRefusesSymlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-open")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "target")
+	if err := ioutil.WriteFile(target, []byte("untouched"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "pids.max")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeValues(dir, []Value{{filename: "pids.max", value: int64(10)}}); err == nil {
+		t.Fatal("expected the write through a symlink to fail")
+	}
+	if data, _ := ioutil.ReadFile(target); string(data) != "untouched" {
+		t.Fatalf("expected the symlink target not to be written but received %q", data)
+	}
+	if _, err := readBounded(filepath.Join(dir, "pids.max")); err == nil {
+		t.Fatal("expected the read through a symlink to fail")
+	}
+	if err := os.Mkdir(filepath.Join(dir, "memory.max"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBounded(filepath.Join(dir, "memory.max")); errors.Cause(err) != ErrUnexpectedFileType {
+		t.Fatalf("expected ErrUnexpectedFileType reading a directory but received %v", err)
+	}
+	if _, err := openDir(target); err == nil {
+		t.Fatal("expected opening a file as a directory to fail")
+	}
+}
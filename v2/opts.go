@@ -0,0 +1,287 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/pkg/errors"
+)
+
+// InitOpts allows configuration for the creation or loading of a manager
+type InitOpts func(*InitConfig) error
+
+// InitConfig provides configuration options for the creation
+// or loading of a manager
+type InitConfig struct {
+	// PersistentFiles keeps the frequently read stat files open between
+	// Stat calls and reads them with pread instead of opening them each time
+	PersistentFiles bool
+	// RuncCompat makes the systemd driver set the same unit properties as
+	// runc and write the remaining resources to the cgroup after the unit starts
+	RuncCompat bool
+	// UpdateExisting makes GetOrCreate apply the resources to a group that
+	// already exists
+	UpdateExisting bool
+	// Owner of created groups and their delegatable files, nil leaves them
+	// owned by the caller
+	Owner *Owner
+	// FileModes are applied to the interface files of created groups
+	FileModes map[string]os.FileMode
+	// Interceptors are called around the manager's operations
+	Interceptors []Interceptor
+	// Tracer starts spans around the manager's operations
+	Tracer Tracer
+	// ClockSource timestamps the metrics returned by Stat
+	ClockSource ClockSource
+	// Pacer paces retries, polling and rate limits, nil uses the system
+	// clock
+	Pacer clock.Clock
+	// PropertyReport is called by NewSystemd with the resource properties
+	// the running systemd does not support
+	PropertyReport func(PropertyReport)
+	// Timeouts bound the manager's reads, writes and dbus calls, there
+	// are no bounds when nil
+	Timeouts *Timeouts
+	// AccountingOnly creates groups with their controllers enabled but
+	// without writing any of the resource limits
+	AccountingOnly bool
+	// RateLimit bounds how often each manager changes its group, nil is
+	// unlimited
+	RateLimit *RateLimit
+	// Warnings collects what creating the group skipped or approximated
+	Warnings *Warnings
+	// Unwritable is the policy for resource files that exist but cannot
+	// be written, UnwritableFail by default
+	Unwritable UnwritablePolicy
+	// UnwritableApplier applies the writes queued by UnwritableQueue
+	UnwritableApplier UnwritableApplier
+}
+
+// Owner is the user and group that created groups are delegated to
+type Owner struct {
+	UID int
+	GID int
+}
+
+func newInitConfig(opts []InitOpts) (*InitConfig, error) {
+	config := &InitConfig{}
+	for _, o := range opts {
+		if err := o(config); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// forManager returns the config kept by a manager. The caller's warnings
+// only collect what the constructor call skipped, they are not kept so that
+// later operations, possibly concurrent, do not append to them.
+func (c *InitConfig) forManager() *InitConfig {
+	if c == nil || c.Warnings == nil {
+		return c
+	}
+	config := *c
+	config.Warnings = nil
+	return &config
+}
+
+// WithPersistentFiles keeps memory.current, cpu.stat and pids.current open
+// for the lifetime of the manager, removing most open and close syscalls from
+// tight sampling loops. Close must be called to release the files.
+func WithPersistentFiles() InitOpts {
+	return func(c *InitConfig) error {
+		c.PersistentFiles = true
+		return nil
+	}
+}
+
+// WithRuncCompat makes NewSystemd match runc's systemd driver: the unit
+// properties are mapped as runc maps them and, once the unit has started,
+// all resources are also written directly to the cgroup. This covers
+// settings systemd has no property for on older versions, such as cpuset,
+// so that groups created by runc and this package are configured alike.
+func WithRuncCompat() InitOpts {
+	return func(c *InitConfig) error {
+		c.RuncCompat = true
+		return nil
+	}
+}
+
+// WithUpdateExisting makes GetOrCreate update an existing group with the
+// provided resources instead of leaving its current settings in place
+func WithUpdateExisting() InitOpts {
+	return func(c *InitConfig) error {
+		c.UpdateExisting = true
+		return nil
+	}
+}
+
+// WithOwner chowns created groups and the files needed to manage them, such
+// as cgroup.procs and cgroup.subtree_control, to uid and gid. This delegates
+// the group to an unprivileged user as described in the kernel's cgroup v2
+// documentation.
+func WithOwner(uid, gid int) InitOpts {
+	return func(c *InitConfig) error {
+		c.Owner = &Owner{
+			UID: uid,
+			GID: gid,
+		}
+		return nil
+	}
+}
+
+// WithFileModes chmods the named interface files of created groups, for
+// example to make cgroup.procs group writable so that a non-root supervisor
+// in that group can place processes. Files missing on the running kernel are
+// skipped.
+func WithFileModes(modes map[string]os.FileMode) InitOpts {
+	return func(c *InitConfig) error {
+		for name := range modes {
+			// the group itself and its parent are not interface files
+			if name != filepath.Base(name) || name == "." || name == ".." {
+				return errors.Wrapf(ErrInvalidGroupPath, "file mode for %q", name)
+			}
+		}
+		c.FileModes = modes
+		return nil
+	}
+}
+
+// WithInterceptors adds interceptors called around the manager's New, Update,
+// AddProc and Delete operations, in the order they are provided
+func WithInterceptors(interceptors ...Interceptor) InitOpts {
+	return func(c *InitConfig) error {
+		c.Interceptors = append(c.Interceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithTracer traces the manager's operations with the provided tracer
+func WithTracer(tracer Tracer) InitOpts {
+	return func(c *InitConfig) error {
+		c.Tracer = tracer
+		return nil
+	}
+}
+
+// WithClockSource sets the clock used to timestamp the metrics returned by
+// Stat, ClockMonotonic by default
+func WithClockSource(clock ClockSource) InitOpts {
+	return func(c *InitConfig) error {
+		c.ClockSource = clock
+		return nil
+	}
+}
+
+// WithPacer sets the clock that paces the manager's retry backoff, polling
+// and rate limiting, so that tests can drive them with a fake clock. The
+// timeouts of reads, writes and dbus calls, and the wait for the kernel to
+// apply a freezer state, always use the system clock as they guard against
+// the kernel or systemd not responding.
+func WithPacer(c clock.Clock) InitOpts {
+	return func(config *InitConfig) error {
+		config.Pacer = c
+		return nil
+	}
+}
+
+// WithPropertyReport calls fn with the report of the resource properties
+// NewSystemd skipped or wrote directly to the cgroup because the running
+// systemd is too old to support them
+func WithPropertyReport(fn func(PropertyReport)) InitOpts {
+	return func(c *InitConfig) error {
+		c.PropertyReport = fn
+		return nil
+	}
+}
+
+// WithTimeouts sets the timeouts of the manager's reads, writes and dbus
+// calls in place of DefaultTimeouts. WithTimeouts(Timeouts{}) disables them.
+func WithTimeouts(timeouts Timeouts) InitOpts {
+	return func(c *InitConfig) error {
+		c.Timeouts = &timeouts
+		return nil
+	}
+}
+
+// WithAccountingOnly creates the group purely for accounting: the controllers
+// of the resources, or all available controllers when the resources have
+// none, are enabled so that usage is reported but no limits are written.
+// Limits can be applied later with Update once the usage is understood.
+func WithAccountingOnly() InitOpts {
+	return func(c *InitConfig) error {
+		c.AccountingOnly = true
+		return nil
+	}
+}
+
+// WithRateLimit limits how often each manager changes the resources of its
+// group, protecting the kernel from a misbehaving caller flapping limits. Every manager created
+// with the option has its own limit.
+func WithRateLimit(limit RateLimit) InitOpts {
+	return func(c *InitConfig) error {
+		if limit.Interval <= 0 {
+			return ErrInvalidInterval
+		}
+		c.RateLimit = &limit
+		return nil
+	}
+}
+
+// WithUnwritable sets the policy for the resource files of the group that
+// exist but cannot be written because of EROFS, EPERM or EACCES, such as
+// inside a container with a read-only cgroup mount. By default the first such
+// write fails the operation. UnwritableSkip continues with a warning for
+// each skipped file, see WithWarnings and WithUpdateWarnings, and
+// UnwritableQueue requires WithUnwritableApplier. Files missing on the
+// running kernel are not affected by the policy.
+func WithUnwritable(policy UnwritablePolicy) InitOpts {
+	return func(c *InitConfig) error {
+		if policy < UnwritableFail || policy > UnwritableQueue {
+			return errors.Errorf("invalid unwritable policy %d", policy)
+		}
+		c.Unwritable = policy
+		return nil
+	}
+}
+
+// WithUnwritableApplier queues the writes of resource files that cannot be
+// written and hands them to fn once the other files are written, for
+// example to forward them to a privileged process outside of the container.
+// It sets the unwritable policy to UnwritableQueue.
+func WithUnwritableApplier(fn UnwritableApplier) InitOpts {
+	return func(c *InitConfig) error {
+		c.Unwritable = UnwritableQueue
+		c.UnwritableApplier = fn
+		return nil
+	}
+}
+
+// WithWarnings appends to w what creating the group skipped or approximated
+// without failing, such as unsupported systemd properties, values adjusted
+// by a policy or file modes for files the kernel does not have, so that
+// callers can log them. Only the constructor call appends to w, the
+// operations of the manager take WithUpdateWarnings.
+func WithWarnings(w *Warnings) InitOpts {
+	return func(c *InitConfig) error {
+		c.Warnings = w
+		return nil
+	}
+}
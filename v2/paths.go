@@ -17,26 +17,47 @@
 package v2
 
 import (
-	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 // NestedGroupPath will nest the cgroups based on the calling processes cgroup
 // placing its child processes inside its own path
 func NestedGroupPath(suffix string) (string, error) {
-	path, err := parseCgroupFile("/proc/self/cgroup")
+	path, err := ParseCgroupFile("/proc/self/cgroup")
 	if err != nil {
 		return "", err
 	}
 	return filepath.Join(path, suffix), nil
 }
 
+// ProcOpts configures how the cgroup of a process is read from procfs
+type ProcOpts func(*procConfig)
+
+type procConfig struct {
+	root string
+}
+
+// WithProcRoot reads processes from procfs mounted at root instead of /proc,
+// e.g. /host/proc for an agent running in a container with the procfs of
+// the host mounted
+func WithProcRoot(root string) ProcOpts {
+	return func(c *procConfig) {
+		c.root = root
+	}
+}
+
 // PidGroupPath will return the correct cgroup paths for an existing process running inside a cgroup
 // This is commonly used for the Load function to restore an existing container
-func PidGroupPath(pid int) (string, error) {
-	p := fmt.Sprintf("/proc/%d/cgroup", pid)
-	return parseCgroupFile(p)
+func PidGroupPath(pid int, opts ...ProcOpts) (string, error) {
+	config := procConfig{
+		root: procRoot,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	return ParseCgroupFile(filepath.Join(config.root, strconv.Itoa(pid), "cgroup"))
 }
 
 // VerifyGroupPath verifies the format of group path string g.
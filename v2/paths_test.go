@@ -17,6 +17,7 @@
 package v2
 
 import (
+	"os"
 	"testing"
 )
 
@@ -44,3 +45,15 @@ func TestVerifyGroupPath(t *testing.T) {
 		}
 	}
 }
+
+func TestPidGroupPathProcRoot(t *testing.T) {
+	dir := fakeProcfs(t, map[int]string{1: "/user.slice"})
+	defer os.RemoveAll(dir)
+	group, err := PidGroupPath(1, WithProcRoot(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group != "/user.slice" {
+		t.Fatalf("expected /user.slice but received %q", group)
+	}
+}
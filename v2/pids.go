@@ -16,21 +16,15 @@
 
 package v2
 
-import "strconv"
-
 type Pids struct {
-	Max int64
+	Max int64 `json:"max,omitempty"`
 }
 
 func (r *Pids) Values() (o []Value) {
 	if r.Max != 0 {
-		limit := "max"
-		if r.Max > 0 {
-			limit = strconv.FormatInt(r.Max, 10)
-		}
 		o = append(o, Value{
 			filename: "pids.max",
-			value:    limit,
+			value:    FormatMax(r.Max),
 		})
 	}
 	return o
@@ -0,0 +1,159 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Policy checks the resources of every NewManager and Update before they are
+// written. Returning an error rejects the change, the returned resources,
+// which may be clamped, are written otherwise. The resources passed in are a
+// copy that the policy is free to modify.
+type Policy func(op Op, resources *Resources) (*Resources, error)
+
+// PolicyMode is what a bounds policy does with out-of-bounds values
+type PolicyMode int
+
+const (
+	// PolicyReject rejects changes with out-of-bounds values
+	PolicyReject PolicyMode = iota
+	// PolicyClamp moves out-of-bounds values to the nearest bound
+	PolicyClamp
+)
+
+// Range is an inclusive range of values, a zero Min or Max is unbounded
+type Range struct {
+	Min int64
+	Max int64
+}
+
+// Bounds are the ranges the resources are kept within by BoundsPolicy, a nil
+// range leaves the setting unbounded. Memory limits of "max", written as a
+// negative value, are above any Max.
+type Bounds struct {
+	MemoryMax  *Range
+	MemoryHigh *Range
+	MemoryLow  *Range
+	MemorySwap *Range
+	CPUWeight  *Range
+	PidsMax    *Range
+}
+
+// WithPolicy checks the resources of NewManager and Update against policy
+// before they are written. It is installed as an interceptor, so it runs
+// after the interceptors added before it.
+func WithPolicy(policy Policy) InitOpts {
+	return func(c *InitConfig) error {
+		c.Interceptors = append(c.Interceptors, func(op *Operation, next func() error) error {
+			if (op.Op != OpNew && op.Op != OpUpdate) || op.Resources == nil {
+				return next()
+			}
+			resources, err := policy(op.Op, op.Resources.Clone())
+			if err != nil {
+				return err
+			}
+			warnPolicyChanges(op.Warnings, op.Resources, resources)
+			op.Resources = resources
+			return next()
+		})
+		return nil
+	}
+}
+
+// BoundsPolicy returns a policy keeping the resources within bounds, either
+// rejecting out-of-bounds values with ErrPolicyViolation or clamping them
+func BoundsPolicy(bounds Bounds, mode PolicyMode) Policy {
+	return func(_ Op, r *Resources) (*Resources, error) {
+		if r.Memory != nil {
+			for _, b := range []struct {
+				file  string
+				value *int64
+				r     *Range
+			}{
+				{"memory.max", r.Memory.Max, bounds.MemoryMax},
+				{"memory.high", r.Memory.High, bounds.MemoryHigh},
+				{"memory.low", r.Memory.Low, bounds.MemoryLow},
+				{"memory.swap.max", r.Memory.Swap, bounds.MemorySwap},
+			} {
+				if b.value == nil {
+					continue
+				}
+				if err := b.r.apply(b.file, b.value, mode); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if r.CPU != nil && r.CPU.Weight != nil {
+			weight := int64(*r.CPU.Weight)
+			if err := bounds.CPUWeight.apply("cpu.weight", &weight, mode); err != nil {
+				return nil, err
+			}
+			*r.CPU.Weight = uint64(weight)
+		}
+		if r.Pids != nil && r.Pids.Max != 0 {
+			if err := bounds.PidsMax.apply("pids.max", &r.Pids.Max, mode); err != nil {
+				return nil, err
+			}
+		}
+		return r, nil
+	}
+}
+
+// apply checks or clamps v, a negative value is the unlimited "max"
+func (r *Range) apply(file string, v *int64, mode PolicyMode) error {
+	if r == nil {
+		return nil
+	}
+	unlimited := *v < 0
+	switch {
+	case r.Max > 0 && (unlimited || *v > r.Max):
+		if mode == PolicyReject {
+			if unlimited {
+				return errors.Wrapf(ErrPolicyViolation, "%s max above maximum %d", file, r.Max)
+			}
+			return errors.Wrapf(ErrPolicyViolation, "%s %d above maximum %d", file, *v, r.Max)
+		}
+		*v = r.Max
+	case r.Min > 0 && !unlimited && *v < r.Min:
+		if mode == PolicyReject {
+			return errors.Wrapf(ErrPolicyViolation, "%s %d below minimum %d", file, *v, r.Min)
+		}
+		*v = r.Min
+	}
+	return nil
+}
+
+// warnPolicyChanges adds a warning for every value the policy changed
+func warnPolicyChanges(warnings *Warnings, requested, applied *Resources) {
+	if warnings == nil || applied == nil {
+		return
+	}
+	values := make(map[string]string)
+	for _, v := range applied.Values() {
+		data, _ := v.bytes()
+		values[v.filename] = string(data)
+	}
+	for _, v := range requested.Values() {
+		data, _ := v.bytes()
+		if value, ok := values[v.filename]; !ok {
+			warnings.add(v.filename, "%s removed by policy", data)
+		} else if value != string(data) {
+			warnings.add(v.filename, "%s changed to %s by policy", data, value)
+		}
+	}
+}
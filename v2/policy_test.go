@@ -0,0 +1,105 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBoundsPolicy(t *testing.T) {
+	bounds := Bounds{
+		MemoryMax: &Range{Min: 64 << 20},
+		CPUWeight: &Range{Min: 10, Max: 1000},
+		PidsMax:   &Range{Max: 100},
+	}
+	low, unlimited, weight := int64(1<<20), int64(-1), uint64(5000)
+
+	r := &Resources{Memory: &Memory{Max: &low}}
+	if _, err := BoundsPolicy(bounds, PolicyReject)(OpUpdate, r); errors.Cause(err) != ErrPolicyViolation {
+		t.Fatalf("expected ErrPolicyViolation, got %v", err)
+	}
+	r = &Resources{Memory: &Memory{Max: &unlimited}, Pids: &Pids{Max: -1}}
+	if _, err := BoundsPolicy(bounds, PolicyReject)(OpUpdate, r); errors.Cause(err) != ErrPolicyViolation || !strings.Contains(err.Error(), "pids.max") {
+		t.Fatalf("expected an unlimited pids.max to violate the maximum, got %v", err)
+	}
+
+	r = &Resources{
+		Memory: &Memory{Max: &low},
+		CPU:    &CPU{Weight: &weight},
+		Pids:   &Pids{Max: -1},
+	}
+	clamped, err := BoundsPolicy(bounds, PolicyClamp)(OpUpdate, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *clamped.Memory.Max != 64<<20 || *clamped.CPU.Weight != 1000 || clamped.Pids.Max != 100 {
+		t.Fatalf("unexpected clamped resources memory.max %d cpu.weight %d pids.max %d",
+			*clamped.Memory.Max, *clamped.CPU.Weight, clamped.Pids.Max)
+	}
+}
+
+func TestWithPolicy(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy := BoundsPolicy(Bounds{PidsMax: &Range{Max: 100}}, PolicyClamp)
+	requested := &Resources{Pids: &Pids{Max: 1000}}
+	m, err := NewManager(mountpoint, "/test", requested, WithPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := readTestFile(t, m, "pids.max"); v != "100" {
+		t.Fatalf("expected pids.max to be clamped to 100, got %q", v)
+	}
+	if requested.Pids.Max != 1000 {
+		t.Fatalf("expected the caller's resources to be left unchanged, got %d", requested.Pids.Max)
+	}
+
+	m, err = LoadManager(mountpoint, "/test", WithPolicy(BoundsPolicy(Bounds{PidsMax: &Range{Max: 100}}, PolicyReject)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Update(&Resources{Pids: &Pids{Max: 200}}); KindOf(err) != KindInvalidInput {
+		t.Fatalf("expected the update to be rejected, got %v", err)
+	}
+	if v := readTestFile(t, m, "pids.max"); v != "100" {
+		t.Fatalf("expected pids.max to be left at 100, got %q", v)
+	}
+
+	// children are created through the policy of their parent
+	child, err := m.NewChild("child", &Resources{Pids: &Pids{Max: 200}})
+	if err == nil || KindOf(err) != KindInvalidInput {
+		t.Fatalf("expected the child to be rejected, got %v", err)
+	}
+	if child != nil {
+		t.Fatal("expected no manager for a rejected child")
+	}
+	if _, err := os.Stat(filepath.Join(mountpoint, "test", "child")); !os.IsNotExist(err) {
+		t.Fatalf("expected the rejected child not to be created: %v", err)
+	}
+}
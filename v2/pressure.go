@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+const cgroupPressure = "cgroup.pressure"
+
+// PressureEnabled reports whether PSI accounting is enabled for the cgroup.
+// ErrPressureNotSupported is returned on kernels without cgroup.pressure.
+func (c *Manager) PressureEnabled() (bool, error) {
+	data, err := readBounded(filepath.Join(c.path, cgroupPressure))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, ErrPressureNotSupported
+		}
+		return false, err
+	}
+	switch string(bytes.TrimSpace(data)) {
+	case "0":
+		return false, nil
+	case "1":
+		return true, nil
+	}
+	return false, ErrInvalidFormat
+}
+
+// SetPressure enables or disables PSI accounting for the cgroup. Disabling
+// it removes the *.pressure files of the cgroup and the overhead of
+// maintaining them, which can be worthwhile for large trees where pressure
+// is only of interest for some nodes.
+func (c *Manager) SetPressure(enabled bool) error {
+	if _, err := os.Stat(filepath.Join(c.path, cgroupPressure)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrPressureNotSupported
+		}
+		return err
+	}
+	v := Value{
+		filename: cgroupPressure,
+		value:    "0",
+	}
+	if enabled {
+		v.value = "1"
+	}
+	return writeValues(c.path, []Value{v})
+}
@@ -0,0 +1,68 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/cgroups/cgroupstest"
+)
+
+func TestPressureNotSupported(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-pressure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &Manager{path: dir}
+	if _, err := m.PressureEnabled(); err != ErrPressureNotSupported {
+		t.Fatalf("expected ErrPressureNotSupported, got %v", err)
+	}
+	if err := m.SetPressure(false); err != ErrPressureNotSupported {
+		t.Fatalf("expected ErrPressureNotSupported, got %v", err)
+	}
+}
+
+func TestSetPressure(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	m, err := NewManager(sandbox.Mountpoint, filepath.Join(sandbox.Group, "pressure"), &Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Delete()
+	if _, err := m.PressureEnabled(); err == ErrPressureNotSupported {
+		t.Skip(err)
+	}
+	for _, enabled := range []bool{false, true} {
+		if err := m.SetPressure(enabled); err != nil {
+			t.Fatal(err)
+		}
+		got, err := m.PressureEnabled()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != enabled {
+			t.Fatalf("expected pressure enabled to be %v, got %v", enabled, got)
+		}
+	}
+}
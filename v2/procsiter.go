@@ -0,0 +1,71 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"strings"
+
+	"github.com/containerd/cgroups/internal/cgfs"
+)
+
+// ProcsIterator streams the pids of a cgroup from cgroup.procs without
+// reading them all into memory first, which matters for cgroups with tens
+// of thousands of processes. Iteration can be stopped at any point by
+// calling Close.
+//
+//	it := m.IterProcs(true)
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(it.Pid())
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type ProcsIterator = cgfs.ProcsIterator
+
+// IterProcs returns an iterator over the pids of the cgroup and, when
+// recursive is true, of all its descendants
+func (c *Manager) IterProcs(recursive bool) *ProcsIterator {
+	return cgfs.NewProcsIterator(c.path, recursive)
+}
+
+// ProcessCount returns the number of tasks in the cgroup and its
+// descendants. It reads pids.current when the pids controller is enabled,
+// and otherwise falls back to counting the processes listed in cgroup.procs.
+// pids.current counts tasks, every thread of a process included, so with
+// the pids controller the count is not a number of processes; use IterProcs
+// to count those. Either way a count of zero reliably means that the cgroup
+// subtree is empty.
+func (c *Manager) ProcessCount() (uint64, error) {
+	data, err := c.readFile("pids.current")
+	if err == nil {
+		return parseUint(strings.TrimSpace(string(data)), 10, 64)
+	}
+	if !os.IsNotExist(err) {
+		return 0, err
+	}
+	var (
+		n  uint64
+		it = c.IterProcs(true)
+	)
+	defer it.Close()
+	for it.Next() {
+		n++
+	}
+	return n, it.Err()
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIterProcs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-procsiter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for group, procs := range map[string]string{
+		"":    "1\n2\n",
+		"a":   "3\n",
+		"a/b": "",
+		"c":   "4\n5\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, group), defaultDirPerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, group, cgroupProcs), []byte(procs), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := &Manager{path: dir}
+	for recursive, expected := range map[bool][]uint64{
+		false: {1, 2},
+		true:  {1, 2, 3, 4, 5},
+	} {
+		var pids []uint64
+		it := m.IterProcs(recursive)
+		for it.Next() {
+			pids = append(pids, it.Pid())
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		it.Close()
+		if !reflect.DeepEqual(pids, expected) {
+			t.Errorf("recursive=%v: expected %v, got %v", recursive, expected, pids)
+		}
+	}
+
+	// stop early
+	it := m.IterProcs(true)
+	if !it.Next() || it.Pid() != 1 {
+		t.Fatalf("expected first pid 1, got %d (%v)", it.Pid(), it.Err())
+	}
+	it.Close()
+	if it.Next() {
+		t.Fatal("expected no more pids after Close")
+	}
+}
+
+func TestProcessCount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "child"), defaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{cgroupProcs, filepath.Join("child", cgroupProcs)} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("1\n2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := &Manager{path: dir}
+	n, err := m.ProcessCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Fatalf("expected 4 processes counted from cgroup.procs, got %d", n)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "pids.current"), []byte("7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = m.ProcessCount(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 7 {
+		t.Fatalf("expected pids.current to be used, got %d", n)
+	}
+}
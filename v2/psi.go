@@ -0,0 +1,101 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PSIData is one line of a pressure stall information file
+type PSIData struct {
+	// Avg10, Avg60 and Avg300 are the percentage of time stalled over the
+	// last 10, 60 and 300 seconds
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	// Total is the cumulative stall time in microseconds
+	Total uint64
+}
+
+// PSIStats is the pressure stall information of a resource. Some is the
+// share of time at least one task was stalled, Full the share of time all
+// non-idle tasks were stalled at once.
+type PSIStats struct {
+	Some PSIData
+	Full PSIData
+}
+
+// PSI returns the pressure stall information for the resource, one of
+// "cpu", "memory" or "io"
+func (c *Manager) PSI(resource string) (PSIStats, error) {
+	data, err := readBounded(filepath.Join(c.path, resource+".pressure"))
+	if err != nil {
+		return PSIStats{}, err
+	}
+	return parsePSI(data, resource+".pressure"), nil
+}
+
+// parsePSI parses the contents of a *.pressure file, skipping malformed lines
+func parsePSI(data []byte, file string) PSIStats {
+	var (
+		out PSIStats
+		s   = bufio.NewScanner(bytes.NewReader(data))
+	)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var d *PSIData
+		switch fields[0] {
+		case "some":
+			d = &out.Some
+		case "full":
+			d = &out.Full
+		default:
+			logrus.Warnf("cgroups: skipping invalid line while parsing %s (line=%q)", file, s.Text())
+			continue
+		}
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			var err error
+			switch parts[0] {
+			case "avg10":
+				d.Avg10, err = strconv.ParseFloat(parts[1], 64)
+			case "avg60":
+				d.Avg60, err = strconv.ParseFloat(parts[1], 64)
+			case "avg300":
+				d.Avg300, err = strconv.ParseFloat(parts[1], 64)
+			case "total":
+				d.Total, err = strconv.ParseUint(parts[1], 10, 64)
+			}
+			if err != nil {
+				logrus.Warnf("cgroups: skipping invalid field while parsing %s (field=%q): %v", file, field, err)
+			}
+		}
+	}
+	return out
+}
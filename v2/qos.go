@@ -0,0 +1,128 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// QoSClass is the quality of service a cgroup's settings give its processes,
+// named after the Kubernetes classes
+type QoSClass string
+
+const (
+	// QoSGuaranteed cgroups have both a memory and a cpu limit and are not
+	// throttled below their memory limit
+	QoSGuaranteed QoSClass = "guaranteed"
+	// QoSBurstable cgroups have some limits, protections or an increased
+	// cpu weight, but are not guaranteed
+	QoSBurstable QoSClass = "burstable"
+	// QoSBestEffort cgroups have no limits or protections and at most the
+	// default cpu weight
+	QoSBestEffort QoSClass = "best-effort"
+)
+
+// QoSSettings are the settings a QoS class is inferred from
+type QoSSettings struct {
+	// MemoryMax and MemoryHigh are math.MaxUint64 when they are "max"
+	MemoryMax  uint64
+	MemoryHigh uint64
+	// MemoryMin and MemoryLow are 0 when memory is not protected
+	MemoryMin uint64
+	MemoryLow uint64
+	CPUMax    CPUMax
+	CPUWeight uint64
+}
+
+// InferQoS classifies the settings: guaranteed when memory.max and the
+// cpu.max quota are both set and memory.high is not below memory.max,
+// best-effort when there are no limits or memory protections and cpu.weight
+// is at most the default of 100, and burstable otherwise
+func InferQoS(s QoSSettings) QoSClass {
+	quota, _, _ := ParseCPUMax(string(s.CPUMax))
+	var (
+		memoryLimited = s.MemoryMax != math.MaxUint64
+		cpuLimited    = quota != nil
+	)
+	if memoryLimited && cpuLimited && s.MemoryHigh >= s.MemoryMax {
+		return QoSGuaranteed
+	}
+	if !memoryLimited && !cpuLimited && s.MemoryHigh == math.MaxUint64 &&
+		s.MemoryMin == 0 && s.MemoryLow == 0 && s.CPUWeight <= defaultWeight {
+		return QoSBestEffort
+	}
+	return QoSBurstable
+}
+
+// QoSSettings reads the settings the QoS class of the cgroup is inferred
+// from, files of controllers not enabled for the cgroup read as unset
+func (c *Manager) QoSSettings() (QoSSettings, error) {
+	if _, err := os.Stat(c.path); err != nil {
+		if os.IsNotExist(err) {
+			return QoSSettings{}, ErrCgroupDeleted
+		}
+		return QoSSettings{}, err
+	}
+	s := QoSSettings{
+		MemoryMax:  math.MaxUint64,
+		MemoryHigh: math.MaxUint64,
+		CPUMax:     "max",
+		CPUWeight:  defaultWeight,
+	}
+	for _, f := range []struct {
+		name  string
+		value *uint64
+	}{
+		{"memory.max", &s.MemoryMax},
+		{"memory.high", &s.MemoryHigh},
+		{"memory.min", &s.MemoryMin},
+		{"memory.low", &s.MemoryLow},
+		{"cpu.weight", &s.CPUWeight},
+	} {
+		v, err := c.readUint64(f.name)
+		if err != nil {
+			if os.IsNotExist(errors.Cause(err)) {
+				continue
+			}
+			return s, err
+		}
+		*f.value = v
+	}
+	data, err := c.readFile("cpu.max")
+	if err != nil {
+		if !os.IsNotExist(errors.Cause(err)) {
+			return s, err
+		}
+	} else {
+		s.CPUMax = CPUMax(strings.TrimSpace(string(data)))
+	}
+	return s, nil
+}
+
+// QoSClass infers the QoS class of the cgroup from its current settings, to
+// audit nodes managed by several tools for misclassified workloads
+func (c *Manager) QoSClass() (QoSClass, error) {
+	s, err := c.QoSSettings()
+	if err != nil {
+		return "", err
+	}
+	return InferQoS(s), nil
+}
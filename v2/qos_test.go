@@ -0,0 +1,78 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestInferQoS(t *testing.T) {
+	unset := QoSSettings{
+		MemoryMax:  math.MaxUint64,
+		MemoryHigh: math.MaxUint64,
+		CPUMax:     "max 100000",
+		CPUWeight:  100,
+	}
+	for _, tc := range []struct {
+		name     string
+		modify   func(*QoSSettings)
+		expected QoSClass
+	}{
+		{"unset", func(*QoSSettings) {}, QoSBestEffort},
+		{"lowest weight", func(s *QoSSettings) { s.CPUWeight = 1 }, QoSBestEffort},
+		{"raised weight", func(s *QoSSettings) { s.CPUWeight = 200 }, QoSBurstable},
+		{"memory protected", func(s *QoSSettings) { s.MemoryLow = 1 << 20 }, QoSBurstable},
+		{"memory limited", func(s *QoSSettings) { s.MemoryMax = 1 << 30 }, QoSBurstable},
+		{"limited", func(s *QoSSettings) {
+			s.MemoryMax = 1 << 30
+			s.CPUMax = "50000 100000"
+		}, QoSGuaranteed},
+		{"throttled below limit", func(s *QoSSettings) {
+			s.MemoryMax = 1 << 30
+			s.MemoryHigh = 1 << 29
+			s.CPUMax = "50000 100000"
+		}, QoSBurstable},
+	} {
+		s := unset
+		tc.modify(&s)
+		if class := InferQoS(s); class != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, class)
+		}
+	}
+}
+
+func TestManagerQoSClass(t *testing.T) {
+	c := shrinkTestManager(t, map[string]string{
+		"memory.max":  "1073741824\n",
+		"memory.high": "max\n",
+		"cpu.max":     "50000 100000\n",
+	})
+	defer os.RemoveAll(c.path)
+	class, err := c.QoSClass()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class != QoSGuaranteed {
+		t.Fatalf("expected a guaranteed cgroup, got %s", class)
+	}
+	os.RemoveAll(c.path)
+	if _, err := c.QoSClass(); err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"sync"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/pkg/errors"
+)
+
+// RateLimit bounds how often the resources of a manager's cgroup are
+// changed. Tokens are added every Interval up to Burst, each Update and
+// Freeze takes one. Thaw and Delete are never limited so that a cgroup can
+// always be released, and neither is AddProc, so that the moves of AddProcs,
+// Swap and AttachSelf cannot stop halfway and leave processes split between
+// groups.
+type RateLimit struct {
+	// Interval is the time it takes for one token to be added
+	Interval time.Duration
+	// Burst is the number of changes that can be made at once, at least 1
+	Burst int
+	// Wait makes limited operations wait for a token instead of failing
+	// with ErrRateLimited
+	Wait bool
+}
+
+// rateLimiter is a token bucket
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &rateLimiter{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+	}
+}
+
+// take takes a token, returning how long the caller has to wait for it. No
+// token is taken and false is returned when the caller cannot wait.
+func (l *rateLimiter) take(now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.limit.Interval)
+		if max := float64(l.limit.Burst); l.tokens > max {
+			l.tokens = max
+		}
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	if !l.limit.Wait {
+		return 0, false
+	}
+	wait := time.Duration((1 - l.tokens) * float64(l.limit.Interval))
+	// the token is owed, later callers wait behind this one
+	l.tokens--
+	return wait, true
+}
+
+// throttle applies the manager's rate limit to the named operation, the
+// throttled operations are reported to the observer as CallThrottled
+func (c *Manager) throttle(name string) error {
+	if c.limiter == nil {
+		return nil
+	}
+	clk := c.config.pacer()
+	start := time.Now()
+	wait, ok := c.limiter.take(clk.Now())
+	if !ok {
+		err := errors.Wrapf(ErrRateLimited, "%s %s", name, c.path)
+		observe(CallThrottled, name, start, err)
+		return err
+	}
+	if wait > 0 {
+		clock.Sleep(clk, wait)
+		observe(CallThrottled, name, start, nil)
+	}
+	return nil
+}
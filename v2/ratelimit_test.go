@@ -0,0 +1,114 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(RateLimit{Interval: time.Second, Burst: 2})
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, ok := l.take(now); !ok {
+			t.Fatalf("expected burst token %d", i)
+		}
+	}
+	if _, ok := l.take(now); ok {
+		t.Fatal("expected the limiter to refuse once the burst is used")
+	}
+	if _, ok := l.take(now.Add(time.Second)); !ok {
+		t.Fatal("expected a token after the interval")
+	}
+
+	l = newRateLimiter(RateLimit{Interval: time.Second, Wait: true})
+	if wait, ok := l.take(now); !ok || wait != 0 {
+		t.Fatalf("expected the first token without waiting, got %v %v", wait, ok)
+	}
+	if wait, _ := l.take(now); wait != time.Second {
+		t.Fatalf("expected to wait an interval, got %v", wait)
+	}
+	if wait, _ := l.take(now); wait != 2*time.Second {
+		t.Fatalf("expected to wait behind the previous caller, got %v", wait)
+	}
+}
+
+func TestManagerRateLimit(t *testing.T) {
+	c := shrinkTestManager(t, nil)
+	defer os.RemoveAll(c.path)
+	c.config = &InitConfig{}
+	c.limiter = newRateLimiter(RateLimit{Interval: time.Hour})
+
+	var throttled []string
+	SetObserver(func(call Call, name string, _ time.Duration, err error) {
+		if call == CallThrottled {
+			throttled = append(throttled, name)
+		}
+	})
+	defer SetObserver(nil)
+
+	if err := c.Update(&Resources{Pids: &Pids{Max: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Update(&Resources{Pids: &Pids{Max: 20}}); KindOf(err) != KindBusy {
+		t.Fatalf("expected the second update to be rate limited, got %v", err)
+	}
+	if v := readTestFile(t, c, "pids.max"); v != "10" {
+		t.Fatalf("expected pids.max to be left at 10, got %q", v)
+	}
+	if len(throttled) != 1 || throttled[0] != "update" {
+		t.Fatalf("expected the throttled update to be observed, got %v", throttled)
+	}
+	// moving processes is not limited
+	for _, pid := range []uint64{1234, 5678} {
+		if err := c.AddProc(pid); err != nil {
+			t.Fatalf("expected moving %d not to be rate limited, got %v", pid, err)
+		}
+	}
+}
+
+func TestManagerRateLimitClock(t *testing.T) {
+	c := shrinkTestManager(t, nil)
+	defer os.RemoveAll(c.path)
+	fake := clock.NewFake(time.Now())
+	c.config = &InitConfig{Pacer: fake}
+	c.limiter = newRateLimiter(RateLimit{Interval: time.Hour, Wait: true})
+
+	if err := c.Update(&Resources{Pids: &Pids{Max: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Update(&Resources{Pids: &Pids{Max: 20}})
+	}()
+	// the update waits for a token until the clock is advanced by an hour
+	fake.BlockUntil(1)
+	if v := readTestFile(t, c, "pids.max"); v != "10" {
+		t.Fatalf("expected pids.max to be left at 10 while waiting, got %q", v)
+	}
+	fake.Advance(time.Hour)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	if v := readTestFile(t, c, "pids.max"); v != "20" {
+		t.Fatalf("expected pids.max to be 20, got %q", v)
+	}
+}
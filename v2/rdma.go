@@ -21,13 +21,13 @@ import (
 )
 
 type RDMA struct {
-	Limit []RDMAEntry
+	Limit []RDMAEntry `json:"limit,omitempty"`
 }
 
 type RDMAEntry struct {
-	Device     string
-	HcaHandles uint32
-	HcaObjects uint32
+	Device     string `json:"device"`
+	HcaHandles uint32 `json:"hca_handles"`
+	HcaObjects uint32 `json:"hca_objects"`
 }
 
 func (r RDMAEntry) String() string {
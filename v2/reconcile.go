@@ -0,0 +1,190 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultReconcileInterval = 10 * time.Second
+
+// ReconcilerOpt configures a Reconciler
+type ReconcilerOpt func(*Reconciler)
+
+// WithReconcileInterval sets how often Run compares the live configuration
+// with the desired resources
+func WithReconcileInterval(interval time.Duration) ReconcilerOpt {
+	return func(r *Reconciler) {
+		r.interval = interval
+	}
+}
+
+// WithReapplyLimit sets the minimum time between two applies of the desired
+// resources, limiting the writes when another manager keeps changing them
+func WithReapplyLimit(limit time.Duration) ReconcilerOpt {
+	return func(r *Reconciler) {
+		r.limit = limit
+	}
+}
+
+// WithDriftHandler sets a function called with the files that drifted from
+// the desired resources each time drift is detected
+func WithDriftHandler(fn func(drift []Mismatch)) ReconcilerOpt {
+	return func(r *Reconciler) {
+		r.onDrift = fn
+	}
+}
+
+// Reconciler defends the resources of a cgroup against external
+// modification by comparing the live configuration with the desired
+// resources and re-applying them when they drift
+type Reconciler struct {
+	manager  *Manager
+	interval time.Duration
+	limit    time.Duration
+	onDrift  func([]Mismatch)
+
+	mu        sync.Mutex
+	desired   *Resources
+	applied   map[string]string
+	lastApply time.Time
+}
+
+// NewReconciler returns a Reconciler keeping the manager's cgroup at the
+// desired resources
+func NewReconciler(manager *Manager, desired *Resources, opts ...ReconcilerOpt) *Reconciler {
+	r := &Reconciler{
+		manager:  manager,
+		desired:  desired,
+		interval: defaultReconcileInterval,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// SetDesired replaces the desired resources, they are applied on the next
+// reconcile
+func (r *Reconciler) SetDesired(desired *Resources) {
+	r.mu.Lock()
+	r.desired = desired
+	r.applied = nil
+	r.mu.Unlock()
+}
+
+// Reconcile compares the live configuration with the values applied for the
+// desired resources and re-applies them if they drifted, unless they were
+// applied within the reapply limit. The drifted files are returned.
+//
+// The values are compared with what the kernel applied rather than what was
+// requested so that rounding, such as memory limits rounded to the page
+// size, is not reported as drift.
+func (r *Reconciler) Reconcile() ([]Mismatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.applied == nil {
+		return nil, r.apply()
+	}
+	drift, err := r.drift()
+	if err != nil {
+		return nil, err
+	}
+	if len(drift) == 0 {
+		return nil, nil
+	}
+	if r.onDrift != nil {
+		r.onDrift(drift)
+	}
+	if r.manager.config.pacer().Now().Sub(r.lastApply) < r.limit {
+		return drift, nil
+	}
+	return drift, r.apply()
+}
+
+// Run reconciles at the configured interval until ctx is canceled or the
+// group is removed. Failed reconciles are logged and retried at the next
+// interval, so that a transient error does not leave the group undefended.
+func (r *Reconciler) Run(ctx context.Context) error {
+	ticker := clock.NewTicker(r.manager.config.pacer(), r.interval)
+	for {
+		if _, err := r.Reconcile(); err != nil {
+			if _, serr := os.Stat(r.manager.path); os.IsNotExist(serr) {
+				return ErrCgroupDeleted
+			}
+			logrus.Warnf("cgroups: unable to reconcile %s, retrying in %s: %v", r.manager.path, r.interval, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.Next():
+		}
+	}
+}
+
+// apply writes the desired resources and records the values the kernel
+// applied for them
+func (r *Reconciler) apply() error {
+	if r.desired == nil {
+		r.applied = make(map[string]string)
+		return nil
+	}
+	if err := r.manager.Update(r.desired); err != nil {
+		return err
+	}
+	r.lastApply = r.manager.config.pacer().Now()
+	applied := make(map[string]string)
+	for _, v := range r.desired.Values() {
+		data, err := readBounded(filepath.Join(r.manager.path, v.filename))
+		if err != nil {
+			return err
+		}
+		applied[v.filename] = strings.TrimSpace(string(data))
+	}
+	r.applied = applied
+	return nil
+}
+
+func (r *Reconciler) drift() ([]Mismatch, error) {
+	var drift []Mismatch
+	for filename, expected := range r.applied {
+		data, err := readBounded(filepath.Join(r.manager.path, filename))
+		if err != nil {
+			return nil, err
+		}
+		if current := strings.TrimSpace(string(data)); current != expected {
+			drift = append(drift, Mismatch{
+				File:      filename,
+				Requested: expected,
+				Applied:   current,
+			})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		return drift[i].File < drift[j].File
+	})
+	return drift, nil
+}
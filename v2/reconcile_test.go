@@ -0,0 +1,168 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+)
+
+func TestReconciler(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-reconcile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	path := filepath.Join(mountpoint, "test")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"memory.max", "pids.max"} {
+		if err := ioutil.WriteFile(filepath.Join(path, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := LoadManager(mountpoint, "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	max := int64(1 << 20)
+	var reported []Mismatch
+	r := NewReconciler(m, &Resources{
+		Memory: &Memory{Max: &max},
+		Pids:   &Pids{Max: 10},
+	}, WithDriftHandler(func(drift []Mismatch) {
+		reported = drift
+	}))
+	if drift, err := r.Reconcile(); err != nil || drift != nil {
+		t.Fatalf("expected the first reconcile to apply without drift: %v %v", drift, err)
+	}
+	if drift, err := r.Reconcile(); err != nil || drift != nil {
+		t.Fatalf("expected no drift: %v %v", drift, err)
+	}
+
+	// another manager changes the limit
+	if err := ioutil.WriteFile(filepath.Join(path, "pids.max"), []byte("max"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	drift, err := r.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Mismatch{File: "pids.max", Requested: "10", Applied: "max"}
+	if len(drift) != 1 || drift[0] != expected || len(reported) != 1 {
+		t.Fatalf("expected drift %+v but received %+v", expected, drift)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(path, "pids.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "10" {
+		t.Fatalf("expected pids.max to be re-applied but it is %q", data)
+	}
+}
+
+func TestReconcilerReapplyLimit(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-reconcile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	path := filepath.Join(mountpoint, "test")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(path, "pids.max"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManager(mountpoint, "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReconciler(m, &Resources{Pids: &Pids{Max: 10}}, WithReapplyLimit(time.Hour))
+	if _, err := r.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(path, "pids.max"), []byte("max"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if drift, err := r.Reconcile(); err != nil || len(drift) != 1 {
+		t.Fatalf("expected drift to be reported: %v %v", drift, err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(path, "pids.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "max" {
+		t.Fatalf("expected the re-apply to be rate limited but pids.max is %q", data)
+	}
+}
+
+func TestReconcilerRunRetries(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-reconcile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	path := filepath.Join(mountpoint, "test")
+	// a directory in place of pids.max makes the writes fail
+	if err := os.MkdirAll(filepath.Join(path, "pids.max"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fake := clock.NewFake(time.Unix(0, 0))
+	m, err := LoadManager(mountpoint, "/test", WithPacer(fake))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReconciler(m, &Resources{Pids: &Pids{Max: 10}}, WithReconcileInterval(time.Second))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(ctx)
+	}()
+
+	fake.BlockUntil(1)
+	if err := os.Remove(filepath.Join(path, "pids.max")); err != nil {
+		t.Fatal(err)
+	}
+	fake.Advance(time.Second)
+	fake.BlockUntil(1)
+	data, err := ioutil.ReadFile(filepath.Join(path, "pids.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "10" {
+		t.Fatalf("expected pids.max to be applied after the error but it is %q", data)
+	}
+
+	// Run returns once the group is removed
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+	r.SetDesired(&Resources{Pids: &Pids{Max: 10}})
+	fake.Advance(time.Second)
+	if err := <-done; err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted but received %v", err)
+	}
+	cancel()
+}
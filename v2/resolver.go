@@ -0,0 +1,254 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Resolver maintains an in-memory index of pids to the managers of their
+// groups, so that agents receiving pids from kernel events share a manager
+// per group rather than creating one for every lookup.
+//
+// The group of a pid is read from /proc/<pid>/cgroup on every lookup and
+// refresh, so that processes that migrated between groups, and pids reused
+// by new processes, resolve to their current group. Entries are kept until
+// the process is forgotten, pruned by Refresh, or its group is removed
+// while Watch is running.
+type Resolver struct {
+	mountpoint string
+	config     *InitConfig
+	// procRoot is where procfs is mounted
+	procRoot string
+
+	mu sync.RWMutex
+	// pids maps pids to their group
+	pids map[int]string
+	// groups are shared by all pids of a group
+	groups map[string]*resolvedGroup
+}
+
+// resolvedGroup is the manager of an indexed group and its number of pids
+type resolvedGroup struct {
+	manager *Manager
+	pids    int
+}
+
+// ResolverOpts configures a Resolver
+type ResolverOpts func(*resolverConfig)
+
+type resolverConfig struct {
+	initOpts []InitOpts
+	procOpts []ProcOpts
+}
+
+// WithResolverInitOpts sets the options used for every manager returned by
+// the resolver
+func WithResolverInitOpts(opts ...InitOpts) ResolverOpts {
+	return func(c *resolverConfig) {
+		c.initOpts = opts
+	}
+}
+
+// WithResolverProcOpts sets how the resolver reads processes from procfs,
+// such as WithProcRoot for an agent that sees the host's procfs elsewhere
+func WithResolverProcOpts(opts ...ProcOpts) ResolverOpts {
+	return func(c *resolverConfig) {
+		c.procOpts = opts
+	}
+}
+
+// NewResolver returns an empty resolver for groups under the mountpoint
+func NewResolver(mountpoint string, opts ...ResolverOpts) (*Resolver, error) {
+	var rc resolverConfig
+	for _, o := range opts {
+		o(&rc)
+	}
+	config, err := newInitConfig(rc.initOpts)
+	if err != nil {
+		return nil, err
+	}
+	proc := procConfig{
+		root: procRoot,
+	}
+	for _, o := range rc.procOpts {
+		o(&proc)
+	}
+	return &Resolver{
+		mountpoint: mountpoint,
+		config:     config,
+		procRoot:   proc.root,
+		pids:       make(map[int]string),
+		groups:     make(map[string]*resolvedGroup),
+	}, nil
+}
+
+// LookupPID returns the manager of the current group of pid. ErrProcessNotFound
+// is returned, and the pid forgotten, if the process does not exist.
+func (r *Resolver) LookupPID(pid int) (*Manager, error) {
+	if pid <= 0 {
+		return nil, ErrInvalidPid
+	}
+	group, err := r.readPidGroup(pid)
+	if err != nil {
+		if err == ErrProcessNotFound {
+			r.Forget(pid)
+		}
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.add(pid, group), nil
+}
+
+// Forget drops pid from the index, it should be called when a process exits
+// or is moved to another group
+func (r *Resolver) Forget(pid int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remove(pid)
+}
+
+// Len returns the number of indexed pids
+func (r *Resolver) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.pids)
+}
+
+// Refresh updates the index from procfs: processes that exited are dropped
+// and the group of every other process is read again.
+func (r *Resolver) Refresh() error {
+	entries, err := ioutil.ReadDir(r.procRoot)
+	if err != nil {
+		return err
+	}
+	live := make(map[int]bool, len(entries))
+	for _, e := range entries {
+		if pid, err := strconv.Atoi(e.Name()); err == nil && e.IsDir() {
+			live[pid] = true
+		}
+	}
+	r.mu.Lock()
+	for pid := range r.pids {
+		if !live[pid] {
+			r.remove(pid)
+		}
+	}
+	r.mu.Unlock()
+
+	for pid := range live {
+		group, err := r.readPidGroup(pid)
+		if err != nil {
+			if err == ErrProcessNotFound {
+				r.Forget(pid)
+				continue
+			}
+			return err
+		}
+		r.mu.Lock()
+		r.add(pid, group)
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// Watch keeps the index current with the groups below the group root until
+// ctx is done: when a group is removed its processes are dropped. The
+// groups are watched once Watch returns. The error that ended the watch, if
+// any, is delivered on the returned channel, which is closed once the watch
+// ended.
+func (r *Resolver) Watch(ctx context.Context, root string) <-chan error {
+	events, errCh := Subscribe(ctx, r.mountpoint, root)
+	out := make(chan error, 1)
+	go func() {
+		defer close(out)
+		for event := range events {
+			if event.Type == GroupRemoved {
+				r.removeGroup(event.Group)
+			}
+		}
+		if err := <-errCh; err != nil {
+			out <- err
+		}
+	}()
+	return out
+}
+
+// add indexes pid in group, r.mu must be held
+func (r *Resolver) add(pid int, group string) *Manager {
+	if g, ok := r.pids[pid]; ok {
+		if g == group {
+			return r.groups[group].manager
+		}
+		r.remove(pid)
+	}
+	g, ok := r.groups[group]
+	if !ok {
+		g = &resolvedGroup{
+			manager: newManager(r.mountpoint, filepath.Join(r.mountpoint, group), r.config),
+		}
+		r.groups[group] = g
+	}
+	g.pids++
+	r.pids[pid] = group
+	return g.manager
+}
+
+// remove drops pid from the index along with the manager of its group once
+// no pids refer to it, r.mu must be held
+func (r *Resolver) remove(pid int) {
+	group, ok := r.pids[pid]
+	if !ok {
+		return
+	}
+	delete(r.pids, pid)
+	g := r.groups[group]
+	if g.pids--; g.pids == 0 {
+		delete(r.groups, group)
+	}
+}
+
+// removeGroup drops the pids of the group and the groups below it
+func (r *Resolver) removeGroup(group string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for pid, g := range r.pids {
+		if g == group || strings.HasPrefix(g, group+"/") {
+			delete(r.pids, pid)
+			delete(r.groups, g)
+		}
+	}
+}
+
+// readPidGroup returns the group of pid from procfs
+func (r *Resolver) readPidGroup(pid int) (string, error) {
+	group, err := ParseCgroupFile(filepath.Join(r.procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrProcessNotFound
+		}
+		return "", err
+	}
+	return group, nil
+}
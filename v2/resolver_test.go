@@ -0,0 +1,184 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func fakeProcfs(t *testing.T, groups map[int]string) string {
+	dir, err := ioutil.TempDir("", "cgroups-procfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pid, group := range groups {
+		writeFakeProc(t, dir, pid, group)
+	}
+	return dir
+}
+
+func writeFakeProc(t *testing.T, dir string, pid int, group string) {
+	p := filepath.Join(dir, strconv.Itoa(pid))
+	if err := os.MkdirAll(p, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(p, "cgroup"), []byte("0::"+group+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	dir := fakeProcfs(t, map[int]string{
+		1: "/root/a",
+		2: "/root/a",
+		3: "/root/b",
+	})
+	defer os.RemoveAll(dir)
+	defer func(root string) { procRoot = root }(procRoot)
+	procRoot = dir
+
+	r, err := NewResolver("/sys/fs/cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m1, err := r.LookupPID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := r.LookupPID(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m1 != m2 || m1.path != "/sys/fs/cgroup/root/a" {
+		t.Fatalf("expected pids 1 and 2 to share the manager of /root/a, got %q and %q", m1.path, m2.path)
+	}
+	if _, err := r.LookupPID(4); err != ErrProcessNotFound {
+		t.Fatalf("expected ErrProcessNotFound but received %v", err)
+	}
+	if _, err := r.LookupPID(0); err != ErrInvalidPid {
+		t.Fatalf("expected ErrInvalidPid but received %v", err)
+	}
+
+	if err := r.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 3 {
+		t.Fatalf("expected 3 pids after refresh but received %d", r.Len())
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "1")); err != nil {
+		t.Fatal(err)
+	}
+	writeFakeProc(t, dir, 5, "/root/b")
+	if err := r.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 3 {
+		t.Fatalf("expected 3 pids after the second refresh but received %d", r.Len())
+	}
+	if _, ok := r.pids[1]; ok {
+		t.Fatal("expected the exited pid to be dropped")
+	}
+	r.Forget(2)
+	if _, ok := r.groups["/root/a"]; ok {
+		t.Fatal("expected the manager of /root/a to be dropped with its last pid")
+	}
+
+	// a process that moved, or a reused pid, resolves to its current group
+	writeFakeProc(t, dir, 3, "/root/c")
+	m3, err := r.LookupPID(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m3.path != "/sys/fs/cgroup/root/c" {
+		t.Fatalf("expected pid 3 to resolve to /root/c, got %q", m3.path)
+	}
+	writeFakeProc(t, dir, 5, "/root/c")
+	if err := r.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if group := r.pids[5]; group != "/root/c" {
+		t.Fatalf("expected the refresh to move pid 5 to /root/c, got %q", group)
+	}
+	if _, ok := r.groups["/root/b"]; ok {
+		t.Fatal("expected the manager of /root/b to be dropped")
+	}
+}
+
+func TestResolverProcRoot(t *testing.T) {
+	dir := fakeProcfs(t, map[int]string{1: "/host/a"})
+	defer os.RemoveAll(dir)
+
+	r, err := NewResolver("/sys/fs/cgroup", WithResolverProcOpts(WithProcRoot(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := r.LookupPID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.path != "/sys/fs/cgroup/host/a" {
+		t.Fatalf("expected pid 1 to be read from the procfs at %s, got %q", dir, m.path)
+	}
+}
+
+func TestResolverWatch(t *testing.T) {
+	dir := fakeProcfs(t, map[int]string{1: "/root/a", 2: "/root/b"})
+	defer os.RemoveAll(dir)
+	defer func(root string) { procRoot = root }(procRoot)
+	procRoot = dir
+
+	mountpoint, err := ioutil.TempDir("", "cgroups-resolver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	for _, group := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(mountpoint, "root", group), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r, err := NewResolver(mountpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := r.Watch(ctx, "/root")
+	if err := os.Remove(filepath.Join(mountpoint, "root", "a")); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Len() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the removed group to be dropped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("expected no error after cancel but received %v", err)
+	}
+}
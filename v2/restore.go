@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// stateVersion is the hierarchy version recorded in the state of managers
+const stateVersion = 2
+
+// ManagerState is the minimal state needed to reattach to a cgroup without
+// discovering the hierarchy again
+type ManagerState struct {
+	// Version is the cgroup hierarchy version, always 2
+	Version    int    `json:"version"`
+	Mountpoint string `json:"mountpoint"`
+	// Group is the path of the cgroup below the mountpoint
+	Group string `json:"group"`
+	// Controllers are the controllers enabled for the cgroup when the
+	// state was marshaled
+	Controllers []string `json:"controllers,omitempty"`
+}
+
+// MarshalState returns the state of the manager so that a restarted process
+// can reattach to the cgroup with RestoreManager
+func (c *Manager) MarshalState() ([]byte, error) {
+	group, err := filepath.Rel(c.unifiedMountpoint, c.path)
+	if err != nil {
+		return nil, err
+	}
+	controllers, err := c.Controllers()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrCgroupDeleted
+		}
+		return nil, err
+	}
+	return json.Marshal(ManagerState{
+		Version:     stateVersion,
+		Mountpoint:  c.unifiedMountpoint,
+		Group:       filepath.Join("/", group),
+		Controllers: controllers,
+	})
+}
+
+// RestoreManager reattaches to the cgroup from the state returned by
+// MarshalState. ErrCgroupDeleted is returned if the cgroup no longer exists.
+// The InitOpts are not part of the state and must be passed again.
+func RestoreManager(data []byte, opts ...InitOpts) (*Manager, error) {
+	var state ManagerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.Wrap(err, "invalid manager state")
+	}
+	if state.Version != stateVersion {
+		return nil, errors.Errorf("unsupported manager state version %d", state.Version)
+	}
+	m, err := LoadManager(state.Mountpoint, state.Group, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(m.path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrCgroupDeleted
+		}
+		return nil, err
+	}
+	return m, nil
+}
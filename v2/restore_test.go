@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarshalRestoreManager(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-restore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+
+	m, err := LoadManager(mountpoint, "/a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.MarshalState(); err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted, got %v", err)
+	}
+	if err := os.MkdirAll(m.path, defaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(m.path, controllersFile), []byte("cpu memory\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := m.MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreManager(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.path != m.path || restored.unifiedMountpoint != mountpoint {
+		t.Fatalf("expected manager for %s, got %s", m.path, restored.path)
+	}
+
+	if err := os.RemoveAll(m.path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreManager(data); err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted, got %v", err)
+	}
+	if _, err := RestoreManager([]byte(`{"version":1}`)); err == nil {
+		t.Fatal("expected an error for an unsupported version")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/containerd/cgroups/v2/stats"
+	"github.com/pkg/errors"
+)
+
+// SchemaVersion is the version of the JSON encoding of resources and
+// metrics. It is incremented whenever a field is renamed or removed, or the
+// meaning of a value changes.
+const SchemaVersion = 1
+
+// resourcesDocument is the JSON encoding of resources
+type resourcesDocument struct {
+	SchemaVersion int        `json:"schema_version"`
+	Resources     *Resources `json:"resources"`
+}
+
+// metricsDocument is the JSON encoding of metrics
+type metricsDocument struct {
+	SchemaVersion int            `json:"schema_version"`
+	Metrics       *stats.Metrics `json:"metrics"`
+}
+
+// MarshalResources encodes the resources as JSON along with the
+// SchemaVersion. Nil and zero fields are left out, as they are not written to
+// the cgroup either.
+func MarshalResources(r *Resources) ([]byte, error) {
+	return json.Marshal(resourcesDocument{
+		SchemaVersion: SchemaVersion,
+		Resources:     r,
+	})
+}
+
+// UnmarshalResources decodes resources encoded with MarshalResources. Data
+// with another schema version or with unknown fields is rejected rather
+// than silently decoded into partial resources.
+func UnmarshalResources(data []byte) (*Resources, error) {
+	var doc resourcesDocument
+	if err := unmarshalDocument(data, &doc, &doc.SchemaVersion); err != nil {
+		return nil, errors.Wrap(err, "invalid resources")
+	}
+	if doc.Resources == nil {
+		return &Resources{}, nil
+	}
+	return doc.Resources, nil
+}
+
+// MarshalMetrics encodes the metrics as JSON along with the SchemaVersion.
+// Nil sections and zero values are left out.
+func MarshalMetrics(m *stats.Metrics) ([]byte, error) {
+	return json.Marshal(metricsDocument{
+		SchemaVersion: SchemaVersion,
+		Metrics:       m,
+	})
+}
+
+// UnmarshalMetrics decodes metrics encoded with MarshalMetrics. Data with
+// another schema version or with unknown fields is rejected.
+func UnmarshalMetrics(data []byte) (*stats.Metrics, error) {
+	var doc metricsDocument
+	if err := unmarshalDocument(data, &doc, &doc.SchemaVersion); err != nil {
+		return nil, errors.Wrap(err, "invalid metrics")
+	}
+	if doc.Metrics == nil {
+		return &stats.Metrics{}, nil
+	}
+	return doc.Metrics, nil
+}
+
+func unmarshalDocument(data []byte, doc interface{}, version *int) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(doc); err != nil {
+		return err
+	}
+	if *version != SchemaVersion {
+		return errors.Errorf("unsupported schema version %d", *version)
+	}
+	return nil
+}
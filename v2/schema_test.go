@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestResourcesJSON(t *testing.T) {
+	var (
+		weight uint64 = 200
+		max    int64  = 1 << 20
+	)
+	r := &Resources{
+		CPU:    &CPU{Weight: &weight, Max: "50000 100000"},
+		Memory: &Memory{Max: &max},
+		IO:     &IO{Max: []Entry{{Type: ReadBPS, Major: 8, Minor: 0, Rate: 1024}}},
+	}
+	data, err := MarshalResources(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"schema_version":1,"resources":{"cpu":{"weight":200,"max":"50000 100000"},"memory":{"max":1048576},"io":{"bfq":{},"max":[{"type":"rbps","major":8,"minor":0,"rate":1024}]}}}`
+	if string(data) != expected {
+		t.Fatalf("expected %s but received %s", expected, data)
+	}
+	decoded, err := UnmarshalResources(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, r) {
+		t.Fatalf("expected %+v but received %+v", r, decoded)
+	}
+}
+
+func TestUnmarshalResourcesRejectsDrift(t *testing.T) {
+	for _, data := range []string{
+		`{"schema_version":2,"resources":{}}`,
+		`{"schema_version":1,"resources":{"cpu":{"shares":2}}}`,
+	} {
+		if _, err := UnmarshalResources([]byte(data)); err == nil {
+			t.Errorf("expected %s to be rejected", data)
+		}
+	}
+}
+
+func TestMetricsJSON(t *testing.T) {
+	m := &stats.Metrics{
+		Pids: &stats.PidsStat{Current: 3, Limit: 10},
+	}
+	data, err := MarshalMetrics(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"pids":{"current":3,"limit":10}`) {
+		t.Fatalf("unexpected encoding %s", data)
+	}
+	decoded, err := UnmarshalMetrics(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Pids.Current != 3 || decoded.CPU != nil {
+		t.Fatalf("unexpected metrics %+v", decoded)
+	}
+}
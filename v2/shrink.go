@@ -0,0 +1,172 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"math"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// DefaultShrinkStep is the amount memory.high is lowered by at a time
+	DefaultShrinkStep = 64 << 20
+	// DefaultShrinkInterval is how long to wait for reclaim between steps
+	DefaultShrinkInterval = 100 * time.Millisecond
+)
+
+// ShrinkOpts configures ShrinkMemory
+type ShrinkOpts func(*shrinkConfig)
+
+type shrinkConfig struct {
+	step     uint64
+	interval time.Duration
+}
+
+// WithShrinkStep sets the amount memory.high is lowered by at a time
+func WithShrinkStep(step uint64) ShrinkOpts {
+	return func(c *shrinkConfig) {
+		c.step = step
+	}
+}
+
+// WithShrinkInterval sets how long to wait for reclaim between steps
+func WithShrinkInterval(interval time.Duration) ShrinkOpts {
+	return func(c *shrinkConfig) {
+		c.interval = interval
+	}
+}
+
+// ShrinkResult reports how far ShrinkMemory got
+type ShrinkResult struct {
+	// Limit is memory.max when ShrinkMemory returned
+	Limit uint64
+	// Usage is the last memory.current read
+	Usage uint64
+	// Reached is true when the target limit was applied
+	Reached bool
+}
+
+// ShrinkMemory lowers memory.max of the cgroup to target. Writing a
+// memory.max below the current usage OOM kills processes when the kernel
+// cannot reclaim enough memory, so proactive reclaim is requested through
+// memory.reclaim first and memory.high is then lowered by a step at a time,
+// which throttles and reclaims without OOM kills. memory.max is only written
+// once the usage is below target and memory.high is restored afterwards.
+// The shrink continues until the target is reached or ctx is done, in which
+// case memory.max is left unchanged and the result is returned alongside
+// ctx.Err(). The error of restoring memory.high is returned when the shrink
+// itself did not fail.
+func (c *Manager) ShrinkMemory(ctx context.Context, target uint64, opts ...ShrinkOpts) (result ShrinkResult, err error) {
+	config := &shrinkConfig{
+		step:     DefaultShrinkStep,
+		interval: DefaultShrinkInterval,
+	}
+	for _, o := range opts {
+		o(config)
+	}
+	if config.step == 0 {
+		return ShrinkResult{}, errors.New("cgroups: shrink step must be positive")
+	}
+	if result.Limit, err = c.readUint64("memory.max"); err != nil {
+		return result, err
+	}
+	if result.Usage, err = c.readUint64("memory.current"); err != nil {
+		return result, err
+	}
+	if result.Usage > target {
+		high, err := c.readFile("memory.high")
+		if err != nil {
+			return result, err
+		}
+		defer func() {
+			if rerr := writeValues(c.path, []Value{
+				{
+					filename: "memory.high",
+					value:    high,
+				},
+			}); rerr != nil && err == nil {
+				err = rerr
+			}
+		}()
+		if err := c.reclaim(result.Usage - target); err != nil {
+			return result, err
+		}
+		step := uint64(math.MaxUint64)
+		for {
+			if result.Usage, err = c.readUint64("memory.current"); err != nil {
+				return result, err
+			}
+			if result.Usage <= target {
+				break
+			}
+			// never step below the usage by more than a step at a time
+			if step > result.Usage {
+				step = result.Usage
+			}
+			if step-target > config.step {
+				step -= config.step
+			} else {
+				step = target
+			}
+			if err := writeValues(c.path, []Value{
+				{
+					filename: "memory.high",
+					value:    step,
+				},
+			}); err != nil {
+				return result, err
+			}
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-c.config.pacer().After(config.interval):
+			}
+		}
+	}
+	if err := writeValues(c.path, []Value{
+		{
+			filename: "memory.max",
+			value:    target,
+		},
+	}); err != nil {
+		return result, err
+	}
+	result.Limit = target
+	result.Reached = true
+	return result, nil
+}
+
+// reclaim asks the kernel to reclaim n bytes from the cgroup. Kernels
+// without memory.reclaim are ignored and partial reclaim, reported with
+// EAGAIN, is not an error as the steps of ShrinkMemory carry on from there.
+func (c *Manager) reclaim(n uint64) error {
+	err := writeValues(c.path, []Value{
+		{
+			filename: "memory.reclaim",
+			value:    n,
+		},
+	})
+	if err == nil || os.IsNotExist(errors.Cause(err)) || errors.Is(err, unix.EAGAIN) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func shrinkTestManager(t *testing.T, files map[string]string) *Manager {
+	dir, err := ioutil.TempDir("", "cgroups-shrink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Manager{path: dir}
+}
+
+func readTestFile(t *testing.T, c *Manager, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join(c.path, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestShrinkMemory(t *testing.T) {
+	c := shrinkTestManager(t, map[string]string{
+		"memory.max":     "max\n",
+		"memory.high":    "max\n",
+		"memory.current": "4096\n",
+	})
+	defer os.RemoveAll(c.path)
+	result, err := c.ShrinkMemory(context.Background(), 8192)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Reached || result.Limit != 8192 || result.Usage != 4096 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if v := readTestFile(t, c, "memory.max"); v != "8192" {
+		t.Fatalf("expected memory.max 8192, got %s", v)
+	}
+}
+
+func TestShrinkMemoryTimeout(t *testing.T) {
+	c := shrinkTestManager(t, map[string]string{
+		"memory.max":     "max\n",
+		"memory.high":    "max\n",
+		"memory.current": "209715200\n",
+	})
+	defer os.RemoveAll(c.path)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := c.ShrinkMemory(ctx, 10<<20, WithShrinkInterval(10*time.Millisecond))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if result.Reached || result.Usage != 200<<20 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	// memory.max is left alone and memory.high restored
+	if v := readTestFile(t, c, "memory.max"); v != "max" {
+		t.Fatalf("expected memory.max to be unchanged, got %s", v)
+	}
+	if v := readTestFile(t, c, "memory.high"); v != "max" {
+		t.Fatalf("expected memory.high to be restored, got %s", v)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/cgroups/topology"
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+// procRoot is a var so that the tests can read process state from a fake procfs
+var procRoot = "/proc"
+
+// Consequence is an immediate effect that applying proposed resources would
+// have on a cgroup
+type Consequence struct {
+	// File is the interface file the proposed value is written to
+	File string
+	// Description explains the consequence
+	Description string
+}
+
+// Simulate reports what would happen if resources were applied to the
+// cgroup now, without changing anything: processes over a lower pids.max,
+// usage above a lower memory.max or memory.high, and cpus removed from
+// cpuset.cpus that processes last ran on. An empty result means the change
+// has no immediate consequences.
+func (c *Manager) Simulate(resources *Resources) ([]Consequence, error) {
+	metrics, err := c.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var cpus map[int][]uint64
+	if resources.CPU != nil && resources.CPU.Cpus != "" {
+		if cpus, err = c.lastCPUs(); err != nil {
+			return nil, err
+		}
+	}
+	return SimulateResources(metrics, cpus, resources)
+}
+
+// lastCPUs returns the processes of the cgroup and its descendants keyed by
+// the cpu they last ran on
+func (c *Manager) lastCPUs() (map[int][]uint64, error) {
+	out := make(map[int][]uint64)
+	it := c.IterProcs(true)
+	defer it.Close()
+	for it.Next() {
+		cpu, ok := lastCPU(it.Pid())
+		if ok {
+			out[cpu] = append(out[cpu], it.Pid())
+		}
+	}
+	return out, it.Err()
+}
+
+// lastCPU reads the processor field of /proc/<pid>/stat, false is returned
+// if the process exited
+func lastCPU(pid uint64) (int, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(procRoot, strconv.FormatUint(pid, 10), "stat"))
+	if err != nil {
+		return 0, false
+	}
+	// the command name may contain spaces, fields are counted after it
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return 0, false
+	}
+	// processor is field 39, the 37th after the command name
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 37 {
+		return 0, false
+	}
+	cpu, err := strconv.Atoi(fields[36])
+	if err != nil {
+		return 0, false
+	}
+	return cpu, true
+}
+
+// SimulateResources reports the consequences of applying resources to a
+// cgroup with the metrics and whose processes last ran on the cpus, keyed by
+// cpu. See Manager.Simulate.
+func SimulateResources(metrics *stats.Metrics, cpus map[int][]uint64, resources *Resources) ([]Consequence, error) {
+	var out []Consequence
+	if p := resources.Pids; p != nil && p.Max > 0 && metrics.Pids != nil {
+		if current := metrics.Pids.Current; current > uint64(p.Max) {
+			out = append(out, Consequence{
+				File:        "pids.max",
+				Description: fmt.Sprintf("%d tasks exceed the limit of %d, new forks will fail until %d exit", current, p.Max, current-uint64(p.Max)),
+			})
+		}
+	}
+	if m := resources.Memory; m != nil && metrics.Memory != nil {
+		usage := metrics.Memory.Usage
+		if m.Max != nil && *m.Max >= 0 && usage > uint64(*m.Max) {
+			out = append(out, Consequence{
+				File:        "memory.max",
+				Description: fmt.Sprintf("usage of %d bytes exceeds the limit of %d, %d bytes must be reclaimed or processes will be OOM killed", usage, *m.Max, usage-uint64(*m.Max)),
+			})
+		}
+		if m.High != nil && *m.High >= 0 && usage > uint64(*m.High) {
+			out = append(out, Consequence{
+				File:        "memory.high",
+				Description: fmt.Sprintf("usage of %d bytes exceeds the limit of %d, processes will be throttled while %d bytes are reclaimed", usage, *m.High, usage-uint64(*m.High)),
+			})
+		}
+		if m.Swap != nil && *m.Swap >= 0 && metrics.Memory.SwapUsage > uint64(*m.Swap) {
+			out = append(out, Consequence{
+				File:        "memory.swap.max",
+				Description: fmt.Sprintf("swap usage of %d bytes exceeds the limit of %d", metrics.Memory.SwapUsage, *m.Swap),
+			})
+		}
+	}
+	if h := resources.HugeTlb; h != nil {
+		for _, e := range *h {
+			for _, s := range metrics.Hugetlb {
+				if s.Pagesize == e.HugePageSize && s.Current > e.Limit {
+					out = append(out, Consequence{
+						File:        "hugetlb." + e.HugePageSize + ".max",
+						Description: fmt.Sprintf("usage of %d bytes exceeds the limit of %d, new faults will fail", s.Current, e.Limit),
+					})
+				}
+			}
+		}
+	}
+	if r := resources.CPU; r != nil && r.Cpus != "" {
+		allowed, err := topology.ParseCPUSet(r.Cpus)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			removed []int
+			moved   int
+		)
+		for cpu, pids := range cpus {
+			if !allowed.Contains(cpu) {
+				removed = append(removed, cpu)
+				moved += len(pids)
+			}
+		}
+		if len(removed) > 0 {
+			sort.Ints(removed)
+			out = append(out, Consequence{
+				File:        "cpuset.cpus",
+				Description: fmt.Sprintf("%d processes on cpus %s will be migrated", moved, topology.NewCPUSet(removed...)),
+			})
+		}
+	}
+	return out, nil
+}
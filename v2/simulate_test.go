@@ -0,0 +1,105 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestSimulateResources(t *testing.T) {
+	metrics := &stats.Metrics{
+		Pids:    &stats.PidsStat{Current: 10},
+		Memory:  &stats.MemoryStat{Usage: 4096, SwapUsage: 1024},
+		Hugetlb: []*stats.HugeTlbStat{{Pagesize: "2MB", Current: 4 << 20}},
+	}
+	cpus := map[int][]uint64{0: {1}, 2: {2, 3}, 3: {4}}
+	max, high, swap := int64(2048), int64(1024), int64(0)
+	out, err := SimulateResources(metrics, cpus, &Resources{
+		Pids:    &Pids{Max: 5},
+		Memory:  &Memory{Max: &max, High: &high, Swap: &swap},
+		HugeTlb: &HugeTlb{{HugePageSize: "2MB", Limit: 2 << 20}},
+		CPU:     &CPU{Cpus: "0-1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, c := range out {
+		files = append(files, c.File)
+	}
+	if got, want := strings.Join(files, ","), "pids.max,memory.max,memory.high,memory.swap.max,hugetlb.2MB.max,cpuset.cpus"; got != want {
+		t.Fatalf("expected consequences for %s, got %s", want, got)
+	}
+	if d := out[len(out)-1].Description; !strings.Contains(d, "3 processes on cpus 2-3") {
+		t.Fatalf("unexpected cpuset consequence %q", d)
+	}
+}
+
+func TestSimulateResourcesNoConsequences(t *testing.T) {
+	metrics := &stats.Metrics{
+		Pids:   &stats.PidsStat{Current: 10},
+		Memory: &stats.MemoryStat{Usage: 4096},
+	}
+	max := int64(-1)
+	out, err := SimulateResources(metrics, map[int][]uint64{1: {1}}, &Resources{
+		Pids:   &Pids{Max: 10},
+		Memory: &Memory{Max: &max},
+		CPU:    &CPU{Cpus: "0-1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("expected no consequences, got %v", out)
+	}
+}
+
+func TestLastCPU(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(root string) { procRoot = root }(procRoot)
+	procRoot = dir
+
+	if err := os.Mkdir(filepath.Join(dir, "42"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fields := make([]string, 50)
+	for i := range fields {
+		fields[i] = "0"
+	}
+	fields[36] = "7"
+	stat := "42 (a (b) c) " + strings.Join(fields, " ") + "\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "42", "stat"), []byte(stat), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cpu, ok := lastCPU(42)
+	if !ok || cpu != 7 {
+		t.Fatalf("expected cpu 7, got %d (%v)", cpu, ok)
+	}
+	if _, ok := lastCPU(43); ok {
+		t.Fatal("expected exited process to be skipped")
+	}
+}
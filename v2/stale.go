@@ -0,0 +1,178 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// errStale is returned by a statReader for a file that was not read in time
+// while the cgroup is frozen
+var errStale = errors.New("cgroups: read timed out while the cgroup is frozen")
+
+// statReader bounds the reads of Stat while the cgroup is frozen, when
+// reads of some interface files may block, and records the files that were
+// not read in time
+type statReader struct {
+	// timeout bounds each read, there is none if the cgroup is not frozen
+	timeout time.Duration
+	pending *pendingReads
+	stale   []string
+}
+
+// pendingReads tracks the files of a Manager whose reads timed out and are
+// still blocked, so that at most one read of each file is ever in flight
+type pendingReads struct {
+	mu    sync.Mutex
+	files map[string]struct{}
+}
+
+// start marks file as being read, returning false if a previous read of it
+// is still in flight
+func (p *pendingReads) start(file string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.files[file]; ok {
+		return false
+	}
+	if p.files == nil {
+		p.files = make(map[string]struct{})
+	}
+	p.files[file] = struct{}{}
+	return true
+}
+
+func (p *pendingReads) done(file string) {
+	p.mu.Lock()
+	delete(p.files, file)
+	p.mu.Unlock()
+}
+
+func (p *pendingReads) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.files)
+}
+
+func (c *Manager) newStatReader() *statReader {
+	r := &statReader{pending: &c.pending}
+	if timeout := c.config.timeouts().FrozenRead; timeout > 0 {
+		r.timeout = timeout
+		if !c.frozen(r) {
+			r.timeout = 0
+		}
+	}
+	return r
+}
+
+// frozen returns true if the cgroup is frozen or being frozen, either itself
+// or through an ancestor. The files are read with the timeout of r, and a
+// read that does not return in time is taken as the cgroup being frozen.
+func (c *Manager) frozen(r *statReader) bool {
+	var data []byte
+	err := r.read("cgroup.freeze", func() (err error) {
+		data, err = c.readFile("cgroup.freeze")
+		return err
+	})
+	switch {
+	case err == errStale:
+		return true
+	case err == nil && strings.TrimSpace(string(data)) == "1":
+		return true
+	}
+	out := make(map[string]interface{})
+	if err := r.read(cgroupEvents, func() error {
+		return c.readKVStats(cgroupEvents, out)
+	}); err != nil {
+		return err == errStale
+	}
+	return getUint64Value("frozen", out) == 1
+}
+
+// read runs fn to read file, returning errStale if it does not return in
+// time or if a previous read of file is still in flight. A timed out fn
+// keeps running until the read returns, so it must not share state with the
+// caller, and no other read of file is started until then.
+func (r *statReader) read(file string, fn func() error) error {
+	if r.timeout <= 0 {
+		return fn()
+	}
+	if !r.pending.start(file) {
+		return errStale
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		err := fn()
+		r.pending.done(file)
+		errCh <- err
+	}()
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-errCh:
+		return err
+	case <-timer.C:
+		return errStale
+	}
+}
+
+// do reads file like read, recording the file if it is stale
+func (r *statReader) do(file string, fn func() error) error {
+	err := r.read(file, fn)
+	if err == errStale {
+		r.stale = append(r.stale, file)
+	}
+	return err
+}
+
+// kv reads file into a new map with fn, merging the map into out once read
+func (r *statReader) kv(file string, out map[string]interface{}, fn func(file string, out map[string]interface{}) error) error {
+	values := make(map[string]interface{})
+	if err := r.do(file, func() error {
+		return fn(file, values)
+	}); err != nil {
+		return err
+	}
+	for k, v := range values {
+		out[k] = v
+	}
+	return nil
+}
+
+// value returns the value read by fn, or nil if file is stale
+func (r *statReader) value(file string, fn func() interface{}) interface{} {
+	var v interface{}
+	if err := r.do(file, func() error {
+		v = fn()
+		return nil
+	}); err != nil {
+		return nil
+	}
+	return v
+}
+
+// uint64 returns the value of file read by fn, or 0 if file is stale
+func (r *statReader) uint64(file string, fn func(file string) uint64) uint64 {
+	v, _ := r.value(file, func() interface{} {
+		return fn(file)
+	}).(uint64)
+	return v
+}
@@ -0,0 +1,145 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestStatStaleWhileFrozen(t *testing.T) {
+	c := shrinkTestManager(t, map[string]string{
+		controllersFile:  "memory",
+		"cgroup.freeze":  "1\n",
+		"memory.current": "4096\n",
+	})
+	defer os.RemoveAll(c.path)
+	c.config = &InitConfig{
+		Timeouts: &Timeouts{FrozenRead: 50 * time.Millisecond},
+	}
+	// opening a fifo without a writer blocks like a stuck kernfs read
+	fifo := filepath.Join(c.path, "memory.stat")
+	if err := unix.Mkfifo(fifo, 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		// unblock the timed out read
+		if f, err := os.OpenFile(fifo, os.O_RDWR, 0); err == nil {
+			f.Close()
+		}
+	}()
+
+	metrics, stale, err := c.StatStale()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stale, []string{"memory.stat"}) {
+		t.Fatalf("expected memory.stat to be stale but received %v", stale)
+	}
+	if metrics.Memory.Usage != 4096 {
+		t.Fatalf("expected the usage to be read but received %d", metrics.Memory.Usage)
+	}
+}
+
+func TestStatNotFrozen(t *testing.T) {
+	c := shrinkTestManager(t, map[string]string{
+		controllersFile:  "memory",
+		"cgroup.freeze":  "0\n",
+		"memory.current": "4096\n",
+	})
+	defer os.RemoveAll(c.path)
+	c.config = &InitConfig{
+		Timeouts: &Timeouts{FrozenRead: 50 * time.Millisecond},
+	}
+	if r := c.newStatReader(); r.timeout != 0 {
+		t.Fatalf("expected no timeout for a cgroup that is not frozen but received %s", r.timeout)
+	}
+	_, stale, err := c.StatStale()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 0 {
+		t.Fatalf("expected no stale files but received %v", stale)
+	}
+}
+
+func TestStatStaleSingleRead(t *testing.T) {
+	c := shrinkTestManager(t, map[string]string{
+		controllersFile:  "memory",
+		"cgroup.freeze":  "1\n",
+		"memory.current": "4096\n",
+	})
+	defer os.RemoveAll(c.path)
+	c.config = &InitConfig{
+		Timeouts: &Timeouts{FrozenRead: 50 * time.Millisecond},
+	}
+	fifo := filepath.Join(c.path, "memory.stat")
+	if err := unix.Mkfifo(fifo, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		_, stale, err := c.StatStale()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(stale, []string{"memory.stat"}) {
+			t.Fatalf("expected memory.stat to be stale but received %v", stale)
+		}
+	}
+	if n := c.pending.len(); n != 1 {
+		t.Fatalf("expected a single read in flight but received %d", n)
+	}
+	// unblock the timed out read, after which the file is read again
+	f, err := os.OpenFile(fifo, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	for i := 0; c.pending.len() != 0; i++ {
+		if i == 100 {
+			t.Fatal("expected the pending read to return")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFrozenBlockedRead(t *testing.T) {
+	c := shrinkTestManager(t, map[string]string{
+		controllersFile: "memory",
+	})
+	defer os.RemoveAll(c.path)
+	c.config = &InitConfig{
+		Timeouts: &Timeouts{FrozenRead: 50 * time.Millisecond},
+	}
+	fifo := filepath.Join(c.path, "cgroup.freeze")
+	if err := unix.Mkfifo(fifo, 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if f, err := os.OpenFile(fifo, os.O_RDWR, 0); err == nil {
+			f.Close()
+		}
+	}()
+	if r := c.newStatReader(); r.timeout == 0 {
+		t.Fatal("expected a blocked read of cgroup.freeze to be taken as frozen")
+	}
+}
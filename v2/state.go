@@ -17,7 +17,6 @@
 package v2
 
 import (
-	"io/ioutil"
 	"path/filepath"
 	"strings"
 )
@@ -50,7 +49,7 @@ func (s State) Values() []Value {
 }
 
 func fetchState(path string) (State, error) {
-	current, err := ioutil.ReadFile(filepath.Join(path, cgroupFreeze))
+	current, err := readBounded(filepath.Join(path, cgroupFreeze))
 	if err != nil {
 		return Unknown, err
 	}
@@ -0,0 +1,30 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package stats
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// Clone returns a deep copy of the metrics that shares no memory with m, it
+// can be retained as a snapshot across later calls to Stat
+func (m *Metrics) Clone() *Metrics {
+	if m == nil {
+		return nil
+	}
+	return proto.Clone(m).(*Metrics)
+}
@@ -25,16 +25,19 @@ var _ = math.Inf
 const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 
 type Metrics struct {
-	Pids                 *PidsStat      `protobuf:"bytes,1,opt,name=pids,proto3" json:"pids,omitempty"`
-	CPU                  *CPUStat       `protobuf:"bytes,2,opt,name=cpu,proto3" json:"cpu,omitempty"`
-	Memory               *MemoryStat    `protobuf:"bytes,4,opt,name=memory,proto3" json:"memory,omitempty"`
-	Rdma                 *RdmaStat      `protobuf:"bytes,5,opt,name=rdma,proto3" json:"rdma,omitempty"`
-	Io                   *IOStat        `protobuf:"bytes,6,opt,name=io,proto3" json:"io,omitempty"`
-	Hugetlb              []*HugeTlbStat `protobuf:"bytes,7,rep,name=hugetlb,proto3" json:"hugetlb,omitempty"`
-	MemoryEvents         *MemoryEvents  `protobuf:"bytes,8,opt,name=memory_events,json=memoryEvents,proto3" json:"memory_events,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	Pids         *PidsStat      `protobuf:"bytes,1,opt,name=pids,proto3" json:"pids,omitempty"`
+	CPU          *CPUStat       `protobuf:"bytes,2,opt,name=cpu,proto3" json:"cpu,omitempty"`
+	Memory       *MemoryStat    `protobuf:"bytes,4,opt,name=memory,proto3" json:"memory,omitempty"`
+	Rdma         *RdmaStat      `protobuf:"bytes,5,opt,name=rdma,proto3" json:"rdma,omitempty"`
+	Io           *IOStat        `protobuf:"bytes,6,opt,name=io,proto3" json:"io,omitempty"`
+	Hugetlb      []*HugeTlbStat `protobuf:"bytes,7,rep,name=hugetlb,proto3" json:"hugetlb,omitempty"`
+	MemoryEvents *MemoryEvents  `protobuf:"bytes,8,opt,name=memory_events,json=memoryEvents,proto3" json:"memory_events,omitempty"`
+	// time in nanoseconds of the manager's clock source, CLOCK_MONOTONIC by
+	// default, at which the metrics were read
+	Timestamp            uint64   `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Metrics) Reset()      { *m = Metrics{} }
@@ -396,6 +399,8 @@ type IOEntry struct {
 	Wbytes               uint64   `protobuf:"varint,4,opt,name=wbytes,proto3" json:"wbytes,omitempty"`
 	Rios                 uint64   `protobuf:"varint,5,opt,name=rios,proto3" json:"rios,omitempty"`
 	Wios                 uint64   `protobuf:"varint,6,opt,name=wios,proto3" json:"wios,omitempty"`
+	Dbytes               uint64   `protobuf:"varint,7,opt,name=dbytes,proto3" json:"dbytes,omitempty"`
+	Dios                 uint64   `protobuf:"varint,8,opt,name=dios,proto3" json:"dios,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -492,82 +497,84 @@ func init() {
 }
 
 var fileDescriptor_2fc6005842049e6b = []byte{
-	// 1198 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0x4d, 0x73, 0xd4, 0x46,
-	0x13, 0x66, 0xed, 0xc5, 0xeb, 0xed, 0xb5, 0xc1, 0x0c, 0x86, 0x57, 0xc0, 0xcb, 0xda, 0x5e, 0x02,
-	0x45, 0xaa, 0x92, 0xdd, 0x94, 0xf3, 0x55, 0x49, 0x91, 0x4a, 0x19, 0x02, 0x45, 0x8a, 0x10, 0x5c,
-	0x02, 0x57, 0x8e, 0xaa, 0x59, 0x69, 0x2c, 0x0d, 0x96, 0x34, 0xaa, 0x99, 0x91, 0x1d, 0x73, 0xca,
-	0x21, 0xd7, 0x54, 0x7e, 0x4d, 0xfe, 0x03, 0xb7, 0xe4, 0x98, 0x53, 0x2a, 0xf8, 0x97, 0xa4, 0xba,
-	0x67, 0x64, 0x29, 0x07, 0x43, 0x6e, 0xd3, 0x4f, 0x3f, 0xdd, 0xea, 0x8f, 0x99, 0x6e, 0xc1, 0x27,
-	0xa9, 0xb4, 0x59, 0x3d, 0x9f, 0xc6, 0xaa, 0x98, 0xc5, 0xaa, 0xb4, 0x5c, 0x96, 0x42, 0x27, 0xb3,
-	0x38, 0xd5, 0xaa, 0xae, 0xcc, 0xec, 0x70, 0x7b, 0x66, 0x2c, 0xb7, 0x66, 0x56, 0x08, 0xab, 0x65,
-	0x6c, 0xa6, 0x95, 0x56, 0x56, 0xb1, 0x40, 0xaa, 0x69, 0xcb, 0x9e, 0x7a, 0xf6, 0xf4, 0x70, 0xfb,
-	0xfa, 0x7a, 0xaa, 0x52, 0x45, 0xa4, 0x19, 0x9e, 0x1c, 0x7f, 0xf2, 0xdb, 0x22, 0x0c, 0x9e, 0x3a,
-	0x0f, 0xec, 0x33, 0xe8, 0x57, 0x32, 0x31, 0x41, 0x6f, 0xb3, 0x77, 0x77, 0xb4, 0x3d, 0x99, 0x9e,
-	0xe5, 0x6a, 0xba, 0x2b, 0x13, 0xf3, 0xdc, 0x72, 0x1b, 0x12, 0x9f, 0xdd, 0x83, 0xc5, 0xb8, 0xaa,
-	0x83, 0x05, 0x32, 0xdb, 0x3a, 0xdb, 0xec, 0xc1, 0xee, 0x1e, 0x5a, 0xdd, 0x1f, 0x9c, 0xfc, 0xb5,
-	0xb1, 0xf8, 0x60, 0x77, 0x2f, 0x44, 0x33, 0x76, 0x0f, 0x96, 0x0a, 0x51, 0x28, 0x7d, 0x1c, 0xf4,
-	0xc9, 0xc1, 0x7b, 0x67, 0x3b, 0x78, 0x4a, 0x3c, 0xfa, 0xb2, 0xb7, 0xc1, 0x98, 0x75, 0x52, 0xf0,
-	0xe0, 0xfc, 0xbb, 0x62, 0x0e, 0x93, 0x82, 0xbb, 0x98, 0x91, 0xcf, 0x3e, 0x82, 0x05, 0xa9, 0x82,
-	0x25, 0xb2, 0xda, 0x3c, 0xdb, 0xea, 0xdb, 0x67, 0x64, 0xb3, 0x20, 0x15, 0xfb, 0x1a, 0x06, 0x59,
-	0x9d, 0x0a, 0x9b, 0xcf, 0x83, 0xc1, 0xe6, 0xe2, 0xdd, 0xd1, 0xf6, 0xed, 0xb3, 0xcd, 0x1e, 0xd7,
-	0xa9, 0x78, 0x91, 0xcf, 0xc9, 0xb6, 0xb1, 0x62, 0x4f, 0x60, 0xd5, 0x05, 0x1d, 0x89, 0x43, 0x51,
-	0x5a, 0x13, 0x2c, 0xd3, 0xd7, 0xef, 0xbc, 0x2b, 0xdf, 0x87, 0xc4, 0x0e, 0x57, 0x8a, 0x8e, 0x34,
-	0xf9, 0x12, 0x96, 0x9b, 0x2e, 0xb0, 0x00, 0x06, 0x71, 0xad, 0xb5, 0x28, 0x2d, 0xb5, 0xae, 0x1f,
-	0x36, 0x22, 0x5b, 0x87, 0xf3, 0xb9, 0x2c, 0xa4, 0xa5, 0xde, 0xf4, 0x43, 0x27, 0x4c, 0x7e, 0xef,
-	0xc1, 0xc0, 0xf7, 0x82, 0xdd, 0x04, 0xa8, 0x0d, 0x4f, 0x45, 0x54, 0x1b, 0x11, 0x7b, 0xf3, 0x21,
-	0x21, 0x7b, 0x46, 0xc4, 0xec, 0x06, 0x0c, 0x6b, 0x23, 0xb4, 0xd3, 0x3a, 0x27, 0xcb, 0x08, 0x90,
-	0x72, 0x03, 0x46, 0xe6, 0xd8, 0x58, 0x51, 0x38, 0xf5, 0x22, 0xa9, 0xc1, 0x41, 0x44, 0xb8, 0x09,
-	0x50, 0xea, 0xa8, 0x12, 0x5a, 0xaa, 0xc4, 0x50, 0x7b, 0xfb, 0xe1, 0xb0, 0xd4, 0xbb, 0x0e, 0x60,
-	0x5b, 0xb0, 0x52, 0xea, 0xc8, 0x66, 0x5a, 0x59, 0x9b, 0x8b, 0x84, 0x7a, 0xd8, 0x0f, 0x47, 0xa5,
-	0x7e, 0xd1, 0x40, 0xec, 0x36, 0x5c, 0x38, 0xd5, 0xbb, 0xaf, 0x2c, 0x11, 0x69, 0xf5, 0x14, 0xc5,
-	0x0f, 0x4d, 0x7e, 0x1d, 0x02, 0xb4, 0x97, 0x83, 0x31, 0xe8, 0xf3, 0x52, 0x95, 0x3e, 0x1d, 0x3a,
-	0x23, 0xb6, 0x2f, 0x73, 0xe1, 0x93, 0xa0, 0x33, 0x06, 0x70, 0x20, 0x74, 0x29, 0xf2, 0xc8, 0x58,
-	0x1e, 0x1f, 0xf8, 0x0c, 0x46, 0x0e, 0x7b, 0x8e, 0x10, 0x9a, 0x99, 0x9c, 0xcf, 0x7d, 0xf0, 0x74,
-	0x26, 0x4c, 0xc5, 0x07, 0x3e, 0x5e, 0x3a, 0x63, 0xa5, 0x4d, 0x56, 0x88, 0xc2, 0xc7, 0xe7, 0x04,
-	0xac, 0x10, 0x7e, 0x28, 0x2a, 0x78, 0x55, 0x89, 0x24, 0x18, 0xb8, 0x0a, 0x21, 0xf4, 0x94, 0x10,
-	0xac, 0x10, 0x11, 0x12, 0xa9, 0xed, 0x31, 0x5d, 0x88, 0x7e, 0x38, 0x44, 0xe4, 0x1b, 0x04, 0x30,
-	0x7d, 0x52, 0x1f, 0x69, 0x69, 0xc5, 0x1c, 0x43, 0x1c, 0xba, 0xf4, 0x11, 0xfd, 0xa1, 0x01, 0xd9,
-	0x35, 0x58, 0xc6, 0x1c, 0x23, 0x9b, 0x55, 0x01, 0xb8, 0x1b, 0x80, 0xf2, 0x8b, 0xac, 0x62, 0xb7,
-	0x60, 0x55, 0x96, 0x3c, 0xb6, 0xf2, 0x50, 0x44, 0x54, 0x93, 0x11, 0xe9, 0x57, 0x1a, 0x70, 0x07,
-	0x6b, 0xb3, 0x01, 0xa3, 0x2e, 0x65, 0xc5, 0x85, 0xd9, 0x21, 0x74, 0xbd, 0x50, 0x15, 0x57, 0xff,
-	0xed, 0xe5, 0x11, 0x56, 0xb3, 0xf5, 0x42, 0x94, 0x0b, 0x5d, 0x2f, 0x44, 0xd8, 0x84, 0x51, 0x5d,
-	0x8a, 0x43, 0x19, 0x5b, 0x3e, 0xcf, 0x45, 0x70, 0xd1, 0x55, 0xbb, 0x03, 0xb1, 0xf7, 0x61, 0x0d,
-	0x2b, 0x1c, 0x69, 0x11, 0xe7, 0x5c, 0x16, 0x44, 0x5b, 0x23, 0xda, 0x45, 0xc4, 0xc3, 0x16, 0x66,
-	0x1f, 0x02, 0x23, 0x6a, 0x5d, 0x76, 0xc9, 0x97, 0x88, 0x7c, 0x09, 0x35, 0x7b, 0x5d, 0x05, 0xbe,
-	0x91, 0x2a, 0xdd, 0xe7, 0x75, 0x6e, 0x03, 0xe6, 0x2a, 0xe4, 0x45, 0x36, 0x06, 0xa8, 0xd2, 0x82,
-	0xbf, 0x74, 0xca, 0xcb, 0x2e, 0xea, 0x16, 0xc1, 0x0f, 0x1d, 0x29, 0x7d, 0x20, 0xcb, 0xd4, 0x08,
-	0x1b, 0x69, 0xe1, 0x78, 0xeb, 0xee, 0x43, 0xad, 0x26, 0x74, 0x0a, 0x36, 0x83, 0xcb, 0x1d, 0x3a,
-	0x65, 0xcf, 0xad, 0x08, 0xae, 0x10, 0xbf, 0xe3, 0x69, 0xc7, 0x6b, 0xd8, 0xa7, 0x70, 0xb5, 0x63,
-	0x50, 0xaa, 0x44, 0xf8, 0xb8, 0x83, 0xab, 0x64, 0x73, 0xa5, 0xd5, 0x7e, 0xdf, 0x2a, 0xd9, 0x75,
-	0x58, 0xae, 0x52, 0x2d, 0xf6, 0x65, 0x9e, 0x07, 0xff, 0x73, 0x0f, 0xb3, 0x91, 0xd9, 0x55, 0x58,
-	0xaa, 0x52, 0x13, 0xf3, 0x32, 0x08, 0x48, 0xe3, 0x25, 0x57, 0x04, 0x63, 0x05, 0xcf, 0x83, 0x6b,
-	0x4d, 0x11, 0x48, 0x74, 0x45, 0x38, 0x0d, 0xf6, 0x7a, 0x53, 0x84, 0x06, 0x61, 0x13, 0x58, 0xa9,
-	0xd2, 0x44, 0x9c, 0x32, 0x6e, 0xb8, 0xfe, 0x77, 0x31, 0xe7, 0x23, 0xe7, 0xaf, 0x8e, 0xf7, 0xb5,
-	0x10, 0xc1, 0xff, 0x1b, 0x1f, 0x0d, 0x82, 0xed, 0x6f, 0xa5, 0x24, 0xb8, 0xe9, 0xda, 0xdf, 0x81,
-	0xd8, 0x1d, 0xb8, 0x68, 0xb3, 0x2a, 0xa2, 0x42, 0x46, 0x3c, 0xcf, 0x55, 0x1c, 0x8c, 0x9b, 0xe7,
-	0x5e, 0x3d, 0x42, 0x74, 0x07, 0x41, 0xf6, 0x01, 0x30, 0xe4, 0xc5, 0x2a, 0xcf, 0x79, 0x65, 0x84,
-	0xa7, 0x6e, 0x10, 0x75, 0xcd, 0x66, 0xd5, 0x03, 0xaf, 0x70, 0xec, 0x75, 0x38, 0x4f, 0x03, 0x2d,
-	0xd8, 0x74, 0x4f, 0x93, 0x04, 0xbc, 0xad, 0x6e, 0xf0, 0xb9, 0x01, 0xb9, 0xe5, 0xc2, 0x25, 0xe8,
-	0x3b, 0x44, 0xf0, 0x69, 0x9a, 0x23, 0x5e, 0x45, 0xce, 0x76, 0xe2, 0x9e, 0x26, 0x22, 0x7b, 0x64,
-	0xdf, 0xa8, 0x9d, 0xf9, 0xad, 0x56, 0x4d, 0xd6, 0x13, 0x03, 0x2b, 0xdd, 0xe9, 0xcd, 0xd6, 0x60,
-	0x31, 0x57, 0x47, 0x7e, 0x22, 0xe1, 0x11, 0xa7, 0x48, 0x26, 0xd3, 0xac, 0x19, 0x48, 0x78, 0x46,
-	0x56, 0xc1, 0x7f, 0xf4, 0x73, 0x08, 0x8f, 0x88, 0x28, 0x55, 0xf8, 0xf1, 0x83, 0x47, 0x7c, 0xec,
-	0x4a, 0x15, 0xd1, 0x01, 0x36, 0xde, 0x4d, 0xa0, 0x81, 0x52, 0xc5, 0x13, 0x99, 0xe7, 0x93, 0x9f,
-	0x7b, 0xb0, 0xdc, 0xec, 0x39, 0xf6, 0x55, 0x77, 0x2b, 0xe0, 0xbe, 0xba, 0xf5, 0xf6, 0xe5, 0xf8,
-	0xb0, 0xb4, 0xfa, 0xb8, 0x5d, 0x1d, 0x5f, 0xb4, 0xab, 0xe3, 0x3f, 0x1b, 0xfb, 0xfd, 0x22, 0x60,
-	0x78, 0x8a, 0xe1, 0x5d, 0x4c, 0xf0, 0x81, 0x0b, 0xca, 0x7d, 0x18, 0x7a, 0x09, 0xeb, 0x9f, 0xc5,
-	0x3c, 0xca, 0x78, 0x99, 0xe4, 0xc2, 0x50, 0x15, 0x56, 0x43, 0xc8, 0x62, 0xfe, 0xd8, 0x21, 0x0d,
-	0x41, 0xcd, 0x5f, 0x8a, 0xd8, 0x1a, 0xaa, 0x89, 0x23, 0x3c, 0x73, 0xc8, 0x64, 0x07, 0x96, 0xdc,
-	0x7a, 0x66, 0x9f, 0x37, 0x1d, 0x76, 0x89, 0x6e, 0xbd, 0x6d, 0x9f, 0xfb, 0x48, 0x89, 0x3f, 0xf9,
-	0xa5, 0x07, 0x03, 0x0f, 0xe1, 0x35, 0x29, 0xf8, 0x4b, 0xa5, 0x7d, 0x8f, 0x9c, 0x40, 0xa8, 0x2c,
-	0x95, 0x6e, 0x36, 0x28, 0x09, 0x98, 0x94, 0x9e, 0x1f, 0x5b, 0x61, 0x7c, 0xab, 0xbc, 0x84, 0xf8,
-	0x91, 0xc3, 0x5d, 0xc3, 0xbc, 0x84, 0xbd, 0xd6, 0x52, 0x99, 0x66, 0x63, 0xe0, 0x19, 0xb1, 0x23,
-	0xc4, 0xdc, 0xc2, 0xa0, 0xf3, 0x64, 0x0f, 0x46, 0x9d, 0x5f, 0x87, 0xb7, 0x2c, 0x76, 0x7f, 0x51,
-	0x16, 0xda, 0x8b, 0x82, 0xf3, 0x80, 0xa7, 0xc2, 0xc8, 0x57, 0x82, 0x82, 0x1a, 0x86, 0xa7, 0xf2,
-	0xfd, 0xe0, 0xf5, 0x9b, 0xf1, 0xb9, 0x3f, 0xdf, 0x8c, 0xcf, 0xfd, 0x74, 0x32, 0xee, 0xbd, 0x3e,
-	0x19, 0xf7, 0xfe, 0x38, 0x19, 0xf7, 0xfe, 0x3e, 0x19, 0xf7, 0xe6, 0x4b, 0xf4, 0x17, 0xf8, 0xf1,
-	0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x4f, 0x2b, 0x30, 0xd6, 0x6d, 0x0a, 0x00, 0x00,
+	// 1226 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0xcd, 0x6e, 0xdc, 0x36,
+	0x10, 0xce, 0x7a, 0x37, 0x5e, 0x2f, 0xd7, 0x4e, 0x1c, 0xc6, 0x49, 0x99, 0xbf, 0xb5, 0xbd, 0x69,
+	0x82, 0x14, 0x68, 0x77, 0x0b, 0xf7, 0x0f, 0x2d, 0x52, 0x14, 0x4e, 0x9a, 0x20, 0x45, 0x9a, 0xc6,
+	0x50, 0x62, 0xf4, 0x28, 0x70, 0x25, 0x5a, 0x62, 0x2c, 0x89, 0x02, 0x49, 0xd9, 0xdd, 0x9c, 0x7a,
+	0xe8, 0xbd, 0xcf, 0xd3, 0x37, 0xc8, 0xad, 0x05, 0x7a, 0xe9, 0xa9, 0x68, 0xfc, 0x24, 0xc5, 0x0c,
+	0xa9, 0x95, 0x7a, 0xb0, 0xd3, 0xdb, 0xcc, 0x37, 0xdf, 0x8c, 0xe6, 0x87, 0xe4, 0x88, 0x7c, 0x9a,
+	0x48, 0x9b, 0x56, 0xb3, 0x49, 0xa4, 0xf2, 0x69, 0xa4, 0x0a, 0xcb, 0x65, 0x21, 0x74, 0x3c, 0x8d,
+	0x12, 0xad, 0xaa, 0xd2, 0x4c, 0x8f, 0x76, 0xa6, 0xc6, 0x72, 0x6b, 0xa6, 0xb9, 0xb0, 0x5a, 0x46,
+	0x66, 0x52, 0x6a, 0x65, 0x15, 0x65, 0x52, 0x4d, 0x1a, 0xf6, 0xc4, 0xb3, 0x27, 0x47, 0x3b, 0xd7,
+	0x37, 0x12, 0x95, 0x28, 0x24, 0x4d, 0x41, 0x72, 0xfc, 0xf1, 0x9f, 0x5d, 0xd2, 0x7f, 0xe6, 0x22,
+	0xd0, 0xcf, 0x49, 0xaf, 0x94, 0xb1, 0x61, 0x9d, 0xad, 0xce, 0xbd, 0xe1, 0xce, 0x78, 0x72, 0x5a,
+	0xa8, 0xc9, 0x9e, 0x8c, 0xcd, 0x0b, 0xcb, 0x6d, 0x80, 0x7c, 0x7a, 0x9f, 0x74, 0xa3, 0xb2, 0x62,
+	0x4b, 0xe8, 0xb6, 0x7d, 0xba, 0xdb, 0xc3, 0xbd, 0x7d, 0xf0, 0x7a, 0xd0, 0x3f, 0xf9, 0x7b, 0xb3,
+	0xfb, 0x70, 0x6f, 0x3f, 0x00, 0x37, 0x7a, 0x9f, 0x2c, 0xe7, 0x22, 0x57, 0x7a, 0xce, 0x7a, 0x18,
+	0xe0, 0xfd, 0xd3, 0x03, 0x3c, 0x43, 0x1e, 0x7e, 0xd9, 0xfb, 0x40, 0xce, 0x3a, 0xce, 0x39, 0x3b,
+	0xff, 0xae, 0x9c, 0x83, 0x38, 0xe7, 0x2e, 0x67, 0xe0, 0xd3, 0x8f, 0xc9, 0x92, 0x54, 0x6c, 0x19,
+	0xbd, 0xb6, 0x4e, 0xf7, 0xfa, 0xee, 0x39, 0xfa, 0x2c, 0x49, 0x45, 0xbf, 0x21, 0xfd, 0xb4, 0x4a,
+	0x84, 0xcd, 0x66, 0xac, 0xbf, 0xd5, 0xbd, 0x37, 0xdc, 0xb9, 0x73, 0xba, 0xdb, 0x93, 0x2a, 0x11,
+	0x2f, 0xb3, 0x19, 0xfa, 0xd6, 0x5e, 0xf4, 0x29, 0x59, 0x73, 0x49, 0x87, 0xe2, 0x48, 0x14, 0xd6,
+	0xb0, 0x15, 0xfc, 0xfa, 0xdd, 0x77, 0xd5, 0xfb, 0x08, 0xd9, 0xc1, 0x6a, 0xde, 0xd2, 0xe8, 0x4d,
+	0x32, 0xb0, 0x32, 0x17, 0xc6, 0xf2, 0xbc, 0x64, 0x83, 0xad, 0xce, 0xbd, 0x5e, 0xd0, 0x00, 0xe3,
+	0xaf, 0xc8, 0x4a, 0x3d, 0x23, 0xca, 0x48, 0x3f, 0xaa, 0xb4, 0x16, 0x85, 0xc5, 0xc1, 0xf6, 0x82,
+	0x5a, 0xa5, 0x1b, 0xe4, 0x7c, 0x26, 0x73, 0x69, 0x71, 0x72, 0xbd, 0xc0, 0x29, 0xe3, 0xdf, 0x3b,
+	0xa4, 0xef, 0x27, 0x45, 0x6f, 0x11, 0x52, 0x19, 0x9e, 0x88, 0xb0, 0x32, 0x22, 0xf2, 0xee, 0x03,
+	0x44, 0xf6, 0x8d, 0x88, 0xe8, 0x0d, 0x32, 0xa8, 0x8c, 0xd0, 0xce, 0xea, 0x82, 0xac, 0x00, 0x80,
+	0xc6, 0x4d, 0x32, 0x34, 0x73, 0x63, 0x45, 0xee, 0xcc, 0x5d, 0x34, 0x13, 0x07, 0x21, 0xe1, 0x16,
+	0x21, 0x85, 0x0e, 0x4b, 0xa1, 0xa5, 0x8a, 0x0d, 0x0e, 0xbf, 0x17, 0x0c, 0x0a, 0xbd, 0xe7, 0x00,
+	0xba, 0x4d, 0x56, 0x0b, 0x1d, 0xda, 0x54, 0x2b, 0x6b, 0x33, 0x11, 0xe3, 0x84, 0x7b, 0xc1, 0xb0,
+	0xd0, 0x2f, 0x6b, 0x88, 0xde, 0x21, 0x17, 0x16, 0x76, 0xf7, 0x95, 0x65, 0x24, 0xad, 0x2d, 0x50,
+	0xf8, 0xd0, 0xf8, 0xd7, 0x01, 0x21, 0xcd, 0xd1, 0xa1, 0x94, 0xf4, 0x78, 0xa1, 0x0a, 0x5f, 0x0e,
+	0xca, 0x80, 0x1d, 0xc8, 0x4c, 0xf8, 0x22, 0x50, 0x86, 0x04, 0x0e, 0x85, 0x2e, 0x44, 0x16, 0x1a,
+	0xcb, 0xa3, 0x43, 0x5f, 0xc1, 0xd0, 0x61, 0x2f, 0x00, 0x02, 0x37, 0x93, 0xf1, 0x99, 0x4f, 0x1e,
+	0x65, 0xc4, 0x54, 0x74, 0xe8, 0xf3, 0x45, 0x19, 0x3a, 0x6d, 0xd2, 0x5c, 0xe4, 0x3e, 0x3f, 0xa7,
+	0x40, 0x87, 0xe0, 0x43, 0x61, 0xce, 0xcb, 0x52, 0xc4, 0xac, 0xef, 0x3a, 0x04, 0xd0, 0x33, 0x44,
+	0xa0, 0x43, 0x48, 0x88, 0xa5, 0xb6, 0x73, 0x3c, 0x2e, 0xbd, 0x60, 0x00, 0xc8, 0xb7, 0x00, 0x40,
+	0xf9, 0x68, 0x3e, 0xd6, 0xd2, 0x8a, 0x19, 0xa4, 0xe8, 0x0e, 0xc2, 0x1a, 0xa0, 0x3f, 0xd6, 0x20,
+	0xbd, 0x46, 0x56, 0xa0, 0xc6, 0xd0, 0xa6, 0x25, 0x23, 0xee, 0x04, 0x80, 0xfe, 0x32, 0x2d, 0xe9,
+	0x6d, 0xb2, 0x26, 0x0b, 0x1e, 0x59, 0x79, 0x24, 0x42, 0xec, 0xc9, 0x10, 0xed, 0xab, 0x35, 0xb8,
+	0x0b, 0xbd, 0xd9, 0x24, 0xc3, 0x36, 0x65, 0xd5, 0xa5, 0xd9, 0x22, 0xb4, 0xa3, 0x60, 0x17, 0xd7,
+	0xfe, 0x1b, 0xe5, 0x31, 0x74, 0xb3, 0x89, 0x82, 0x94, 0x0b, 0xed, 0x28, 0x48, 0xd8, 0x22, 0xc3,
+	0xaa, 0x10, 0x47, 0x32, 0xb2, 0x7c, 0x96, 0x09, 0x76, 0xd1, 0x75, 0xbb, 0x05, 0xd1, 0x0f, 0xc8,
+	0x3a, 0x74, 0x38, 0xd4, 0x22, 0xca, 0xb8, 0xcc, 0x91, 0xb6, 0x8e, 0xb4, 0x8b, 0x80, 0x07, 0x0d,
+	0x4c, 0x3f, 0x22, 0x14, 0xa9, 0x55, 0xd1, 0x26, 0x5f, 0x42, 0xf2, 0x25, 0xb0, 0xec, 0xb7, 0x0d,
+	0x70, 0x47, 0xca, 0xe4, 0x80, 0x57, 0x99, 0x65, 0xd4, 0x75, 0xc8, 0xab, 0x74, 0x44, 0x48, 0x99,
+	0xe4, 0xfc, 0x95, 0x33, 0x5e, 0x76, 0x59, 0x37, 0x08, 0x7c, 0xe8, 0x58, 0xe9, 0x43, 0x59, 0x24,
+	0x46, 0xd8, 0x50, 0x0b, 0xc7, 0xdb, 0x70, 0x1f, 0x6a, 0x2c, 0x81, 0x33, 0xd0, 0x29, 0xb9, 0xdc,
+	0xa2, 0x63, 0xf5, 0xdc, 0x0a, 0x76, 0x05, 0xf9, 0xad, 0x48, 0xbb, 0xde, 0x42, 0x3f, 0x23, 0x57,
+	0x5b, 0x0e, 0x85, 0x8a, 0x85, 0xcf, 0x9b, 0x5d, 0x45, 0x9f, 0x2b, 0x8d, 0xf5, 0x87, 0xc6, 0x48,
+	0xaf, 0x93, 0x95, 0x32, 0xd1, 0xe2, 0x40, 0x66, 0x19, 0x7b, 0xcf, 0x5d, 0xcc, 0x5a, 0xa7, 0x57,
+	0xc9, 0x72, 0x99, 0x98, 0x88, 0x17, 0x8c, 0xa1, 0xc5, 0x6b, 0xae, 0x09, 0xc6, 0x0a, 0x9e, 0xb1,
+	0x6b, 0x75, 0x13, 0x50, 0x75, 0x4d, 0x58, 0x24, 0x7b, 0xbd, 0x6e, 0x42, 0x8d, 0xd0, 0x31, 0x59,
+	0x2d, 0x93, 0x58, 0x2c, 0x18, 0x37, 0xdc, 0xfc, 0xdb, 0x98, 0x8b, 0x91, 0xf1, 0xd7, 0xf3, 0x03,
+	0x2d, 0x04, 0xbb, 0x59, 0xc7, 0xa8, 0x11, 0x18, 0x7f, 0xa3, 0xc5, 0xec, 0x96, 0x1b, 0x7f, 0x0b,
+	0xa2, 0x77, 0xc9, 0x45, 0x9b, 0x96, 0x21, 0x36, 0x32, 0xe4, 0x59, 0xa6, 0x22, 0x36, 0xaa, 0xaf,
+	0x7b, 0xf9, 0x18, 0xd0, 0x5d, 0x00, 0xe9, 0x87, 0x84, 0x02, 0x2f, 0x52, 0x59, 0xc6, 0x4b, 0x23,
+	0x3c, 0x75, 0x13, 0xa9, 0xeb, 0x36, 0x2d, 0x1f, 0x7a, 0x83, 0x63, 0x6f, 0x90, 0xf3, 0xf8, 0xa0,
+	0xb1, 0x2d, 0x77, 0x35, 0x51, 0x81, 0xd3, 0x8a, 0x42, 0xe8, 0x1e, 0xc8, 0x6d, 0x97, 0x2e, 0x42,
+	0xdf, 0x03, 0x02, 0x57, 0xd3, 0x1c, 0xf3, 0x32, 0x74, 0xbe, 0x63, 0x77, 0x35, 0x01, 0xd9, 0x47,
+	0xff, 0xda, 0xec, 0xdc, 0x6f, 0x37, 0x66, 0xf4, 0x1e, 0x1b, 0xb2, 0xda, 0x7e, 0xdb, 0xe9, 0x3a,
+	0xe9, 0x66, 0xea, 0xd8, 0xbf, 0x48, 0x20, 0xc2, 0x2b, 0x92, 0xca, 0x24, 0xad, 0x1f, 0x24, 0x90,
+	0x81, 0x95, 0xf3, 0x9f, 0xfc, 0x3b, 0x04, 0x22, 0x20, 0x4a, 0xe5, 0xfe, 0xf9, 0x01, 0x11, 0x2e,
+	0xbb, 0x52, 0x79, 0x78, 0x08, 0x83, 0x77, 0x2f, 0x50, 0x5f, 0xa9, 0xfc, 0xa9, 0xcc, 0xb2, 0xf1,
+	0x2f, 0x1d, 0xb2, 0x52, 0x6f, 0x41, 0xfa, 0x75, 0x7b, 0x2b, 0xc0, 0x36, 0xbb, 0x7d, 0xf6, 0xea,
+	0x7c, 0x54, 0x58, 0x3d, 0x6f, 0x56, 0xc7, 0x97, 0xcd, 0xea, 0xf8, 0xdf, 0xce, 0x7e, 0xbf, 0x08,
+	0x32, 0x58, 0x60, 0x70, 0x16, 0x63, 0xb8, 0xe0, 0x02, 0x6b, 0x1f, 0x04, 0x5e, 0x83, 0xfe, 0xa7,
+	0x11, 0x0f, 0x53, 0x5e, 0xc4, 0x99, 0x30, 0xd8, 0x85, 0xb5, 0x80, 0xa4, 0x11, 0x7f, 0xe2, 0x90,
+	0x9a, 0xa0, 0x66, 0xaf, 0x44, 0x64, 0x0d, 0xeb, 0x2e, 0x08, 0xcf, 0x1d, 0x32, 0xde, 0x25, 0xcb,
+	0x6e, 0x79, 0xd3, 0x2f, 0xea, 0x09, 0xbb, 0x42, 0xb7, 0xcf, 0xda, 0xf6, 0x3e, 0x53, 0xe4, 0x8f,
+	0x7f, 0xeb, 0x90, 0xbe, 0x87, 0xe0, 0x98, 0xe4, 0xfc, 0x95, 0xd2, 0x7e, 0x46, 0x4e, 0x41, 0x54,
+	0x16, 0x4a, 0xd7, 0x1b, 0x14, 0x15, 0x28, 0x4a, 0xcf, 0xe6, 0x56, 0x18, 0x3f, 0x2a, 0xaf, 0x01,
+	0x7e, 0xec, 0x70, 0x37, 0x30, 0xaf, 0xc1, 0xac, 0xb5, 0x54, 0xa6, 0xde, 0x18, 0x20, 0x03, 0x76,
+	0x0c, 0x98, 0x5b, 0x18, 0x28, 0x63, 0xb3, 0x9c, 0xbf, 0x5b, 0x15, 0x5e, 0x03, 0x6e, 0x0c, 0x5c,
+	0xb7, 0x20, 0x50, 0x1e, 0xef, 0x93, 0x61, 0xeb, 0x27, 0xe4, 0x8c, 0x9f, 0x00, 0x7f, 0xa8, 0x96,
+	0x9a, 0x43, 0x05, 0x6f, 0x07, 0x4f, 0x84, 0x91, 0xaf, 0x05, 0x16, 0x30, 0x08, 0x16, 0xfa, 0x03,
+	0xf6, 0xe6, 0xed, 0xe8, 0xdc, 0x5f, 0x6f, 0x47, 0xe7, 0x7e, 0x3e, 0x19, 0x75, 0xde, 0x9c, 0x8c,
+	0x3a, 0x7f, 0x9c, 0x8c, 0x3a, 0xff, 0x9c, 0x8c, 0x3a, 0xb3, 0x65, 0xfc, 0x9f, 0xfc, 0xe4, 0xdf,
+	0x01, 0x00, 0x7f, 0x23, 0x7a, 0x76, 0xb7, 0x0a, 0x00, 0x00,
 }
 
 func (m *Metrics) Marshal() (dAtA []byte, err error) {
@@ -657,6 +664,11 @@ func (m *Metrics) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n6
 	}
+	if m.Timestamp != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintMetrics(dAtA, i, uint64(m.Timestamp))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1187,6 +1199,16 @@ func (m *IOEntry) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintMetrics(dAtA, i, uint64(m.Wios))
 	}
+	if m.Dbytes != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintMetrics(dAtA, i, uint64(m.Dbytes))
+	}
+	if m.Dios != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintMetrics(dAtA, i, uint64(m.Dios))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1275,6 +1297,9 @@ func (m *Metrics) Size() (n int) {
 		l = m.MemoryEvents.Size()
 		n += 1 + l + sovMetrics(uint64(l))
 	}
+	if m.Timestamp != 0 {
+		n += 1 + sovMetrics(uint64(m.Timestamp))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1561,6 +1586,12 @@ func (m *IOEntry) Size() (n int) {
 	if m.Wios != 0 {
 		n += 1 + sovMetrics(uint64(m.Wios))
 	}
+	if m.Dbytes != 0 {
+		n += 1 + sovMetrics(uint64(m.Dbytes))
+	}
+	if m.Dios != 0 {
+		n += 1 + sovMetrics(uint64(m.Dios))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -1614,6 +1645,7 @@ func (this *Metrics) String() string {
 		`Io:` + strings.Replace(fmt.Sprintf("%v", this.Io), "IOStat", "IOStat", 1) + `,`,
 		`Hugetlb:` + strings.Replace(fmt.Sprintf("%v", this.Hugetlb), "HugeTlbStat", "HugeTlbStat", 1) + `,`,
 		`MemoryEvents:` + strings.Replace(fmt.Sprintf("%v", this.MemoryEvents), "MemoryEvents", "MemoryEvents", 1) + `,`,
+		`Timestamp:` + fmt.Sprintf("%v", this.Timestamp) + `,`,
 		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
 		`}`,
 	}, "")
@@ -1754,6 +1786,8 @@ func (this *IOEntry) String() string {
 		`Wbytes:` + fmt.Sprintf("%v", this.Wbytes) + `,`,
 		`Rios:` + fmt.Sprintf("%v", this.Rios) + `,`,
 		`Wios:` + fmt.Sprintf("%v", this.Wios) + `,`,
+		`Dbytes:` + fmt.Sprintf("%v", this.Dbytes) + `,`,
+		`Dios:` + fmt.Sprintf("%v", this.Dios) + `,`,
 		`XXX_unrecognized:` + fmt.Sprintf("%v", this.XXX_unrecognized) + `,`,
 		`}`,
 	}, "")
@@ -2059,6 +2093,25 @@ func (m *Metrics) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetrics(dAtA[iNdEx:])
@@ -3689,6 +3742,44 @@ func (m *IOEntry) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dbytes", wireType)
+			}
+			m.Dbytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Dbytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dios", wireType)
+			}
+			m.Dios = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMetrics
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Dios |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipMetrics(dAtA[iNdEx:])
@@ -0,0 +1,275 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// subscribeMask are the inotify events watched on every group directory
+const subscribeMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_DELETE_SELF | unix.IN_ONLYDIR
+
+// GroupEventType is the type of a GroupEvent
+type GroupEventType int
+
+const (
+	// GroupAdded is delivered when a group is created
+	GroupAdded GroupEventType = iota
+	// GroupRemoved is delivered when a group is removed
+	GroupRemoved
+)
+
+func (t GroupEventType) String() string {
+	switch t {
+	case GroupAdded:
+		return "added"
+	case GroupRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// GroupEvent is delivered by Subscribe when a group is created or removed
+type GroupEvent struct {
+	Type GroupEventType
+	// Group is the path of the group below the mountpoint
+	Group string
+	// Manager is a manager for the group, created with the options passed
+	// to Subscribe. For removed groups it only identifies the group.
+	Manager *Manager
+}
+
+// Subscribe watches the groups below the group root under the mountpoint
+// with inotify and delivers a GroupEvent whenever one is created or removed,
+// at any depth. Groups that already exist are delivered as added first so
+// that none are missed between a scan and the subscription.
+//
+// If the kernel drops events because the inotify queue overflowed an error
+// is delivered, and the caller has to subscribe again to resynchronize.
+// Both channels are closed once ctx is done, the root is removed or an error
+// was delivered.
+func Subscribe(ctx context.Context, mountpoint, root string, opts ...InitOpts) (<-chan GroupEvent, <-chan error) {
+	var (
+		ch    = make(chan GroupEvent)
+		errCh = make(chan error, 1)
+	)
+	s, err := newSubscription(mountpoint, root, opts)
+	if err != nil {
+		errCh <- err
+		close(ch)
+		close(errCh)
+		return ch, errCh
+	}
+	go func() {
+		defer close(errCh)
+		defer close(ch)
+		defer s.inotify.Close()
+		if err := s.run(ctx, ch); err != nil && ctx.Err() == nil {
+			errCh <- err
+		}
+	}()
+	return ch, errCh
+}
+
+// subscription tracks the watched group directories
+type subscription struct {
+	mountpoint string
+	root       string
+	config     *InitConfig
+	fd         int
+	inotify    *os.File
+	// groups maps watch descriptors to their group
+	groups map[int]string
+	known  map[string]bool
+	// pending are the events not yet delivered
+	pending []GroupEvent
+}
+
+func newSubscription(mountpoint, root string, opts []InitOpts) (*subscription, error) {
+	if err := VerifyGroupPath(root); err != nil {
+		return nil, err
+	}
+	config, err := newInitConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(filepath.Join(mountpoint, root)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrCgroupDeleted
+		}
+		return nil, err
+	}
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, errors.Wrap(err, "inotify init")
+	}
+	s := &subscription{
+		mountpoint: mountpoint,
+		root:       root,
+		config:     config,
+		fd:         fd,
+		// the fd is non-blocking so reads go through the runtime poller
+		// and honour read deadlines
+		inotify: os.NewFile(uintptr(fd), "inotify"),
+		groups:  make(map[int]string),
+		known:   make(map[string]bool),
+	}
+	if err := s.watch(root); err != nil {
+		s.inotify.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// watch adds watches for the group and every group below it, queueing them
+// as added except for the root
+func (s *subscription) watch(group string) error {
+	if s.known[group] {
+		return nil
+	}
+	dir := filepath.Join(s.mountpoint, group)
+	wd, err := unix.InotifyAddWatch(s.fd, dir, subscribeMask)
+	if err != nil {
+		if err == unix.ENOENT && group != s.root {
+			// removed before it could be watched, the parent reports it
+			return nil
+		}
+		return errors.Wrapf(err, "watch %q", dir)
+	}
+	s.groups[wd] = group
+	s.known[group] = true
+	if group != s.root {
+		s.queue(GroupAdded, group)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if err := s.watch(filepath.Join(group, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forget drops the group and every group below it, queueing the group as
+// removed
+func (s *subscription) forget(group string) {
+	if !s.known[group] {
+		return
+	}
+	for g := range s.known {
+		if g == group || strings.HasPrefix(g, group+"/") {
+			delete(s.known, g)
+		}
+	}
+	s.queue(GroupRemoved, group)
+}
+
+func (s *subscription) queue(typ GroupEventType, group string) {
+	s.pending = append(s.pending, GroupEvent{
+		Type:    typ,
+		Group:   group,
+		Manager: newManager(s.mountpoint, filepath.Join(s.mountpoint, group), s.config),
+	})
+}
+
+func (s *subscription) run(ctx context.Context, ch chan<- GroupEvent) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.inotify.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	buffer := make([]byte, (unix.SizeofInotifyEvent+unix.NAME_MAX+1)*16)
+	for {
+		for len(s.pending) > 0 {
+			select {
+			case ch <- s.pending[0]:
+				s.pending = s.pending[1:]
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		n, err := s.inotify.Read(buffer)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		removed, err := s.handle(buffer[:n])
+		if err != nil || removed {
+			return err
+		}
+	}
+}
+
+// handle processes the inotify events in buf, returning true once the root
+// was removed
+func (s *subscription) handle(buf []byte) (bool, error) {
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buf); {
+		event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		start := offset + unix.SizeofInotifyEvent
+		name := strings.TrimRight(string(buf[start:start+int(event.Len)]), "\x00")
+		offset = start + int(event.Len)
+
+		if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+			return false, errors.New("inotify queue overflowed, group events were lost")
+		}
+		parent, ok := s.groups[int(event.Wd)]
+		if !ok {
+			continue
+		}
+		switch {
+		case event.Mask&unix.IN_IGNORED != 0:
+			delete(s.groups, int(event.Wd))
+		case event.Mask&unix.IN_DELETE_SELF != 0:
+			if parent == s.root {
+				return true, nil
+			}
+		case event.Mask&unix.IN_ISDIR == 0:
+		case event.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+			if err := s.watch(filepath.Join(parent, name)); err != nil {
+				return false, err
+			}
+		case event.Mask&(unix.IN_DELETE|unix.IN_MOVED_FROM) != 0:
+			s.forget(filepath.Join(parent, name))
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,98 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-subscribe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := os.MkdirAll(filepath.Join(mountpoint, "root", "existing"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, errCh := Subscribe(ctx, mountpoint, "/root")
+
+	next := func() GroupEvent {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				t.Fatalf("subscription closed: %v", <-errCh)
+			}
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a group event")
+		}
+		return GroupEvent{}
+	}
+	expect := func(typ GroupEventType, group string) {
+		e := next()
+		if e.Type != typ || e.Group != group {
+			t.Fatalf("expected %s %s but received %s %s", typ, group, e.Type, e.Group)
+		}
+		if e.Manager.path != filepath.Join(mountpoint, group) {
+			t.Fatalf("unexpected manager path %q", e.Manager.path)
+		}
+	}
+
+	expect(GroupAdded, "/root/existing")
+	if err := os.Mkdir(filepath.Join(mountpoint, "root", "existing", "child"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	expect(GroupAdded, "/root/existing/child")
+	if err := os.Remove(filepath.Join(mountpoint, "root", "existing", "child")); err != nil {
+		t.Fatal(err)
+	}
+	expect(GroupRemoved, "/root/existing/child")
+	// files are not groups
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, "root", "cgroup.procs"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(mountpoint, "root", "new"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	expect(GroupAdded, "/root/new")
+
+	cancel()
+	for range ch {
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("expected no error after cancel but received %v", err)
+	}
+}
+
+func TestSubscribeMissingRoot(t *testing.T) {
+	ch, errCh := Subscribe(context.Background(), os.TempDir(), "/cgroups-subscribe-missing")
+	if _, ok := <-ch; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if err := <-errCh; err != ErrCgroupDeleted {
+		t.Fatalf("expected ErrCgroupDeleted but received %v", err)
+	}
+}
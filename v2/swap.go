@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// Swap exchanges the processes of the cgroup with those of other. Both
+// cgroups are frozen with RunFrozen while the processes are migrated, so
+// that none of them run or fork in the wrong cgroup halfway through, and
+// are thawed again once the swap completes or fails. If a process cannot be
+// moved the processes already moved are moved back before the error is
+// returned. Only the processes directly in each cgroup are swapped,
+// descendant cgroups stay where they are. A cgroup cannot be swapped with
+// itself.
+func (c *Manager) Swap(ctx context.Context, other *Manager) error {
+	if other == c || other.path == c.path {
+		return errors.Wrapf(ErrInvalidGroupPath, "swap %s with itself", c.path)
+	}
+	// the freezer states are locked in the order of the paths so that
+	// concurrent swaps of the same cgroups do not deadlock
+	first, second := c, other
+	if second.path < first.path {
+		first, second = second, first
+	}
+	first.stateMu.Lock()
+	defer first.stateMu.Unlock()
+	second.stateMu.Lock()
+	defer second.stateMu.Unlock()
+	return first.runFrozen(ctx, func() error {
+		return second.runFrozen(ctx, func() error {
+			ours, err := c.Procs(false)
+			if err != nil {
+				return err
+			}
+			theirs, err := other.Procs(false)
+			if err != nil {
+				return err
+			}
+			if moved, err := migrate(ours, other); err != nil {
+				return rollback(err, moved, c)
+			}
+			if moved, err := migrate(theirs, c); err != nil {
+				return rollback(rollback(err, moved, other), ours, c)
+			}
+			return nil
+		})
+	})
+}
+
+// migrate moves pids into c, skipping processes that have exited. It
+// returns the pids that were moved.
+func migrate(pids []uint64, c *Manager) ([]uint64, error) {
+	var moved []uint64
+	for _, pid := range pids {
+		if err := c.AddProc(pid); err != nil {
+			if errors.Is(err, unix.ESRCH) {
+				continue
+			}
+			return moved, err
+		}
+		moved = append(moved, pid)
+	}
+	return moved, nil
+}
+
+// rollback moves the pids back to c after the swap failed with err,
+// returning err along with any failure to move them back
+func rollback(err error, pids []uint64, c *Manager) error {
+	if _, rerr := migrate(pids, c); rerr != nil {
+		return errors.Wrapf(err, "cgroups: unable to roll back the swap: %v", rerr)
+	}
+	return err
+}
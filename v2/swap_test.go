@@ -0,0 +1,127 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/cgroups/cgroupstest"
+)
+
+func TestSwap(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	var (
+		managers [2]*Manager
+		cmds     [2]*exec.Cmd
+	)
+	for i, name := range []string{"blue", "green"} {
+		m, err := NewManager(sandbox.Mountpoint, filepath.Join(sandbox.Group, name), &Resources{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer m.Delete()
+		cmd := exec.Command("sleep", "60")
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer cmd.Wait()
+		defer cmd.Process.Kill()
+		if err := m.AddProc(uint64(cmd.Process.Pid)); err != nil {
+			t.Fatal(err)
+		}
+		managers[i], cmds[i] = m, cmd
+	}
+	if err := managers[0].Swap(context.Background(), managers[1]); err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range managers {
+		procs, err := m.Procs(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := uint64(cmds[1-i].Process.Pid); len(procs) != 1 || procs[0] != expected {
+			t.Fatalf("expected %s to contain %d after the swap, got %v", m.path, expected, procs)
+		}
+		if state, _ := fetchState(m.path); state != Thawed {
+			t.Fatalf("expected %s to be thawed, got %q", m.path, state)
+		}
+	}
+}
+
+func TestSwapRollback(t *testing.T) {
+	sandbox := cgroupstest.New(t)
+	defer sandbox.Close()
+
+	var (
+		managers [2]*Manager
+		pids     [2]uint64
+	)
+	// refuse moving the process of green into blue, after the process of
+	// blue was moved into green
+	deny := func(op *Operation, next func() error) error {
+		if op.Op == OpAddProc && managers[0] != nil && op.Path == managers[0].path && op.Pid == pids[1] {
+			return ErrPolicyViolation
+		}
+		return next()
+	}
+	for i, name := range []string{"blue", "green"} {
+		m, err := NewManager(sandbox.Mountpoint, filepath.Join(sandbox.Group, name), &Resources{}, WithInterceptors(deny))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer m.Delete()
+		cmd := exec.Command("sleep", "60")
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer cmd.Wait()
+		defer cmd.Process.Kill()
+		if err := m.AddProc(uint64(cmd.Process.Pid)); err != nil {
+			t.Fatal(err)
+		}
+		managers[i], pids[i] = m, uint64(cmd.Process.Pid)
+	}
+	if err := managers[0].Swap(context.Background(), managers[1]); err != ErrPolicyViolation {
+		t.Fatalf("expected the refused move to be returned, got %v", err)
+	}
+	for i, m := range managers {
+		procs, err := m.Procs(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(procs) != 1 || procs[0] != pids[i] {
+			t.Fatalf("expected %s to contain %d after the rollback, got %v", m.path, pids[i], procs)
+		}
+		if state, _ := fetchState(m.path); state != Thawed {
+			t.Fatalf("expected %s to be thawed, got %q", m.path, state)
+		}
+	}
+}
+
+func TestSwapSelf(t *testing.T) {
+	c := &Manager{path: "/sys/fs/cgroup/test"}
+	for _, other := range []*Manager{c, {path: c.path}} {
+		if err := c.Swap(context.Background(), other); KindOf(err) != KindInvalidInput {
+			t.Fatalf("expected swapping a cgroup with itself to be rejected, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+
+	systemdDbus "github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pkg/errors"
+)
+
+// SystemdProperties returns the unit properties that NewSystemd sets for the
+// resources, for callers that manage units over their own dbus connection.
+// Only the resource properties are returned, unit settings such as Slice,
+// PIDs or Delegate are left to the caller.
+func SystemdProperties(resources *Resources) []systemdDbus.Property {
+	var properties []systemdDbus.Property
+	if resources == nil {
+		return nil
+	}
+	if resources.Memory != nil && resources.Memory.Max != nil && *resources.Memory.Max != 0 {
+		properties = append(properties,
+			newSystemdProperty("MemoryMax", uint64(*resources.Memory.Max)))
+	}
+	if resources.CPU != nil && resources.CPU.Weight != nil && *resources.CPU.Weight != 0 {
+		properties = append(properties,
+			newSystemdProperty("CPUWeight", *resources.CPU.Weight))
+	}
+	if resources.CPU != nil && resources.CPU.Max != "" {
+		properties = append(properties,
+			newSystemdProperty("CPUQuotaPerSecUSec", cpuQuotaPerSecUSec(resources.CPU.Max)))
+	}
+	if resources.Pids != nil && resources.Pids.Max > 0 {
+		properties = append(properties,
+			newSystemdProperty("TasksAccounting", true),
+			newSystemdProperty("TasksMax", uint64(resources.Pids.Max)))
+	}
+	return properties
+}
+
+// RuncSystemdProperties returns the unit properties for the resources as
+// runc's systemd driver maps them for cgroup v2, as set by NewSystemd with
+// WithRuncCompat. A limit of -1 is converted to infinity like runc does.
+func RuncSystemdProperties(resources *Resources) []systemdDbus.Property {
+	var properties []systemdDbus.Property
+	if resources == nil {
+		return nil
+	}
+	if mem := resources.Memory; mem != nil {
+		if mem.Max != nil && *mem.Max != 0 {
+			properties = append(properties,
+				newSystemdProperty("MemoryMax", uint64(*mem.Max)))
+		}
+		if mem.Low != nil && *mem.Low != 0 {
+			properties = append(properties,
+				newSystemdProperty("MemoryLow", uint64(*mem.Low)))
+		}
+		if mem.Swap != nil && *mem.Swap != 0 {
+			properties = append(properties,
+				newSystemdProperty("MemorySwapMax", uint64(*mem.Swap)))
+		}
+	}
+	if cpu := resources.CPU; cpu != nil {
+		if cpu.Weight != nil && *cpu.Weight != 0 {
+			properties = append(properties,
+				newSystemdProperty("CPUWeight", *cpu.Weight))
+		}
+		if cpu.Max != "" {
+			properties = append(properties,
+				newSystemdProperty("CPUQuotaPerSecUSec", cpuQuotaPerSecUSec(cpu.Max)))
+		}
+	}
+	if pids := resources.Pids; pids != nil && (pids.Max > 0 || pids.Max == -1) {
+		properties = append(properties,
+			newSystemdProperty("TasksAccounting", true),
+			newSystemdProperty("TasksMax", uint64(pids.Max)))
+	}
+	return properties
+}
+
+// cpuQuotaPerSecUSec converts the cpu.max value into systemd's
+// CPUQuotaPerSecUSec
+func cpuQuotaPerSecUSec(max CPUMax) uint64 {
+	quota, period := max.extractQuotaAndPeriod()
+	// cpu.cfs_quota_us and cpu.cfs_period_us are controlled by systemd.
+	// corresponds to USEC_INFINITY in systemd
+	// if USEC_INFINITY is provided, CPUQuota is left unbound by systemd
+	// always setting a property value ensures we can apply a quota and remove it later
+	cpuQuotaPerSecUSec := uint64(math.MaxUint64)
+	if quota > 0 && quota != math.MaxInt64 && period > 0 {
+		// systemd converts CPUQuotaPerSecUSec (microseconds per CPU second) to CPUQuota
+		// (integer percentage of CPU) internally.  This means that if a fractional percent of
+		// CPU is indicated by Resources.CpuQuota, we need to round up to the nearest
+		// 10ms (1% of a second) such that child cgroups can set the cpu.cfs_quota_us they expect.
+		cpuQuotaPerSecUSec = uint64(quota*1000000) / period
+		if cpuQuotaPerSecUSec%10000 != 0 {
+			cpuQuotaPerSecUSec = ((cpuQuotaPerSecUSec / 10000) + 1) * 10000
+		}
+	}
+	return cpuQuotaPerSecUSec
+}
+
+// unitActive checks the state of a unit whose job result was not received
+func unitActive(conn *systemdDbus.Conn, unit string) error {
+	p, err := conn.GetUnitProperty(unit, "ActiveState")
+	if err != nil {
+		return err
+	}
+	state, _ := p.Value.Value().(string)
+	switch state {
+	case "active", "activating", "reloading":
+		return nil
+	}
+	return errors.Wrapf(ErrJobFailed, "unit %s is %s", unit, state)
+}
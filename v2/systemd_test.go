@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"math"
+	"testing"
+
+	systemdDbus "github.com/coreos/go-systemd/v22/dbus"
+)
+
+func propertyMap(properties []systemdDbus.Property) map[string]interface{} {
+	m := make(map[string]interface{}, len(properties))
+	for _, p := range properties {
+		m[p.Name] = p.Value.Value()
+	}
+	return m
+}
+
+func TestRuncSystemdProperties(t *testing.T) {
+	var (
+		max    int64  = 1 << 30
+		low    int64  = 1 << 29
+		swap   int64  = -1
+		weight uint64 = 100
+		period uint64 = 100000
+		quota  int64  = 50001
+	)
+	properties := propertyMap(RuncSystemdProperties(&Resources{
+		Memory: &Memory{Max: &max, Low: &low, Swap: &swap},
+		CPU:    &CPU{Weight: &weight, Max: NewCPUMax(&quota, &period)},
+		Pids:   &Pids{Max: -1},
+	}))
+	for name, expected := range map[string]interface{}{
+		"MemoryMax":          uint64(max),
+		"MemoryLow":          uint64(low),
+		"MemorySwapMax":      uint64(math.MaxUint64),
+		"CPUWeight":          weight,
+		"CPUQuotaPerSecUSec": uint64(510000),
+		"TasksAccounting":    true,
+		"TasksMax":           uint64(math.MaxUint64),
+	} {
+		if v := properties[name]; v != expected {
+			t.Errorf("%s: expected %v but received %v", name, expected, v)
+		}
+	}
+
+	// the default mapping does not set the runc only properties
+	properties = propertyMap(SystemdProperties(&Resources{
+		Memory: &Memory{Low: &low},
+		Pids:   &Pids{Max: -1},
+	}))
+	if len(properties) != 0 {
+		t.Fatalf("expected no properties but received %v", properties)
+	}
+}
+
+func TestSystemdPropertiesNilResources(t *testing.T) {
+	if properties := SystemdProperties(nil); properties != nil {
+		t.Fatalf("expected no properties but received %v", properties)
+	}
+}
+
+func TestCPUQuotaPerSecUSec(t *testing.T) {
+	period := uint64(100000)
+	if v := cpuQuotaPerSecUSec(NewCPUMax(nil, &period)); v != math.MaxUint64 {
+		t.Fatalf("expected infinity for max quota but received %d", v)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"strconv"
+	"strings"
+
+	systemdDbus "github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pkg/errors"
+)
+
+// systemdPropertyVersions is the systemd version that introduced each of
+// the resource properties
+var systemdPropertyVersions = map[string]int{
+	"TasksAccounting":    227,
+	"TasksMax":           227,
+	"IOWeight":           230,
+	"MemoryMax":          231,
+	"MemoryHigh":         231,
+	"MemoryLow":          231,
+	"CPUWeight":          231,
+	"MemorySwapMax":      232,
+	"MemoryMin":          240,
+	"CPUQuotaPeriodUSec": 242,
+	"AllowedCPUs":        244,
+	"AllowedMemoryNodes": 244,
+}
+
+// systemdPropertyFiles is the cgroup file written directly for a resource
+// property the running systemd does not support
+var systemdPropertyFiles = map[string]string{
+	"TasksMax":           "pids.max",
+	"MemoryMax":          "memory.max",
+	"MemoryHigh":         "memory.high",
+	"MemoryLow":          "memory.low",
+	"MemorySwapMax":      "memory.swap.max",
+	"CPUWeight":          "cpu.weight",
+	"AllowedCPUs":        "cpuset.cpus",
+	"AllowedMemoryNodes": "cpuset.mems",
+}
+
+// SystemdVersion returns the version of the systemd instance conn is
+// connected to
+func SystemdVersion(conn *systemdDbus.Conn) (int, error) {
+	v, err := conn.GetManagerProperty("Version")
+	if err != nil {
+		return 0, err
+	}
+	return parseSystemdVersion(v)
+}
+
+// parseSystemdVersion parses the leading number of versions such as
+// "245.4-4ubuntu3" or "v252", quoted as dbus variants are formatted
+func parseSystemdVersion(v string) (int, error) {
+	s := strings.TrimPrefix(strings.Trim(v, `"`), "v")
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i >= 0 {
+		s = s[:i]
+	}
+	version, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidFormat, "systemd version %q", v)
+	}
+	return version, nil
+}
+
+// SystemdPropertySupported reports whether the systemd version supports the
+// unit property. Properties the package does not know about are assumed to
+// be supported.
+func SystemdPropertySupported(version int, name string) bool {
+	return version >= systemdPropertyVersions[name]
+}
+
+// PropertyReport describes the resource properties NewSystemd could not set
+// through systemd because the running version does not support them
+type PropertyReport struct {
+	// Version is the version of the running systemd
+	Version int
+	// Skipped are the properties that were not sent to systemd
+	Skipped []string
+	// Direct are the cgroup files written directly for skipped properties,
+	// skipped properties without a file are not applied at all
+	Direct []string
+}
+
+// FilterSystemdProperties splits properties into those the systemd version
+// supports and a report of those it does not, instead of letting systemd
+// fail the whole unit with an unknown property error
+func FilterSystemdProperties(version int, properties []systemdDbus.Property) ([]systemdDbus.Property, PropertyReport) {
+	report := PropertyReport{
+		Version: version,
+	}
+	var supported []systemdDbus.Property
+	for _, p := range properties {
+		if !SystemdPropertySupported(version, p.Name) {
+			report.Skipped = append(report.Skipped, p.Name)
+			if file, ok := systemdPropertyFiles[p.Name]; ok {
+				report.Direct = append(report.Direct, file)
+			}
+			continue
+		}
+		supported = append(supported, p)
+	}
+	return supported, report
+}
+
+// directValues returns the values of resources for the files the report
+// lists for direct writes
+func directValues(report PropertyReport, resources *Resources) []Value {
+	if len(report.Direct) == 0 || resources == nil {
+		return nil
+	}
+	files := make(map[string]struct{}, len(report.Direct))
+	for _, f := range report.Direct {
+		files[f] = struct{}{}
+	}
+	var values []Value
+	for _, v := range resources.Values() {
+		if _, ok := files[v.filename]; ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
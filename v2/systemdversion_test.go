@@ -0,0 +1,72 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSystemdVersion(t *testing.T) {
+	for v, expected := range map[string]int{
+		`"245.4-4ubuntu3"`: 245,
+		`"v252"`:           252,
+		"239":              239,
+	} {
+		version, err := parseSystemdVersion(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != expected {
+			t.Errorf("expected %d for %s, got %d", expected, v, version)
+		}
+	}
+	if _, err := parseSystemdVersion(`"unknown"`); err == nil {
+		t.Error("expected an invalid version to be rejected")
+	}
+}
+
+func TestFilterSystemdProperties(t *testing.T) {
+	var (
+		max    int64  = 1 << 30
+		swap   int64  = 1 << 29
+		weight uint64 = 100
+	)
+	resources := &Resources{
+		Memory: &Memory{Max: &max, Swap: &swap},
+		CPU:    &CPU{Weight: &weight},
+		Pids:   &Pids{Max: 10},
+	}
+	properties, report := FilterSystemdProperties(231, RuncSystemdProperties(resources))
+	var names []string
+	for _, p := range properties {
+		names = append(names, p.Name)
+	}
+	if expected := []string{"MemoryMax", "CPUWeight", "TasksAccounting", "TasksMax"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected properties %v, got %v", expected, names)
+	}
+	if expected := []string{"MemorySwapMax"}; !reflect.DeepEqual(report.Skipped, expected) {
+		t.Fatalf("expected skipped properties %v, got %v", expected, report.Skipped)
+	}
+	values := directValues(report, resources)
+	if len(values) != 1 || values[0].filename != "memory.swap.max" || values[0].value != FormatMax(swap) {
+		t.Fatalf("expected memory.swap.max to be written directly, got %+v", values)
+	}
+	if _, report := FilterSystemdProperties(252, RuncSystemdProperties(resources)); len(report.Skipped) != 0 {
+		t.Fatalf("expected all properties to be supported, got %v", report.Skipped)
+	}
+}
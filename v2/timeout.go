@@ -0,0 +1,102 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"time"
+)
+
+// Timeouts bound how long the manager waits on the kernel and systemd, so
+// that a slow operation, such as a cpuset change racing cpu hotplug, cannot
+// hang the caller indefinitely. A zero duration waits without a bound.
+type Timeouts struct {
+	// Read bounds reading a single interface file. The kernel cannot be
+	// interrupted, a read that times out is left to finish in the
+	// background.
+	Read time.Duration
+	// Write bounds the writes of NewManager, Update, Freeze and Thaw. It is
+	// checked between the files written, a write in flight is always waited
+	// for, so an operation that returns ErrTimeout has stopped with only
+	// the files before the deadline written.
+	Write time.Duration
+	// Dbus bounds the systemd calls of NewSystemd and DeleteSystemd, a call
+	// that times out is left to finish in the background
+	Dbus time.Duration
+	// FrozenRead bounds reading a single interface file in Stat while the
+	// cgroup is frozen, files not read in time are reported as stale
+	FrozenRead time.Duration
+}
+
+// DefaultTimeouts are the timeouts of managers created without WithTimeouts
+var DefaultTimeouts = Timeouts{
+	Read:  10 * time.Second,
+	Write: 30 * time.Second,
+	Dbus:  30 * time.Second,
+
+	FrozenRead: time.Second,
+}
+
+// timeouts returns the configured timeouts, DefaultTimeouts unless they
+// were set with WithTimeouts
+func (c *InitConfig) timeouts() Timeouts {
+	if c == nil || c.Timeouts == nil {
+		return DefaultTimeouts
+	}
+	return *c.Timeouts
+}
+
+// deadline bounds a sequence of writes. It is checked between the writes
+// rather than abandoning one in flight, so that the caller's locks are held
+// until the kernel is done. The zero deadline never expires.
+type deadline time.Time
+
+// newDeadline returns the deadline d from now, none if d is not positive
+func newDeadline(d time.Duration) deadline {
+	if d <= 0 {
+		return deadline{}
+	}
+	return deadline(time.Now().Add(d))
+}
+
+// check returns ErrTimeout once the deadline has passed
+func (d deadline) check() error {
+	if t := time.Time(d); !t.IsZero() && time.Now().After(t) {
+		return ErrTimeout
+	}
+	return nil
+}
+
+// withTimeout runs fn and returns ErrTimeout if it does not return within d,
+// leaving fn running. It must only be used for operations that are safe to
+// abandon, writes are bounded with a deadline instead.
+func withTimeout(d time.Duration, fn func() error) error {
+	if d <= 0 {
+		return fn()
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn()
+	}()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case err := <-errCh:
+		return err
+	case <-timer.C:
+		return ErrTimeout
+	}
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	if err := withTimeout(10*time.Millisecond, func() error {
+		<-release
+		return nil
+	}); err != ErrTimeout {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if err := withTimeout(time.Second, func() error { return ErrInvalidFormat }); err != ErrInvalidFormat {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+	if IsRetryable(ErrTimeout) {
+		t.Fatal("expected ErrTimeout not to be retryable")
+	}
+}
+
+func TestDeadline(t *testing.T) {
+	if err := (deadline{}).check(); err != nil {
+		t.Fatalf("expected the zero deadline not to expire, got %v", err)
+	}
+	if err := newDeadline(time.Hour).check(); err != nil {
+		t.Fatalf("expected a future deadline not to expire, got %v", err)
+	}
+	if err := newDeadline(time.Nanosecond).check(); err != ErrTimeout {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestInterceptWriteTimeout(t *testing.T) {
+	config, err := newInitConfig([]InitOpts{WithTimeouts(Timeouts{Write: 10 * time.Millisecond})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "cgroups-timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// the write in flight when the deadline passes completes, the ones after
+	// it are not made
+	values := []Value{
+		{filename: "pids.max", value: int64(10)},
+		{filename: "memory.max", value: int64(4096)},
+	}
+	op := &Operation{Op: OpUpdate}
+	if err := config.intercept(op, func() error {
+		time.Sleep(20 * time.Millisecond)
+		return config.writeValues(dir, values, nil, op.deadline)
+	}); err != ErrTimeout {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pids.max")); !os.IsNotExist(err) {
+		t.Fatalf("expected no write after the deadline, got %v", err)
+	}
+	if timeouts := (&InitConfig{}).timeouts(); timeouts != DefaultTimeouts {
+		t.Fatalf("expected the default timeouts, got %+v", timeouts)
+	}
+	config, err = newInitConfig([]InitOpts{WithTimeouts(Timeouts{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeouts := config.timeouts(); timeouts != (Timeouts{}) {
+		t.Fatalf("expected the timeouts to be disabled, got %+v", timeouts)
+	}
+}
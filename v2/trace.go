@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Span is a single traced operation
+type Span interface {
+	// SetAttribute records a key/value pair on the span
+	SetAttribute(key, value string)
+	// End finishes the span with the result of the operation
+	End(err error)
+}
+
+// Tracer starts spans for the manager's operations. It is small enough to be
+// implemented on top of OpenTelemetry or any other tracing library. Start
+// receives the context of the operation, the caller's for operations that
+// take one such as RunFrozen and Swap, and returns the context carrying the
+// new span so that the spans of nested operations are its children.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+const (
+	// AttributePath is the span attribute holding the path of the group
+	AttributePath = "cgroup.path"
+	// AttributeControllers is the span attribute holding the comma separated
+	// controllers an operation touched
+	AttributeControllers = "cgroup.controllers"
+)
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key, value string) {}
+
+func (noopSpan) End(err error) {}
+
+// startSpan starts a span for an operation on path, a no-op span is returned
+// when no tracer is configured
+func (c *InitConfig) startSpan(ctx context.Context, name, path string) (context.Context, Span) {
+	if c == nil || c.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := c.Tracer.Start(ctx, name)
+	span.SetAttribute(AttributePath, path)
+	return ctx, span
+}
+
+// touchedControllers returns the controllers of the interface files values
+// are written to
+func touchedControllers(values []Value) string {
+	var controllers []string
+	for _, v := range values {
+		controller := v.filename
+		if i := strings.Index(controller, "."); i > 0 {
+			controller = controller[:i]
+		}
+		if !contains(controllers, controller) {
+			controllers = append(controllers, controller)
+		}
+	}
+	sort.Strings(controllers)
+	return strings.Join(controllers, ",")
+}
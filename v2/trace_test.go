@@ -0,0 +1,122 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testSpan struct {
+	name   string
+	parent string
+	attrs  map[string]string
+	ended  bool
+	err    error
+}
+
+func (s *testSpan) SetAttribute(key, value string) {
+	s.attrs[key] = value
+}
+
+func (s *testSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+type testSpanKey struct{}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &testSpan{name: name, attrs: make(map[string]string)}
+	if parent, ok := ctx.Value(testSpanKey{}).(*testSpan); ok {
+		s.parent = parent.name
+	}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, testSpanKey{}, s), s
+}
+
+func TestTracer(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tracer := &testTracer{}
+	m, err := NewManager(mountpoint, "/test", &Resources{}, WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	max := int64(1 << 20)
+	if err := m.Update(&Resources{
+		Pids:   &Pids{Max: 10},
+		Memory: &Memory{Max: &max},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(m.path, controllersFile), []byte("pids"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stat(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.spans) != 2 {
+		t.Fatalf("expected 2 spans but received %d", len(tracer.spans))
+	}
+	update, stat := tracer.spans[0], tracer.spans[1]
+	if update.name != "cgroups.Update" || !update.ended || update.err != nil {
+		t.Errorf("unexpected update span %+v", update)
+	}
+	if v := update.attrs[AttributeControllers]; v != "memory,pids" {
+		t.Errorf("expected the update to touch memory,pids but received %q", v)
+	}
+	if stat.name != "cgroups.Stat" || !stat.ended {
+		t.Errorf("unexpected stat span %+v", stat)
+	}
+	for _, s := range tracer.spans {
+		if v := s.attrs[AttributePath]; v != m.path {
+			t.Errorf("expected path %q on %s but received %q", m.path, s.name, v)
+		}
+	}
+
+	// the spans of nested operations are children of the caller's span
+	if err := ioutil.WriteFile(filepath.Join(m.path, cgroupEvents), []byte("populated 0\nfrozen 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tracer.spans = nil
+	ctx, parent := tracer.Start(context.Background(), "caller")
+	if err := m.RunFrozen(ctx, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	parent.End(nil)
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected 3 spans but received %d", len(tracer.spans))
+	}
+	if run, thaw := tracer.spans[1], tracer.spans[2]; run.name != "cgroups.RunFrozen" || run.parent != "caller" ||
+		thaw.name != "cgroups.Thaw" || thaw.parent != "cgroups.RunFrozen" {
+		t.Errorf("unexpected spans %+v and %+v", run, thaw)
+	}
+}
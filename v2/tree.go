@@ -0,0 +1,164 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/pkg/errors"
+)
+
+// TreeNode is the declarative description of a group and its children,
+// converged by ApplyTree
+type TreeNode struct {
+	// Name of the group relative to its parent, for the root node it is the
+	// group path relative to the mountpoint
+	Name string
+	// Resources of the group, nil leaves them as they are
+	Resources *Resources
+	// Controllers to enable for the children of the group. The controllers
+	// required by the children's resources are always enabled.
+	Controllers []string
+	// Procs are moved into the group
+	Procs []uint64
+	// Children of the group
+	Children []*TreeNode
+}
+
+// TreeOpts configures ApplyTree
+type TreeOpts func(*treeConfig)
+
+type treeConfig struct {
+	prune bool
+}
+
+// WithPrune removes the groups under the tree that are not described by it.
+// Groups with processes cannot be removed and fail the apply.
+func WithPrune() TreeOpts {
+	return func(c *treeConfig) {
+		c.prune = true
+	}
+}
+
+// ApplyTree converges the subtree at root.Name under the mountpoint to the
+// description: missing groups are created, controllers are enabled,
+// resources are written and processes are moved. Parents are configured
+// before their children so that the controllers the children use are
+// enabled first.
+func ApplyTree(mountpoint string, root *TreeNode, opts ...TreeOpts) error {
+	var config treeConfig
+	for _, o := range opts {
+		o(&config)
+	}
+	if err := VerifyGroupPath(root.Name); err != nil {
+		return err
+	}
+	return applyNode(mountpoint, filepath.Join(mountpoint, root.Name), root, &config)
+}
+
+func applyNode(mountpoint, path string, node *TreeNode, config *treeConfig) error {
+	if err := os.MkdirAll(path, defaultDirPerm); err != nil {
+		return err
+	}
+	m := newManager(mountpoint, path, &InitConfig{})
+	if controllers := node.subtreeControllers(); len(controllers) > 0 {
+		if err := m.writeSubtreeControl(filepath.Join(path, subtreeControl), controllers, Enable); err != nil {
+			return errors.Wrapf(err, "failed to enable controllers %+v for %q", controllers, path)
+		}
+	}
+	if err := setResources(path, node.Resources); err != nil {
+		return errors.Wrapf(err, "failed to set resources for %q", path)
+	}
+	for _, pid := range node.Procs {
+		if err := m.AddProc(pid); err != nil {
+			return errors.Wrapf(err, "failed to add %d to %q", pid, path)
+		}
+	}
+	names := make(map[string]struct{}, len(node.Children))
+	for _, child := range node.Children {
+		if child.Name == "" || filepath.Base(child.Name) != child.Name {
+			return errors.Wrapf(ErrInvalidGroupPath, "child name %q of %q", child.Name, path)
+		}
+		names[child.Name] = struct{}{}
+		if err := applyNode(mountpoint, filepath.Join(path, child.Name), child, config); err != nil {
+			return err
+		}
+	}
+	if config.prune {
+		return pruneChildren(path, names)
+	}
+	return nil
+}
+
+// subtreeControllers returns the node's controllers and the controllers
+// required by the resources of its children
+func (n *TreeNode) subtreeControllers() []string {
+	set := make(map[string]struct{})
+	for _, c := range n.Controllers {
+		set[c] = struct{}{}
+	}
+	for _, child := range n.Children {
+		if child.Resources == nil {
+			continue
+		}
+		for _, c := range child.Resources.EnabledControllers() {
+			set[c] = struct{}{}
+		}
+	}
+	controllers := make([]string, 0, len(set))
+	for c := range set {
+		controllers = append(controllers, c)
+	}
+	sort.Strings(controllers)
+	return controllers
+}
+
+// pruneChildren removes the child groups of path not in keep
+func pruneChildren(path string, keep map[string]struct{}) error {
+	f, err := openDir(path)
+	if err != nil {
+		return err
+	}
+	entries, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, ok := keep[e.Name()]; ok {
+			continue
+		}
+		if err := removeTree(filepath.Join(path, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeTree removes the group and its children, deepest first
+func removeTree(path string) error {
+	if err := pruneChildren(path, nil); err != nil {
+		return err
+	}
+	return remove(path, clock.Real)
+}
@@ -0,0 +1,79 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyTree(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	root := filepath.Join(mountpoint, "workloads")
+	for _, dir := range []string{root, filepath.Join(root, "stale", "child")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tree := &TreeNode{
+		Name: "/workloads",
+		Children: []*TreeNode{
+			{Name: "batch", Resources: &Resources{Pids: &Pids{Max: 100}}},
+			{Name: "web"},
+		},
+	}
+	if err := ApplyTree(mountpoint, tree, WithPrune()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(root, subtreeControl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "+pids" {
+		t.Fatalf("expected the pids controller to be enabled but received %q", data)
+	}
+	data, err = ioutil.ReadFile(filepath.Join(root, "batch", "pids.max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "100" {
+		t.Fatalf("expected pids.max of 100 but received %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "web")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "stale")); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale group to be pruned: %v", err)
+	}
+
+	if err := ApplyTree(mountpoint, &TreeNode{
+		Name:     "/workloads",
+		Children: []*TreeNode{{Name: "../escape"}},
+	}); err == nil {
+		t.Fatal("expected an error for a child name with a path")
+	}
+}
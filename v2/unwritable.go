@@ -0,0 +1,108 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// UnwritablePolicy decides what creating or updating a group does with the
+// resource files that exist but cannot be written, as is common inside
+// containers where the cgroup filesystem is mounted read-only or only some
+// of its files are delegated. Files that do not exist always fail. The
+// device rules are loaded as a BPF program rather than written to a file,
+// they are not covered by the policy.
+type UnwritablePolicy int
+
+const (
+	// UnwritableFail returns the error of the first file that cannot be
+	// written, this is the default
+	UnwritableFail UnwritablePolicy = iota
+	// UnwritableSkip leaves the files that cannot be written unchanged and
+	// adds a warning for each of them
+	UnwritableSkip
+	// UnwritableQueue hands the writes that could not be made to the
+	// applier set with WithUnwritableApplier, such as a privileged helper
+	// outside of the container
+	UnwritableQueue
+)
+
+// PendingWrite is a write of a resource file that could not be made
+type PendingWrite struct {
+	// Path is the absolute path of the file
+	Path string
+	// Value is the content to write to the file
+	Value string
+}
+
+// UnwritableApplier applies the writes queued by UnwritableQueue, in the
+// order they are given. Its error is returned by the operation that queued
+// them.
+type UnwritableApplier func([]PendingWrite) error
+
+// isUnwritable returns true if err is the kernel refusing a write to a file
+// that exists, because of a read-only mount or missing permission
+func isUnwritable(err error) bool {
+	return errors.Is(err, unix.EROFS) || errors.Is(err, unix.EPERM) || errors.Is(err, unix.EACCES)
+}
+
+// writeValues writes the values to the group at path, applying the
+// unwritable policy of the config to the files that cannot be written and
+// adding warnings to w. The deadline is checked before every write.
+func (c *InitConfig) writeValues(path string, values []Value, w *Warnings, dl deadline) error {
+	var pending []PendingWrite
+	for _, v := range values {
+		if err := dl.check(); err != nil {
+			return err
+		}
+		err := v.write(path, defaultFilePerm)
+		if err == nil {
+			continue
+		}
+		if c == nil || c.Unwritable == UnwritableFail || !isUnwritable(err) {
+			return err
+		}
+		// files are opened with O_CREAT, a read-only mount or directory
+		// reports a missing file as unwritable too
+		if _, serr := os.Lstat(filepath.Join(path, v.filename)); serr != nil {
+			return serr
+		}
+		if c.Unwritable == UnwritableSkip {
+			w.add(v.filename, "not written, the file is not writable: %v", errors.Cause(err))
+			continue
+		}
+		data, err := v.bytes()
+		if err != nil {
+			return err
+		}
+		pending = append(pending, PendingWrite{
+			Path:  filepath.Join(path, v.filename),
+			Value: string(data),
+		})
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if c.UnwritableApplier == nil {
+		return errors.Errorf("cgroups: no applier for %d unwritable files of %s", len(pending), path)
+	}
+	return c.UnwritableApplier(pending)
+}
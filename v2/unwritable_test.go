@@ -0,0 +1,154 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// readOnlyTestManager returns a manager with memory.max bind mounted read-only
+// over itself, as a container would see a file it is not delegated
+func readOnlyTestManager(t *testing.T) (*Manager, func()) {
+	if os.Geteuid() != 0 {
+		t.Skip("bind mounting a read-only file requires root")
+	}
+	c := shrinkTestManager(t, map[string]string{
+		"memory.max": "max\n",
+		"pids.max":   "max\n",
+	})
+	file := filepath.Join(c.path, "memory.max")
+	if err := unix.Mount(file, file, "", unix.MS_BIND, ""); err != nil {
+		os.RemoveAll(c.path)
+		t.Skipf("unable to bind mount: %v", err)
+	}
+	cleanup := func() {
+		unix.Unmount(file, unix.MNT_DETACH)
+		os.RemoveAll(c.path)
+	}
+	if err := unix.Mount("", file, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY, ""); err != nil {
+		cleanup()
+		t.Skipf("unable to remount read-only: %v", err)
+	}
+	return c, cleanup
+}
+
+func TestUnwritablePolicy(t *testing.T) {
+	max := int64(1 << 20)
+	resources := &Resources{
+		Memory: &Memory{Max: &max},
+		Pids:   &Pids{Max: 10},
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		c, cleanup := readOnlyTestManager(t)
+		defer cleanup()
+		c.config = &InitConfig{}
+		if err := c.Update(resources); !errors.Is(err, unix.EROFS) {
+			t.Fatalf("expected EROFS, got %v", err)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		c, cleanup := readOnlyTestManager(t)
+		defer cleanup()
+		c.config = &InitConfig{Unwritable: UnwritableSkip}
+		var w Warnings
+		if err := c.Update(resources, WithUpdateWarnings(&w)); err != nil {
+			t.Fatal(err)
+		}
+		if v := readTestFile(t, c, "pids.max"); v != "10" {
+			t.Fatalf("expected pids.max to be written, got %q", v)
+		}
+		if len(w) != 1 || w[0].File != "memory.max" {
+			t.Fatalf("expected a warning for memory.max, got %+v", w)
+		}
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		c, cleanup := readOnlyTestManager(t)
+		defer cleanup()
+		var queued []PendingWrite
+		config, err := newInitConfig([]InitOpts{WithUnwritableApplier(func(writes []PendingWrite) error {
+			queued = append(queued, writes...)
+			return nil
+		})})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.config = config
+		if err := c.Update(resources); err != nil {
+			t.Fatal(err)
+		}
+		if v := readTestFile(t, c, "pids.max"); v != "10" {
+			t.Fatalf("expected pids.max to be written, got %q", v)
+		}
+		expected := PendingWrite{
+			Path:  filepath.Join(c.path, "memory.max"),
+			Value: "1048576",
+		}
+		if len(queued) != 1 || queued[0] != expected {
+			t.Fatalf("expected %+v to be queued, got %+v", expected, queued)
+		}
+	})
+
+	t.Run("queue without applier", func(t *testing.T) {
+		c, cleanup := readOnlyTestManager(t)
+		defer cleanup()
+		c.config = &InitConfig{Unwritable: UnwritableQueue}
+		if err := c.Update(resources); err == nil {
+			t.Fatal("expected the queued writes to fail without an applier")
+		}
+	})
+}
+
+func TestUnwritableMissingFile(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("bind mounting a read-only directory requires root")
+	}
+	c := shrinkTestManager(t, map[string]string{"pids.max": "max\n"})
+	defer os.RemoveAll(c.path)
+	if err := unix.Mount(c.path, c.path, "", unix.MS_BIND, ""); err != nil {
+		t.Skipf("unable to bind mount: %v", err)
+	}
+	defer unix.Unmount(c.path, unix.MNT_DETACH)
+	if err := unix.Mount("", c.path, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY, ""); err != nil {
+		t.Skipf("unable to remount read-only: %v", err)
+	}
+	c.config = &InitConfig{Unwritable: UnwritableSkip}
+	var (
+		w      Warnings
+		weight = uint64(100)
+	)
+	err := c.Update(&Resources{CPU: &CPU{Weight: &weight}}, WithUpdateWarnings(&w))
+	if !os.IsNotExist(err) {
+		t.Fatalf("expected the missing cpu.weight to fail, got %v", err)
+	}
+	if len(w) != 0 {
+		t.Fatalf("expected no warnings, got %+v", w)
+	}
+}
+
+func TestWithUnwritable(t *testing.T) {
+	if _, err := newInitConfig([]InitOpts{WithUnwritable(UnwritablePolicy(42))}); err == nil {
+		t.Fatal("expected an invalid policy to be refused")
+	}
+}
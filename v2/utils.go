@@ -20,7 +20,6 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
@@ -28,6 +27,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/containerd/cgroups/clock"
 	"github.com/containerd/cgroups/v2/stats"
 	"github.com/godbus/dbus/v5"
 	"github.com/opencontainers/runtime-spec/specs-go"
@@ -37,6 +37,7 @@ import (
 
 const (
 	cgroupProcs    = "cgroup.procs"
+	cgroupThreads  = "cgroup.threads"
 	defaultDirPerm = 0755
 )
 
@@ -48,13 +49,13 @@ const (
 var defaultFilePerm = os.FileMode(0)
 
 // remove will remove a cgroup path handling EAGAIN and EBUSY errors and
-// retrying the remove after a exp timeout
-func remove(path string) error {
+// retrying the remove after a exp timeout paced by clk
+func remove(path string, clk clock.Clock) error {
 	var err error
 	delay := 10 * time.Millisecond
 	for i := 0; i < 5; i++ {
 		if i != 0 {
-			time.Sleep(delay)
+			clock.Sleep(clk, delay)
 			delay *= 2
 		}
 		if err = os.RemoveAll(path); err == nil {
@@ -65,8 +66,12 @@ func remove(path string) error {
 }
 
 // parseCgroupProcsFile parses /sys/fs/cgroup/$GROUPPATH/cgroup.procs
-func parseCgroupProcsFile(path string) ([]uint64, error) {
-	f, err := os.Open(path)
+func parseCgroupProcsFile(path string) (_ []uint64, err error) {
+	start := time.Now()
+	defer func() {
+		observe(CallRead, filepath.Base(path), start, err)
+	}()
+	f, err := openFile(path, os.O_RDONLY, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -123,8 +128,10 @@ func parseUint(s string, base, bitSize int) (uint64, error) {
 	return v, nil
 }
 
-// parseCgroupFile parses /proc/PID/cgroup file and return string
-func parseCgroupFile(path string) (string, error) {
+// ParseCgroupFile parses a /proc/<pid>/cgroup file, which may be read from
+// any procfs root, and returns the path of the process's group in the
+// unified hierarchy
+func ParseCgroupFile(path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return "", err
@@ -242,10 +249,16 @@ func ToResources(spec *specs.LinuxResources) *Resources {
 
 // Gets uint64 parsed content of single value cgroup stat file
 func getStatFileContentUint64(filePath string) uint64 {
-	contents, err := ioutil.ReadFile(filePath)
+	start := time.Now()
+	contents, err := readBounded(filePath)
+	observe(CallRead, filepath.Base(filePath), start, err)
 	if err != nil {
 		return 0
 	}
+	return parseStatFileContentUint64(contents, filePath)
+}
+
+func parseStatFileContentUint64(contents []byte, filePath string) uint64 {
 	trimmed := strings.TrimSpace(string(contents))
 	if trimmed == "max" {
 		return math.MaxUint64
@@ -262,16 +275,20 @@ func getStatFileContentUint64(filePath string) uint64 {
 
 func readIoStats(path string) []*stats.IOEntry {
 	// more details on the io.stat file format: https://www.kernel.org/doc/Documentation/cgroup-v2.txt
-	var usage []*stats.IOEntry
-	fpath := filepath.Join(path, "io.stat")
-	currentData, err := ioutil.ReadFile(fpath)
+	currentData, err := readBounded(filepath.Join(path, "io.stat"))
 	if err != nil {
-		return usage
+		return nil
 	}
-	entries := strings.Split(string(currentData), "\n")
+	return parseIoStats(string(currentData))
+}
 
-	for _, entry := range entries {
-		parts := strings.Split(entry, " ")
+// parseIoStats parses the per device lines of io.stat, lines that cannot be
+// parsed are skipped so that a device being added or removed while the file
+// is read does not drop the stats of the other devices
+func parseIoStats(data string) []*stats.IOEntry {
+	var usage []*stats.IOEntry
+	for _, entry := range strings.Split(data, "\n") {
+		parts := strings.Fields(entry)
 		if len(parts) < 2 {
 			continue
 		}
@@ -281,18 +298,17 @@ func readIoStats(path string) []*stats.IOEntry {
 		}
 		major, err := strconv.ParseUint(majmin[0], 10, 0)
 		if err != nil {
-			return usage
+			continue
 		}
 		minor, err := strconv.ParseUint(majmin[1], 10, 0)
 		if err != nil {
-			return usage
+			continue
 		}
-		parts = parts[1:]
 		ioEntry := stats.IOEntry{
 			Major: major,
 			Minor: minor,
 		}
-		for _, s := range parts {
+		for _, s := range parts[1:] {
 			keyPairValue := strings.Split(s, "=")
 			if len(keyPairValue) != 2 {
 				continue
@@ -310,6 +326,10 @@ func readIoStats(path string) []*stats.IOEntry {
 				ioEntry.Rios = v
 			case "wios":
 				ioEntry.Wios = v
+			case "dbytes":
+				ioEntry.Dbytes = v
+			case "dios":
+				ioEntry.Dios = v
 			}
 		}
 		usage = append(usage, &ioEntry)
@@ -318,7 +338,7 @@ func readIoStats(path string) []*stats.IOEntry {
 }
 
 func rdmaStats(filepath string) []*stats.RdmaEntry {
-	currentData, err := ioutil.ReadFile(filepath)
+	currentData, err := readBounded(filepath)
 	if err != nil {
 		return []*stats.RdmaEntry{}
 	}
@@ -382,15 +402,12 @@ func systemdUnitFromPath(path string) string {
 	return unit
 }
 
-func readHugeTlbStats(path string) []*stats.HugeTlbStat {
+// readHugeTlbStats reads the hugetlb files of the cgroup at path, whose
+// directory is open as dir
+func readHugeTlbStats(dir *os.File, path string) []*stats.HugeTlbStat {
 	var usage = []*stats.HugeTlbStat{}
 	var keyUsage = make(map[string]*stats.HugeTlbStat)
-	f, err := os.Open(path)
-	if err != nil {
-		return usage
-	}
-	files, err := f.Readdir(-1)
-	f.Close()
+	files, err := dir.Readdir(-1)
 	if err != nil {
 		return usage
 	}
@@ -406,7 +423,7 @@ func readHugeTlbStats(path string) []*stats.HugeTlbStat {
 				hugeTlb = &stats.HugeTlbStat{}
 			}
 			hugeTlb.Pagesize = pageSize
-			out, err := ioutil.ReadFile(filepath.Join(path, file.Name()))
+			out, err := readBounded(filepath.Join(path, file.Name()))
 			if err != nil {
 				continue
 			}
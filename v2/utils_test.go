@@ -20,6 +20,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/containerd/cgroups/v2/stats"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/stretchr/testify/assert"
 )
@@ -64,3 +65,16 @@ func TestToResources(t *testing.T) {
 	v2resources2 := ToResources(&res2)
 	assert.Equal(t, CPUMax("max 10000"), v2resources2.CPU.Max)
 }
+
+func TestParseIoStats(t *testing.T) {
+	const data = `8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=4096 dios=3
+garbage
+8:x rbytes=1
+253:0 rbytes=10 wbytes=20 rios=30 wios=40 dbytes=50 dios=60
+`
+	expected := []*stats.IOEntry{
+		{Major: 8, Minor: 0, Rbytes: 1024, Wbytes: 2048, Rios: 1, Wios: 2, Dbytes: 4096, Dios: 3},
+		{Major: 253, Minor: 0, Rbytes: 10, Wbytes: 20, Rios: 30, Wios: 40, Dbytes: 50, Dios: 60},
+	}
+	assert.Equal(t, expected, parseIoStats(data))
+}
@@ -0,0 +1,152 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/cgroups/topology"
+)
+
+// UpdateOpts configures an Update
+type UpdateOpts func(*updateConfig)
+
+type updateConfig struct {
+	verify   bool
+	warnings *Warnings
+}
+
+// WithVerify reads back every file written by Update and returns a
+// *VerifyError if the kernel or another manager applied a different value
+// than the one requested, e.g. memory limits rounded to the page size
+func WithVerify() UpdateOpts {
+	return func(c *updateConfig) {
+		c.verify = true
+	}
+}
+
+// WithUpdateWarnings appends to w what Update skipped or approximated
+// without failing, such as values adjusted by a policy
+func WithUpdateWarnings(w *Warnings) UpdateOpts {
+	return func(c *updateConfig) {
+		c.warnings = w
+	}
+}
+
+// Mismatch is a file whose applied value differs from the requested one
+type Mismatch struct {
+	File      string
+	Requested string
+	Applied   string
+}
+
+// VerifyError is returned when the values read back after an update do not
+// match the requested values
+type VerifyError struct {
+	Mismatches []Mismatch
+}
+
+func (e *VerifyError) Error() string {
+	parts := make([]string, 0, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		parts = append(parts, fmt.Sprintf("%s: requested %q, applied %q", m.File, m.Requested, m.Applied))
+	}
+	return "cgroups: applied values differ from the requested values: " + strings.Join(parts, ", ")
+}
+
+// verifyValues reads back the values from path and returns a *VerifyError
+// listing all the files where the applied value differs
+func verifyValues(path string, values []Value) error {
+	var mismatches []Mismatch
+	for _, v := range values {
+		data, err := v.bytes()
+		if err != nil {
+			return err
+		}
+		applied, err := readBounded(filepath.Join(path, v.filename))
+		if err != nil {
+			return err
+		}
+		requested := strings.TrimSpace(string(data))
+		if !valueApplied(v.filename, requested, string(applied)) {
+			mismatches = append(mismatches, Mismatch{
+				File:      v.filename,
+				Requested: requested,
+				Applied:   strings.TrimSpace(string(applied)),
+			})
+		}
+	}
+	if len(mismatches) > 0 {
+		return &VerifyError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// valueApplied returns true if the contents of the file reflect the
+// requested value, taking the kernel's formatting of each file into account
+func valueApplied(filename, requested, applied string) bool {
+	applied = strings.TrimSpace(applied)
+	if requested == applied {
+		return true
+	}
+	switch filename {
+	case "cpuset.cpus", "cpuset.mems":
+		r, err := topology.ParseCPUSet(requested)
+		if err != nil {
+			return false
+		}
+		a, err := topology.ParseCPUSet(applied)
+		if err != nil {
+			return false
+		}
+		return r.Equal(a)
+	}
+	if requested == "-1" && applied == "max" {
+		return true
+	}
+	// keyed files such as io.max or rdma.max list every key on its own line
+	// with all of its settings, the requested settings must be on the line
+	// for the requested key
+	fields := strings.Fields(requested)
+	if len(fields) < 2 {
+		return false
+	}
+	for _, line := range strings.Split(applied, "\n") {
+		appliedFields := strings.Fields(line)
+		if len(appliedFields) == 0 || appliedFields[0] != fields[0] {
+			continue
+		}
+		for _, f := range fields[1:] {
+			if !contains(appliedFields[1:], f) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
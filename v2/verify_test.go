@@ -0,0 +1,70 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValueApplied(t *testing.T) {
+	for _, tc := range []struct {
+		filename, requested, applied string
+		expected                     bool
+	}{
+		{"memory.max", "1048576", "1048576\n", true},
+		{"memory.max", "1000000", "999424\n", false},
+		{"memory.max", "-1", "max\n", true},
+		{"cpuset.cpus", "0,1,2,3", "0-3\n", true},
+		{"cpuset.cpus", "0-3", "0-1\n", false},
+		{"io.max", "8:0 rbps=1024", "8:16 rbps=max wbps=max riops=max wiops=max\n8:0 rbps=1024 wbps=max riops=max wiops=max\n", true},
+		{"io.max", "8:0 rbps=1024", "8:0 rbps=max wbps=max riops=max wiops=max\n", false},
+		{"cpu.max", "50000 100000", "60000 100000\n", false},
+	} {
+		if applied := valueApplied(tc.filename, tc.requested, tc.applied); applied != tc.expected {
+			t.Errorf("%s: requested %q applied %q: expected %v", tc.filename, tc.requested, tc.applied, tc.expected)
+		}
+	}
+}
+
+func TestVerifyValues(t *testing.T) {
+	path, err := ioutil.TempDir("", "cgroups-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	// the kernel rounds memory limits down to the page size
+	if err := ioutil.WriteFile(filepath.Join(path, "memory.max"), []byte("999424\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(path, "pids.max"), []byte("10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = verifyValues(path, []Value{
+		{filename: "memory.max", value: int64(1000000)},
+		{filename: "pids.max", value: "10"},
+	})
+	verr, ok := err.(*VerifyError)
+	if !ok {
+		t.Fatalf("expected a *VerifyError but received %v", err)
+	}
+	if len(verr.Mismatches) != 1 || verr.Mismatches[0] != (Mismatch{File: "memory.max", Requested: "1000000", Applied: "999424"}) {
+		t.Fatalf("unexpected mismatches %+v", verr.Mismatches)
+	}
+}
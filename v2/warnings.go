@@ -0,0 +1,53 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+)
+
+// Warning is something a manager operation skipped or approximated without
+// failing, such as a systemd property the running systemd does not support
+// or a value clamped by a policy
+type Warning struct {
+	// File is the interface file or systemd property concerned, if any
+	File    string
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.File == "" {
+		return w.Message
+	}
+	return w.File + ": " + w.Message
+}
+
+// Warnings collects the warnings of manager operations, see WithWarnings and
+// WithUpdateWarnings
+type Warnings []Warning
+
+// add appends a warning, it is a no-op on a nil collector so that callers
+// not interested in warnings pay nothing
+func (w *Warnings) add(file, format string, args ...interface{}) {
+	if w == nil {
+		return
+	}
+	*w = append(*w, Warning{
+		File:    file,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,102 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-warnings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings Warnings
+	policy := BoundsPolicy(Bounds{PidsMax: &Range{Max: 100}}, PolicyClamp)
+	m, err := NewManager(mountpoint, "/test", &Resources{Pids: &Pids{Max: 1000}},
+		WithPolicy(policy),
+		WithFileModes(map[string]os.FileMode{"memory.oom.group": 0664}),
+		WithWarnings(&warnings),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"pids.max: 1000 changed to 100 by policy",
+		"memory.oom.group: file mode not applied, the file does not exist",
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected warnings %v, got %v", expected, warnings)
+	}
+	for i, w := range warnings {
+		if w.String() != expected[i] {
+			t.Fatalf("expected warning %q, got %q", expected[i], w)
+		}
+	}
+
+	var updateWarnings Warnings
+	if err := m.Update(&Resources{Pids: &Pids{Max: 50}}, WithUpdateWarnings(&updateWarnings)); err != nil {
+		t.Fatal(err)
+	}
+	if len(updateWarnings) != 0 {
+		t.Fatalf("expected no warnings for an update within the policy, got %v", updateWarnings)
+	}
+	if err := m.Update(&Resources{Pids: &Pids{Max: -1}}, WithUpdateWarnings(&updateWarnings)); err != nil {
+		t.Fatal(err)
+	}
+	if len(updateWarnings) != 1 || updateWarnings[0].String() != "pids.max: max changed to 100 by policy" {
+		t.Fatalf("unexpected update warnings %v", updateWarnings)
+	}
+
+	// the constructor's collector is not kept by the manager
+	var childWarnings Warnings
+	if _, err := m.NewChild("child", &Resources{Pids: &Pids{Max: 1000}}, WithUpdateWarnings(&childWarnings)); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected the constructor's warnings to be unchanged, got %v", warnings)
+	}
+	if len(childWarnings) != 2 || childWarnings[0].String() != "pids.max: 1000 changed to 100 by policy" {
+		t.Fatalf("unexpected child warnings %v", childWarnings)
+	}
+}
+
+func TestWarningsAccountingOnly(t *testing.T) {
+	mountpoint, err := ioutil.TempDir("", "cgroups-warnings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mountpoint)
+	if err := ioutil.WriteFile(filepath.Join(mountpoint, subtreeControl), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var warnings Warnings
+	if _, err := NewManager(mountpoint, "/test", &Resources{Pids: &Pids{Max: 10}}, WithAccountingOnly(), WithWarnings(&warnings)); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].File != "pids.max" {
+		t.Fatalf("expected a warning for the unwritten pids.max, got %v", warnings)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultLimitFiles are the files watched by WatchLimits when none are given
+var DefaultLimitFiles = []string{
+	"memory.max",
+	"memory.high",
+	"memory.swap.max",
+	"cpu.max",
+	"cpu.weight",
+	"cpuset.cpus",
+	"cpuset.mems",
+	"pids.max",
+}
+
+// LimitChange is delivered when the contents of a watched limit file change
+type LimitChange struct {
+	// File is the name of the file, e.g. "memory.max"
+	File string
+	// Old and New are the trimmed contents of the file before and after
+	// the change
+	Old string
+	New string
+}
+
+// WatchLimits watches the limit files of the cgroup and delivers a
+// LimitChange whenever one of them is changed, for example when an external
+// agent resizes the cgroup in place. DefaultLimitFiles are watched when files
+// is empty. Files that do not exist, such as those of controllers not
+// enabled for the cgroup, are skipped.
+//
+// Both channels are closed once ctx is done or an error was delivered.
+func (c *Manager) WatchLimits(ctx context.Context, files []string, opts ...WatchOpts) (<-chan LimitChange, <-chan error) {
+	var (
+		ch    = make(chan LimitChange)
+		errCh = make(chan error, 1)
+	)
+	w, err := c.newLimitWatcher(files, opts)
+	if err != nil {
+		errCh <- err
+		close(ch)
+		close(errCh)
+		return ch, errCh
+	}
+	go func() {
+		defer close(errCh)
+		defer close(ch)
+		defer w.Close()
+		for {
+			changes, err := w.Wait(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					errCh <- err
+				}
+				return
+			}
+			for _, change := range changes {
+				select {
+				case ch <- LimitChange(change):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, errCh
+}
+
+func (c *Manager) newLimitWatcher(files []string, opts []WatchOpts) (*Watcher, error) {
+	if len(files) == 0 {
+		files = DefaultLimitFiles
+	}
+	var existing []string
+	for _, name := range files {
+		if _, err := os.Stat(filepath.Join(c.path, name)); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		existing = append(existing, name)
+	}
+	if len(existing) == 0 {
+		return nil, errors.Errorf("none of the limit files exist in %q", c.path)
+	}
+	return NewWatcher(c.path, existing, opts...)
+}
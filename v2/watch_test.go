@@ -0,0 +1,94 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchLimits(t *testing.T) {
+	testWatchLimits(t)
+}
+
+func TestWatchLimitsPolling(t *testing.T) {
+	testWatchLimits(t, WithPolling(), WithPollInterval(time.Millisecond, 10*time.Millisecond))
+}
+
+func testWatchLimits(t *testing.T, opts ...WatchOpts) {
+	dir, err := ioutil.TempDir("", "cgroups-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "memory.max"), []byte("max\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := &Manager{path: dir}
+	ch, errCh := m.WatchLimits(ctx, nil, opts...)
+
+	// a single write without truncating, like the kernel's update of the
+	// file, so that the watcher cannot see it empty in between
+	f, err := os.OpenFile(filepath.Join(dir, "memory.max"), os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Write([]byte("1048576\n"))
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case change := <-ch:
+		expected := LimitChange{File: "memory.max", Old: "max", New: "1048576"}
+		if change != expected {
+			t.Fatalf("expected %+v, got %+v", expected, change)
+		}
+	case err := <-errCh:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for limit change")
+	}
+
+	cancel()
+	for range ch {
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchLimitsNoFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := &Manager{path: dir}
+	_, errCh := m.WatchLimits(context.Background(), nil)
+	if err := <-errCh; err == nil {
+		t.Fatal("expected an error when no limit files exist")
+	}
+}
@@ -0,0 +1,257 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containerd/cgroups/clock"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	defaultMinPollInterval = 100 * time.Millisecond
+	defaultMaxPollInterval = 5 * time.Second
+)
+
+// WatchOpts configures a Watcher
+type WatchOpts func(*watchConfig)
+
+type watchConfig struct {
+	poll        bool
+	minInterval time.Duration
+	maxInterval time.Duration
+	clock       clock.Clock
+}
+
+// WithPolling makes the watcher poll the files instead of using inotify
+func WithPolling() WatchOpts {
+	return func(c *watchConfig) {
+		c.poll = true
+	}
+}
+
+// WithPollInterval sets the bounds of the adaptive polling interval. Polling
+// starts at min and backs off up to max while nothing changes. With inotify
+// the files are also re-checked every max to catch missed events.
+func WithPollInterval(min, max time.Duration) WatchOpts {
+	return func(c *watchConfig) {
+		c.minInterval = min
+		c.maxInterval = max
+	}
+}
+
+// WithWatchClock sets the clock pacing the polling of the watcher, inotify
+// read deadlines always use the system clock
+func WithWatchClock(c clock.Clock) WatchOpts {
+	return func(config *watchConfig) {
+		config.clock = c
+	}
+}
+
+// FileChange describes a change to the contents of a watched file
+type FileChange struct {
+	// File is the name of the file within the cgroup
+	File string
+	// Old and New are the trimmed contents of the file before and after
+	// the change
+	Old string
+	New string
+}
+
+// Watcher reports changes to files of a cgroup.
+//
+// It uses inotify where possible. If inotify is unavailable or a watch
+// cannot be added the watcher transparently falls back to polling with an
+// adaptive interval. In both modes the file contents are compared so that
+// only real changes are reported, which also covers files for which the
+// kernel does not generate modify events.
+type Watcher struct {
+	path     string
+	config   watchConfig
+	inotify  *os.File
+	interval time.Duration
+	values   map[string]string
+	buffer   []byte
+}
+
+// NewWatcher returns a watcher for the named files in the cgroup at path.
+// All files must exist.
+func NewWatcher(path string, files []string, opts ...WatchOpts) (*Watcher, error) {
+	config := watchConfig{
+		minInterval: defaultMinPollInterval,
+		maxInterval: defaultMaxPollInterval,
+	}
+	for _, o := range opts {
+		o(&config)
+	}
+	config.clock = clock.Or(config.clock)
+	if config.minInterval <= 0 || config.maxInterval < config.minInterval {
+		return nil, errors.Errorf("invalid poll interval %s-%s", config.minInterval, config.maxInterval)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no files to watch")
+	}
+	w := &Watcher{
+		path:     path,
+		config:   config,
+		interval: config.minInterval,
+		values:   make(map[string]string, len(files)),
+	}
+	for _, name := range files {
+		value, err := w.read(name)
+		if err != nil {
+			return nil, err
+		}
+		w.values[name] = value
+	}
+	if !config.poll {
+		w.inotify = watchInotify(path, files)
+		if w.inotify != nil {
+			w.buffer = make([]byte, (unix.SizeofInotifyEvent+unix.NAME_MAX+1)*16)
+		}
+	}
+	return w, nil
+}
+
+// watchInotify returns an inotify file watching all files for modification,
+// or nil if inotify cannot be used for them
+func watchInotify(path string, files []string) *os.File {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil
+	}
+	// the fd is non-blocking so reads go through the runtime poller and
+	// honour read deadlines
+	f := os.NewFile(uintptr(fd), "inotify")
+	for _, name := range files {
+		if _, err := unix.InotifyAddWatch(fd, filepath.Join(path, name), unix.IN_MODIFY); err != nil {
+			f.Close()
+			return nil
+		}
+	}
+	return f
+}
+
+// Polling reports whether the watcher polls instead of using inotify
+func (w *Watcher) Polling() bool {
+	return w.inotify == nil
+}
+
+// Close releases the resources held by the watcher
+func (w *Watcher) Close() error {
+	if w.inotify != nil {
+		return w.inotify.Close()
+	}
+	return nil
+}
+
+// Wait blocks until the contents of one or more files change, returning the
+// changes, or until ctx is done
+func (w *Watcher) Wait(ctx context.Context) ([]FileChange, error) {
+	for {
+		var err error
+		if w.inotify != nil {
+			err = w.waitInotify(ctx)
+		} else {
+			err = w.sleep(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		changes, err := w.check()
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			w.interval = w.config.minInterval
+			return changes, nil
+		}
+		if w.interval *= 2; w.interval > w.config.maxInterval {
+			w.interval = w.config.maxInterval
+		}
+	}
+}
+
+func (w *Watcher) sleep(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.config.clock.After(w.interval):
+		return nil
+	}
+}
+
+// waitInotify waits for an inotify event, or for the maximum poll interval
+// to pass so that the files are re-checked even if an event was missed
+func (w *Watcher) waitInotify(ctx context.Context) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.inotify.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	if err := w.inotify.SetReadDeadline(time.Now().Add(w.config.maxInterval)); err != nil {
+		return err
+	}
+	_, err := w.inotify.Read(w.buffer)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil && !os.IsTimeout(err) {
+		return err
+	}
+	return nil
+}
+
+// check re-reads the files and returns those whose contents differ from the
+// last seen values
+func (w *Watcher) check() ([]FileChange, error) {
+	var changes []FileChange
+	for name, old := range w.values {
+		value, err := w.read(name)
+		if err != nil {
+			return nil, err
+		}
+		if value == old {
+			continue
+		}
+		w.values[name] = value
+		changes = append(changes, FileChange{
+			File: name,
+			Old:  old,
+			New:  value,
+		})
+	}
+	return changes, nil
+}
+
+func (w *Watcher) read(name string) (string, error) {
+	data, err := readBounded(filepath.Join(w.path, name))
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(data)), nil
+}
@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherPollingBackoff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "pids.max"), []byte("max\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(dir, []string{"pids.max"}, WithPolling(), WithPollInterval(time.Millisecond, 8*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if !w.Polling() {
+		t.Fatal("expected the watcher to poll")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := w.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline to be exceeded, got %v", err)
+	}
+	if w.interval != 8*time.Millisecond {
+		t.Fatalf("expected interval to back off to the maximum, got %s", w.interval)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "pids.max"), []byte("10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := w.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0] != (FileChange{File: "pids.max", Old: "max", New: "10"}) {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	if w.interval != time.Millisecond {
+		t.Fatalf("expected interval to reset after a change, got %s", w.interval)
+	}
+}
+
+func TestWatcherInotifyCancel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroups-watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "pids.max"), []byte("max\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(dir, []string{"pids.max"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.Polling() {
+		t.Skip("inotify is not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := w.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestNewWatcherMissingFile(t *testing.T) {
+	if _, err := NewWatcher(os.TempDir(), []string{"does-not-exist"}); !os.IsNotExist(err) {
+		t.Fatalf("expected not exist error, got %v", err)
+	}
+}
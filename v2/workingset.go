@@ -0,0 +1,60 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+// WorkingSetMode selects how WorkingSet derives the memory in active use
+// from memory.current, which includes page cache the kernel can reclaim
+type WorkingSetMode int
+
+const (
+	// WorkingSetInactiveFile subtracts the inactive file pages from the
+	// usage, matching the kubelet and cAdvisor working set used for
+	// eviction decisions
+	WorkingSetInactiveFile WorkingSetMode = iota
+	// WorkingSetNoCache subtracts the whole page cache from the usage,
+	// leaving the anonymous and kernel memory
+	WorkingSetNoCache
+	// WorkingSetUsage returns memory.current unchanged
+	WorkingSetUsage
+)
+
+// WorkingSet returns the working set of the cgroup in bytes computed from
+// its metrics using the mode
+func WorkingSet(m *stats.Metrics, mode WorkingSetMode) uint64 {
+	if m == nil || m.Memory == nil {
+		return 0
+	}
+	usage := m.Memory.Usage
+	switch mode {
+	case WorkingSetInactiveFile:
+		return saturatingSub(usage, m.Memory.InactiveFile)
+	case WorkingSetNoCache:
+		return saturatingSub(usage, m.Memory.File)
+	}
+	return usage
+}
+
+func saturatingSub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
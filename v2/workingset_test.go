@@ -0,0 +1,46 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+
+	"github.com/containerd/cgroups/v2/stats"
+)
+
+func TestWorkingSet(t *testing.T) {
+	m := &stats.Metrics{
+		Memory: &stats.MemoryStat{
+			Usage:        100,
+			InactiveFile: 30,
+			File:         60,
+		},
+	}
+	for mode, expected := range map[WorkingSetMode]uint64{
+		WorkingSetInactiveFile: 70,
+		WorkingSetNoCache:      40,
+		WorkingSetUsage:        100,
+	} {
+		if ws := WorkingSet(m, mode); ws != expected {
+			t.Errorf("mode %d: expected %d, got %d", mode, expected, ws)
+		}
+	}
+	m.Memory.InactiveFile = 200
+	if ws := WorkingSet(m, WorkingSetInactiveFile); ws != 0 {
+		t.Fatalf("working set must not underflow, got %d", ws)
+	}
+}
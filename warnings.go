@@ -0,0 +1,50 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"fmt"
+)
+
+// Warning is something creating a cgroup skipped without failing, such as a
+// subsystem that is not active on the host
+type Warning struct {
+	// Subsystem is the subsystem concerned, if any
+	Subsystem Name
+	Message   string
+}
+
+func (w Warning) String() string {
+	if w.Subsystem == "" {
+		return w.Message
+	}
+	return string(w.Subsystem) + ": " + w.Message
+}
+
+// Warnings collects the warnings of creating a cgroup, see WithWarnings
+type Warnings []Warning
+
+// add appends a warning, it is a no-op on a nil collector
+func (w *Warnings) add(subsystem Name, format string, args ...interface{}) {
+	if w == nil {
+		return
+	}
+	*w = append(*w, Warning{
+		Subsystem: subsystem,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,61 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cgroups
+
+import (
+	v1 "github.com/containerd/cgroups/stats/v1"
+)
+
+// WorkingSetMode selects how WorkingSet derives the memory in active use
+// from the raw usage, which includes page cache the kernel can reclaim
+type WorkingSetMode int
+
+const (
+	// WorkingSetInactiveFile subtracts the inactive file pages from the
+	// usage, matching the kubelet and cAdvisor working set used for
+	// eviction decisions
+	WorkingSetInactiveFile WorkingSetMode = iota
+	// WorkingSetNoCache subtracts the whole page cache from the usage,
+	// leaving the anonymous and kernel memory
+	WorkingSetNoCache
+	// WorkingSetUsage returns the raw usage_in_bytes
+	WorkingSetUsage
+)
+
+// WorkingSet returns the working set of the cgroup in bytes computed from
+// its metrics using the mode. The hierarchical total_* counters are used so
+// that the result covers the cgroup's descendants like usage_in_bytes does.
+func WorkingSet(m *v1.Metrics, mode WorkingSetMode) uint64 {
+	if m == nil || m.Memory == nil || m.Memory.Usage == nil {
+		return 0
+	}
+	usage := m.Memory.Usage.Usage
+	switch mode {
+	case WorkingSetInactiveFile:
+		return saturatingSub(usage, m.Memory.TotalInactiveFile)
+	case WorkingSetNoCache:
+		return saturatingSub(usage, m.Memory.TotalCache)
+	}
+	return usage
+}
+
+func saturatingSub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}